@@ -2,6 +2,7 @@ package uuid
 
 import (
     "crypto/rand"
+    "encoding/hex"
     "fmt"
     "io"
 )
@@ -10,15 +11,17 @@ import (
 // https://groups.google.com/d/msg/golang-nuts/owCogizIuZs/CQzU4nLdu14J. Taking
 // definitive def to be this one: http://tools.ietf.org/rfc/rfc4122.txt, to
 // which the algorithm below is faithful
-func Type4() ( string, error ) {
-    b := make( []byte, 16 )
-    _, err := io.ReadFull( rand.Reader, b )
-    if err != nil { return "", err }
+func Type4Bytes() ( b [ 16 ]byte, err error ) {
+    if _, err = io.ReadFull( rand.Reader, b[ : ] ); err != nil { return }
     b[ 6 ] = ( b[ 6 ] & 0x0f ) | 0x40
     b[ 8 ] = ( b[ 8 ] &^ 0x40 ) | 0x80
-    res := fmt.Sprintf( "%x-%x-%x-%x-%x", 
-        b[ : 4 ], b[ 4 : 6 ], b[ 6 : 8 ], b[ 8 : 10 ], b[ 10 : ] )
-    return res, nil
+    return
+}
+
+func Type4() ( string, error ) {
+    b, err := Type4Bytes()
+    if err != nil { return "", err }
+    return Format( b ), nil
 }
 
 func MustType4() string {
@@ -26,3 +29,29 @@ func MustType4() string {
     if err != nil { panic( err ) }
     return res
 }
+
+// Returns the canonical, lower-case form of b
+func Format( b [ 16 ]byte ) string {
+    return fmt.Sprintf( "%x-%x-%x-%x-%x", 
+        b[ : 4 ], b[ 4 : 6 ], b[ 6 : 8 ], b[ 8 : 10 ], b[ 10 : ] )
+}
+
+type ParseError struct { Input string }
+
+func ( e *ParseError ) Error() string {
+    return fmt.Sprintf( "invalid uuid: %q", e.Input )
+}
+
+// Parses the hyphenated form returned by Format(), accepting hex digits in
+// either case
+func Parse( s string ) ( b [ 16 ]byte, err error ) {
+    if len( s ) != 36 { return b, &ParseError{ s } }
+    for _, i := range []int{ 8, 13, 18, 23 } {
+        if s[ i ] != '-' { return b, &ParseError{ s } }
+    }
+    digs := s[ : 8 ] + s[ 9 : 13 ] + s[ 14 : 18 ] + s[ 19 : 23 ] + s[ 24 : ]
+    if _, err = hex.Decode( b[ : ], []byte( digs ) ); err != nil {
+        return b, &ParseError{ s }
+    }
+    return b, nil
+}
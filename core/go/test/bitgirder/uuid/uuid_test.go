@@ -33,3 +33,33 @@ func TestType4Sanity( t *testing.T ) {
     id2 := MustType4()
     if id1 == id2 { t.Fatalf( "id1 and id2 are both %s", id1 ) }
 }
+
+func TestParseFormat( t *testing.T ) {
+    id := MustType4()
+    b, err := Parse( id )
+    if err != nil { t.Fatal( err ) }
+    if s := Format( b ); s != id { t.Fatalf( "got %s, expected %s", s, id ) }
+    upper := "A0B1C2D3-E4F5-4617-8899-AABBCCDDEEFF"
+    if b, err = Parse( upper ); err != nil { t.Fatal( err ) }
+    expct := "a0b1c2d3-e4f5-4617-8899-aabbccddeeff"
+    if s := Format( b ); s != expct {
+        t.Fatalf( "got %s, expected %s", s, expct )
+    }
+}
+
+func TestParseFail( t *testing.T ) {
+    for _, s := range []string {
+        "",
+        "a0b1c2d3e4f5461788 99aabbccddeeff",
+        "a0b1c2d3-e4f5-4617-8899-aabbccddeef",
+        "a0b1c2d3-e4f5-4617-8899-aabbccddeeffa",
+        "a0b1c2d3-e4f5-4617-8899_aabbccddeeff",
+        "a0b1c2d3-e4f5-4617-8899-aabbccddeefg",
+        "{a0b1c2d3-e4f5-4617-8899-aabbccddeeff}",
+    } {
+        _, err := Parse( s )
+        if pe, ok := err.( *ParseError ); ! ok || pe.Input != s {
+            t.Fatalf( "expected parse error for %q, got: %v", s, err )
+        }
+    }
+}
@@ -5,6 +5,7 @@ import (
     mgRct "mingle/reactor"
     "mingle/parser"
     "mingle/types"
+    "mingle/compiler/interp"
     "bitgirder/objpath"
     "bitgirder/pipeline"
     "bitgirder/stack"
@@ -12,6 +13,9 @@ import (
     "fmt"
     "bytes"
     "strings"
+    "strconv"
+    "math"
+    "math/big"
     "encoding/base64"
 )
 
@@ -96,6 +100,14 @@ func ( vt valueFieldTyper ) fieldTypeFor(
     return mg.TypeNullableValue, nil
 }
 
+// types the fields of a map of type mt
+type mapFieldTyper struct { mt *mg.MapTypeReference }
+
+func ( ft mapFieldTyper ) fieldTypeFor( 
+    fld *mg.Identifier, path objpath.PathNode ) ( mg.TypeReference, error ) {
+    return ft.mt.ValueType, nil
+}
+
 type SymbolMapFieldSetGetter interface {
     GetFieldSet( path objpath.PathNode ) ( *types.FieldSet, error )
 }
@@ -103,6 +115,10 @@ type SymbolMapFieldSetGetter interface {
 type TypeErrorFormatter func( 
     expct, act mg.TypeReference, path objpath.PathNode ) ( error, bool )
 
+// called with the path of each deprecated field or enum value seen during a
+// cast and the message, possibly empty, with which it was deprecated
+type DeprecationHandler func( path objpath.PathNode, msg string )
+
 type Reactor struct {
 
     dm types.DefinitionGetter
@@ -119,6 +135,8 @@ type Reactor struct {
 
     FormatTypeError TypeErrorFormatter
 
+    HandleDeprecation DeprecationHandler
+
     SkipPathSetter bool
 }
 
@@ -232,6 +250,34 @@ type fieldCast struct {
     ft fieldTyper
     await *mg.IdentifierMap
     passFields *mg.IdentifierMap
+    constraints []*types.StructConstraint
+    vals *mg.IdentifierMap // set only when there are constraints to check
+    curFld *mg.Identifier
+}
+
+// records the value of the field most recently started when the cast produces
+// an atomic value; a value cast to a struct, such as by way of a constructor,
+// instead starts with a struct event and is left recorded as nil
+func ( fc *fieldCast ) valueRecorder( 
+    next mgRct.EventProcessor ) mgRct.EventProcessor {
+
+    first := true
+    return mgRct.EventProcessorFunc( func( ev mgRct.Event ) error {
+        if ve, ok := ev.( *mgRct.ValueEvent ); ok && first { 
+            fc.vals.Put( fc.curFld, ve.Val ) 
+        }
+        first = false
+        return next.ProcessEvent( ev )
+    })
+}
+
+func ( fc *fieldCast ) checkConstraints( p objpath.PathNode ) error {
+    for _, sc := range fc.constraints {
+        ok, err := interp.CheckStructConstraint( sc, fc.vals )
+        if err != nil { return mg.NewInputError( p, err.Error() ) }
+        if ! ok { return mg.NewInputError( p, sc.FailureMessage() ) }
+    }
+    return nil
 }
 
 func ( fc *fieldCast ) isPassthroughField( fld *mg.Identifier ) bool {
@@ -280,6 +326,7 @@ func processDefaults(
                 return err 
             }
             fc.await.Delete( fld )
+            if fc.vals != nil { fc.vals.Put( fld, defl ) }
         }
         return nil
     }
@@ -295,7 +342,7 @@ func createMissingFieldsError( p objpath.PathNode, fc *fieldCast ) error {
 }
 
 type listCast struct {
-    sawValues bool
+    count int
     lt *mg.ListTypeReference
     startPath objpath.PathNode
 }
@@ -353,7 +400,8 @@ func ( c atomicCastCall ) castString() ( mg.Value, error ) {
     switch v := c.val().( type ) {
     case mg.String: return v, nil
     case mg.Boolean, mg.Int32, mg.Int64, mg.Uint32, mg.Uint64, mg.Float32, 
-         mg.Float64:
+         mg.Float64, mg.BigInt, mg.Decimal, mg.Date, mg.LocalTime, 
+         mg.Duration, mg.Uuid, mg.Uri:
         return mg.String( v.( fmt.Stringer ).String() ), nil
     case mg.Timestamp: return mg.String( v.Rfc3339Nano() ), nil
     case mg.Buffer:
@@ -372,14 +420,18 @@ func ( c atomicCastCall ) parseNumberForCast(
     s mg.String ) ( mg.Value, error ) {
 
     numTyp := c.at.Name()
-    asFloat := mg.IsIntegerTypeName( numTyp ) && isDecimalNumString( s )
     parseTyp := numTyp
-    if asFloat { parseTyp = mg.QnameFloat64 }
+    if mg.IsIntegerTypeName( numTyp ) && isDecimalNumString( s ) {
+        // BigInt goes through Decimal so that no precision is lost
+        parseTyp = mg.QnameFloat64
+        if numTyp.Equals( mg.QnameBigInt ) { parseTyp = mg.QnameDecimal }
+    }
     val, err := mg.ParseNumber( string( s ), parseTyp )
     if ne, ok := err.( *mg.NumberFormatError ); ok {
         err = mg.NewInputError( c.path(), ne.Error() )
     }
-    if err != nil || ( ! asFloat ) { return val, err }
+    if err != nil || parseTyp == numTyp { return val, err }
+    if d, ok := val.( mg.Decimal ); ok { return d.Truncate(), nil }
     f64 := float64( val.( mg.Float64 ) )
     switch {
     case numTyp.Equals( mg.QnameInt32 ): val = mg.Int32( int32( f64 ) )
@@ -398,6 +450,7 @@ func ( c atomicCastCall ) castInt32() ( mg.Value, error ) {
     case mg.Uint64: return mg.Int32( int32( v ) ), nil
     case mg.Float32: return mg.Int32( int32( v ) ), nil
     case mg.Float64: return mg.Int32( int32( v ) ), nil
+    case mg.BigInt, mg.Decimal: return c.castBigNumber( v )
     case mg.String: return c.parseNumberForCast( v )
     }
     return nil, c.newTypeInputErrorValue()
@@ -411,6 +464,7 @@ func ( c atomicCastCall ) castInt64() ( mg.Value, error ) {
     case mg.Uint64: return mg.Int64( int64( v ) ), nil
     case mg.Float32: return mg.Int64( int64( v ) ), nil
     case mg.Float64: return mg.Int64( int64( v ) ), nil
+    case mg.BigInt, mg.Decimal: return c.castBigNumber( v )
     case mg.String: return c.parseNumberForCast( v )
     }
     return nil, c.newTypeInputErrorValue()
@@ -433,6 +487,7 @@ func ( c atomicCastCall ) castUint32() ( mg.Value, error ) {
     case mg.Uint64: return mg.Uint32( uint32( v ) ), nil
     case mg.Float32: return c.castCheckNeg( v < 0, v, mg.Uint32( uint32( v ) ) )
     case mg.Float64: return c.castCheckNeg( v < 0, v, mg.Uint32( uint32( v ) ) )
+    case mg.BigInt, mg.Decimal: return c.castBigNumber( v )
     case mg.String: return c.parseNumberForCast( v )
     }
     return nil, c.newTypeInputErrorValue()
@@ -446,6 +501,7 @@ func ( c atomicCastCall ) castUint64() ( mg.Value, error ) {
     case mg.Uint64: return v, nil
     case mg.Float32: return c.castCheckNeg( v < 0, v, mg.Uint64( uint64( v ) ) )
     case mg.Float64: return c.castCheckNeg( v < 0, v, mg.Uint64( uint64( v ) ) )
+    case mg.BigInt, mg.Decimal: return c.castBigNumber( v )
     case mg.String: return c.parseNumberForCast( v )
     }
     return nil, c.newTypeInputErrorValue()
@@ -459,6 +515,7 @@ func ( c atomicCastCall ) castFloat32() ( mg.Value, error ) {
     case mg.Uint64: return mg.Float32( float32( v ) ), nil
     case mg.Float32: return v, nil
     case mg.Float64: return mg.Float32( float32( v ) ), nil
+    case mg.BigInt, mg.Decimal: return c.castBigNumber( v )
     case mg.String: return c.parseNumberForCast( v )
     }
     return nil, c.newTypeInputErrorValue()
@@ -472,6 +529,96 @@ func ( c atomicCastCall ) castFloat64() ( mg.Value, error ) {
     case mg.Uint64: return mg.Float64( float64( v ) ), nil
     case mg.Float32: return mg.Float64( float64( v ) ), nil
     case mg.Float64: return v, nil
+    case mg.BigInt, mg.Decimal: return c.castBigNumber( v )
+    case mg.String: return c.parseNumberForCast( v )
+    }
+    return nil, c.newTypeInputErrorValue()
+}
+
+// BigInt and Decimal values are cast to the fixed-size integer types exactly,
+// with a Decimal first truncated toward zero, and to the floating point types
+// through their string forms
+func ( c atomicCastCall ) castBigNumber( v mg.Value ) ( mg.Value, error ) {
+    numTyp := c.at.Name()
+    if ! mg.IsIntegerTypeName( numTyp ) {
+        return c.parseNumberForCast( mg.String( v.( fmt.Stringer ).String() ) )
+    }
+    i, ok := asBigInt( v )
+    if ! ok { i = v.( mg.Decimal ).Truncate().Int() }
+    switch {
+    case numTyp.Equals( mg.QnameInt32 ):
+        if i.IsInt64() {
+            if n := i.Int64(); n >= math.MinInt32 && n <= math.MaxInt32 {
+                return mg.Int32( int32( n ) ), nil
+            }
+        }
+    case numTyp.Equals( mg.QnameInt64 ):
+        if i.IsInt64() { return mg.Int64( i.Int64() ), nil }
+    case numTyp.Equals( mg.QnameUint32 ):
+        if i.IsUint64() && i.Uint64() <= math.MaxUint32 {
+            return mg.Uint32( uint32( i.Uint64() ) ), nil
+        }
+    case numTyp.Equals( mg.QnameUint64 ):
+        if i.IsUint64() { return mg.Uint64( i.Uint64() ), nil }
+    default: return mg.NewBigInt( i ), nil
+    }
+    return nil, mg.NewInputErrorf( c.path(), "value out of range: %s", v )
+}
+
+// returns the value of val as a big.Int if val is of an integer type
+func asBigInt( val mg.Value ) ( *big.Int, bool ) {
+    switch v := val.( type ) {
+    case mg.Int32: return big.NewInt( int64( v ) ), true
+    case mg.Int64: return big.NewInt( int64( v ) ), true
+    case mg.Uint32: return big.NewInt( int64( v ) ), true
+    case mg.Uint64: return new( big.Int ).SetUint64( uint64( v ) ), true
+    case mg.BigInt: return v.Int(), true
+    }
+    return nil, false
+}
+
+func ( c atomicCastCall ) checkFinite( f float64, in mg.Value ) error {
+    if math.IsInf( f, 0 ) || math.IsNaN( f ) {
+        return mg.NewInputErrorf( c.path(), "value out of range: %s", in )
+    }
+    return nil
+}
+
+func ( c atomicCastCall ) floatToBigInt( 
+    f float64, in mg.Value ) ( mg.Value, error ) {
+
+    if err := c.checkFinite( f, in ); err != nil { return nil, err }
+    i, _ := big.NewFloat( f ).Int( nil )
+    return mg.NewBigInt( i ), nil
+}
+
+func ( c atomicCastCall ) castBigInt() ( mg.Value, error ) {
+    if i, ok := asBigInt( c.val() ); ok { return mg.NewBigInt( i ), nil }
+    switch v := c.val().( type ) {
+    case mg.Float32: return c.floatToBigInt( float64( v ), v )
+    case mg.Float64: return c.floatToBigInt( float64( v ), v )
+    case mg.Decimal: return v.Truncate(), nil
+    case mg.String: return c.parseNumberForCast( v )
+    }
+    return nil, c.newTypeInputErrorValue()
+}
+
+// uses the shortest decimal string that reads back as f, so that a Float32 1.1
+// becomes 1.1 and not 1.10000002384185791015625
+func ( c atomicCastCall ) floatToDecimal( 
+    f float64, bitSize int, in mg.Value ) ( mg.Value, error ) {
+
+    if err := c.checkFinite( f, in ); err != nil { return nil, err }
+    s := strconv.FormatFloat( f, 'g', -1, bitSize )
+    return c.parseNumberForCast( mg.String( s ) )
+}
+
+func ( c atomicCastCall ) castDecimal() ( mg.Value, error ) {
+    if i, ok := asBigInt( c.val() ); ok { return mg.NewDecimal( i, 0 ), nil }
+    switch v := c.val().( type ) {
+    case mg.Float32: return c.floatToDecimal( float64( v ), 32, v )
+    case mg.Float64: return c.floatToDecimal( float64( v ), 64, v )
+    case mg.Decimal: return v, nil
     case mg.String: return c.parseNumberForCast( v )
     }
     return nil, c.newTypeInputErrorValue()
@@ -489,6 +636,66 @@ func ( c atomicCastCall ) castTimestamp() ( mg.Value, error ) {
     return nil, c.newTypeInputErrorValue()
 }
 
+func ( c atomicCastCall ) castDate() ( mg.Value, error ) {
+    switch v := c.val().( type ) {
+    case mg.Date: return v, nil
+    case mg.String:
+        d, err := parser.ParseDate( string( v ) )
+        if err == nil { return d, nil }
+        msg := "Invalid date: %s"
+        return nil, mg.NewInputErrorf( c.path(), msg, err.Error() )
+    }
+    return nil, c.newTypeInputErrorValue()
+}
+
+func ( c atomicCastCall ) castLocalTime() ( mg.Value, error ) {
+    switch v := c.val().( type ) {
+    case mg.LocalTime: return v, nil
+    case mg.String:
+        t, err := parser.ParseLocalTime( string( v ) )
+        if err == nil { return t, nil }
+        msg := "Invalid local time: %s"
+        return nil, mg.NewInputErrorf( c.path(), msg, err.Error() )
+    }
+    return nil, c.newTypeInputErrorValue()
+}
+
+func ( c atomicCastCall ) castDuration() ( mg.Value, error ) {
+    switch v := c.val().( type ) {
+    case mg.Duration: return v, nil
+    case mg.String:
+        d, err := parser.ParseDuration( string( v ) )
+        if err == nil { return d, nil }
+        msg := "Invalid duration: %s"
+        return nil, mg.NewInputErrorf( c.path(), msg, err.Error() )
+    }
+    return nil, c.newTypeInputErrorValue()
+}
+
+func ( c atomicCastCall ) castUuid() ( mg.Value, error ) {
+    switch v := c.val().( type ) {
+    case mg.Uuid: return v, nil
+    case mg.String:
+        u, err := parser.ParseUuid( string( v ) )
+        if err == nil { return u, nil }
+        msg := "Invalid uuid: %s"
+        return nil, mg.NewInputErrorf( c.path(), msg, err.Error() )
+    }
+    return nil, c.newTypeInputErrorValue()
+}
+
+func ( c atomicCastCall ) castUri() ( mg.Value, error ) {
+    switch v := c.val().( type ) {
+    case mg.Uri: return v, nil
+    case mg.String:
+        u, err := parser.ParseUri( string( v ) )
+        if err == nil { return u, nil }
+        msg := "Invalid uri: %s"
+        return nil, mg.NewInputErrorf( c.path(), msg, err.Error() )
+    }
+    return nil, c.newTypeInputErrorValue()
+}
+
 func ( c atomicCastCall ) castSymbolMap() ( mg.Value, error ) {
     switch v := c.val().( type ) {
     case *mg.SymbolMap: return v, nil
@@ -516,7 +723,14 @@ func ( c atomicCastCall ) castAtomicUnrestricted() ( mg.Value, error ) {
     case nm.Equals( mg.QnameUint64 ): return c.castUint64()
     case nm.Equals( mg.QnameFloat32 ): return c.castFloat32()
     case nm.Equals( mg.QnameFloat64 ): return c.castFloat64()
+    case nm.Equals( mg.QnameBigInt ): return c.castBigInt()
+    case nm.Equals( mg.QnameDecimal ): return c.castDecimal()
     case nm.Equals( mg.QnameTimestamp ): return c.castTimestamp()
+    case nm.Equals( mg.QnameDate ): return c.castDate()
+    case nm.Equals( mg.QnameLocalTime ): return c.castLocalTime()
+    case nm.Equals( mg.QnameDuration ): return c.castDuration()
+    case nm.Equals( mg.QnameUuid ): return c.castUuid()
+    case nm.Equals( mg.QnameUri ): return c.castUri()
     case nm.Equals( mg.QnameSymbolMap ): return c.castSymbolMap()
     }
     return nil, c.newTypeInputErrorValue()
@@ -612,7 +826,7 @@ func ( cr *Reactor ) completeCastEnum(
     ed *types.EnumDefinition, 
     path objpath.PathNode ) ( *mg.Enum, error ) {
 
-    if res := ed.GetValue( id ); res != nil { return res, nil }
+    if res := ed.ResolveValue( id ); res != nil { return res, nil }
     tmpl := "illegal value for enum %s: %s"
     return nil, mg.NewInputErrorf( path, tmpl, ed.GetName(), id )
 }
@@ -630,6 +844,22 @@ func ( cr *Reactor ) castEnumFromString(
     return cr.completeCastEnum( id, ed, path )
 }
 
+func ( cr *Reactor ) castEnumFromOrdinal(
+    ord int64,
+    ed *types.EnumDefinition,
+    path objpath.PathNode ) ( *mg.Enum, error ) {
+
+    if ord >= math.MinInt32 && ord <= math.MaxInt32 {
+        if res := ed.GetValueForOrdinal( int32( ord ) ); res != nil { 
+            return res, nil 
+        }
+    }
+    tmpl := "illegal ordinal for enum %s: %d"
+    return nil, mg.NewInputErrorf( path, tmpl, ed.GetName(), ord )
+}
+
+// integer values are accepted only for enums declaring explicit ordinals, so
+// that casting an integer to any other enum remains a type error
 func ( cr *Reactor ) castEnum( 
     val mg.Value, 
     ed *types.EnumDefinition, 
@@ -642,10 +872,33 @@ func ( cr *Reactor ) castEnum(
             return cr.completeCastEnum( v.Value, ed, path )
         }
     }
+    if len( ed.Ordinals ) > 0 {
+        switch v := val.( type ) {
+        case mg.Int32: return cr.castEnumFromOrdinal( int64( v ), ed, path )
+        case mg.Int64: return cr.castEnumFromOrdinal( int64( v ), ed, path )
+        case mg.Uint32: return cr.castEnumFromOrdinal( int64( v ), ed, path )
+        case mg.Uint64: 
+            if v <= math.MaxInt32 {
+                return cr.castEnumFromOrdinal( int64( v ), ed, path )
+            }
+            tmpl := "illegal ordinal for enum %s: %d"
+            return nil, mg.NewInputErrorf( path, tmpl, ed.GetName(), v )
+        }
+    }
     t := ed.GetName().AsAtomicType()
     return nil, cr.newTypeInputErrorValue( t, val, path )
 }
 
+func ( cr *Reactor ) checkEnumDeprecation( 
+    ed *types.EnumDefinition, en *mg.Enum, path objpath.PathNode ) {
+
+    if cr.HandleDeprecation == nil { return }
+    annots := ed.GetValueAnnotations( en.Value )
+    if msg, ok := types.DeprecationMessage( annots ); ok { 
+        cr.HandleDeprecation( path, msg ) 
+    }
+}
+
 func ( cr *Reactor ) castAtomicForDefinition(
     v mg.Value,
     at *mg.AtomicTypeReference,
@@ -655,6 +908,7 @@ func ( cr *Reactor ) castAtomicForDefinition(
         switch td := def.( type ) {
         case *types.EnumDefinition:
             res, err := cr.castEnum( v, td, path )
+            if err == nil { cr.checkEnumDeprecation( td, res, path ) }
             return res, err, true
         case *types.StructDefinition: 
             return cr.castStructConstructor( v, td, path )
@@ -698,9 +952,10 @@ func ( cr *Reactor ) processNullableValue(
     return cr.processValueWithType( ve, nt.Type, callTyp, next )
 }
 
-func ( cr *Reactor ) processValueForListType(
+// handles a value where typ, a list or map type, expects a container
+func ( cr *Reactor ) processValueForContainerType(
     ve *mgRct.ValueEvent,
-    typ *mg.ListTypeReference,
+    typ mg.TypeReference,
     callTyp mg.TypeReference,
     next mgRct.EventProcessor ) error {
 
@@ -722,6 +977,40 @@ func ( cr *Reactor ) matchUnionDefType(
     return ut.MatchType( typ )
 }
 
+type taggedUnionFieldTyper struct { ut *types.UnionTypeDefinition }
+
+func ( ft taggedUnionFieldTyper ) fieldTypeFor(
+    fld *mg.Identifier, path objpath.PathNode ) ( mg.TypeReference, error ) {
+
+    if typ, ok := ft.ut.MatchTag( fld ); ok { return typ, nil }
+    return nil, mg.NewUnrecognizedFieldError( path, fld )
+}
+
+// a tagged union value is a map holding its value under a single field named
+// by the tag of the member type to which the value is cast; the map itself is
+// preserved in the output
+func ( cr *Reactor ) getTaggedUnionApplication(
+    ev mgRct.Event,
+    ud *types.UnionDefinition,
+    next mgRct.EventProcessor ) func() error {
+
+    me, ok := ev.( *mgRct.MapStartEvent )
+    if ! ok { return nil }
+    cstr := &types.StructConstraint{
+        Expression: &types.FieldPresence{
+            Rule: types.PresenceExactlyOne,
+            Fields: ud.Union.Tags,
+        },
+        Message: fmt.Sprintf( "expected exactly one tag for union %s", 
+            ud.Name ),
+    }
+    cstrs := []*types.StructConstraint{ cstr }
+    ft := taggedUnionFieldTyper{ ud.Union }
+    return func() error { 
+        return cr.implMapStart( me, ft, nil, nil, cstrs, next ) 
+    }
+}
+
 func ( cr *Reactor ) getUnionApplication(
     ev mgRct.Event,
     at *mg.AtomicTypeReference,
@@ -729,6 +1018,9 @@ func ( cr *Reactor ) getUnionApplication(
 
     if def, ok := cr.dm.GetDefinition( at.Name() ); ok {
         if ud, ok := def.( *types.UnionDefinition ); ok {
+            if ud.Union.IsTagged() { 
+                return cr.getTaggedUnionApplication( ev, ud, next )
+            }
             if mtch, ok := cr.matchUnionDefType( ev, ud ); ok {
                 return func() error { 
                     cr.pushType( mtch )
@@ -754,8 +1046,8 @@ func ( cr *Reactor ) processValueWithType(
         return cr.processValueWithType( ve, v.Type, callTyp, next )
     case *mg.NullableTypeReference:
         return cr.processNullableValue( ve, v, callTyp, next )
-    case *mg.ListTypeReference:
-        return cr.processValueForListType( ve, v, callTyp, next )
+    case *mg.ListTypeReference, *mg.MapTypeReference:
+        return cr.processValueForContainerType( ve, v, callTyp, next )
     }
     panic( libErrorf( "unhandled type: %T", typ ) )
 }
@@ -767,9 +1059,12 @@ func ( cr *Reactor ) processValue(
     switch v := cr.stack.Peek().( type ) {
     case mg.TypeReference: 
         cr.stack.Pop()
+        if fc, ok := cr.stack.Peek().( *fieldCast ); ok && fc.vals != nil {
+            next = fc.valueRecorder( next )
+        }
         return cr.processValueWithType( ve, v, v, next )
     case *listCast:
-        v.sawValues = true
+        v.count++
         typ := v.lt.ElementType
         return cr.processValueWithType( ve, typ, typ, next )
     }
@@ -781,9 +1076,11 @@ func ( cr *Reactor ) implMapStart(
     ft fieldTyper, 
     fs *types.FieldSet,
     passFields *mg.IdentifierMap,
+    cstrs []*types.StructConstraint,
     next mgRct.EventProcessor ) error {
 
-    fc := &fieldCast{ ft: ft, passFields: passFields }
+    fc := &fieldCast{ ft: ft, passFields: passFields, constraints: cstrs }
+    if len( cstrs ) > 0 { fc.vals = mg.NewIdentifierMap() }
     if fs != nil {
         fc.await = mg.NewIdentifierMap()
         fs.EachDefinition( func( fd *types.FieldDefinition) {
@@ -849,13 +1146,15 @@ func ( cr *Reactor ) completeStartStruct(
     var ev mgRct.Event = ss
     fs, err := fieldSetForTypeInDefMap( ss.Type, cr.dm, ss.GetPath() )
     if err != nil { return err }
+    var cstrs []*types.StructConstraint
     if def, ok := cr.dm.GetDefinition( ss.Type ); ok {
-        if _, ok := def.( *types.SchemaDefinition ); ok { 
-            ev = asMapStartEvent( ss ) 
+        switch v := def.( type ) {
+        case *types.SchemaDefinition: ev = asMapStartEvent( ss )
+        case *types.StructDefinition: cstrs = v.Constraints
         }
     } 
     pf := cr.passFieldsForQn( ss.Type )
-    return cr.implMapStart( ev, ft, fs, pf, next )
+    return cr.implMapStart( ev, ft, fs, pf, cstrs, next )
 }
 
 func ( cr *Reactor ) inferStructForQname( qn *mg.QualifiedTypeName ) bool {
@@ -894,7 +1193,7 @@ func ( cr *Reactor ) processMapStartWithAtomicType(
             if err != nil { return err }
             if fs != nil { ft = &fieldSetTyper{ flds: fs, dm: cr.dm } }
         }
-        return cr.implMapStart( me, ft, fs, nil, next )
+        return cr.implMapStart( me, ft, fs, nil, nil, next )
     }
 
     if err, ok := cr.inferStructForMap( me, at, next ); ok { return err }
@@ -916,6 +1215,9 @@ func ( cr *Reactor ) processMapStartWithType(
         return cr.processMapStartWithType( me, v.Type, callTyp, next )
     case *mg.NullableTypeReference:
         return cr.processMapStartWithType( me, v.Type, callTyp, next )
+    case *mg.MapTypeReference:
+        ft := mapFieldTyper{ v }
+        return cr.implMapStart( me, ft, nil, nil, nil, next )
     }
     return cr.newTypeInputError( callTyp, typ, me.GetPath() )
 }
@@ -928,24 +1230,44 @@ func ( cr *Reactor ) processMapStart(
         cr.stack.Pop()
         return cr.processMapStartWithType( me, v, v, next )
     case *listCast:
-        v.sawValues = true
+        v.count++
         typ := v.lt.ElementType
         return cr.processMapStartWithType( me, typ, typ, next )
     }
     panic( cr.errStackUnrecognized() )
 }
 
+// only fields typed by a field set, such as those of a struct, carry the
+// annotations by which a field is deprecated
+func ( cr *Reactor ) checkFieldDeprecation( 
+    fc *fieldCast, fs *mgRct.FieldStartEvent ) {
+
+    if cr.HandleDeprecation == nil { return }
+    ft, ok := fc.ft.( *fieldSetTyper )
+    if ! ok { return }
+    if fd := ft.flds.Get( fs.Field ); fd != nil {
+        if msg, ok := types.DeprecationMessage( fd.Annotations ); ok {
+            cr.HandleDeprecation( fs.GetPath(), msg )
+        }
+    }
+}
+
 func ( cr *Reactor ) processFieldStart(
     fs *mgRct.FieldStartEvent, next mgRct.EventProcessor ) error {
 
     fc := cr.stack.Peek().( *fieldCast )
     if fc.await != nil { fc.await.Delete( fs.Field ) }
+    if fc.vals != nil { 
+        fc.vals.Put( fs.Field, nil ) 
+        fc.curFld = fs.Field
+    }
     
     if fc.isPassthroughField( fs.Field ) {
         cr.passthroughTracker = mgRct.NewDepthTracker()
     } else {
         typ, err := fc.ft.fieldTypeFor( fs.Field, fs.GetPath().Parent() )
         if err != nil { return err }
+        cr.checkFieldDeprecation( fc, fs )
         cr.pushType( typ )
     }
 
@@ -954,22 +1276,27 @@ func ( cr *Reactor ) processFieldStart(
 
 func ( cr *Reactor ) processListEnd() error {
     lc := cr.stack.Pop().( *listCast )
-    if ! ( lc.sawValues || lc.lt.AllowsEmpty ) {
+    if ! ( lc.count > 0 || lc.lt.AllowsEmpty ) {
         return mg.NewInputError( lc.startPath, "empty list" )
     }
-    return nil
+    rx := lc.lt.Restriction
+    if rx == nil || rx.AcceptsLength( lc.count ) { return nil }
+    return mg.NewInputErrorf( lc.startPath, 
+        "List length %d does not satisfy restriction %s",
+        lc.count, rx.ExternalForm() )
 }
 
 func ( cr *Reactor ) processFieldsEnd( 
     ee *mgRct.EndEvent, next mgRct.EventProcessor ) error {
 
     fc := cr.stack.Pop().( *fieldCast )
-    if fc.await == nil { return nil }
     p := ee.GetPath()
-    if err := processDefaults( fc, p, next ); err != nil { return err }
-    fc.removeOptFields()
-    if fc.await.Len() > 0 { return createMissingFieldsError( p, fc ) }
-    return nil
+    if fc.await != nil {
+        if err := processDefaults( fc, p, next ); err != nil { return err }
+        fc.removeOptFields()
+        if fc.await.Len() > 0 { return createMissingFieldsError( p, fc ) }
+    }
+    return fc.checkConstraints( p )
 }
 
 func ( cr *Reactor ) processEnd(
@@ -1042,6 +1369,9 @@ func ( cr *Reactor ) processStructStartWithType(
         return cr.processStructStartWithType( ss, v.Type, callTyp, next )
     case *mg.NullableTypeReference:
         return cr.processStructStartWithType( ss, v.Type, callTyp, next )
+    case *mg.MapTypeReference:
+        me := asMapStartEvent( ss )
+        return cr.processMapStartWithType( me, v, callTyp, next )
     }
     return cr.newTypeInputError( typ, callTyp, ss.GetPath() )
 }
@@ -1055,7 +1385,7 @@ func ( cr *Reactor ) processStructStart(
         cr.stack.Pop()
         return cr.processStructStartWithType( ss, v, v, next )
     case *listCast:
-        v.sawValues = true
+        v.count++
         typ := v.lt.ElementType
         return cr.processStructStartWithType( ss, typ, typ, next )
     }
@@ -1108,6 +1438,8 @@ func ( cr *Reactor ) processListStartWithType(
         return cr.processListStartWithListType( le, v, callTyp, next )
     case *mg.NullableTypeReference:
         return cr.processListStartWithType( le, v.Type, callTyp, next )
+    case *mg.MapTypeReference:
+        return cr.newTypeInputError( callTyp, le.Type, le.GetPath() )
     }
     panic( libErrorf( "unhandled type: %T", typ ) )
 }
@@ -1120,7 +1452,7 @@ func ( cr *Reactor ) processListStart(
         cr.stack.Pop()
         return cr.processListStartWithType( le, v, v, next )
     case *listCast:
-        v.sawValues = true
+        v.count++
         return cr.processListStartWithType( le, v.lt.ElementType, v.lt, next )
     }
     panic( cr.errStackUnrecognized() )
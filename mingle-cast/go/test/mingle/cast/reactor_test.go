@@ -2,10 +2,52 @@ package cast
 
 import (
     "testing"
+    "sort"
+    mg "mingle"
     mgRct "mingle/reactor"
+    "mingle/parser"
+    "mingle/types"
+    "mingle/types/builtin"
     "bitgirder/assert"
+    "bitgirder/objpath"
 )
 
 func TestReactors( t *testing.T ) {
     mgRct.RunReactorTests( GetReactorTests(), assert.NewPathAsserter( t ) )
 }
+
+func TestDeprecationHandler( t *testing.T ) {
+    s1 := types.MakeStructDef( "ns1@v1/S1",
+        []*types.FieldDefinition{
+            types.MakeFieldDef( "f1", "&Int32?", nil ),
+            types.MakeFieldDef( "f2", "&Int32?", nil ),
+            types.MakeFieldDef( "f3", "ns1@v1/E1*", nil ),
+        },
+    )
+    s1.Fields.Get( mkId( "f1" ) ).Annotations = 
+        []*types.Annotation{ types.MakeAnnotation( "deprecated", "use f2" ) }
+    e1 := types.MakeEnumDef( "ns1@v1/E1", "red", "green" )
+    e1.ValueAnnotations = []*types.EnumValueAnnotations{
+        {
+            Value: mkId( "green" ),
+            Annotations: []*types.Annotation{ 
+                types.MakeAnnotation( "deprecated" ),
+            },
+        },
+    }
+    cr := NewReactor( asType( "ns1@v1/S1" ), builtin.MakeDefMap( s1, e1 ) )
+    act := []string{}
+    cr.HandleDeprecation = func( path objpath.PathNode, msg string ) {
+        act = append( act, mg.FormatIdPath( path ) + ": " + msg )
+    }
+    vb := mgRct.NewBuildReactor( mgRct.ValueBuilderFactory )
+    pip := mgRct.InitReactorPipeline( cr, vb )
+    in := parser.MustStruct( "ns1@v1/S1",
+        "f1", int32( 1 ),
+        "f2", int32( 2 ),
+        "f3", mg.MustList( "red", "green" ),
+    )
+    if err := mgRct.VisitValue( in, pip ); err != nil { t.Fatal( err ) }
+    sort.Strings( act ) // field visit order is unspecified
+    assert.Equal( []string{ "f1: use f2", "f3[ 1 ]: " }, act )
+}
@@ -10,6 +10,7 @@ import (
     "encoding/base64"
     "math"
     "fmt"
+    "strings"
 )
 
 var newVcErr = mg.NewInputError
@@ -250,6 +251,28 @@ func ( rti *rtInit ) addStringTests() {
         "Value \"ac\" does not satisfy restriction [\"aa\",\"ac\")",
         dm,
     )
+    rti.addIdent( "ab", "String~[1,2]", dm )
+    rti.addIdent( "\u00e9\u00e9", "String~[1,2]", dm ) // counts characters
+    rti.addIdent( mg.MustList( "a" ), "String*~[1,2]", dm )
+    rti.addIdent( mg.MustList( "a", "b" ), "String~[1,1]+~(0,2]", dm )
+    rti.addVcError(
+        "abc",
+        "String~[1,2]",
+        "Value \"abc\" does not satisfy restriction [1,2]",
+        dm,
+    )
+    rti.addVcError(
+        mg.MustList( "a", "b", "c" ),
+        "String*~[1,2]",
+        "List length 3 does not satisfy restriction [1,2]",
+        dm,
+    )
+    rti.addVcError(
+        mg.MustList(),
+        "String*~[1,2]",
+        "List length 0 does not satisfy restriction [1,2]",
+        dm,
+    )
     rti.addTests(
         &ReactorTest{
             Map: dm,
@@ -287,6 +310,8 @@ func ( rti *rtInit ) addIdentityNumTests() {
         { val: mg.Uint64( 1 ), str: "1", typ: mg.TypeUint64 },
         { val: mg.Float32( 1.0 ), str: "1", typ: mg.TypeFloat32 },
         { val: mg.Float64( 1.0 ), str: "1", typ: mg.TypeFloat64 },
+        { val: mg.MustBigInt( "1" ), str: "1", typ: mg.TypeBigInt },
+        { val: mg.MustDecimal( "1" ), str: "1", typ: mg.TypeDecimal },
     }
     s1 := parser.MustStruct( "ns1@v1/S1" )
     e1 := parser.MustEnum( "ns1@v1/E1", "e" )
@@ -375,6 +400,76 @@ func ( rti *rtInit ) addRangeErrorNumTests() {
     }
 }
 
+func ( rti *rtInit ) addBigNumTests() {
+    dm := types.NewDefinitionMap()
+    big1 := "123456789012345678901234567890"
+    rti.addSucc( big1, mg.MustBigInt( big1 ), mg.TypeBigInt, dm )
+    rti.addSucc( mg.MustBigInt( big1 ), big1, mg.TypeString, dm )
+    rti.addSucc( big1 + ".9", mg.MustBigInt( big1 ), mg.TypeBigInt, dm )
+    rti.addSucc( "-1.9e1", mg.MustBigInt( "-19" ), mg.TypeBigInt, dm )
+    rti.addSucc( 
+        uint64( math.MaxUint64 ), 
+        mg.MustBigInt( "18446744073709551615" ), 
+        mg.TypeBigInt, 
+        dm,
+    )
+    rti.addSucc( mg.Float64( -2.7 ), mg.MustBigInt( "-2" ), mg.TypeBigInt, dm )
+    rti.addSucc( 
+        mg.MustDecimal( "-2.7" ), mg.MustBigInt( "-2" ), mg.TypeBigInt, dm )
+    rti.addSucc( mg.MustBigInt( "-3" ), int32( -3 ), mg.TypeInt32, dm )
+    dec1 := big1 + ".000000000000000000001"
+    rti.addSucc( dec1, mg.MustDecimal( dec1 ), mg.TypeDecimal, dm )
+    rti.addSucc( mg.MustDecimal( dec1 ), dec1, mg.TypeString, dm )
+    rti.addSucc( mg.MustDecimal( "1.50" ), "1.50", mg.TypeString, dm )
+    rti.addSucc( 
+        mg.Float32( 1.1 ), mg.MustDecimal( "1.1" ), mg.TypeDecimal, dm )
+    rti.addSucc( 
+        mg.Float64( 1e21 ), mg.MustDecimal( "1e21" ), mg.TypeDecimal, dm )
+    rti.addSucc( int64( -5 ), mg.MustDecimal( "-5" ), mg.TypeDecimal, dm )
+    rti.addSucc( mg.MustDecimal( "2.5" ), int64( 2 ), mg.TypeInt64, dm )
+    // above 2^53, so not exactly representable as a Float64
+    rti.addSucc( 
+        mg.MustDecimal( "9007199254740993.9" ), 
+        int64( 9007199254740993 ), 
+        mg.TypeInt64, 
+        dm,
+    )
+    rti.addSucc( 
+        mg.MustDecimal( "9007199254740993" ), 
+        uint64( 9007199254740993 ), 
+        mg.TypeUint64, 
+        dm,
+    )
+    rti.addSucc( mg.MustDecimal( "-2147483648.5" ), 
+        int32( math.MinInt32 ), mg.TypeInt32, dm )
+    rti.addVcError( mg.MustDecimal( "2147483648" ), mg.TypeInt32, 
+        "value out of range: 2147483648", dm )
+    rti.addVcError( mg.MustDecimal( "-0.5e20" ), mg.TypeUint64, 
+        "value out of range: -50000000000000000000", dm )
+    rti.addSucc( mg.MustDecimal( "2.5" ), float64( 2.5 ), mg.TypeFloat64, dm )
+    rti.addVcError( 
+        mg.MustBigInt( big1 ), mg.TypeInt64, "value out of range: " + big1, dm )
+    rti.addVcError( 
+        mg.MustBigInt( "-1" ), mg.TypeUint32, "value out of range: -1", dm )
+    rti.addVcError( 
+        mg.Float64( math.Inf( 1 ) ), mg.TypeDecimal, 
+        "value out of range: +Inf", dm )
+    rti.addVcError( 
+        mg.Float64( math.NaN() ), mg.TypeBigInt, "value out of range: NaN", dm )
+    rti.addVcError( 
+        "1e-5000", mg.TypeDecimal, "value out of range: 1e-5000", dm )
+    rti.addVcError( "1.2.3", mg.TypeBigInt, 
+        "invalid mingle:core@v1/Decimal: 1.2.3", dm )
+    rti.addIdent( mg.MustBigInt( "5" ), "BigInt~[0,10)", dm )
+    rti.addVcError( mg.MustBigInt( big1 ), "BigInt~[0,10)",
+        fmt.Sprintf( "Value %s does not satisfy restriction [0,10)", big1 ), 
+        dm,
+    )
+    rti.addIdent( mg.MustDecimal( "1.5" ), "Decimal~[0,1.5]", dm )
+    rti.addVcError( "1.51", "Decimal~[0,1.5]", 
+        "Value 1.51 does not satisfy restriction [0,1.5]", dm )
+}
+
 func ( rti *rtInit ) addNumTests() {
     dm := types.NewDefinitionMap()
     for _, qn := range mg.NumericTypeNames {
@@ -384,6 +479,7 @@ func ( rti *rtInit ) addNumTests() {
     rti.addIdentityNumTests()
     rti.addTruncateNumTests()
     rti.addRangeErrorNumTests()
+    rti.addBigNumTests()
     rti.addSucc( "1", int64( 1 ), "Int64~[-1,1]", dm ) 
     for _, tmpl := range []string{ "%s", "&%s", "&%s?" } {
         rti.addVcError(
@@ -404,6 +500,10 @@ func ( rti *rtInit ) addBufferTests() {
         mg.NewPointerTypeReference( mg.TypeBuffer ), dm )
     rti.addVcError( "abc$/@", mg.TypeBuffer, 
         "Invalid base64 string: illegal base64 data at input byte 3", dm )
+    rti.addIdent( testValBuf1, "Buffer~[0,3]", dm )
+    rti.addSucc( buf1B64, testValBuf1, "Buffer~[3,3]", dm )
+    rti.addVcError( testValBuf1, "Buffer~(3,)", 
+        "Value buf[3] does not satisfy restriction (3,)", dm )
 }
 
 func ( rti *rtInit ) addTimeTests() {
@@ -420,6 +520,66 @@ func ( rti *rtInit ) addTimeTests() {
             "[\"2000-01-01T00:00:00Z\",\"2001-01-01T00:00:00Z\"]",
         dm,
     )
+    d1 := parser.MustDate( "2012-02-29" )
+    rti.addIdent( d1, mg.TypeDate, dm )
+    rti.addSucc( d1, "2012-02-29", mg.TypeString, dm )
+    rti.addSucc( "2012-02-29", d1, mg.TypeDate, dm )
+    rti.addIdent( d1, `Date~["2012-01-01","2013-01-01")`, dm )
+    rti.addVcError( "2013-02-29", mg.TypeDate, 
+        "Invalid date: [<input>, line 1, col 1]: " +
+            `Invalid ISO 8601 date: "2013-02-29"`, 
+        dm,
+    )
+    rti.addVcError( d1, `Date~["2013-01-01",)`,
+        `Value 2012-02-29 does not satisfy restriction ["2013-01-01",)`, dm )
+    lt1 := parser.MustLocalTime( "13:15:43.12345" )
+    rti.addIdent( lt1, mg.TypeLocalTime, dm )
+    rti.addSucc( lt1, "13:15:43.12345", mg.TypeString, dm )
+    rti.addSucc( "13:15:43.123450", lt1, mg.TypeLocalTime, dm )
+    rti.addIdent( lt1, `LocalTime~["09:00:00","17:00:00")`, dm )
+    rti.addVcError( "25:00:00", mg.TypeLocalTime, 
+        "Invalid local time: [<input>, line 1, col 1]: " +
+            `Invalid ISO 8601 time: "25:00:00"`, 
+        dm,
+    )
+    rti.addVcError( lt1, `LocalTime~(,"12:00:00"]`,
+        `Value 13:15:43.12345 does not satisfy restriction (,"12:00:00"]`, 
+        dm )
+    dur1 := parser.MustDuration( "P1DT2H" )
+    rti.addIdent( dur1, mg.TypeDuration, dm )
+    rti.addSucc( dur1, "P1DT2H", mg.TypeString, dm )
+    rti.addSucc( "PT26H", dur1, mg.TypeDuration, dm )
+    rti.addIdent( dur1, `Duration~("PT0S",)`, dm )
+    rti.addVcError( "P1Y", mg.TypeDuration, 
+        "Invalid duration: [<input>, line 1, col 1]: " +
+            `Invalid ISO 8601 duration: "P1Y"`, 
+        dm,
+    )
+    rti.addVcError( mg.Duration( 0 ), `Duration~("PT0S",)`,
+        `Value PT0S does not satisfy restriction ("PT0S",)`, dm )
+    rti.addTcError( mg.Int64( 1 ), mg.TypeDuration, mg.TypeInt64, dm )
+}
+
+func ( rti *rtInit ) addIdTests() {
+    dm := types.NewDefinitionMap()
+    uuidStr := "a0b1c2d3-e4f5-4617-8899-aabbccddeeff"
+    uuid1 := parser.MustUuid( uuidStr )
+    rti.addIdent( uuid1, mg.TypeUuid, dm )
+    rti.addSucc( uuid1, uuidStr, mg.TypeString, dm )
+    rti.addSucc( strings.ToUpper( uuidStr ), uuid1, mg.TypeUuid, dm )
+    rti.addVcError( "a0b1c2d3", mg.TypeUuid, 
+        "Invalid uuid: [<input>, line 1, col 1]: " +
+            `Invalid UUID: "a0b1c2d3"`,
+        dm,
+    )
+    rti.addTcError( testValBuf1, mg.TypeUuid, mg.TypeBuffer, dm )
+    uri1 := parser.MustUri( "http://example.com/a" )
+    rti.addIdent( uri1, mg.TypeUri, dm )
+    rti.addSucc( uri1, "http://example.com/a", mg.TypeString, dm )
+    rti.addSucc( "HTTP://Example.com/a", uri1, mg.TypeUri, dm )
+    rti.addVcError( "/a", mg.TypeUri, 
+        `Invalid uri: [<input>, line 1, col 1]: Invalid URI: "/a"`, dm )
+    rti.addTcError( uuid1, mg.TypeUri, mg.TypeUuid, dm )
 }
 
 func ( rti *rtInit ) addNullableTests() {
@@ -602,6 +762,70 @@ func ( rti *rtInit ) addMapTests() {
     rti.addNullValueError( nil, "&SymbolMap", dm )
 }
 
+func ( rti *rtInit ) addTypedMapTests() {
+    dm := builtin.MakeDefMap(
+        types.MakeStructDef( "ns1@v1/S1", 
+            []*types.FieldDefinition{ 
+                types.MakeFieldDef( "f1", "Int32", nil ),
+                types.MakeFieldDef( "f2", "Int32", nil ),
+            },
+        ),
+    )
+    i32Map := parser.MustSymbolMap( "f1", int32( 1 ), "f2", int32( 2 ) )
+    rti.addIdent( mg.MustSymbolMap(), "{Int32}", dm )
+    rti.addIdent( i32Map, "{Int32}", dm )
+    rti.addIdent( i32Map, "&{Int32}", dm )
+    rti.addIdent( nil, "{Int32}?", dm )
+    rti.addIdent( i32Map, "{Int32~[0,3)}", dm )
+    rti.addIdent( mg.MustList( i32Map, mg.MustSymbolMap() ), "{Int32}*", dm )
+    rti.addIdent( 
+        parser.MustSymbolMap( "f1", mg.MustList( int32( 1 ) ) ), 
+        "{Int32+}", 
+        dm,
+    )
+    rti.addIdent(
+        parser.MustSymbolMap( "f1", i32Map ),
+        "{{Int32}}",
+        dm,
+    )
+    rti.addSucc( 
+        parser.MustSymbolMap( "f1", "1", "f2", int64( 2 ) ), 
+        i32Map, 
+        "{Int32}", 
+        dm,
+    )
+    rti.addSucc( 
+        &mg.Struct{ Type: mkQn( "ns1@v1/S1" ), Fields: i32Map },
+        i32Map,
+        "{Int32}",
+        dm,
+    )
+    rti.addNullValueError( nil, "{Int32}", dm )
+    rti.addTcError( int32( 1 ), "{Int32}", mg.TypeInt32, dm )
+    rti.addTcError( mg.MustList(), "{Int32}", mg.TypeOpaqueList, dm )
+    rti.addError(
+        parser.MustSymbolMap( "f1", int32( 1 ), "f2", true ),
+        "{Int32}",
+        newTcErr( "Int32", "Boolean", objpath.RootedAt( mkId( "f2" ) ) ),
+        dm,
+    )
+    rti.addError(
+        parser.MustSymbolMap( "f1", int32( 3 ) ),
+        "{Int32~[0,3)}",
+        newVcErr( 
+            objpath.RootedAt( mkId( "f1" ) ),
+            "Value 3 does not satisfy restriction [0,3)",
+        ),
+        dm,
+    )
+    rti.addError(
+        parser.MustSymbolMap( "f1", mg.NullVal ),
+        "{Int32}",
+        newVcErr( objpath.RootedAt( mkId( "f1" ) ), "Value is null" ),
+        dm,
+    )
+}
+
 func ( rti *rtInit ) addBaseFieldCastTests() {
     p := mg.MakeTestIdPath
     qn1Str := "ns1@v1/S1"
@@ -784,8 +1008,9 @@ func ( rti *rtInit ) addStructValCastTests() {
     rti.addIdent( s1, "&ns1@v1/S1", dm )
     rti.addIdent( s1, "&ns1@v1/S1?", dm )
     l1 := mg.MustList( s1, s1 )
-    rti.addIdent( l1, &mg.ListTypeReference{ t1, false }, dm )
-    rti.addIdent( l1, &mg.ListTypeReference{ t1, true }, dm )
+    rti.addIdent( l1, &mg.ListTypeReference{ ElementType: t1 }, dm )
+    rti.addIdent( 
+        l1, &mg.ListTypeReference{ ElementType: t1, AllowsEmpty: true }, dm )
     rti.addTcError( int32( 1 ), s1.Type.AsAtomicType(), mg.TypeInt32, dm )
     rti.addIdent( nil, "&ns1@v1/S1?", dm )
     rti.addNullValueError( nil, "&ns1@v1/S1", dm )
@@ -1063,10 +1288,19 @@ func ( rti *rtInit ) addSchemaCastTests() {
 }
 
 func ( rti *rtInit ) addEnumValCastTests() {
+    e3Def := types.MakeEnumDef( "ns1@v1/E3", "c1", "c2", "c3" )
+    e3Def.Ordinals = []*types.EnumValueOrdinal{
+        { Value: mkId( "c1" ), Ordinal: 1 },
+        { Value: mkId( "c2" ), Ordinal: -2 },
+    }
+    e3Def.Aliases = []*types.EnumValueAlias{
+        { Alias: mkId( "c1-old" ), Value: mkId( "c1" ) },
+    }
     dm := builtin.MakeDefMap(
         types.MakeStructDef( "ns1@v1/S1", []*types.FieldDefinition{} ),
         types.MakeEnumDef( "ns1@v1/E1", "c1", "c2" ),
         types.MakeEnumDef( "ns1@v1/E2", "c1", "c2" ),
+        e3Def,
     )
     addTest := func( in, expct interface{}, typ interface{}, err error ) {
         t := &ReactorTest{
@@ -1146,6 +1380,30 @@ func ( rti *rtInit ) addEnumValCastTests() {
         "ns1@v1/E1", 
         newTcErr( "ns1@v1/E1", "ns1@v1/S2", nil ),
     )
+    e3c1 := parser.MustEnum( "ns1@v1/E3", "c1" )
+    for _, in := range []interface{} { 
+        int32( 1 ), int64( 1 ), uint32( 1 ), uint64( 1 ), 
+        "c1-old", parser.MustEnum( "ns1@v1/E3", "c1-old" ),
+    } {
+        addSucc( in, e3c1, "ns1@v1/E3" )
+    }
+    addSucc( int64( -2 ), parser.MustEnum( "ns1@v1/E3", "c2" ), "ns1@v1/E3" )
+    addSucc( 
+        mg.MustList( int32( 1 ), "c1-old", "c1" ),
+        mg.MustList( e3c1, e3c1, e3c1 ),
+        "ns1@v1/E3*",
+    )
+    for _, ord := range []interface{} { 
+        int32( 3 ), int64( 1 << 32 + 1 ), uint64( 1 << 32 + 1 ),
+    } {
+        msg := fmt.Sprintf( "illegal ordinal for enum ns1@v1/E3: %d", ord )
+        addFail( ord, "ns1@v1/E3", vcErr( msg ) )
+    }
+    addFail( 
+        float64( 1 ), 
+        "ns1@v1/E3", 
+        newTcErr( "ns1@v1/E3", mg.TypeFloat64, nil ),
+    )
 }
 
 // Just coverage that structs and defined types don't cause things to go nutso
@@ -1337,6 +1595,103 @@ func ( rti *rtInit ) addDefaultCastTests() {
     )
 }
 
+func ( rti *rtInit ) addStructConstraintTests() {
+    s1 := types.MakeStructDef( "ns1@v1/S1",
+        []*types.FieldDefinition{
+            types.MakeFieldDef( "f1", "&Int32?", nil ),
+            types.MakeFieldDef( "f2", "&Int32?", nil ),
+            types.MakeFieldDef( "f3", "Int32", int32( 1 ) ),
+            types.MakeFieldDef( "f4", "String?", nil ),
+            types.MakeFieldDef( "f5", "Int32*?", nil ),
+        },
+    )
+    s1.Constraints = []*types.StructConstraint{
+        {
+            Expression: &types.FieldComparison{
+                Field: mkId( "f1" ),
+                Operator: types.ComparisonLess,
+                Other: mkId( "f2" ),
+            },
+        },
+        {
+            Expression: &types.FieldComparison{
+                Field: mkId( "f3" ),
+                Operator: types.ComparisonNotEqual,
+                Value: mg.Int32( 0 ),
+            },
+            Message: "f3 must not be 0",
+        },
+        {
+            Expression: &types.FieldPresence{
+                Rule: types.PresenceExactlyOne,
+                Fields: []*mg.Identifier{ mkId( "f4" ), mkId( "f5" ) },
+            },
+        },
+    }
+    dm := builtin.MakeDefMap( s1 )
+    addTest := func( in, expct interface{}, typ string, err error ) {
+        t := &ReactorTest{ 
+            Map: dm, 
+            In: mg.MustValue( in ), 
+            Type: asType( typ ),
+        }
+        if expct != nil { t.Expect = mg.MustValue( expct ) }
+        if err != nil { t.Err = err }
+        rti.addTests( t )
+    }
+    s1Inst := func( pairs ...interface{} ) *mg.Struct {
+        return parser.MustStruct( "ns1@v1/S1", pairs... )
+    }
+    addSucc := func( in, expct interface{} ) { 
+        addTest( in, expct, "ns1@v1/S1", nil ) 
+    }
+    addSucc(
+        s1Inst( "f1", int32( 1 ), "f2", "2", "f4", "s" ),
+        s1Inst( "f1", int32( 1 ), "f2", int32( 2 ), "f3", int32( 1 ), 
+            "f4", "s" ),
+    )
+    addSucc(
+        parser.MustSymbolMap( "f2", int32( 2 ), "f3", int32( 3 ), 
+            "f5", mg.MustList() ),
+        s1Inst( "f2", int32( 2 ), "f3", int32( 3 ), "f5", mg.MustList() ),
+    )
+    addSucc(
+        s1Inst( "f1", int32( 3 ), "f2", mg.NullVal, "f4", "s" ),
+        s1Inst( "f1", int32( 3 ), "f2", mg.NullVal, "f3", int32( 1 ), 
+            "f4", "s" ),
+    )
+    addFail := func( in interface{}, typ string, err error ) {
+        addTest( in, nil, typ, err )
+    }
+    addFail(
+        s1Inst( "f1", int32( 2 ), "f2", "2", "f4", "s" ),
+        "ns1@v1/S1",
+        mg.NewInputError( nil, "constraint not satisfied: f1 < f2" ),
+    )
+    addFail(
+        s1Inst( "f3", int32( 0 ), "f4", "s" ),
+        "ns1@v1/S1",
+        mg.NewInputError( nil, "f3 must not be 0" ),
+    )
+    addFail(
+        mg.MustList( 
+            s1Inst( "f4", "s" ), 
+            s1Inst( "f4", "s", "f5", mg.MustList( int32( 1 ) ) ),
+        ),
+        "ns1@v1/S1*",
+        mg.NewInputError( 
+            mg.MakeTestIdPath( "1" ),
+            "constraint not satisfied: exactlyOne( f4, f5 )",
+        ),
+    )
+    addFail(
+        s1Inst( "f4", mg.NullVal ),
+        "ns1@v1/S1",
+        mg.NewInputError( 
+            nil, "constraint not satisfied: exactlyOne( f4, f5 )" ),
+    )
+}
+
 func ( rti *rtInit ) addUnionTests() {
     dm := builtin.MakeDefMap( 
         func() *types.StructDefinition {
@@ -1466,6 +1821,75 @@ func ( rti *rtInit ) addUnionTests() {
     )
 }
 
+func ( rti *rtInit ) addTaggedUnionTests() {
+    dm := builtin.MakeDefMap(
+        &types.UnionDefinition{
+            Name: mkQn( "ns1@v1/Tagged1" ),
+            Union: types.MustTaggedUnionTypeDefinition(
+                makeIdList( "name", "label", "count" ),
+                []mg.TypeReference{
+                    asType( "String" ),
+                    asType( "String" ),
+                    asType( "Int32" ),
+                },
+            ),
+        },
+    )
+    add := func( in, expct, typ interface{}, err error ) {
+        t := &ReactorTest{ 
+            Map: dm, 
+            In: mg.MustValue( in ), 
+            Type: asType( typ ),
+        }
+        if expct != nil { t.Expect = mg.MustValue( expct ) }
+        if err != nil { t.Err = err }
+        rti.addTests( t )
+    }
+    addOk := func( in, expct, typ interface{} ) { add( in, expct, typ, nil ) }
+    addIdent := func( in, typ interface{} ) { addOk( in, in, typ ) }
+    addErr := func( in, typ interface{}, err error ) { 
+        add( in, nil, typ, err ) 
+    }
+    addIdent( parser.MustSymbolMap( "name", "s1" ), "ns1@v1/Tagged1" )
+    addIdent( parser.MustSymbolMap( "label", "s1" ), "ns1@v1/Tagged1" )
+    addIdent( mg.NullVal, "&ns1@v1/Tagged1?" )
+    addOk(
+        parser.MustSymbolMap( "count", "1" ),
+        parser.MustSymbolMap( "count", int32( 1 ) ),
+        "ns1@v1/Tagged1",
+    )
+    addOk(
+        mg.MustList(
+            parser.MustSymbolMap( "name", "s1" ),
+            parser.MustSymbolMap( "count", int64( 2 ) ),
+        ),
+        mg.MustList( 
+            asType( "ns1@v1/Tagged1*" ),
+            parser.MustSymbolMap( "name", "s1" ),
+            parser.MustSymbolMap( "count", int32( 2 ) ),
+        ),
+        "ns1@v1/Tagged1*",
+    )
+    addErr(
+        parser.MustSymbolMap( "other", "s1" ),
+        "ns1@v1/Tagged1",
+        mg.NewUnrecognizedFieldError( nil, mkId( "other" ) ),
+    )
+    tagErr := mg.NewInputError( 
+        nil, "expected exactly one tag for union ns1@v1/Tagged1" )
+    addErr( mg.EmptySymbolMap(), "ns1@v1/Tagged1", tagErr )
+    addErr(
+        parser.MustSymbolMap( "name", "s1", "label", "s2" ),
+        "ns1@v1/Tagged1",
+        tagErr,
+    )
+    addErr(
+        mg.String( "s1" ),
+        "ns1@v1/Tagged1",
+        newTcErr( "ns1@v1/Tagged1", "String", nil ),
+    )
+}
+
 func ( rti *rtInit ) addCastDisableTests() {
     dm := builtin.MakeDefMap(
         types.MakeStructDef( "ns1@v1/S1",
@@ -1872,9 +2296,11 @@ func GetReactorTests() []mgRct.ReactorTest {
     rti.addNumTests()
     rti.addBufferTests()
     rti.addTimeTests()
+    rti.addIdTests()
     rti.addNullableTests()
     rti.addListTests()
     rti.addMapTests()
+    rti.addTypedMapTests()
     rti.addBaseFieldCastTests()
     rti.addFieldSetCastTests()
     rti.addStructTests()
@@ -1882,7 +2308,9 @@ func GetReactorTests() []mgRct.ReactorTest {
     rti.addEnumValCastTests()
     rti.addDeepCatchallTests()
     rti.addDefaultCastTests()
+    rti.addStructConstraintTests()
     rti.addUnionTests()
+    rti.addTaggedUnionTests()
     rti.addConstructorCastTests()
     rti.addDefaultPathTests()
     rti.addCastDisableTests()
@@ -0,0 +1,84 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "log"
+    "os"
+    "path/filepath"
+    "mingle/compat"
+    "mingle/compiler"
+    "mingle/parser/tree"
+    "mingle/types"
+    "mingle/types/builtin"
+)
+
+var allowFile string
+
+func usage() {
+    fmt.Fprintln( os.Stderr, "usage: mingle-compat [-allow FILE] PREV NEXT" )
+    fmt.Fprintln( os.Stderr,
+        "PREV and NEXT are each a .mg source or a directory of them" )
+    flag.PrintDefaults()
+    os.Exit( 2 )
+}
+
+func parseArgs() ( prev, next string ) {
+    flag.StringVar( &allowFile, "allow", "",
+        "File listing incompatibilities which are intended" )
+    flag.Usage = usage
+    flag.Parse()
+    if flag.NArg() != 2 { usage() }
+    return flag.Arg( 0 ), flag.Arg( 1 )
+}
+
+func sourceFiles( path string ) ( []string, error ) {
+    fi, err := os.Stat( path )
+    if err != nil { return nil, err }
+    if ! fi.IsDir() { return []string{ path }, nil }
+    return filepath.Glob( filepath.Join( path, "*.mg" ) )
+}
+
+func compile( path string ) ( *types.DefinitionMap, error ) {
+    files, err := sourceFiles( path )
+    if err != nil { return nil, err }
+    c := compiler.NewCompilation()
+    c.SetExternalTypes( builtin.BuiltinTypes() )
+    for _, file := range files {
+        r, err := os.Open( file )
+        if err != nil { return nil, err }
+        nsUnit, err := tree.ParseSource( file, r )
+        r.Close()
+        if err != nil { return nil, err }
+        c.AddSource( nsUnit )
+    }
+    cr, err := c.Execute()
+    if err != nil { return nil, err }
+    if len( cr.Errors ) > 0 {
+        for _, ce := range cr.Errors { log.Print( ce ) }
+        return nil, fmt.Errorf( "%s: compilation failed", path )
+    }
+    return cr.BuiltTypes, nil
+}
+
+func readAllowList() ( *compat.AllowList, error ) {
+    if allowFile == "" { return compat.NewAllowList(), nil }
+    f, err := os.Open( allowFile )
+    if err != nil { return nil, err }
+    defer f.Close()
+    return compat.ParseAllowList( f )
+}
+
+func main() {
+    prevPath, nextPath := parseArgs()
+    allow, err := readAllowList()
+    if err != nil { log.Fatal( err ) }
+    prev, err := compile( prevPath )
+    if err != nil { log.Fatal( err ) }
+    next, err := compile( nextPath )
+    if err != nil { log.Fatal( err ) }
+    breaks, allowed := allow.Filter( compat.Check( prev, next ) )
+    for _, inc := range allowed { fmt.Printf( "allowed: %s\n", inc ) }
+    for _, inc := range breaks { fmt.Println( inc ) }
+    if len( breaks ) > 0 { os.Exit( 1 ) }
+}
@@ -0,0 +1,124 @@
+package compat
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "strings"
+    mg "mingle"
+    "mingle/parser"
+)
+
+type allowEntry struct {
+    typ *mg.QualifiedTypeName
+    member string
+    kind ChangeKind // empty to allow changes of any kind
+}
+
+// An entry with a member allows changes to that member and to anything
+// within it, so that allowing "Service1.op1" also allows changes to the
+// parameters of op1
+func ( e *allowEntry ) allows( inc *Incompatibility ) bool {
+    if ! e.typ.Equals( inc.Type ) { return false }
+    if e.kind != "" && e.kind != inc.Kind { return false }
+    return e.member == "" ||
+        e.member == inc.Member ||
+        strings.HasPrefix( inc.Member, e.member + "." )
+}
+
+// Incompatibilities which are intended, and so should not fail a check
+type AllowList struct {
+    entries []*allowEntry
+}
+
+func NewAllowList() *AllowList {
+    return &AllowList{ entries: make( []*allowEntry, 0, 4 ) }
+}
+
+// Allows changes of kind to member of qn. member may be empty to allow changes
+// anywhere in qn, and kind may be empty to allow changes of any kind.
+func ( al *AllowList ) Allow(
+    qn *mg.QualifiedTypeName, member string, kind ChangeKind ) {
+
+    e := &allowEntry{ typ: qn, member: member, kind: kind }
+    al.entries = append( al.entries, e )
+}
+
+func ( al *AllowList ) Allows( inc *Incompatibility ) bool {
+    for _, e := range al.entries { if e.allows( inc ) { return true } }
+    return false
+}
+
+// Splits incs into those which al does not allow and those which it does
+func ( al *AllowList ) Filter(
+    incs []*Incompatibility ) ( breaks, allowed []*Incompatibility ) {
+
+    breaks = make( []*Incompatibility, 0, len( incs ) )
+    allowed = make( []*Incompatibility, 0, len( incs ) )
+    for _, inc := range incs {
+        if al.Allows( inc ) {
+            allowed = append( allowed, inc )
+        } else { breaks = append( breaks, inc ) }
+    }
+    return
+}
+
+// Parses a member path such as "op1.param1", returning it in the form used by
+// Incompatibility.Member
+func parseMember( s string ) ( string, error ) {
+    parts := strings.Split( s, "." )
+    for i, part := range parts {
+        id, err := parser.ParseIdentifier( part )
+        if err != nil { return "", err }
+        parts[ i ] = id.ExternalForm()
+    }
+    return strings.Join( parts, "." ), nil
+}
+
+func ( al *AllowList ) addLine( ln string ) error {
+    flds := strings.Fields( ln )
+    if len( flds ) > 2 { return fmt.Errorf( "unexpected text: %s", flds[ 2 ] ) }
+    subj, member := flds[ 0 ], ""
+    if i := strings.Index( subj, "." ); i >= 0 {
+        var err error
+        if member, err = parseMember( subj[ i + 1 : ] ); err != nil {
+            return err
+        }
+        subj = subj[ : i ]
+    }
+    qn, err := parser.ParseQualifiedTypeName( subj )
+    if err != nil { return err }
+    var kind ChangeKind
+    if len( flds ) == 2 {
+        if ! isChangeKind( flds[ 1 ] ) {
+            return fmt.Errorf( "unknown change kind: %s", flds[ 1 ] )
+        }
+        kind = ChangeKind( flds[ 1 ] )
+    }
+    al.Allow( qn, member, kind )
+    return nil
+}
+
+// Reads an allow list having one entry per line, each a qualified type name,
+// optionally followed by a dot and the path of a member within the type, and
+// then optionally by the kind of change allowed. Blank lines and text
+// following '#' are ignored:
+//
+//  # op1 is no longer offered
+//  ns1@v1/Service1.op1 operation-removed
+//  ns1@v1/Struct1.f1
+//
+func ParseAllowList( r io.Reader ) ( *AllowList, error ) {
+    res := NewAllowList()
+    scanner := bufio.NewScanner( r )
+    for lineNo := 1; scanner.Scan(); lineNo++ {
+        ln := scanner.Text()
+        if i := strings.Index( ln, "#" ); i >= 0 { ln = ln[ : i ] }
+        if strings.TrimSpace( ln ) == "" { continue }
+        if err := res.addLine( ln ); err != nil {
+            return nil, libErrorf( "line %d: %s", lineNo, err )
+        }
+    }
+    if err := scanner.Err(); err != nil { return nil, err }
+    return res, nil
+}
@@ -0,0 +1,421 @@
+// Package compat finds changes between two versions of a set of definitions
+// which would break clients built against the earlier version.
+//
+// Values which clients were able to send under the previous definitions must
+// still be accepted under the next ones, so removing a field or enum value,
+// adding a field which clients would have to supply, and narrowing the values
+// a type accepts are all breaking changes. Operations and their signatures are
+// held to stricter rules, since clients also depend on what they return and
+// throw.
+package compat
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    mg "mingle"
+    "mingle/types"
+)
+
+type ChangeKind string
+
+const (
+    ChangeTypeRemoved = ChangeKind( "type-removed" )
+    ChangeTypeKindChanged = ChangeKind( "type-kind-changed" )
+    ChangeFieldRemoved = ChangeKind( "field-removed" )
+    ChangeFieldRetyped = ChangeKind( "field-retyped" )
+    ChangeRequiredFieldAdded = ChangeKind( "required-field-added" )
+    ChangeRestrictionNarrowed = ChangeKind( "restriction-narrowed" )
+    ChangeAliasRetyped = ChangeKind( "alias-retyped" )
+    ChangeEnumValueRemoved = ChangeKind( "enum-value-removed" )
+    ChangeUnionTypeRemoved = ChangeKind( "union-type-removed" )
+    ChangeOperationRemoved = ChangeKind( "operation-removed" )
+    ChangeReturnRetyped = ChangeKind( "return-retyped" )
+    ChangeThrowsChanged = ChangeKind( "throws-changed" )
+)
+
+var changeKinds = []ChangeKind{
+    ChangeTypeRemoved,
+    ChangeTypeKindChanged,
+    ChangeFieldRemoved,
+    ChangeFieldRetyped,
+    ChangeRequiredFieldAdded,
+    ChangeRestrictionNarrowed,
+    ChangeAliasRetyped,
+    ChangeEnumValueRemoved,
+    ChangeUnionTypeRemoved,
+    ChangeOperationRemoved,
+    ChangeReturnRetyped,
+    ChangeThrowsChanged,
+}
+
+func isChangeKind( s string ) bool {
+    for _, k := range changeKinds { if string( k ) == s { return true } }
+    return false
+}
+
+type Incompatibility struct {
+    Kind ChangeKind
+    Type *mg.QualifiedTypeName
+
+    // The dotted path within Type of the field, operation, parameter or enum
+    // value affected, or empty if the change is to Type itself
+    Member string
+
+    Message string
+}
+
+// Returns Type and Member in the form used by allow lists, such as
+// "ns1@v1/Service1.op1.param1"
+func ( inc *Incompatibility ) Subject() string {
+    if inc.Member == "" { return inc.Type.ExternalForm() }
+    return inc.Type.ExternalForm() + "." + inc.Member
+}
+
+func ( inc *Incompatibility ) String() string {
+    return fmt.Sprintf( "%s: %s (%s)", inc.Subject(), inc.Message, inc.Kind )
+}
+
+type incompatibilitySort []*Incompatibility
+
+func ( s incompatibilitySort ) Len() int { return len( s ) }
+
+func ( s incompatibilitySort ) Swap( i, j int ) {
+    s[ i ], s[ j ] = s[ j ], s[ i ]
+}
+
+func ( s incompatibilitySort ) Less( i, j int ) bool {
+    si, sj := s[ i ].Subject(), s[ j ].Subject()
+    if si == sj { return s[ i ].Kind < s[ j ].Kind }
+    return si < sj
+}
+
+type typeChange int
+
+const (
+    typeCompatible = typeChange( iota )
+    typeNarrowed
+    typeRetyped
+)
+
+func boundContains(
+    outer, inner mg.Value, outerClosed, innerClosed bool, dir int ) bool {
+
+    if outer == nil { return true }
+    if inner == nil { return false }
+    switch i := outer.( mg.Comparer ).Compare( inner ) * dir; {
+    case i < 0: return true
+    case i == 0: return outerClosed || ! innerClosed
+    }
+    return false
+}
+
+// implemented by both *mg.RangeRestriction and *mg.LengthRestriction
+type bounded interface {
+    Min() mg.Value
+    MinClosed() bool
+    Max() mg.Value
+    MaxClosed() bool
+}
+
+func rangeContains( o, i bounded ) bool {
+    return boundContains( o.Min(), i.Min(), o.MinClosed(), i.MinClosed(), 1 ) &&
+        boundContains( o.Max(), i.Max(), o.MaxClosed(), i.MaxClosed(), -1 )
+}
+
+// Returns true if next accepts every value accepted by prev. Regexes are
+// compared only by their source, since in general there is no telling whether
+// one accepts everything another does.
+func restrictionContains( prev, next mg.ValueRestriction ) bool {
+    if next == nil { return true }
+    switch p := prev.( type ) {
+    case *mg.RangeRestriction:
+        if n, ok := next.( *mg.RangeRestriction ); ok {
+            return rangeContains( n, p )
+        }
+    case *mg.LengthRestriction:
+        if n, ok := next.( *mg.LengthRestriction ); ok {
+            return rangeContains( n, p )
+        }
+    case *mg.RegexRestriction:
+        if n, ok := next.( *mg.RegexRestriction ); ok {
+            return p.Source() == n.Source()
+        }
+    }
+    return false
+}
+
+// like restrictionContains, but for the optional restriction on a list's
+// length, where a nil restriction accepts any length
+func lengthContains( prev, next *mg.LengthRestriction ) bool {
+    if next == nil { return true }
+    if prev == nil { return false }
+    return rangeContains( next, prev )
+}
+
+func maxTypeChange( c1, c2 typeChange ) typeChange {
+    if c1 > c2 { return c1 }
+    return c2
+}
+
+func compareTypes( prev, next mg.TypeReference ) typeChange {
+    if prev.Equals( next ) { return typeCompatible }
+    nn, nextNullable := next.( *mg.NullableTypeReference )
+    switch p := prev.( type ) {
+    case *mg.NullableTypeReference:
+        if nextNullable { return compareTypes( p.Type, nn.Type ) }
+        return typeRetyped
+    case *mg.ListTypeReference:
+        if n, ok := next.( *mg.ListTypeReference ); ok {
+            res := compareTypes( p.ElementType, n.ElementType )
+            if ( p.AllowsEmpty && ! n.AllowsEmpty ) ||
+               ! lengthContains( p.Restriction, n.Restriction ) {
+                res = maxTypeChange( res, typeNarrowed )
+            }
+            return res
+        }
+    case *mg.MapTypeReference:
+        if n, ok := next.( *mg.MapTypeReference ); ok {
+            return compareTypes( p.ValueType, n.ValueType )
+        }
+    case *mg.PointerTypeReference:
+        if n, ok := next.( *mg.PointerTypeReference ); ok {
+            return compareTypes( p.Type, n.Type )
+        }
+    case *mg.AtomicTypeReference:
+        if n, ok := next.( *mg.AtomicTypeReference ); ok {
+            if ! p.Name().Equals( n.Name() ) { return typeRetyped }
+            if restrictionContains( p.Restriction(), n.Restriction() ) {
+                return typeCompatible
+            }
+            return typeNarrowed
+        }
+    }
+    // making a type nullable doesn't break clients who never send null
+    if nextNullable { return compareTypes( prev, nn.Type ) }
+    return typeRetyped
+}
+
+func isRequired( fd *types.FieldDefinition ) bool {
+    if _, ok := fd.Type.( *mg.NullableTypeReference ); ok { return false }
+    return fd.GetDefault() == nil
+}
+
+func definitionKind( def types.Definition ) string {
+    switch def.( type ) {
+    case *types.StructDefinition: return "struct"
+    case *types.SchemaDefinition: return "schema"
+    case *types.EnumDefinition: return "enum"
+    case *types.UnionDefinition: return "union"
+    case *types.AliasedTypeDefinition: return "alias"
+    case *types.PrototypeDefinition: return "prototype"
+    case *types.ServiceDefinition: return "service"
+    case *types.PrimitiveDefinition: return "primitive"
+    }
+    panic( libErrorf( "unhandled definition: %T", def ) )
+}
+
+func memberPath( prefix string, id *mg.Identifier ) string {
+    if prefix == "" { return id.ExternalForm() }
+    return prefix + "." + id.ExternalForm()
+}
+
+func throwsString( utd *types.UnionTypeDefinition ) string {
+    if utd == nil || len( utd.Types ) == 0 { return "nothing" }
+    strs := make( []string, len( utd.Types ) )
+    for i, typ := range utd.Types { strs[ i ] = typ.ExternalForm() }
+    return strings.Join( strs, ", " )
+}
+
+func throwsKeys( utd *types.UnionTypeDefinition ) []string {
+    if utd == nil { return []string{} }
+    res := make( []string, len( utd.Types ) )
+    for i, typ := range utd.Types {
+        res[ i ] = types.UnionTypeKeyForType( typ )
+    }
+    sort.Strings( res )
+    return res
+}
+
+func throwsEqual( prev, next *types.UnionTypeDefinition ) bool {
+    pk, nk := throwsKeys( prev ), throwsKeys( next )
+    if len( pk ) != len( nk ) { return false }
+    for i, k := range pk { if nk[ i ] != k { return false } }
+    return true
+}
+
+type checker struct {
+    next *types.DefinitionMap
+    res []*Incompatibility
+}
+
+func ( c *checker ) add(
+    kind ChangeKind,
+    qn *mg.QualifiedTypeName,
+    member string,
+    tmpl string,
+    argv ...interface{} ) {
+
+    inc := &Incompatibility{
+        Kind: kind,
+        Type: qn,
+        Member: member,
+        Message: fmt.Sprintf( tmpl, argv... ),
+    }
+    c.res = append( c.res, inc )
+}
+
+// Adds an incompatibility of kind retyped for a change from prev to next
+// which is not compatible
+func ( c *checker ) checkType(
+    qn *mg.QualifiedTypeName,
+    member string,
+    retyped ChangeKind,
+    prev, next mg.TypeReference ) {
+
+    switch compareTypes( prev, next ) {
+    case typeNarrowed:
+        c.add( ChangeRestrictionNarrowed, qn, member,
+            "type narrowed from %s to %s", prev, next )
+    case typeRetyped:
+        c.add( retyped, qn, member, "type changed from %s to %s", prev, next )
+    }
+}
+
+func ( c *checker ) checkFields(
+    qn *mg.QualifiedTypeName, prefix string, prev, next *types.FieldSet ) {
+
+    prev.EachDefinition( func( pf *types.FieldDefinition ) {
+        member := memberPath( prefix, pf.Name )
+        if nf := next.Get( pf.Name ); nf == nil {
+            c.add( ChangeFieldRemoved, qn, member, "field removed" )
+        } else {
+            c.checkType( qn, member, ChangeFieldRetyped, pf.Type, nf.Type )
+        }
+    })
+    next.EachDefinition( func( nf *types.FieldDefinition ) {
+        if prev.Get( nf.Name ) != nil || ! isRequired( nf ) { return }
+        c.add( ChangeRequiredFieldAdded, qn, memberPath( prefix, nf.Name ),
+            "required field added with type %s and no default", nf.Type )
+    })
+}
+
+func ( c *checker ) checkCall(
+    qn *mg.QualifiedTypeName, prefix string, prev, next *types.CallSignature ) {
+
+    c.checkFields( qn, prefix, prev.Fields, next.Fields )
+    if ! prev.Return.Equals( next.Return ) {
+        c.add( ChangeReturnRetyped, qn, prefix,
+            "return type changed from %s to %s", prev.Return, next.Return )
+    }
+    if ! throwsEqual( prev.Throws, next.Throws ) {
+        c.add( ChangeThrowsChanged, qn, prefix,
+            "throws changed from %s to %s",
+            throwsString( prev.Throws ), throwsString( next.Throws ) )
+    }
+}
+
+func ( c *checker ) checkEnum( prev, next *types.EnumDefinition ) {
+    for _, val := range prev.Values {
+        if next.GetValue( val ) == nil {
+            c.add( ChangeEnumValueRemoved, prev.Name, val.ExternalForm(),
+                "enum value removed" )
+        }
+    }
+}
+
+func taggedString( utd *types.UnionTypeDefinition ) string {
+    if utd.IsTagged() { return "tagged" }
+    return "untagged"
+}
+
+// a tagged union value is read by its tag, so the type of each tag must be
+// unchanged
+func ( c *checker ) checkTaggedUnion( prev, next *types.UnionDefinition ) {
+    for i, tag := range prev.Union.Tags {
+        member, typ := tag.ExternalForm(), prev.Union.Types[ i ]
+        if nextTyp, ok := next.Union.MatchTag( tag ); ! ok {
+            c.add( ChangeUnionTypeRemoved, prev.Name, member, 
+                "union tag removed" )
+        } else if ! nextTyp.Equals( typ ) {
+            c.add( ChangeUnionTypeRemoved, prev.Name, member,
+                "union tag type changed from %s to %s", typ, nextTyp )
+        }
+    }
+}
+
+func ( c *checker ) checkUnion( prev, next *types.UnionDefinition ) {
+    if prev.Union.IsTagged() != next.Union.IsTagged() {
+        c.add( ChangeUnionTypeRemoved, prev.Name, "", 
+            "union changed from %s to %s", 
+            taggedString( prev.Union ), taggedString( next.Union ) )
+        return
+    }
+    if prev.Union.IsTagged() {
+        c.checkTaggedUnion( prev, next )
+        return
+    }
+    for _, typ := range prev.Union.Types {
+        if _, ok := next.Union.MatchType( typ ); ! ok {
+            c.add( ChangeUnionTypeRemoved, prev.Name, "",
+                "union type removed: %s", typ )
+        }
+    }
+}
+
+func ( c *checker ) checkService( prev, next *types.ServiceDefinition ) {
+    nextOps := types.OpDefsByName( next.Operations )
+    for _, po := range prev.Operations {
+        member := po.Name.ExternalForm()
+        if no, ok := nextOps.GetOk( po.Name ); ok {
+            no := no.( *types.OperationDefinition )
+            c.checkCall( prev.Name, member, po.Signature, no.Signature )
+        } else {
+            c.add( ChangeOperationRemoved, prev.Name, member,
+                "operation removed" )
+        }
+    }
+}
+
+func ( c *checker ) checkDefinition( prev types.Definition ) {
+    qn := prev.GetName()
+    next := c.next.Get( qn )
+    if next == nil {
+        c.add( ChangeTypeRemoved, qn, "", "%s removed", definitionKind( prev ) )
+        return
+    }
+    if pk, nk := definitionKind( prev ), definitionKind( next ); pk != nk {
+        c.add( ChangeTypeKindChanged, qn, "", "changed from %s to %s", pk, nk )
+        return
+    }
+    switch p := prev.( type ) {
+    case *types.StructDefinition, *types.SchemaDefinition:
+        prevFlds := p.( types.FieldContainer ).GetFields()
+        nextFlds := next.( types.FieldContainer ).GetFields()
+        c.checkFields( qn, "", prevFlds, nextFlds )
+    case *types.EnumDefinition: c.checkEnum( p, next.( *types.EnumDefinition ) )
+    case *types.UnionDefinition:
+        c.checkUnion( p, next.( *types.UnionDefinition ) )
+    case *types.AliasedTypeDefinition:
+        nextTyp := next.( *types.AliasedTypeDefinition ).AliasedType
+        c.checkType( qn, "", ChangeAliasRetyped, p.AliasedType, nextTyp )
+    case *types.PrototypeDefinition:
+        nextSig := next.( *types.PrototypeDefinition ).Signature
+        c.checkCall( qn, "", p.Signature, nextSig )
+    case *types.ServiceDefinition:
+        c.checkService( p, next.( *types.ServiceDefinition ) )
+    }
+}
+
+// Returns the changes from prev to next which would break clients built
+// against prev, ordered by the types and members they affect
+func Check( prev, next *types.DefinitionMap ) []*Incompatibility {
+    c := &checker{ next: next, res: make( []*Incompatibility, 0, 4 ) }
+    prev.EachDefinition( func( def types.Definition ) {
+        if ! prev.HasBuiltInDefinition( def.GetName() ) {
+            c.checkDefinition( def )
+        }
+    })
+    sort.Sort( incompatibilitySort( c.res ) )
+    return c.res
+}
@@ -0,0 +1,14 @@
+package compat
+
+import (
+    "fmt"
+    "errors"
+)
+
+func libError( msg string ) error {
+    return errors.New( "mingle/compat: " + msg )
+}
+
+func libErrorf( tmpl string, argv ...interface{} ) error {
+    return fmt.Errorf( "mingle/compat: " + tmpl, argv... )
+}
@@ -0,0 +1,229 @@
+package compat
+
+import (
+    "testing"
+    "strings"
+    "bitgirder/assert"
+    "mingle/compiler"
+    "mingle/parser"
+    "mingle/parser/tree"
+    "mingle/types"
+    "mingle/types/builtin"
+)
+
+func mustCompile( t *testing.T, src string ) *types.DefinitionMap {
+    u, err := tree.ParseSource( "<>", strings.NewReader( src ) )
+    if err != nil { t.Fatal( err ) }
+    c := compiler.NewCompilation().AddSource( u )
+    cr, err := c.SetExternalTypes( builtin.BuiltinTypes() ).Execute()
+    if err != nil { t.Fatal( err ) }
+    for _, ce := range cr.Errors { t.Fatal( ce ) }
+    return cr.BuiltTypes
+}
+
+var prevSrc = `
+@version v1
+namespace ns1
+
+struct S1 {
+    f1 Int32~[0,10]
+    f2 String
+    f3 String~"^a+$"
+    f4 Int32*
+    f5 String?
+    f6 Int64
+    f7 Int32~(0,)
+    f8 String
+    f9 Int32+
+    f14 String~[1,10]
+    f15 String*~[0,5]
+    f16 Int32*~[0,10]
+    f17 {Int32~[0,10]}
+}
+
+struct Gone {}
+
+struct KindChanged {}
+
+enum E1 { red, green, blue }
+
+union U1 { Int32, String }
+
+union U2 { String tag( a ), Int32 tag( b ), String tag( c ) }
+
+union U3 { String, Int32 }
+
+alias A1 String~"^x$"
+
+alias A2 String~"^x$"
+
+struct Err1 {}
+
+struct Err2 {}
+
+prototype P1( p1 String ): String
+
+service Svc1 {
+    op op1( p1 String, p2 Int32~[0,5] ): String throws Err1
+    op op2(): String
+    op op3(): String throws Err1, Err2
+    op op4(): Int32
+}
+`
+
+var nextSrc = `
+@version v1
+namespace ns1
+
+struct S1 {
+    f1 Int32~[0,5]
+    f3 String~"^a*$"
+    f4 Int32+
+    f5 String?
+    f6 Int32
+    f7 Int32~[0,)
+    f8 String?
+    f9 Int32*
+    f10 String
+    f11 String?
+    f12 Int32 default 1
+    f13 Int32*
+    f14 String~[2,10]
+    f15 String*~[0,10]
+    f16 Int32*~[0,5]
+    f17 {Int32~[0,5]}
+}
+
+enum KindChanged { a }
+
+enum E1 { red, blue, purple }
+
+union U1 { String, Boolean }
+
+union U2 { String tag( a ), Int64 tag( b ), Boolean tag( d ) }
+
+union U3 { String tag( s ), Int32 tag( i ) }
+
+alias A1 String~"^y$"
+
+alias A2 String
+
+struct Err1 {}
+
+struct Err2 {}
+
+prototype P1( p1 String, p2 Int32 ): Int32
+
+service Svc1 {
+    op op1( p1 String, p2 Int32~[0,6] ): String throws Err2
+    op op3(): String throws Err2, Err1
+    op op4(): Int64
+}
+`
+
+func incompatibilityStrings( incs []*Incompatibility ) []string {
+    res := make( []string, len( incs ) )
+    for i, inc := range incs { res[ i ] = inc.String() }
+    return res
+}
+
+func TestCheck( t *testing.T ) {
+    prev, next := mustCompile( t, prevSrc ), mustCompile( t, nextSrc )
+    assert.Equal(
+        []string{
+            `ns1@v1/A1: type narrowed from mingle:core@v1/String~"^x$" to ` +
+                `mingle:core@v1/String~"^y$" (restriction-narrowed)`,
+            "ns1@v1/E1.green: enum value removed (enum-value-removed)",
+            "ns1@v1/Gone: struct removed (type-removed)",
+            "ns1@v1/KindChanged: changed from struct to enum " +
+                "(type-kind-changed)",
+            "ns1@v1/P1: return type changed from mingle:core@v1/String to " +
+                "mingle:core@v1/Int32 (return-retyped)",
+            "ns1@v1/P1.p2: required field added with type " +
+                "mingle:core@v1/Int32 and no default (required-field-added)",
+            "ns1@v1/S1.f1: type narrowed from mingle:core@v1/Int32~[0,10] " +
+                "to mingle:core@v1/Int32~[0,5] (restriction-narrowed)",
+            "ns1@v1/S1.f10: required field added with type " +
+                "mingle:core@v1/String and no default (required-field-added)",
+            "ns1@v1/S1.f14: type narrowed from mingle:core@v1/String~[1,10] " +
+                "to mingle:core@v1/String~[2,10] (restriction-narrowed)",
+            "ns1@v1/S1.f16: type narrowed from mingle:core@v1/Int32*~[0,10] " +
+                "to mingle:core@v1/Int32*~[0,5] (restriction-narrowed)",
+            "ns1@v1/S1.f17: type narrowed from " +
+                "{mingle:core@v1/Int32~[0,10]} to " +
+                "{mingle:core@v1/Int32~[0,5]} (restriction-narrowed)",
+            "ns1@v1/S1.f2: field removed (field-removed)",
+            `ns1@v1/S1.f3: type narrowed from mingle:core@v1/String~"^a+$" ` +
+                `to mingle:core@v1/String~"^a*$" (restriction-narrowed)`,
+            "ns1@v1/S1.f4: type narrowed from mingle:core@v1/Int32* to " +
+                "mingle:core@v1/Int32+ (restriction-narrowed)",
+            "ns1@v1/S1.f6: type changed from mingle:core@v1/Int64 to " +
+                "mingle:core@v1/Int32 (field-retyped)",
+            "ns1@v1/Svc1.op1: throws changed from ns1@v1/Err1 to " +
+                "ns1@v1/Err2 (throws-changed)",
+            "ns1@v1/Svc1.op2: operation removed (operation-removed)",
+            "ns1@v1/Svc1.op4: return type changed from mingle:core@v1/Int32 " +
+                "to mingle:core@v1/Int64 (return-retyped)",
+            "ns1@v1/U1: union type removed: mingle:core@v1/Int32 " +
+                "(union-type-removed)",
+            "ns1@v1/U2.b: union tag type changed from mingle:core@v1/Int32 " +
+                "to mingle:core@v1/Int64 (union-type-removed)",
+            "ns1@v1/U2.c: union tag removed (union-type-removed)",
+            "ns1@v1/U3: union changed from untagged to tagged " +
+                "(union-type-removed)",
+        },
+        incompatibilityStrings( Check( prev, next ) ),
+    )
+}
+
+func TestCheckUnchanged( t *testing.T ) {
+    prev := mustCompile( t, prevSrc )
+    assert.Equal( 0, len( Check( prev, mustCompile( t, prevSrc ) ) ) )
+}
+
+func TestAllowList( t *testing.T ) {
+    al, err := ParseAllowList( strings.NewReader( `
+# dropped on purpose
+ns1@v1/Gone
+ns1@v1/Svc1.op2 operation-removed # trailing comment
+ns1@v1/S1.f2 field-retyped
+ns1@v1/P1.p2
+` ) )
+    if err != nil { t.Fatal( err ) }
+    prev, next := mustCompile( t, prevSrc ), mustCompile( t, nextSrc )
+    breaks, allowed := al.Filter( Check( prev, next ) )
+    assert.Equal(
+        []string{
+            "ns1@v1/Gone: struct removed (type-removed)",
+            "ns1@v1/P1.p2: required field added with type " +
+                "mingle:core@v1/Int32 and no default (required-field-added)",
+            "ns1@v1/Svc1.op2: operation removed (operation-removed)",
+        },
+        incompatibilityStrings( allowed ),
+    )
+    assert.Equal( 19, len( breaks ) )
+    al = NewAllowList()
+    al.Allow( parser.MustQualifiedTypeName( "ns1@v1/Svc1" ), "", "" )
+    al.Allow( parser.MustQualifiedTypeName( "ns1@v1/S1" ), "",
+        ChangeRestrictionNarrowed )
+    _, allowed = al.Filter( Check( prev, next ) )
+    assert.Equal( 9, len( allowed ) )
+}
+
+func TestAllowListErrors( t *testing.T ) {
+    a := assert.NewListPathAsserter( t )
+    for _, tt := range []struct { src, errMsg string } {
+        { "ns1@v1/S1 no-such-change",
+          "mingle/compat: line 1: unknown change kind: no-such-change" },
+        { "\nns1@v1/S1 field-removed extra",
+          "mingle/compat: line 2: unexpected text: extra" },
+        { "S1", "" },
+        { "ns1@v1/S1.2bad", "" },
+    } {
+        _, err := ParseAllowList( strings.NewReader( tt.src ) )
+        if err == nil {
+            a.Fatalf( "expected an error for %q", tt.src )
+        } else if tt.errMsg != "" { a.Equal( tt.errMsg, err.Error() ) }
+        a = a.Next()
+    }
+}
@@ -6,6 +6,7 @@ import (
     "strings"
     "bytes"
     "sort"
+    "math"
     "container/list"
     "bitgirder/objpath"
     "mingle/parser/tree"
@@ -43,7 +44,19 @@ func baseTypeIsNum( typ mg.TypeReference ) bool {
            qn.Equals( mg.QnameUint32 ) ||
            qn.Equals( mg.QnameUint64 ) ||
            qn.Equals( mg.QnameFloat32 ) ||
-           qn.Equals( mg.QnameFloat64 )
+           qn.Equals( mg.QnameFloat64 ) ||
+           qn.Equals( mg.QnameBigInt ) ||
+           qn.Equals( mg.QnameDecimal )
+}
+
+// true if typ is a map type, possibly behind a pointer or nullable type
+func isMapType( typ mg.TypeReference ) bool {
+    switch v := typ.( type ) {
+    case *mg.MapTypeReference: return true
+    case *mg.NullableTypeReference: return isMapType( v.Type )
+    case *mg.PointerTypeReference: return isMapType( v.Type )
+    }
+    return false
 }
 
 func isAtomic( typ mg.TypeReference ) bool {
@@ -57,13 +70,17 @@ func asUnrestrictedType( typ mg.TypeReference ) *mg.AtomicTypeReference {
         if v.Restriction() == nil { return v }
         return mg.NewAtomicTypeReference( v.Name(), nil )
     case *mg.ListTypeReference: return asUnrestrictedType( v.ElementType )
+    case *mg.MapTypeReference: return asUnrestrictedType( v.ValueType )
     case *mg.NullableTypeReference: return asUnrestrictedType( v.Type )
     }
     panic( implErrorf( "Unhandled type reference: %T", typ ) )
 }
 
 var (
-    typeValList = &mg.ListTypeReference{ mg.TypeValue, true }
+    typeValList = &mg.ListTypeReference{ 
+        ElementType: mg.TypeValue, 
+        AllowsEmpty: true,
+    }
 
     idAuthentication = mg.NewIdentifierUnsafe( []string{ "authentication" } )
 
@@ -334,6 +351,41 @@ func ( bs *buildScope ) parseTimestamp(
     return tm, false
 }
 
+// true for types other than String and Timestamp which may be given as string
+// literals
+func isStringFormTypeName( qn *mg.QualifiedTypeName ) bool {
+    return qn.Equals( mg.QnameDate ) || 
+           qn.Equals( mg.QnameLocalTime ) ||
+           qn.Equals( mg.QnameDuration ) ||
+           qn.Equals( mg.QnameUuid ) ||
+           qn.Equals( mg.QnameUri )
+}
+
+// qn must be one for which isStringFormTypeName() is true
+func ( bs *buildScope ) parseStringForm( 
+    str string, 
+    qn *mg.QualifiedTypeName, 
+    errLoc *parser.Location ) ( mg.Value, bool ) {
+    
+    var val mg.Value
+    var err error
+    switch {
+    case qn.Equals( mg.QnameDate ): val, err = parser.ParseDate( str )
+    case qn.Equals( mg.QnameLocalTime ): val, err = parser.ParseLocalTime( str )
+    case qn.Equals( mg.QnameDuration ): val, err = parser.ParseDuration( str )
+    case qn.Equals( mg.QnameUuid ): val, err = parser.ParseUuid( str )
+    case qn.Equals( mg.QnameUri ): val, err = parser.ParseUri( str )
+    default: panic( libErrorf( "not a string form type: %s", qn ) )
+    }
+    if err == nil { return val, true }
+    if pe, ok := err.( *parser.ParseError ); ok {
+        bs.c.addError( errLoc, pe.Message )
+        return nil, false
+    }
+    bs.c.addError( errLoc, err.Error() )
+    return nil, false
+}
+
 func ( bs *buildScope ) buildRegexRestriction( 
     rx *parser.RegexRestrictionSyntax,
     tr *typeResolution ) mg.ValueRestriction {
@@ -361,6 +413,12 @@ func ( bs *buildScope ) setRangeBuilderStringValue(
             return 0
         }
         return 1
+    case isStringFormTypeName( qn ):
+        if val, ok := bs.parseStringForm( rx.Str, qn, errLoc ); ok {
+            *valPtr = val
+            return 0
+        }
+        return 1
     }
     bs.c.addErrorf( rx.Loc, "got string as %s value for range", bound )
     return 1
@@ -425,6 +483,22 @@ func ( bs *buildScope ) buildRangeRestriction(
     return nil
 }
 
+func ( bs *buildScope ) addParseError( err error, errLoc *parser.Location ) {
+    if pe, ok := err.( *parser.ParseError ); ok {
+        bs.c.addError( pe.Loc, pe.Message )
+    } else { bs.c.addError( errLoc, err.Error() ) }
+}
+
+func ( bs *buildScope ) buildLengthRestriction(
+    rx *parser.RangeRestrictionSyntax,
+    tr *typeResolution ) mg.ValueRestriction {
+
+    lr, err := parser.CreateLengthRestriction( rx )
+    if err == nil { return lr }
+    bs.addParseError( err, tr.errLoc )
+    return nil
+}
+
 func ( bs *buildScope ) buildValueRestriction(
     qn *mg.QualifiedTypeName, 
     rx parser.RestrictionSyntax,
@@ -434,6 +508,9 @@ func ( bs *buildScope ) buildValueRestriction(
     case *parser.RegexRestrictionSyntax: 
         return bs.buildRegexRestriction( v, tr )
     case *parser.RangeRestrictionSyntax: 
+        if parser.IsLengthRange( qn, v ) { 
+            return bs.buildLengthRestriction( v, tr ) 
+        }
         return bs.buildRangeRestriction( qn, v, tr )
     }
     panic( libErrorf( "unhandled restriction: %T", rx ) )
@@ -534,7 +611,7 @@ func ( bs *buildScope ) completeType(
 
     res, err := typ.CompleteType( typeCompletion{ tr: tr, bs: bs } )
     if err == nil { return res }
-    bs.c.addError( typ.Location(), err.Error() )
+    bs.addParseError( err, typ.Location() )
     return nil
 }
 
@@ -927,12 +1004,16 @@ type unionTypeBuildCheck struct {
 
 func ( c unionTypeBuildCheck ) check() {
     if _, ok := c.typ.( *mg.ListTypeReference ); ok { return }
-    qn := mg.TypeNameIn( c.typ )
-    def := c.c.typeDefForQn( qn )
     fail := func( desc string ) {
         c.c.addErrorf( c.typLoc, "invalid %s in union %s: %s", 
             desc, c.ud.GetName(), c.typ )
     }
+    if isMapType( c.typ ) {
+        fail( "map type" )
+        return
+    }
+    qn := mg.TypeNameIn( c.typ )
+    def := c.c.typeDefForQn( qn )
     switch def.( type ) {
     case *types.PrototypeDefinition: fail( "prototype type" )
     case *types.ServiceDefinition: fail( "service type" )
@@ -947,8 +1028,56 @@ func ( c unionTypeBuildCheck ) check() {
     }
 }
 
+// returns the tags of ud, or nil and false if any is missing or duplicated, in
+// which case errors will have been reported
+func ( c *Compilation ) buildUnionTags( 
+    ud *tree.UnionDecl ) ( []*mg.Identifier, bool ) {
+
+    ok, seen := true, mg.NewIdentifierMap()
+    res := make( []*mg.Identifier, len( ud.Tags ) )
+    for i, tag := range ud.Tags {
+        switch {
+        case tag == nil:
+            ok = false
+            c.addErrorf( ud.Types[ i ].Location(), 
+                "Missing tag for type in tagged union %s", ud.GetName() )
+        case seen.HasKey( tag.Tag ):
+            ok = false
+            c.addErrorf( tag.TagLoc, "Duplicate union tag: %s", tag.Tag )
+        default:
+            seen.Put( tag.Tag, true )
+            res[ i ] = tag.Tag
+        }
+    }
+    if ok { return res, true }
+    return nil, false
+}
+
+// tagged union types are selected by tag rather than by type, and so are not
+// checked for ambiguity
+func ( c *Compilation ) buildTaggedUnionType( bc buildContext ) {
+    ud := bc.td.( *tree.UnionDecl )
+    typs, typsOk := make( []mg.TypeReference, len( ud.Types ) ), true
+    for i, ctr := range ud.Types {
+        ctrLoc := ctr.Location()
+        if typs[ i ] = bc.scope.resolveType( ctr, ctrLoc ); typs[ i ] == nil {
+            typsOk = false
+        } else { 
+            c.addBuildCheck( unionTypeBuildCheck{ c, ud, typs[ i ], ctrLoc } )
+        }
+    }
+    tags, tagsOk := c.buildUnionTags( ud )
+    if ! ( typsOk && tagsOk ) { return }
+    ut := types.MustTaggedUnionTypeDefinition( tags, typs )
+    c.putBuiltType( &types.UnionDefinition{ Name: bc.qname(), Union: ut } )
+}
+
 func ( c *Compilation ) buildUnionType( bc buildContext ) {
     ud := bc.td.( *tree.UnionDecl )
+    if ud.Tags != nil { 
+        c.buildTaggedUnionType( bc )
+        return
+    }
     chk := newTypeSelectionCheck( c )
     chk.errTmpl = "ambiguous types in union %s: %s"
     chk.errArgv = []interface{}{ ud.Info.Name }
@@ -1249,9 +1378,43 @@ func ( c *Compilation ) buildStructType( bc buildContext ) {
     ok := true
     ok = c.buildFieldSet( bc, decl.Fields, decl.Schemas, sd.Fields, sd ) && ok
     ok = c.buildConstructors( bc, sd ) && ok
+    ok = c.buildReservedNames( decl, sd ) && ok
     if ok { c.putBuiltType( sd ) }
 }
 
+// sets the reserved names of sd, which must be built after its fields so that
+// any field, whether declared in decl or mixed in from a schema, that has a
+// reserved name can be reported
+func ( c *Compilation ) buildReservedNames( 
+    decl *tree.StructDecl, sd *types.StructDefinition ) bool {
+
+    ok, locs := true, mg.NewIdentifierMap()
+    for _, rd := range decl.Reserved {
+        for _, rn := range rd.Names {
+            if locs.HasKey( rn.Name ) {
+                ok = false
+                c.addErrorf( rn.NameLoc, 
+                    "Duplicate reserved field name: %s", rn.Name )
+            } else {
+                locs.Put( rn.Name, rn.NameLoc )
+                sd.Reserved = append( sd.Reserved, rn.Name )
+            }
+        }
+    }
+    for _, fldDecl := range decl.Fields {
+        if locs.HasKey( fldDecl.Name ) {
+            locs.Put( fldDecl.Name, fldDecl.NameLoc )
+        }
+    }
+    for _, nm := range sd.Reserved {
+        if sd.Fields.Get( nm ) != nil {
+            ok = false
+            c.addErrorf( locs.Get( nm ), "Field name is reserved: %s", nm )
+        }
+    }
+    return ok
+}
+
 func ( c *Compilation ) buildStructTypes( ctxs []buildContext ) {
     for _, bc := range ctxs {
         if _, ok := bc.td.( *tree.StructDecl ); ok { c.buildStructType( bc ) }
@@ -1377,7 +1540,73 @@ func ( c *Compilation ) buildEnumType( bc buildContext ) {
             seen.Put( val, true )
         }
     }
-    if ok { c.putBuiltType( ed ) }
+    ordsOk := c.setEnumOrdinals( ed, decl, bc.scope )
+    aliasesOk := c.setEnumAliases( ed, decl, seen )
+    if ok && ordsOk && aliasesOk { c.putBuiltType( ed ) }
+}
+
+func ( c *Compilation ) evaluateEnumOrdinal( 
+    exp tree.Expression, bs *buildScope ) ( int32, bool ) {
+
+    comp := c.buildExpression( exp, mg.TypeInt64, bs )
+    if comp == nil { return 0, false }
+    val := c.evaluateExpression( comp, exp.Locate() )
+    if val == nil { return 0, false }
+    i := int64( val.( mg.Int64 ) )
+    if i < math.MinInt32 || i > math.MaxInt32 {
+        c.addErrorf( exp.Locate(), "Enum ordinal out of range: %d", i )
+        return 0, false
+    }
+    return int32( i ), true
+}
+
+func ( c *Compilation ) setEnumOrdinals(
+    ed *types.EnumDefinition, decl *tree.EnumDecl, bs *buildScope ) bool {
+
+    ok, seen := true, make( map[ int32 ]bool )
+    for _, valDecl := range decl.Values {
+        if valDecl.Ordinal == nil { continue }
+        ord, evOk := c.evaluateEnumOrdinal( valDecl.Ordinal, bs )
+        if ! evOk { 
+            ok = false 
+        } else if seen[ ord ] {
+            ok = false
+            c.addErrorf( valDecl.Ordinal.Locate(), 
+                "Duplicate enum ordinal: %d", ord )
+        } else {
+            seen[ ord ] = true
+            eo := &types.EnumValueOrdinal{ Value: valDecl.Value, Ordinal: ord }
+            ed.Ordinals = append( ed.Ordinals, eo )
+        }
+    }
+    return ok
+}
+
+// seen contains the values of ed, and is updated to include each alias as it
+// is added
+func ( c *Compilation ) setEnumAliases(
+    ed *types.EnumDefinition, 
+    decl *tree.EnumDecl, 
+    seen *mg.IdentifierMap ) bool {
+
+    ok := true
+    for _, valDecl := range decl.Values {
+        for _, al := range valDecl.Aliases {
+            if seen.HasKey( al.Alias ) {
+                ok = false
+                c.addErrorf( al.AliasLoc, 
+                    "Enum alias is already a value or alias: %s", al.Alias )
+            } else {
+                seen.Put( al.Alias, true )
+                ea := &types.EnumValueAlias{ 
+                    Alias: al.Alias, 
+                    Value: valDecl.Value,
+                }
+                ed.Aliases = append( ed.Aliases, ea )
+            }
+        }
+    }
+    return ok
 }
 
 func ( c *Compilation ) buildEnumTypes( ctxs []buildContext ) {
@@ -1446,6 +1675,9 @@ func ( c *Compilation ) checkThrownType(
     case *mg.ListTypeReference:
         c.addErrorf( typLoc, "invalid thrown list type: %s", callTyp )
         return false
+    case *mg.MapTypeReference:
+        c.addErrorf( typLoc, "invalid thrown map type: %s", callTyp )
+        return false
     }
     return true
 }
@@ -1730,6 +1962,8 @@ func numExprResTypeOf(
     case qn.Equals( mg.QnameUint64 ): return mg.TypeUint64, true
     case qn.Equals( mg.QnameFloat32 ): return mg.TypeFloat32, false
     case qn.Equals( mg.QnameFloat64 ): return mg.TypeFloat64, false
+    case qn.Equals( mg.QnameBigInt ): return mg.TypeBigInt, true
+    case qn.Equals( mg.QnameDecimal ): return mg.TypeDecimal, false
     }
     bs.c.addErrorf( errLoc, "Expected %s but got number", expctType )
     return nil, false
@@ -1741,6 +1975,12 @@ func asIntExpression(
     errLoc *parser.Location,
     bs *buildScope ) *compiledExpression {
     res := &compiledExpression{ typ: resType }
+    if n.IsInt() && resType.Equals( mg.TypeBigInt ) {
+        num, err := mg.ParseNumber( n.String(), mg.QnameBigInt )
+        if err != nil { panic( implErrorf( "Couldn't process int: %s", err ) ) }
+        res.exp = &interp.Number{ num }
+        return res
+    }
     if n.IsInt() {
         var sInt int64
         var uInt uint64
@@ -1771,6 +2011,15 @@ func asFloatExpression(
     errLoc *parser.Location,
     bs *buildScope ) *compiledExpression {
     res := &compiledExpression{ typ: resType }
+    if resType.Equals( mg.TypeDecimal ) {
+        num, err := mg.ParseNumber( n.String(), mg.QnameDecimal )
+        if err != nil { 
+            bs.c.addError( errLoc, err.Error() )
+            return nil
+        }
+        res.exp = &interp.Number{ num }
+        return res
+    }
     f, err := n.Float64(); 
     if err == nil { 
         if resType.Equals( mg.TypeFloat32 ) {
@@ -1808,6 +2057,12 @@ func asStringExpression(
                 &interp.Timestamp{ tm }, mg.TypeTimestamp }
         }
         return nil
+    } else if qn := qnameIn( expctType ); isStringFormTypeName( qn ) {
+        if val, ok := bs.parseStringForm( str, qn, strLoc ); ok {
+            return &compiledExpression{ 
+                &interp.ParsedString{ val }, qn.AsAtomicType() }
+        }
+        return nil
     }
     bs.c.addErrorf( strLoc, "Expected %s but got string", expctType )
     return nil
@@ -2024,6 +2279,22 @@ func ( c *Compilation ) validateConstVal(
     return true
 }
 
+func ( c *Compilation ) evaluateExpression(
+    exp *compiledExpression, errLoc *parser.Location ) mg.Value {
+
+    val, err := interp.Evaluate( exp.exp )
+    if err == nil { return val }
+    if evErr, ok := err.( *interp.EvaluationError ); ok {
+        if ubErr, ok := evErr.Err.( *interp.UnboundIdentifierError ); ok {
+            c.addErrorf( errLoc, 
+                "Found identifier in constant expression: %s", ubErr.Id )
+            return nil
+        }
+    }
+    c.addError( errLoc, err.Error() )
+    return nil
+}
+
 func ( c *Compilation ) evaluateConstant(
     exp *compiledExpression,
     expctType mg.TypeReference,
@@ -2031,20 +2302,9 @@ func ( c *Compilation ) evaluateConstant(
     errLoc *parser.Location, 
     bs *buildScope ) mg.Value {
 
-    val, err := interp.Evaluate( exp.exp )
-    if err == nil {
-        if ! c.validateConstVal( val, expctType, dm, errLoc ) { return nil }
-    } else {
-        if evErr, ok := err.( *interp.EvaluationError ); ok {
-            if ubErr, ok := evErr.Err.( *interp.UnboundIdentifierError ); ok {
-                c.addErrorf( errLoc, 
-                    "Found identifier in constant expression: %s", ubErr.Id )
-                err = nil
-            }
-        }
-        if err != nil { c.addError( errLoc, err.Error() ) }
-        return nil
-    }
+    val := c.evaluateExpression( exp, errLoc )
+    if val == nil { return nil }
+    if ! c.validateConstVal( val, expctType, dm, errLoc ) { return nil }
     return val
 }
 
@@ -2116,6 +2376,323 @@ func ( c *Compilation ) setDefFieldDefaults( ctxs []buildContext ) {
     for _, f := range c.onDefaults { f() }
 }
 
+// returns the atomic type of the values of a field of type typ, or nil if the
+// field holds lists or maps
+func fieldValueType( typ mg.TypeReference ) *mg.AtomicTypeReference {
+    switch v := typ.( type ) {
+    case *mg.AtomicTypeReference: return v
+    case *mg.NullableTypeReference: return fieldValueType( v.Type )
+    case *mg.PointerTypeReference: return fieldValueType( v.Type )
+    }
+    return nil
+}
+
+func baseTypeIsOrdered( typ mg.TypeReference ) bool {
+    qn := qnameIn( typ )
+    return baseTypeIsNum( typ ) ||
+           qn.Equals( mg.QnameString ) ||
+           qn.Equals( mg.QnameTimestamp ) ||
+           qn.Equals( mg.QnameDate ) ||
+           qn.Equals( mg.QnameLocalTime ) ||
+           qn.Equals( mg.QnameDuration )
+}
+
+// returns the field of sd named by exp, or nil if exp is not a field name, in
+// which case an error will have been added
+func ( c *Compilation ) constraintField( 
+    exp tree.Expression, sd *types.StructDefinition ) *types.FieldDefinition {
+
+    if pe, ok := exp.( *tree.PrimaryExpression ); ok {
+        if id, ok := pe.Prim.( *mg.Identifier ); ok {
+            if fd := sd.Fields.Get( id ); fd != nil { return fd }
+            c.addErrorf( pe.PrimLoc, "No such field in constraint: %s", id )
+            return nil
+        }
+    }
+    c.addErrorf( exp, "Expected a field name" )
+    return nil
+}
+
+func isFieldNameExpression( exp tree.Expression ) bool {
+    if pe, ok := exp.( *tree.PrimaryExpression ); ok {
+        _, res := pe.Prim.( *mg.Identifier )
+        return res
+    }
+    return false
+}
+
+// returns the type of the values of fd, or nil if fd does not hold values
+// which can be compared, in which case an error will have been added at errLoc
+func ( c *Compilation ) comparableFieldType(
+    fd *types.FieldDefinition, 
+    errLoc interface{} ) *mg.AtomicTypeReference {
+
+    if at := fieldValueType( fd.Type ); at != nil {
+        switch def := c.typeDefForType( at ).( type ) {
+        case *types.EnumDefinition: return asUnrestrictedType( at )
+        case *types.PrimitiveDefinition:
+            if qn := def.Name; ! ( qn.Equals( mg.QnameValue ) || 
+                                   qn.Equals( mg.QnameSymbolMap ) ||
+                                   qn.Equals( mg.QnameNull ) ) {
+                return asUnrestrictedType( at )
+            }
+        }
+    }
+    c.addErrorf( errLoc, "Values of field %s (%s) can't be compared", 
+        fd.Name, fd.Type )
+    return nil
+}
+
+func ( c *Compilation ) buildFieldComparison(
+    be *tree.BinaryExpression, 
+    sd *types.StructDefinition,
+    dm *types.DefinitionMap,
+    bs *buildScope ) *types.FieldComparison {
+
+    op, ok := types.ComparisonOperatorOf( string( be.Op ) )
+    if ! ok {
+        c.addErrorf( be.OpLoc, "Unsupported constraint operator: %s", be.Op )
+        return nil
+    }
+    lhs, rhs := be.Left, be.Right
+    if ! isFieldNameExpression( lhs ) && isFieldNameExpression( rhs ) {
+        lhs, rhs, op = rhs, lhs, op.Reverse()
+    }
+    fd := c.constraintField( lhs, sd )
+    if fd == nil { return nil }
+    typ := c.comparableFieldType( fd, lhs )
+    if typ == nil { return nil }
+    if op.IsOrdering() && ! baseTypeIsOrdered( typ ) {
+        c.addErrorf( be.OpLoc, "Values of type %s are not ordered", typ )
+        return nil
+    }
+    res := &types.FieldComparison{ Field: fd.Name, Operator: op }
+    if isFieldNameExpression( rhs ) {
+        fd2 := c.constraintField( rhs, sd )
+        if fd2 == nil { return nil }
+        typ2 := c.comparableFieldType( fd2, rhs )
+        if typ2 == nil { return nil }
+        if ! typ.Equals( typ2 ) {
+            c.addErrorf( be.OpLoc, "Can't compare %s with %s", typ, typ2 )
+            return nil
+        }
+        res.Other = fd2.Name
+        return res
+    }
+    if exp := c.buildExpression( rhs, typ, bs ); exp != nil {
+        res.Value = c.evaluateConstant( exp, typ, dm, rhs.Locate(), bs )
+    }
+    if res.Value == nil { return nil }
+    return res
+}
+
+func ( c *Compilation ) buildFieldPresence(
+    ce *tree.CallExpression, sd *types.StructDefinition ) *types.FieldPresence {
+
+    var res *types.FieldPresence
+    for _, r := range []types.PresenceRule{ 
+        types.PresenceExactlyOne, 
+        types.PresenceAtMostOne, 
+        types.PresenceAtLeastOne,
+    } {
+        if r.Identifier().Equals( ce.Function ) { 
+            res = &types.FieldPresence{ Rule: r } 
+        }
+    }
+    if res == nil {
+        c.addErrorf( ce.FunctionLoc, "Unknown constraint function: %s", 
+            ce.Function.Format( mg.LcCamelCapped ) )
+        return nil
+    }
+    ok := true
+    for _, arg := range ce.Args {
+        if fd := c.constraintField( arg, sd ); fd == nil {
+            ok = false
+        } else { res.Fields = append( res.Fields, fd.Name ) }
+    }
+    if ok && len( ce.Args ) < 2 {
+        c.addErrorf( ce.FunctionLoc, "%s requires at least 2 fields", 
+            ce.Function.Format( mg.LcCamelCapped ) )
+        return nil
+    }
+    if ok { return res }
+    return nil
+}
+
+func ( c *Compilation ) buildStructConstraint(
+    cd *tree.ConstraintDecl, 
+    sd *types.StructDefinition,
+    dm *types.DefinitionMap,
+    bs *buildScope ) *types.StructConstraint {
+
+    res := &types.StructConstraint{}
+    switch v := cd.Expression.( type ) {
+    case *tree.BinaryExpression: 
+        if fc := c.buildFieldComparison( v, sd, dm, bs ); fc != nil {
+            res.Expression = fc
+        }
+    case *tree.CallExpression:
+        if fp := c.buildFieldPresence( v, sd ); fp != nil { 
+            res.Expression = fp 
+        }
+    default: c.addErrorf( cd.Expression, "Unsupported constraint expression" )
+    }
+    if cd.Message != nil {
+        if exp := c.buildExpression( cd.Message, mg.TypeString, bs ); 
+           exp != nil {
+            if val := c.evaluateExpression( exp, cd.Message.Locate() ); 
+               val != nil {
+                res.Message = string( val.( mg.String ) )
+            }
+        }
+    }
+    if res.Expression == nil { return nil }
+    return res
+}
+
+func ( c *Compilation ) setStructConstraints( ctxs []buildContext ) {
+    dm := c.buildConstValCastDefMap()
+    for _, bc := range ctxs {
+        decl, ok := bc.td.( *tree.StructDecl )
+        if ! ok || len( decl.Constraints ) == 0 { continue }
+        sd, ok := c.typeDefForQn( bc.qname() ).( *types.StructDefinition )
+        if ! ok { continue }
+        for _, cd := range decl.Constraints {
+            if sc := c.buildStructConstraint( cd, sd, dm, bc.scope ); 
+               sc != nil {
+                sd.Constraints = append( sd.Constraints, sc )
+            }
+        }
+    }
+}
+
+// Annotation arguments are evaluated with no expected type, so each takes the
+// natural type of its expression (Int64, Float64, String, Boolean, Enum, or a
+// list of those).
+func ( c *Compilation ) buildAnnotation( 
+    decl *tree.Annotation, bs *buildScope ) *types.Annotation {
+
+    res := &types.Annotation{ Name: decl.Name }
+    ok := true
+    for _, arg := range decl.Args {
+        var val mg.Value
+        if exp := c.buildExpression( arg, nil, bs ); exp != nil {
+            val = c.evaluateExpression( exp, arg.Locate() )
+        }
+        if val == nil { 
+            ok = false 
+        } else { res.Arguments = append( res.Arguments, val ) }
+    }
+    if ok && res.Name.Equals( types.IdDeprecated ) {
+        ok = c.checkDeprecatedAnnotation( decl, res )
+    }
+    if ok { return res }
+    return nil
+}
+
+func ( c *Compilation ) checkDeprecatedAnnotation( 
+    decl *tree.Annotation, a *types.Annotation ) bool {
+
+    switch len( a.Arguments ) {
+    case 0: return true
+    case 1: 
+        if _, ok := a.Arguments[ 0 ].( mg.String ); ok { return true }
+    }
+    c.addErrorf( decl.Start, 
+        "@%s takes at most one String argument", types.IdDeprecated )
+    return false
+}
+
+func ( c *Compilation ) buildAnnotations(
+    decls []*tree.Annotation, bs *buildScope ) []*types.Annotation {
+
+    if len( decls ) == 0 { return nil }
+    res := make( []*types.Annotation, 0, len( decls ) )
+    for _, decl := range decls {
+        if a := c.buildAnnotation( decl, bs ); a != nil { 
+            res = append( res, a ) 
+        }
+    }
+    return res
+}
+
+func ( c *Compilation ) setFieldMetadata(
+    fldDecls []*tree.FieldDecl, fs *types.FieldSet, bs *buildScope ) {
+
+    for _, fldDecl := range fldDecls {
+        if fldDef := fs.Get( fldDecl.Name ); fldDef != nil {
+            fldDef.Annotations = c.buildAnnotations( fldDecl.Annotations, bs )
+            fldDef.Doc = fldDecl.Doc
+        }
+    }
+}
+
+func ( c *Compilation ) setFieldContainerFieldMetadata(
+    bc buildContext, def types.FieldContainer ) {
+
+    c.setFieldMetadata( 
+        bc.td.( tree.FieldContainer ).GetFields(), def.GetFields(), bc.scope )
+}
+
+func ( c *Compilation ) setEnumValueMetadata(
+    bc buildContext, ed *types.EnumDefinition ) {
+
+    for _, valDecl := range bc.td.( *tree.EnumDecl ).Values {
+        if len( valDecl.Annotations ) == 0 && valDecl.Doc == "" { continue }
+        va := &types.EnumValueAnnotations{ 
+            Value: valDecl.Value,
+            Annotations: c.buildAnnotations( valDecl.Annotations, bc.scope ),
+            Doc: valDecl.Doc,
+        }
+        ed.ValueAnnotations = append( ed.ValueAnnotations, va )
+    }
+}
+
+func ( c *Compilation ) setServiceOpMetadata(
+    bc buildContext, sd *types.ServiceDefinition ) {
+
+    decl := bc.td.( *tree.ServiceDecl )
+    opDefs := types.OpDefsByName( sd.Operations )
+    for _, opDecl := range decl.Operations {
+        if opDef := opDefs.Get( opDecl.Name ); opDef != nil {
+            od := opDef.( *types.OperationDefinition )
+            od.Annotations = c.buildAnnotations( opDecl.Annotations, bc.scope )
+            od.Doc = opDecl.Doc
+            flds := od.Signature.GetFields()
+            c.setFieldMetadata( opDecl.Call.Fields, flds, bc.scope )
+        }
+    }
+}
+
+func ( c *Compilation ) setDefMetadata( ctxs []buildContext ) {
+    for _, bc := range ctxs {
+        annots := c.buildAnnotations( bc.td.GetAnnotations(), bc.scope )
+        doc := bc.td.GetDoc()
+        switch def := c.typeDefForQn( bc.qname() ).( type ) {
+        case *types.StructDefinition: 
+            def.Annotations, def.Doc = annots, doc
+            c.setFieldContainerFieldMetadata( bc, def )
+        case *types.SchemaDefinition:
+            def.Annotations, def.Doc = annots, doc
+            c.setFieldContainerFieldMetadata( bc, def )
+        case *types.PrototypeDefinition:
+            def.Annotations, def.Doc = annots, doc
+            fldDecls := bc.td.( *tree.PrototypeDecl ).Sig.Fields
+            sigFlds := def.Signature.GetFields()
+            c.setFieldMetadata( fldDecls, sigFlds, bc.scope )
+        case *types.ServiceDefinition: 
+            def.Annotations, def.Doc = annots, doc
+            c.setServiceOpMetadata( bc, def )
+        case *types.EnumDefinition:
+            def.Annotations, def.Doc = annots, doc
+            c.setEnumValueMetadata( bc, def )
+        case *types.AliasedTypeDefinition: 
+            def.Annotations, def.Doc = annots, doc
+        case *types.UnionDefinition: def.Annotations, def.Doc = annots, doc
+        }
+    }
+}
+
 func ( c *Compilation ) runBuildChecks() {
     for _, bc := range c.buildChecks { bc.check() }
 }
@@ -2141,6 +2718,12 @@ func ( c *Compilation ) buildResult() *CompilationResult {
 // - For any instantiable type involving a field default expression, redefine
 // that type, this time computing and validating the field defaults.
 //
+// - Compile the constraints declared on struct types, which may compare fields
+// with constants of the fields' types.
+//
+// - Evaluate and attach any annotations and doc comments declared on types,
+// fields, enum values, and operations.
+//
 func ( c *Compilation ) Execute() ( cr *CompilationResult, err error ) {
     if err = c.validate(); err != nil { return }
     ctxs := c.initBuildContexts()
@@ -2153,6 +2736,76 @@ func ( c *Compilation ) Execute() ( cr *CompilationResult, err error ) {
     c.buildServiceTypes( ctxs )
     c.checkNsUnitCycles()
     c.setDefFieldDefaults( ctxs )
+    c.setStructConstraints( ctxs )
+    c.setDefMetadata( ctxs )
     c.runBuildChecks()
     return c.buildResult(), nil
 }
+
+// The remaining methods are meant for tooling and are only meaningful after a
+// call to Execute(); each answers a question about the scope in which the
+// source unit declaring ns had its type names resolved.
+
+func ( c *Compilation ) scopeForNsOk( ns *mg.Namespace ) ( *buildScope, bool ) {
+    if bs := c.scopesByNs.Get( ns ); bs != nil { 
+        return bs.( *buildScope ), true 
+    }
+    return nil, false
+}
+
+// Returns the name to which nm resolves when referenced from the source unit
+// declaring ns, or nil if nm does not resolve. No compile errors are recorded.
+func ( c *Compilation ) ResolveTypeName( 
+    ns *mg.Namespace, nm mg.TypeName ) *mg.QualifiedTypeName {
+
+    bs, ok := c.scopeForNsOk( ns )
+    if ! ok { return nil }
+    prev := c.ignoreErrors
+    c.ignoreErrors = true
+    defer func() { c.ignoreErrors = prev }()
+    return bs.qnameFor( nm, nil )
+}
+
+type scopeNameAcc struct {
+    m map[ string ]*mg.QualifiedTypeName
+    names []string
+}
+
+func ( acc *scopeNameAcc ) add( qn *mg.QualifiedTypeName ) {
+    k := qn.Name.ExternalForm()
+    if _, ok := acc.m[ k ]; ok { return }
+    acc.m[ k ] = qn
+    acc.names = append( acc.names, k )
+}
+
+func ( acc *scopeNameAcc ) addExternal( 
+    ns *mg.Namespace, extTypes *types.DefinitionMap ) {
+
+    extTypes.EachDefinition( func( def types.Definition ) {
+        if qn := def.GetName(); qn.Namespace.Equals( ns ) { acc.add( qn ) }
+    })
+}
+
+// Returns, sorted by declared name, the types which may be referenced by
+// declared name alone from the source unit declaring ns. When more than one
+// type has the same declared name, only the one to which that name would
+// resolve is included.
+func ( c *Compilation ) TypeNamesInScope( 
+    ns *mg.Namespace ) []*mg.QualifiedTypeName {
+
+    bs, ok := c.scopeForNsOk( ns )
+    if ! ok { return nil }
+    acc := &scopeNameAcc{ m: make( map[ string ]*mg.QualifiedTypeName ) }
+    c.typeDecls.EachPair( func( qn *mg.QualifiedTypeName, _ interface{} ) {
+        if qn.Namespace.Equals( ns ) { acc.add( qn ) }
+    })
+    acc.addExternal( ns, c.extTypes )
+    for k, impNs := range bs.importResolves {
+        acc.add( mg.NewDeclaredTypeNameUnsafe( k ).ResolveIn( impNs ) )
+    }
+    acc.addExternal( mg.CoreNsV1, c.extTypes )
+    sort.Strings( acc.names )
+    res := make( []*mg.QualifiedTypeName, len( acc.names ) )
+    for i, k := range acc.names { res[ i ] = acc.m[ k ] }
+    return res
+}
@@ -2,7 +2,9 @@ package interp
 
 import(
     "fmt"
+    "math/big"
     mg "mingle"
+    "mingle/types"
 )
 
 type Expression interface {}
@@ -18,6 +20,13 @@ type String string
 type EnumValue struct { Value *mg.Enum }
 type Timestamp struct { Value mg.Timestamp }
 
+// a value such as a Date or Uuid already parsed from its string form
+type ParsedString struct { Value mg.Value }
+
+// a number already parsed as its mingle value, used for types such as BigInt
+// and Decimal which have no corresponding go literal type
+type Number struct { Value mg.Value }
+
 type ListValue struct { Values []Expression }
 
 func NewListValue() *ListValue { return &ListValue{ []Expression{} } }
@@ -69,6 +78,10 @@ func negate( neg *Negation, ctx *context ) ( mg.Value, error ) {
     case mg.Int64: return mg.Int64( -int64( v ) ), nil
     case mg.Float32: return mg.Float32( -float32( v ) ), nil
     case mg.Float64: return mg.Float64( -float64( v ) ), nil
+    case mg.BigInt: return mg.NewBigInt( new( big.Int ).Neg( v.Int() ) ), nil
+    case mg.Decimal: 
+        neg := new( big.Int ).Neg( v.Unscaled() )
+        return mg.NewDecimal( neg, v.Scale() ), nil
     }
     return nil, ctx.failEvalf( "Attempt to negate value of type %T", val )
 }
@@ -85,6 +98,8 @@ func evaluate( exp Expression, ctx *context ) ( mg.Value, error ) {
     case String: return mg.String( string( v ) ), nil
     case *EnumValue: return v.Value, nil
     case *Timestamp: return v.Value, nil
+    case *ParsedString: return v.Value, nil
+    case *Number: return v.Value, nil
     case *ListValue: return evalListVal( v, ctx )
     case *IdentifierReference: return evalIdRef( v, ctx )
     case *Negation: return negate( v, ctx )
@@ -96,3 +111,75 @@ func Evaluate( exp Expression ) ( mg.Value, error ) {
     ctx := &context{}
     return evaluate( exp, ctx )
 }
+
+func evalErrorf( tmpl string, argv ...interface{} ) error {
+    return &EvaluationError{ fmt.Errorf( tmpl, argv... ) }
+}
+
+// returns the value of fld in flds unless it is absent or null
+func fieldValue( 
+    fld *mg.Identifier, flds *mg.IdentifierMap ) ( mg.Value, bool ) {
+
+    if val, ok := flds.GetOk( fld ); ok && val != nil {
+        if _, isNull := val.( *mg.Null ); ! isNull { 
+            return val.( mg.Value ), true 
+        }
+    }
+    return nil, false
+}
+
+func checkFieldComparison( 
+    fc *types.FieldComparison, flds *mg.IdentifierMap ) ( bool, error ) {
+
+    v1, ok := fieldValue( fc.Field, flds )
+    if ! ok { return true, nil }
+    v2 := fc.Value
+    if fc.Other != nil {
+        if v2, ok = fieldValue( fc.Other, flds ); ! ok { return true, nil }
+    }
+    if t1, t2 := mg.TypeOf( v1 ), mg.TypeOf( v2 ); ! t1.Equals( t2 ) {
+        return false, evalErrorf( "Can't compare %s with %s", t1, t2 )
+    }
+    if ! fc.Operator.IsOrdering() {
+        eq := mg.EqualValues( v1, v2 )
+        return eq == ( fc.Operator == types.ComparisonEqual ), nil
+    }
+    cmp, ok := v1.( mg.Comparer )
+    if ! ok { 
+        return false, evalErrorf( "Values of type %s are not ordered", 
+            mg.TypeOf( v1 ) )
+    }
+    i := cmp.Compare( v2 )
+    switch fc.Operator {
+    case types.ComparisonLess: return i < 0, nil
+    case types.ComparisonLessOrEqual: return i <= 0, nil
+    case types.ComparisonGreater: return i > 0, nil
+    case types.ComparisonGreaterOrEqual: return i >= 0, nil
+    }
+    panic( fmt.Sprintf( "Unexpected comparison operator: %s", fc.Operator ) )
+}
+
+func checkFieldPresence( 
+    fp *types.FieldPresence, flds *mg.IdentifierMap ) bool {
+
+    n := 0
+    for _, fld := range fp.Fields {
+        if val, ok := flds.GetOk( fld ); ok {
+            if _, isNull := val.( *mg.Null ); ! isNull { n++ }
+        }
+    }
+    return fp.Rule.Accepts( n )
+}
+
+// Checks sc against flds, which maps each field present in a struct to its
+// value. The value may be nil for a field, such as one holding a list or
+// struct, which sc can only require to be present.
+func CheckStructConstraint( 
+    sc *types.StructConstraint, flds *mg.IdentifierMap ) ( bool, error ) {
+
+    switch v := sc.Expression.( type ) {
+    case *types.FieldComparison: return checkFieldComparison( v, flds )
+    case *types.FieldPresence: return checkFieldPresence( v, flds ), nil
+    }
+    panic( fmt.Sprintf( "Unexpected constraint: %T", sc.Expression ) )
+}
@@ -8,7 +8,9 @@ import (
     "bitgirder/assert"
     mg "mingle"
     "mingle/types"
+    "mingle/types/builtin"
     "mingle/parser"
+    "mingle/parser/tree"
 )
 
 func canFormatAsRangeSource( v mg.Value ) bool {
@@ -361,6 +363,33 @@ func TestCompiler( t *testing.T ) {
             },
         ),
 
+        newCompilerTest( "tagged-union" ).
+        setSource( `
+            @version v1
+            namespace ns1
+
+            struct S1 {}
+            union U1 { String tag( name ), String tag( label ), S1 tag( s1 ) }
+        ` ).
+        expectDef( types.MakeStructDef( "ns1@v1/S1", nil ) ).
+        expectDef(
+            &types.UnionDefinition{
+                Name: mkQn( "ns1@v1/U1" ),
+                Union: types.MustTaggedUnionTypeDefinition(
+                    []*mg.Identifier{
+                        parser.MustIdentifier( "name" ),
+                        parser.MustIdentifier( "label" ),
+                        parser.MustIdentifier( "s1" ),
+                    },
+                    []mg.TypeReference{ 
+                        mkTyp( "String" ), 
+                        mkTyp( "String" ), 
+                        mkTyp( "ns1@v1/S1" ),
+                    },
+                ),
+            },
+        ),
+
         newCompilerTest( "schema-variations" ).
         addLib( "lib1", 
             `@version v1; namespace ns2; schema Schema1 { g1 Int32 }` ).
@@ -370,6 +399,7 @@ func TestCompiler( t *testing.T ) {
 
             # we forward declare and reference some schemas so we can check that
             # the compiler is actually reordering correctly.
+
             schema Schema4 { 
                 f2 Int32
                 f3 Int32
@@ -879,7 +909,13 @@ func TestCompiler( t *testing.T ) {
                 ),
             ),
         ).
-        expectDef( types.MakeServiceDef( "ns1@v1/Service3", "ns1@v1/Sec2" ) ),
+        expectDef(
+            func() *types.ServiceDefinition {
+                sd := types.MakeServiceDef( "ns1@v1/Service3", "ns1@v1/Sec2" )
+                sd.Doc = "Test of @security with throws attr"
+                return sd
+            }(),
+        ),
 
         newCompilerTest( "field-constant-tests" ).
         addSource( "f1", `
@@ -912,6 +948,16 @@ func TestCompiler( t *testing.T ) {
                 f21 Uint32 default 4294967295
                 f22 Uint64 default 0
                 f23 Uint64 default 18446744073709551615
+                f24 BigInt default 123456789012345678901234567890
+                f25 BigInt~[-10,10] default -1
+                f26 Decimal default 1.50
+                f27 Decimal~(0,1.5] default 1
+                f28 Decimal default -2.5e-3
+                f29 Date default "2012-02-29"
+                f30 LocalTime~["09:00:00",) default "12:30:00.5"
+                f31 Duration default "PT1H30M"
+                f32 Uuid default "A0B1C2D3-E4F5-4617-8899-AABBCCDDEEFF"
+                f33 Uri default "HTTP://example.com/a"
             }
         ` ).
         expectDef(
@@ -974,6 +1020,44 @@ func TestCompiler( t *testing.T ) {
                         "mingle:core@v1/Uint64", 
                         uint64( 18446744073709551615 ),
                     ),
+                    types.MakeFieldDef(
+                        "f24",
+                        "mingle:core@v1/BigInt",
+                        mg.MustBigInt( "123456789012345678901234567890" ),
+                    ),
+                    types.MakeFieldDef( "f25", 
+                        "mingle:core@v1/BigInt~[-10,10]", 
+                        mg.MustBigInt( "-1" ),
+                    ),
+                    types.MakeFieldDef( "f26", 
+                        "mingle:core@v1/Decimal", mg.MustDecimal( "1.50" ) ),
+                    types.MakeFieldDef( "f27", 
+                        "mingle:core@v1/Decimal~(0,1.5]", 
+                        mg.MustDecimal( "1" ),
+                    ),
+                    types.MakeFieldDef( "f28", 
+                        "mingle:core@v1/Decimal", mg.MustDecimal( "-0.0025" ) ),
+                    types.MakeFieldDef( "f29", 
+                        "mingle:core@v1/Date", 
+                        parser.MustDate( "2012-02-29" ),
+                    ),
+                    types.MakeFieldDef( "f30", 
+                        `mingle:core@v1/LocalTime~["09:00:00",)`, 
+                        parser.MustLocalTime( "12:30:00.5" ),
+                    ),
+                    types.MakeFieldDef( "f31", 
+                        "mingle:core@v1/Duration", 
+                        parser.MustDuration( "PT1H30M" ),
+                    ),
+                    types.MakeFieldDef( "f32", 
+                        "mingle:core@v1/Uuid", 
+                        parser.MustUuid( 
+                            "a0b1c2d3-e4f5-4617-8899-aabbccddeeff" ),
+                    ),
+                    types.MakeFieldDef( "f33", 
+                        "mingle:core@v1/Uri", 
+                        parser.MustUri( "http://example.com/a" ),
+                    ),
                 },
             ),
         ).
@@ -1169,12 +1253,16 @@ func TestCompiler( t *testing.T ) {
             &types.AliasedTypeDefinition{
                 Name: mkQn( "ns2@v1/Alias1" ),
                 AliasedType: mkTyp( "ns1@v1/Struct3" ),
+                Doc: "alias in this namespace that points to type in " +
+                     "another namespace",
             },
         ).
         expectDef(
             &types.AliasedTypeDefinition{
                 Name: mkQn( "ns2@v1/Alias2" ),
                 AliasedType: mkTyp( "mingle:core@v1/String?*" ),
+                Doc: "alias in this namespace that points to alias in " +
+                     "another namespace",
             },
         ).
         expectDef(
@@ -1588,6 +1676,7 @@ func TestCompiler( t *testing.T ) {
 
                 # We simultaneously permute primitive num types and interval
                 # combinations with the next 4
+
                 f3 Int32~( 0, 2 ]
                 f4 Uint32~[ 0, 1 ]
                 f5 Int64~[ 0, 2 )
@@ -1597,6 +1686,13 @@ func TestCompiler( t *testing.T ) {
                 f8 Float64~[ 0.1, 2.1 )
 
                 f9 Timestamp~[ "2012-01-01T12:00:00Z", "2012-01-02T12:00:00Z" ] 
+
+                # length restrictions on strings, buffers and lists
+
+                f10 String~[ 1, 10 ]
+                f11 Buffer~( , 16 ]
+                f12 Int32*~[ 1, 3 ]
+                f13 String~[ 0, 2 ]+~( 0, 5 )
             }
         ` ).
         expectDef(
@@ -1615,6 +1711,10 @@ func TestCompiler( t *testing.T ) {
                         `mingle:core@v1/Timestamp~["2012-01-01T12:00:00Z","2012-01-02T12:00:00Z"]`,
                         nil,
                     ),
+                    fldDef( "f10", "mingle:core@v1/String~[1,10]", nil ),
+                    fldDef( "f11", "mingle:core@v1/Buffer~(,16]", nil ),
+                    fldDef( "f12", "mingle:core@v1/Int32*~[1,3]", nil ),
+                    fldDef( "f13", "mingle:core@v1/String~[0,2]+~(0,5)", nil ),
                 
                 },
             ),
@@ -1782,6 +1882,227 @@ func TestCompiler( t *testing.T ) {
         expectSrcError( "f2", 7, 29, "Unresolved type: ns1@v1/S1" ).
         expectSrcError( "f2", 7, 42, "Unresolved type: ns1@v1/S1" ),
  
+        newCompilerTest( "annotations" ).
+        setSource( `
+            @version v1
+            namespace ns1
+            @doc( "an enum" )
+            enum E1 { @wireName( "V1" ) val1, val2 }
+            @deprecated
+            struct S1 {
+                @range( 1, -2 ) f1 Int32
+                @flags( [ true, false ], E1.val2 ) f2 String
+            }
+            @doc( "a schema" ) schema Sc1 { @x f1 String }
+            @doc( 1.5 ) alias A1 String
+            @doc( "union" ) union U1 { String, Int32 }
+            @doc( "proto" ) prototype P1( @doc( "f1" ) f1 String ): String
+            @doc( "service" )
+            service Svc1 {
+                @deprecated( "use op2" )
+                op op1( @doc( "f1" ) f1 String ): String
+            }
+        ` ).
+        expectDef(
+            func() *types.EnumDefinition {
+                ed := types.MakeEnumDef( "ns1@v1/E1", "val1", "val2" )
+                ed.Annotations = []*types.Annotation{ 
+                    types.MakeAnnotation( "doc", "an enum" ),
+                }
+                ed.ValueAnnotations = []*types.EnumValueAnnotations{
+                    {
+                        Value: parser.MustIdentifier( "val1" ),
+                        Annotations: []*types.Annotation{
+                            types.MakeAnnotation( "wireName", "V1" ),
+                        },
+                    },
+                }
+                return ed
+            }(),
+        ).
+        expectDef(
+            func() *types.StructDefinition {
+                f1 := fldDef( "f1", "Int32", nil )
+                f1.Annotations = []*types.Annotation{
+                    types.MakeAnnotation( "range", int64( 1 ), int64( -2 ) ),
+                }
+                f2 := fldDef( "f2", "String", nil )
+                f2.Annotations = []*types.Annotation{
+                    types.MakeAnnotation( "flags",
+                        mg.MustList( 
+                            mkTyp( "mingle:core@v1/Value*" ), true, false ),
+                        parser.MustEnum( "ns1@v1/E1", "val2" ),
+                    ),
+                }
+                sd := types.MakeStructDef( 
+                    "ns1@v1/S1", []*types.FieldDefinition{ f1, f2 } )
+                sd.Annotations = []*types.Annotation{
+                    types.MakeAnnotation( "deprecated" ),
+                }
+                return sd
+            }(),
+        ).
+        expectDef(
+            func() *types.SchemaDefinition {
+                f1 := fldDef( "f1", "String", nil )
+                f1.Annotations = 
+                    []*types.Annotation{ types.MakeAnnotation( "x" ) }
+                sd := types.MakeSchemaDef( 
+                    "ns1@v1/Sc1", []*types.FieldDefinition{ f1 } )
+                sd.Annotations = []*types.Annotation{
+                    types.MakeAnnotation( "doc", "a schema" ),
+                }
+                return sd
+            }(),
+        ).
+        expectDef(
+            &types.AliasedTypeDefinition{
+                Name: mkQn( "ns1@v1/A1" ),
+                AliasedType: mkTyp( "mingle:core@v1/String" ),
+                Annotations: []*types.Annotation{
+                    types.MakeAnnotation( "doc", float64( 1.5 ) ),
+                },
+            },
+        ).
+        expectDef(
+            func() *types.UnionDefinition {
+                ud := types.MakeUnionDef( "ns1@v1/U1",
+                    "mingle:core@v1/String", "mingle:core@v1/Int32" )
+                ud.Annotations = []*types.Annotation{
+                    types.MakeAnnotation( "doc", "union" ),
+                }
+                return ud
+            }(),
+        ).
+        expectDef(
+            func() *types.PrototypeDefinition {
+                f1 := fldDef( "f1", "String", nil )
+                f1.Annotations = []*types.Annotation{
+                    types.MakeAnnotation( "doc", "f1" ),
+                }
+                return &types.PrototypeDefinition{
+                    Name: mkQn( "ns1@v1/P1" ),
+                    Signature: types.MakeCallSig(
+                        []*types.FieldDefinition{ f1 }, 
+                        "mingle:core@v1/String", 
+                        nil,
+                    ),
+                    Annotations: []*types.Annotation{
+                        types.MakeAnnotation( "doc", "proto" ),
+                    },
+                }
+            }(),
+        ).
+        expectDef(
+            func() *types.ServiceDefinition {
+                f1 := fldDef( "f1", "String", nil )
+                f1.Annotations = []*types.Annotation{
+                    types.MakeAnnotation( "doc", "f1" ),
+                }
+                op1 := types.MakeOpDef( "op1", 
+                    types.MakeCallSig(
+                        []*types.FieldDefinition{ f1 }, 
+                        "mingle:core@v1/String", 
+                        nil,
+                    ),
+                )
+                op1.Annotations = []*types.Annotation{
+                    types.MakeAnnotation( "deprecated", "use op2" ),
+                }
+                sd := types.MakeServiceDef( "ns1@v1/Svc1", "", op1 )
+                sd.Annotations = []*types.Annotation{
+                    types.MakeAnnotation( "doc", "service" ),
+                }
+                return sd
+            }(),
+        ),
+
+        newCompilerTest( "doc-comments" ).
+        setSource( `
+            @version v1
+            namespace ns1
+
+            # An enum
+            enum E1 { 
+                # The first value
+                val1, val2 }
+
+            # A struct
+            # over two lines
+            @deprecated
+            struct S1 {
+                f1 Int32 # trailing, not a doc
+
+                # A field
+                f2 String
+            }
+
+            # A service
+            service Svc1 {
+                # An op
+                op op1( 
+                    # A param
+                    f1 String ): String
+            }
+        ` ).
+        expectDef(
+            func() *types.EnumDefinition {
+                ed := types.MakeEnumDef( "ns1@v1/E1", "val1", "val2" )
+                ed.Doc = "An enum"
+                ed.ValueAnnotations = []*types.EnumValueAnnotations{
+                    { 
+                        Value: parser.MustIdentifier( "val1" ),
+                        Doc: "The first value",
+                    },
+                }
+                return ed
+            }(),
+        ).
+        expectDef(
+            func() *types.StructDefinition {
+                f2 := fldDef( "f2", "String", nil )
+                f2.Doc = "A field"
+                sd := types.MakeStructDef( "ns1@v1/S1", 
+                    []*types.FieldDefinition{ fldDef( "f1", "Int32", nil ), f2 },
+                )
+                sd.Annotations = []*types.Annotation{
+                    types.MakeAnnotation( "deprecated" ),
+                }
+                sd.Doc = "A struct\nover two lines"
+                return sd
+            }(),
+        ).
+        expectDef(
+            func() *types.ServiceDefinition {
+                f1 := fldDef( "f1", "String", nil )
+                f1.Doc = "A param"
+                op1 := types.MakeOpDef( "op1", 
+                    types.MakeCallSig(
+                        []*types.FieldDefinition{ f1 }, 
+                        "mingle:core@v1/String", 
+                        nil,
+                    ),
+                )
+                op1.Doc = "An op"
+                sd := types.MakeServiceDef( "ns1@v1/Svc1", "", op1 )
+                sd.Doc = "A service"
+                return sd
+            }(),
+        ),
+
+        newCompilerTest( "annotation-errors" ).
+        setSource( `
+            @version v1
+            namespace ns1
+            enum E1 { val1 }
+            struct S1 {
+                @a1( val1 ) f1 String
+                @a2( E1.val2, 1 ) f2 String
+            }
+        ` ).
+        expectError( 6, 22, "Found identifier in constant expression: val1" ).
+        expectError( 7, 25, "Invalid value for enum ns1@v1/E1: val2" ),
+
         newCompilerTest( "bad-default-vals" ).
         setSource( `
             @version v1
@@ -1810,6 +2131,8 @@ func TestCompiler( t *testing.T ) {
                 f20 Timestamp default true
                 f21 String default []
                 f22 E1 default val1 # bare enum val not ok
+                f23 BigInt default 1.5
+                f24 Decimal default "1.5"
             }
         ` ).
         expectError( 6, 36,
@@ -1846,7 +2169,9 @@ func TestCompiler( t *testing.T ) {
         expectError( 25, 39, 
             "Expected mingle:core@v1/Timestamp but got boolean" ).
         expectError( 26, 36, "List value not expected" ).
-        expectError( 27, 32, "Found identifier in constant expression: val1" ),
+        expectError( 27, 32, "Found identifier in constant expression: val1" ).
+        expectError( 28, 36, "Expected mingle:core@v1/BigInt but got float" ).
+        expectError( 29, 37, "Expected mingle:core@v1/Decimal but got string" ),
     
         newCompilerTest( "invalid-timestamp-strings" ).
         setSource( `
@@ -1858,6 +2183,33 @@ func TestCompiler( t *testing.T ) {
         expectError( 4, 46, `Invalid RFC3339 time: ""` ).
         expectError( 5, 46, `Invalid RFC3339 time: "2001-01-02.12"` ),
  
+        newCompilerTest( "invalid-temporal-strings" ).
+        setSource( `
+            @version v1
+            namespace ns1
+            struct S1 { f1 Date default "2001-02-29" }
+            struct S2 { f1 LocalTime default "12:00" }
+            struct S3 { f1 Duration default "P1M" }
+            struct S4 { f1 Date~["2001-01-01","2001-13-01"] }
+            struct S5 { f1 Duration default 1 }
+        ` ).
+        expectError( 4, 41, `Invalid ISO 8601 date: "2001-02-29"` ).
+        expectError( 5, 46, `Invalid ISO 8601 time: "12:00"` ).
+        expectError( 6, 45, `Invalid ISO 8601 duration: "P1M"` ).
+        expectError( 7, 28, `Invalid ISO 8601 date: "2001-13-01"` ).
+        expectError( 8, 45, 
+            "Expected mingle:core@v1/Duration but got number" ),
+ 
+        newCompilerTest( "invalid-id-strings" ).
+        setSource( `
+            @version v1
+            namespace ns1
+            struct S1 { f1 Uuid default "a0b1c2d3" }
+            struct S2 { f1 Uri default "a/b" }
+        ` ).
+        expectError( 4, 41, `Invalid UUID: "a0b1c2d3"` ).
+        expectError( 5, 40, `Invalid URI: "a/b"` ),
+ 
         newCompilerTest( "redefined-op-name" ).
         setSource( `
             @version v1
@@ -1890,19 +2242,276 @@ func TestCompiler( t *testing.T ) {
             struct S { 
                 f1 String~"^a+$" default "bbb"
                 f2 Int32~[8,9] default 12
+                f3 String~[1,2] default "abc"
+                f4 Int32*~[1,2] default [ 1, 2, 3 ]
             }
         ` ).
         expectError( 5, 42, 
             `Value "bbb" does not satisfy restriction "^a+$"` ).
         expectError( 6, 40,
-            "Value 12 does not satisfy restriction [8,9]" ),
+            "Value 12 does not satisfy restriction [8,9]" ).
+        expectError( 7, 41,
+            `Value "abc" does not satisfy restriction [1,2]` ).
+        expectError( 8, 41,
+            "List length 3 does not satisfy restriction [1,2]" ),
+
+        newCompilerTest( "length-restriction-errors" ).
+        setSource( `
+            @version v1
+            namespace ns
+            struct S { 
+                f1 Int32*~[ "a", "b" ]
+                f2 Boolean~[1,2]
+                f3 Int32*~[-1,2]
+            }
+        ` ).
+        expectError( 5, 29, "got string as min value for length" ).
+        expectError( 6, 29, "got number as min value for range" ).
+        expectError( 6, 31, "got number as max value for range" ).
+        expectError( 7, 20, "negative min length: -1" ),
+
+        newCompilerTest( "map-types" ).
+        setSource( `
+            @version v1
+            namespace ns1
+            struct S1 {
+                f1 {Int64}
+                f2 &{String~"^a*$"}?
+                f3 {S2}*
+                f4 {{Int32+}}
+            }
+            struct S2 {}
+        ` ).
+        expectDef(
+            types.MakeStructDef( "ns1@v1/S1",
+                []*types.FieldDefinition{
+                    types.MakeFieldDef( "f1", "{Int64}", nil ),
+                    types.MakeFieldDef( "f2", `&{String~"^a*$"}?`, nil ),
+                    types.MakeFieldDef( "f3", "{ns1@v1/S2}*", nil ),
+                    types.MakeFieldDef( "f4", "{{Int32+}}", nil ),
+                },
+            ),
+        ).
+        expectDef( types.MakeStructDef( "ns1@v1/S2", nil ) ),
 
         newCompilerTest( "duplicate-enum-constants" ).
         setSource( 
             "@version v1; namespace ns; enum E1 { c1, c2, c2, c3, c1 }" ).
         expectError( 1, 46, "Duplicate definition of enum value: c2" ).
         expectError( 1, 54, "Duplicate definition of enum value: c1" ),
+
+        newCompilerTest( "enum-ordinals-and-aliases" ).
+        setSource( `
+            @version v1
+            namespace ns1
+            enum E1 { c1: 1, c2: -2 alias( c2Old ), c3 alias( x, y ), c4 }
+        ` ).
+        expectDef(
+            func() *types.EnumDefinition {
+                ed := types.MakeEnumDef( "ns1@v1/E1", "c1", "c2", "c3", "c4" )
+                id := parser.MustIdentifier
+                ed.Ordinals = []*types.EnumValueOrdinal{
+                    { Value: id( "c1" ), Ordinal: 1 },
+                    { Value: id( "c2" ), Ordinal: -2 },
+                }
+                ed.Aliases = []*types.EnumValueAlias{
+                    { Alias: id( "c2Old" ), Value: id( "c2" ) },
+                    { Alias: id( "x" ), Value: id( "c3" ) },
+                    { Alias: id( "y" ), Value: id( "c3" ) },
+                }
+                return ed
+            }(),
+        ),
+
+        newCompilerTest( "enum-ordinal-and-alias-errors" ).
+        setSource( "@version v1; namespace ns; enum E1 { " +
+            "c1: 1, c2: 1, c3: 3000000000, c4: \"a\", " +
+            "c5 alias( c1 ), c6 alias( z, z ) }" ).
+        expectError( 1, 49, "Duplicate enum ordinal: 1" ).
+        expectError( 1, 56, "Enum ordinal out of range: 3000000000" ).
+        expectError( 1, 72, "Expected mingle:core@v1/Int64 but got string" ).
+        expectError( 1, 87, "Enum alias is already a value or alias: c1" ).
+        expectError( 1, 106, "Enum alias is already a value or alias: z" ),
     
+        newCompilerTest( "struct-constraints" ).
+        setSource( `
+            @version v1
+            namespace ns1
+            enum E1 { c1, c2 }
+            struct S1 {
+                f1 Int32
+                f2 Int32
+                f3 &String?
+                f4 String*?
+                f5 E1
+                @constraint( f1 < f2 )
+                @constraint( 0 >= f1, "f1 is positive" )
+                @constraint( f5 != E1.c2 )
+                @constraint( atMostOne( f3, f4 ) )
+            }
+        ` ).
+        expectDef( types.MakeEnumDef( "ns1@v1/E1", "c1", "c2" ) ).
+        expectDef(
+            func() *types.StructDefinition {
+                sd := types.MakeStructDef( "ns1@v1/S1", 
+                    []*types.FieldDefinition{
+                        types.MakeFieldDef( "f1", "mingle:core@v1/Int32", nil ),
+                        types.MakeFieldDef( "f2", "mingle:core@v1/Int32", nil ),
+                        types.MakeFieldDef( 
+                            "f3", "&mingle:core@v1/String?", nil ),
+                        types.MakeFieldDef( 
+                            "f4", "mingle:core@v1/String*?", nil ),
+                        types.MakeFieldDef( "f5", "ns1@v1/E1", nil ),
+                    },
+                )
+                id := parser.MustIdentifier
+                sd.Constraints = []*types.StructConstraint{
+                    {
+                        Expression: &types.FieldComparison{
+                            Field: id( "f1" ),
+                            Operator: types.ComparisonLess,
+                            Other: id( "f2" ),
+                        },
+                    },
+                    {
+                        Expression: &types.FieldComparison{
+                            Field: id( "f1" ),
+                            Operator: types.ComparisonLessOrEqual,
+                            Value: mg.Int32( 0 ),
+                        },
+                        Message: "f1 is positive",
+                    },
+                    {
+                        Expression: &types.FieldComparison{
+                            Field: id( "f5" ),
+                            Operator: types.ComparisonNotEqual,
+                            Value: parser.MustEnum( "ns1@v1/E1", "c2" ),
+                        },
+                    },
+                    {
+                        Expression: &types.FieldPresence{
+                            Rule: types.PresenceAtMostOne,
+                            Fields: []*mg.Identifier{ id( "f3" ), id( "f4" ) },
+                        },
+                    },
+                }
+                return sd
+            }(),
+        ),
+
+        newCompilerTest( "reserved-and-deprecated" ).
+        setSource( `
+            @version v1
+            namespace ns1
+            struct S1 {
+                @deprecated( "use f2" )
+                f1 Int32
+                f2 Int32
+                @reserved( f3, f4 )
+                @reserved( f5 )
+            }
+            enum E1 { red, @deprecated green }
+        ` ).
+        expectDef(
+            func() *types.StructDefinition {
+                res := types.MakeStructDef( "ns1@v1/S1",
+                    []*types.FieldDefinition{
+                        types.MakeFieldDef( "f1", "Int32", nil ),
+                        types.MakeFieldDef( "f2", "Int32", nil ),
+                    },
+                )
+                res.Fields.Get( parser.MustIdentifier( "f1" ) ).Annotations =
+                    []*types.Annotation{ 
+                        types.MakeAnnotation( "deprecated", "use f2" ),
+                    }
+                res.Reserved = []*mg.Identifier{
+                    parser.MustIdentifier( "f3" ),
+                    parser.MustIdentifier( "f4" ),
+                    parser.MustIdentifier( "f5" ),
+                }
+                return res
+            }(),
+        ).
+        expectDef(
+            func() *types.EnumDefinition {
+                res := types.MakeEnumDef( "ns1@v1/E1", "red", "green" )
+                res.ValueAnnotations = []*types.EnumValueAnnotations{
+                    {
+                        Value: parser.MustIdentifier( "green" ),
+                        Annotations: []*types.Annotation{
+                            types.MakeAnnotation( "deprecated" ),
+                        },
+                    },
+                }
+                return res
+            }(),
+        ),
+
+        newCompilerTest( "reserved-and-deprecated-errors" ).
+        setSource( `
+            @version v1
+            namespace ns1
+            schema Schema1 { f4 Int32 }
+            struct S1 {
+                @schema Schema1
+                f1 Int32
+                f2 Int32
+                @reserved( f1, f2, f1, f4 )
+            }
+            struct S2 {
+                @deprecated( 1 )
+                f1 Int32
+                @deprecated( "a", "b" )
+                f2 Int32
+            }
+        ` ).
+        expectError( 7, 17, "Field name is reserved: f1" ).
+        expectError( 8, 17, "Field name is reserved: f2" ).
+        expectError( 9, 36, "Duplicate reserved field name: f1" ).
+        expectError( 9, 40, "Field name is reserved: f4" ).
+        expectError( 12, 17, 
+            "@deprecated takes at most one String argument" ).
+        expectError( 14, 17, 
+            "@deprecated takes at most one String argument" ),
+
+        newCompilerTest( "struct-constraint-errors" ).
+        setSource( `
+            @version v1
+            namespace ns1
+            struct S1 {
+                f1 Int32
+                f2 String
+                f3 Boolean
+                f4 Int32*
+                @constraint( f1 < f9 )
+                @constraint( f1 == f2 )
+                @constraint( f3 < true )
+                @constraint( f4 == f1 )
+                @constraint( 1 < 2 )
+                @constraint( f1 < "a" )
+                @constraint( exactlyOne( f1 ) )
+                @constraint( allOf( f1, f2 ) )
+                @constraint( atLeastOne( f1, 2 ) )
+                @constraint( f1, 1 )
+                @constraint( f1 > 0, 12 )
+            }
+        ` ).
+        expectError( 9, 35, "No such field in constraint: f9" ).
+        expectError( 10, 33, 
+            "Can't compare mingle:core@v1/Int32 with mingle:core@v1/String" ).
+        expectError( 11, 33, 
+            "Values of type mingle:core@v1/Boolean are not ordered" ).
+        expectError( 12, 30, 
+            "Values of field f4 (mingle:core@v1/Int32*) can't be compared" ).
+        expectError( 13, 30, "Expected a field name" ).
+        expectError( 14, 35, "Expected mingle:core@v1/Int32 but got string" ).
+        expectError( 15, 30, "exactlyOne requires at least 2 fields" ).
+        expectError( 16, 30, "Unknown constraint function: allOf" ).
+        expectError( 17, 46, "Expected a field name" ).
+        expectError( 18, 30, "Unsupported constraint expression" ).
+        expectError( 18, 34, "Expected mingle:core@v1/String but got number" ).
+        expectError( 19, 38, "Expected mingle:core@v1/String but got number" ),
+
         newCompilerTest( "default-for-unbound-enum-type" ).
         setSource(
             "@version v1; namespace ns; struct S1 { f1 E2 default nope }" ).
@@ -1980,6 +2589,7 @@ func TestCompiler( t *testing.T ) {
             union U8 { S1, T1 }
             union U9 { S1, Null }
             union U10 { S1, Value }
+            union U11 { S1, {Int32} }
         ` ).
         expectError( 12, 13, `ambiguous types in union U1: ns1@v1/S1 ([<>, line 12, col 24]), &(ns1@v1/S1) ([<>, line 12, col 28])` ).
         expectError( 12, 13, `ambiguous types in union U1: ns1@v1/S1+ ([<>, line 12, col 33]), &(ns1@v1/S1)* ([<>, line 12, col 38])` ).
@@ -2000,7 +2610,24 @@ func TestCompiler( t *testing.T ) {
         expectError( 20, 28,
             `invalid null type in union U9: mingle:core@v1/Null` ).
         expectError( 21, 29,
-            `invalid opaque type in union U10: mingle:core@v1/Value` ),
+            `invalid opaque type in union U10: mingle:core@v1/Value` ).
+        expectError( 22, 29,
+            `invalid map type in union U11: {mingle:core@v1/Int32}` ),
+
+        newCompilerTest( "tagged-union-errors" ).
+        setSource( `
+            @version v1
+            namespace ns1
+
+            struct S1 {}
+            union U1 { S1 tag( a ), String tag( b ), Int32 tag( a ) }
+            union U2 { S1 tag( a ), String }
+            union U3 { S1 tag( a ), Value tag( b ) }
+        ` ).
+        expectError( 6, 65, "Duplicate union tag: a" ).
+        expectError( 7, 37, "Missing tag for type in tagged union U2" ).
+        expectError( 8, 37, 
+            `invalid opaque type in union U3: mingle:core@v1/Value` ),
 
         newCompilerTest( "ambiguous-type-selector-errors" ).
         setSource( `
@@ -2052,10 +2679,12 @@ func TestCompiler( t *testing.T ) {
             struct S1 {}
             struct S2 {}
             prototype P1(): Null throws Int32, S1*, &S2?
+            prototype P2(): Null throws {S1}
         ` ).
         expectError( 6, 41, "invalid thrown type: mingle:core@v1/Int32" ).
         expectError( 6, 48, "invalid thrown list type: ns@v1/S1*" ).
-        expectError( 6, 53, "invalid thrown nullable type: &(ns@v1/S2)?" ),
+        expectError( 6, 53, "invalid thrown nullable type: &(ns@v1/S2)?" ).
+        expectError( 7, 41, "invalid thrown map type: {ns@v1/S1}" ),
     
         newCompilerTest( "alias-errors" ).
         setSource( `
@@ -2294,3 +2923,51 @@ func TestCompiler( t *testing.T ) {
         test.call()
     }
 }
+
+func TestTypeNameQueries( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    c := NewCompilation().SetExternalTypes( builtin.BuiltinTypes() )
+    for _, src := range []string{
+        "@version v1; namespace ns1; struct S1 {}; struct String {}",
+        "@version v1; import ns1/*; namespace ns2; struct S2 {}",
+    } {
+        bb := bytes.NewBufferString( src )
+        nsUnit, err := tree.ParseSource( "<input>", bb )
+        if err != nil { a.Fatal( err ) }
+        c.AddSource( nsUnit )
+    }
+    cr, err := c.Execute()
+    if err != nil { a.Fatal( err ) }
+    if len( cr.Errors ) > 0 { failCompilerTest( cr, t ) }
+    ns2 := parser.MustNamespace( "ns2@v1" )
+    chkResolve := func( nm mg.TypeName, expct string ) {
+        qn := c.ResolveTypeName( ns2, nm )
+        la := a.Descend( nm.ExternalForm() )
+        if expct == "" {
+            la.Truef( qn == nil, "resolved to %s", qn )
+        } else { la.Equal( mkQn( expct ), qn ) }
+    }
+    declNm := parser.MustDeclaredTypeName
+    chkResolve( declNm( "S1" ), "ns1@v1/S1" )
+    chkResolve( declNm( "S2" ), "ns2@v1/S2" )
+    chkResolve( declNm( "Int32" ), "mingle:core@v1/Int32" )
+    chkResolve( declNm( "String" ), "ns1@v1/String" )
+    chkResolve( mkQn( "ns1@v1/S1" ), "ns1@v1/S1" )
+    chkResolve( declNm( "S3" ), "" )
+    chkResolve( mkQn( "ns1@v1/S3" ), "" )
+    a.Equal( 0, len( cr.Errors ) )
+    inScope := c.TypeNamesInScope( ns2 )
+    m := make( map[ string ]string )
+    for _, qn := range inScope { m[ qn.Name.ExternalForm() ] = qn.String() }
+    a.Equal( "ns1@v1/S1", m[ "S1" ] )
+    a.Equal( "ns2@v1/S2", m[ "S2" ] )
+    a.Equal( "ns1@v1/String", m[ "String" ] )
+    a.Equal( "mingle:core@v1/Int32", m[ "Int32" ] )
+    for i := 1; i < len( inScope ); i++ {
+        nm1, nm2 := inScope[ i - 1 ].Name, inScope[ i ].Name
+        a.Truef( nm1.ExternalForm() < nm2.ExternalForm(), 
+            "not sorted: %s, %s", nm1, nm2 )
+    }
+    a.Truef( c.TypeNamesInScope( parser.MustNamespace( "ns3@v1" ) ) == nil,
+        "expected nil scope names for unknown namespace" )
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+    "bytes"
+    "flag"
+    "fmt"
+    "io/ioutil"
+    "log"
+    "os"
+    "mingle/format"
+)
+
+var check bool
+var write bool
+
+func parseArgs() {
+    flag.BoolVar( &check, "check", false,
+        "List unformatted files and exit non-zero if there are any" )
+    flag.BoolVar( &write, "w", false,
+        "Write formatted sources back to their files" )
+    flag.Parse()
+    if check && write {
+        fmt.Fprintln( os.Stderr, "-check and -w are mutually exclusive" )
+        flag.PrintDefaults()
+        os.Exit( 2 )
+    }
+    if write && flag.NArg() == 0 {
+        fmt.Fprintln( os.Stderr, "-w requires at least one file" )
+        flag.PrintDefaults()
+        os.Exit( 2 )
+    }
+}
+
+// Returns true if the file was already formatted
+func formatFile( file string ) ( bool, error ) {
+    src, err := ioutil.ReadFile( file )
+    if err != nil { return false, err }
+    res, err := format.FormatSource( file, src )
+    if err != nil { return false, err }
+    ok := bytes.Equal( src, res )
+    switch {
+    case check: if ! ok { fmt.Println( file ) }
+    case write: if ! ok { err = ioutil.WriteFile( file, res, 0644 ) }
+    default: _, err = os.Stdout.Write( res )
+    }
+    return ok, err
+}
+
+// Returns true if stdin was already formatted
+func formatStdin() ( bool, error ) {
+    src, err := ioutil.ReadAll( os.Stdin )
+    if err != nil { return false, err }
+    res, err := format.FormatSource( "<stdin>", src )
+    if err != nil { return false, err }
+    ok := bytes.Equal( src, res )
+    if check {
+        if ! ok { fmt.Println( "<stdin>" ) }
+    } else { _, err = os.Stdout.Write( res ) }
+    return ok, err
+}
+
+func main() {
+    parseArgs()
+    allFormatted := true
+    if flag.NArg() == 0 {
+        ok, err := formatStdin()
+        if err != nil { log.Fatal( err ) }
+        allFormatted = ok
+    }
+    for _, file := range flag.Args() {
+        ok, err := formatFile( file )
+        if err != nil { log.Fatal( err ) }
+        allFormatted = allFormatted && ok
+    }
+    if check && ! allFormatted { os.Exit( 1 ) }
+}
@@ -0,0 +1,14 @@
+package format
+
+import (
+    "fmt"
+    "errors"
+)
+
+func libError( msg string ) error {
+    return errors.New( "mingle/format: " + msg )
+}
+
+func libErrorf( tmpl string, argv ...interface{} ) error {
+    return fmt.Errorf( "mingle/format: " + tmpl, argv... )
+}
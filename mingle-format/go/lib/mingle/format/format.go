@@ -0,0 +1,511 @@
+// Package format reprints mingle sources in a canonical layout.
+//
+// Imports are sorted, field types within each run of fields are aligned, and
+// expressions, restrictions and type references are written with uniform
+// spacing. Declarations are separated by a single blank line. Doc comments are
+// reprinted from the declarations to which they are attached, and all other
+// comments are kept in place relative to the declarations around them, with a
+// blank line following any comment which would otherwise be taken as a doc.
+package format
+
+import (
+    "bytes"
+    "sort"
+    "strings"
+    mg "mingle"
+    "mingle/parser"
+    "mingle/parser/tree"
+)
+
+const indent = "    "
+
+const maxLineWidth = 80
+
+type printer struct {
+    ver *mg.Identifier
+    lines []string
+    depth int
+
+    // comments not yet printed, in source order
+    comments []*tree.Comment
+
+    // true just after a line which opens a block, where no blank line is
+    // wanted
+    atOpen bool
+}
+
+func lineOf( lc *parser.Location ) int {
+    if lc == nil { return 0 }
+    return lc.Line
+}
+
+func ( p *printer ) emit( s string ) {
+    p.lines = append( p.lines, strings.Repeat( indent, p.depth ) + s )
+    p.atOpen = false
+}
+
+func ( p *printer ) blank() {
+    if p.atOpen || len( p.lines ) == 0 { return }
+    if p.lines[ len( p.lines ) - 1 ] != "" { p.lines = append( p.lines, "" ) }
+}
+
+func ( p *printer ) fits( s string ) bool {
+    return len( indent ) * p.depth + len( s ) <= maxLineWidth
+}
+
+func ( p *printer ) hasCommentsBefore( line int ) bool {
+    return len( p.comments ) > 0 && p.comments[ 0 ].Loc.Line < line
+}
+
+// Prints, each on its own line, all comments from before line, returning true
+// if there were any
+func ( p *printer ) flushBefore( line int ) bool {
+    n := 0
+    for ; n < len( p.comments ) && p.comments[ n ].Loc.Line < line; n++ {
+        p.emit( "#" + p.comments[ n ].Text )
+    }
+    p.comments = p.comments[ n : ]
+    return n > 0
+}
+
+func ( p *printer ) attachTrailing( line int ) {
+    if len( p.comments ) == 0 { return }
+    if c := p.comments[ 0 ]; c.Trailing && c.Loc.Line == line {
+        p.lines[ len( p.lines ) - 1 ] += " #" + c.Text
+        p.comments = p.comments[ 1 : ]
+    }
+}
+
+// Prints s as the rendering of source line srcLine, preceded by any comments
+// from earlier lines and followed by any comment trailing srcLine, unless
+// shared is true, meaning that some element printed later also comes from
+// srcLine and the comment trails that element instead
+func ( p *printer ) lineSharing( srcLine int, s string, shared bool ) {
+    if p.flushBefore( srcLine ) { p.blank() }
+    p.emit( s )
+    if ! shared { p.attachTrailing( srcLine ) }
+}
+
+func ( p *printer ) line( srcLine int, s string ) {
+    p.lineSharing( srcLine, s, false )
+}
+
+func ( p *printer ) openSharing( srcLine int, s string, shared bool ) {
+    p.lineSharing( srcLine, s, shared )
+    p.depth++
+    p.atOpen = true
+}
+
+func ( p *printer ) open( srcLine int, s string ) {
+    p.openSharing( srcLine, s, false )
+}
+
+func ( p *printer ) close( srcLine int, s string ) {
+    p.flushBefore( srcLine )
+    p.depth--
+    p.emit( s )
+    p.attachTrailing( srcLine )
+}
+
+// Removes the comments which the parser took as the doc for an element
+// beginning on line start
+func ( p *printer ) dropDoc( start int, doc string ) {
+    if doc == "" { return }
+    first := start - ( strings.Count( doc, "\n" ) + 1 )
+    res := make( []*tree.Comment, 0, len( p.comments ) )
+    for _, c := range p.comments {
+        ln := c.Loc.Line
+        if c.Trailing || ln < first || ln >= start { res = append( res, c ) }
+    }
+    p.comments = res
+}
+
+func ( p *printer ) dropFieldDocs( flds []*tree.FieldDecl ) {
+    for _, fd := range flds {
+        p.dropDoc( decorationStart( fd.NameLoc, fd.Annotations ), fd.Doc )
+    }
+}
+
+// Removes all doc comments up front, since elements are not always printed in
+// source order, and a doc not yet dropped when an element preceding it in the
+// output is printed would otherwise be flushed as a free comment
+func ( p *printer ) dropDocs( u *tree.NsUnit ) {
+    for _, td := range u.TypeDecls {
+        p.dropDoc( decorationStart( td.Locate(), td.GetAnnotations() ),
+            td.GetDoc() )
+        switch v := td.( type ) {
+        case *tree.StructDecl: p.dropFieldDocs( v.Fields )
+        case *tree.SchemaDecl: p.dropFieldDocs( v.Fields )
+        case *tree.PrototypeDecl: p.dropFieldDocs( v.Sig.Fields )
+        case *tree.EnumDecl:
+            for _, ev := range v.Values {
+                start := decorationStart( ev.ValueLoc, ev.Annotations )
+                p.dropDoc( start, ev.Doc )
+            }
+        case *tree.ServiceDecl:
+            for _, od := range v.Operations {
+                start := decorationStart( od.NameLoc, od.Annotations )
+                p.dropDoc( start, od.Doc )
+                p.dropFieldDocs( od.Call.Fields )
+            }
+        }
+    }
+}
+
+func decorationStart( lc *parser.Location, annots []*tree.Annotation ) int {
+    if len( annots ) > 0 { return lineOf( annots[ 0 ].Start ) }
+    return lineOf( lc )
+}
+
+func isDecorated( doc string, annots []*tree.Annotation ) bool {
+    return doc != "" || len( annots ) > 0
+}
+
+// Prints the doc and annotations of an element at lc
+func ( p *printer ) decorate(
+    lc *parser.Location, doc string, annots []*tree.Annotation ) {
+
+    start := decorationStart( lc, annots )
+    if p.flushBefore( start ) { p.blank() }
+    if doc != "" {
+        for _, ln := range strings.Split( doc, "\n" ) {
+            if ln == "" { p.emit( "#" ) } else { p.emit( "# " + ln ) }
+        }
+    }
+    for _, a := range annots {
+        p.line( lineOf( a.Start ), p.annotationString( a ) )
+    }
+}
+
+// Prints flds with their types aligned within each run of fields, a run being
+// broken by any field having a doc or annotations
+func ( p *printer ) printFields( flds []*tree.FieldDecl, sep string ) {
+    width := 0
+    for i, fld := range flds {
+        decorated := isDecorated( fld.Doc, fld.Annotations )
+        if i == 0 || decorated {
+            width = 0
+            for j, fld2 := range flds[ i : ] {
+                if j > 0 && isDecorated( fld2.Doc, fld2.Annotations ) { break }
+                nm := identifierString( fld2.Name )
+                if len( nm ) > width { width = len( nm ) }
+            }
+        }
+        if i > 0 && decorated { p.blank() }
+        p.decorate( fld.NameLoc, fld.Doc, fld.Annotations )
+        p.line( lineOf( fld.NameLoc ), p.fieldString( fld, width, sep ) )
+    }
+}
+
+func anyFieldDecorated( flds []*tree.FieldDecl ) bool {
+    for _, fld := range flds {
+        if isDecorated( fld.Doc, fld.Annotations ) { return true }
+    }
+    return false
+}
+
+func ( p *printer ) callTail( cs *tree.CallSignature ) string {
+    res := ": " + p.typeReferenceString( cs.Return )
+    if len( cs.Throws ) > 0 {
+        strs := make( []string, len( cs.Throws ) )
+        for i, tt := range cs.Throws {
+            strs[ i ] = p.typeReferenceString( tt.Type )
+        }
+        res += " throws " + strings.Join( strs, ", " )
+    }
+    return res
+}
+
+// Returns the single line form of the call signature cs beginning with head,
+// such as "op op1" or "prototype P1", or the empty string if it must be
+// printed with one field per line
+func ( p *printer ) callLine( head string, cs *tree.CallSignature ) string {
+    endLine := lineOf( cs.FieldsEnd )
+    if anyFieldDecorated( cs.Fields ) || p.hasCommentsBefore( endLine ) {
+        return ""
+    }
+    res := head + "()" + p.callTail( cs )
+    if len( cs.Fields ) > 0 {
+        strs := make( []string, len( cs.Fields ) )
+        for i, fld := range cs.Fields {
+            strs[ i ] = p.fieldString( fld, 0, "" )
+        }
+        res = head + "( " + strings.Join( strs, ", " ) + " )" + p.callTail( cs )
+    }
+    if p.fits( res ) { return res }
+    return ""
+}
+
+func ( p *printer ) printCall(
+    srcLine int, head string, cs *tree.CallSignature ) {
+
+    if s := p.callLine( head, cs ); s != "" {
+        p.line( srcLine, s )
+        return
+    }
+    p.open( srcLine, head + "(" )
+    p.printFields( cs.Fields, "," )
+    p.close( lineOf( cs.FieldsEnd ), ")" + p.callTail( cs ) )
+}
+
+// Prints a block whose elements are printed by body, or, if hasElements is
+// false and there are no comments within the block, prints the block as a
+// single line
+func ( p *printer ) printBlock(
+    head string,
+    start, end *parser.Location,
+    hasElements bool,
+    body func() ) {
+
+    if ! ( hasElements || p.hasCommentsBefore( lineOf( end ) ) ) {
+        p.line( lineOf( start ), head + " {}" )
+        return
+    }
+    p.open( lineOf( start ), head + " {" )
+    body()
+    p.close( lineOf( end ), "}" )
+}
+
+// Prints elements, which are the string forms of the elements at locs, on a
+// single line if they fit and none has a doc or annotations, and otherwise
+// one per line
+func ( p *printer ) printList(
+    head string,
+    start, end *parser.Location,
+    elts []string,
+    locs []*parser.Location,
+    docs []string,
+    annots [][]*tree.Annotation ) {
+
+    oneLine := ! p.hasCommentsBefore( lineOf( end ) )
+    for i := 0; oneLine && i < len( elts ); i++ {
+        oneLine = ! isDecorated( docs[ i ], annots[ i ] )
+    }
+    if oneLine {
+        s := head + " { " + strings.Join( elts, ", " ) + " }"
+        if p.fits( s ) {
+            p.line( lineOf( start ), s )
+            return
+        }
+    }
+    // a comment trailing a source line goes with the last element on it
+    nextLine := func( i int ) int {
+        if i < len( locs ) { return lineOf( locs[ i ] ) }
+        return lineOf( end )
+    }
+    startLine := lineOf( start )
+    p.openSharing( startLine, head + " {", nextLine( 0 ) == startLine )
+    for i, elt := range elts {
+        decorated := isDecorated( docs[ i ], annots[ i ] )
+        if i > 0 && decorated { p.blank() }
+        p.decorate( locs[ i ], docs[ i ], annots[ i ] )
+        ln := lineOf( locs[ i ] )
+        p.lineSharing( ln, elt + ",", nextLine( i + 1 ) == ln )
+    }
+    p.close( lineOf( end ), "}" )
+}
+
+func ( p *printer ) printStructure(
+    kwd string,
+    start *parser.Location,
+    info *tree.TypeDeclInfo,
+    schemas []*tree.SchemaMixinDecl,
+    flds []*tree.FieldDecl,
+    cons []*tree.ConstructorDecl,
+    cstrs []*tree.ConstraintDecl,
+    rsvd []*tree.ReservedDecl,
+    end *parser.Location ) {
+
+    hasElts := len( schemas ) > 0 || len( flds ) > 0 || len( cons ) > 0 ||
+        len( cstrs ) > 0 || len( rsvd ) > 0
+    head := kwd + " " + info.Name.ExternalForm()
+    p.printBlock( head, start, end, hasElts, func() {
+        for _, sd := range schemas {
+            s := "@schema " + p.typeNameString( sd.Name )
+            p.line( lineOf( sd.Start ), s )
+        }
+        if len( flds ) > 0 {
+            p.blank()
+            p.printFields( flds, "" )
+        }
+        if len( cons ) > 0 { p.blank() }
+        for _, cd := range cons {
+            s := "@constructor( " + p.typeReferenceString( cd.ArgType ) + " )"
+            p.line( lineOf( cd.Start ), s )
+        }
+        if len( cstrs ) > 0 { p.blank() }
+        for _, cd := range cstrs {
+            p.line( lineOf( cd.Start ), p.constraintString( cd ) )
+        }
+        if len( rsvd ) > 0 { p.blank() }
+        for _, rd := range rsvd {
+            p.line( lineOf( rd.Start ), reservedString( rd ) )
+        }
+    })
+}
+
+func ( p *printer ) printEnum( ed *tree.EnumDecl ) {
+    l := len( ed.Values )
+    elts, locs := make( []string, l ), make( []*parser.Location, l )
+    docs, annots := make( []string, l ), make( [][]*tree.Annotation, l )
+    for i, ev := range ed.Values {
+        elts[ i ], locs[ i ] = p.enumValueString( ev ), ev.ValueLoc
+        docs[ i ], annots[ i ] = ev.Doc, ev.Annotations
+    }
+    head := "enum " + ed.Name.ExternalForm()
+    p.printList( head, ed.Start, ed.End, elts, locs, docs, annots )
+}
+
+func ( p *printer ) printUnion( ud *tree.UnionDecl ) {
+    l := len( ud.Types )
+    elts, locs := make( []string, l ), make( []*parser.Location, l )
+    for i, typ := range ud.Types {
+        elts[ i ], locs[ i ] = p.typeReferenceString( typ ), typ.Location()
+        if ud.Tags != nil && ud.Tags[ i ] != nil {
+            elts[ i ] += " tag( " + identifierString( ud.Tags[ i ].Tag ) + " )"
+        }
+    }
+    head := "union " + ud.Info.Name.ExternalForm()
+    p.printList( head, ud.Start, ud.End, elts, locs,
+        make( []string, l ), make( [][]*tree.Annotation, l ) )
+}
+
+func ( p *printer ) printService( sd *tree.ServiceDecl ) {
+    hasElts := len( sd.SecurityDecls ) > 0 || len( sd.Operations ) > 0
+    head := "service " + sd.Info.Name.ExternalForm()
+    p.printBlock( head, sd.Start, sd.End, hasElts, func() {
+        for _, sec := range sd.SecurityDecls {
+            s := "@security " + p.typeNameString( sec.Name )
+            p.line( lineOf( sec.Start ), s )
+        }
+        if len( sd.Operations ) > 0 { p.blank() }
+        // operations printed on more than one line, or with a doc or
+        // annotations, are set off from their neighbors by blank lines
+        prevMulti := false
+        for i, od := range sd.Operations {
+            head := "op " + identifierString( od.Name )
+            multi := isDecorated( od.Doc, od.Annotations ) ||
+                p.callLine( head, od.Call ) == ""
+            if i > 0 && ( multi || prevMulti ) { p.blank() }
+            p.decorate( od.NameLoc, od.Doc, od.Annotations )
+            p.printCall( lineOf( od.NameLoc ), head, od.Call )
+            prevMulti = multi
+        }
+    })
+}
+
+func ( p *printer ) printTypeDecl( td tree.TypeDecl ) {
+    p.decorate( td.Locate(), td.GetDoc(), td.GetAnnotations() )
+    switch v := td.( type ) {
+    case *tree.StructDecl:
+        p.printStructure( "struct", v.Start, v.Info, v.Schemas, v.Fields,
+            v.Constructors, v.Constraints, v.Reserved, v.End )
+    case *tree.SchemaDecl:
+        p.printStructure( "schema", v.Start, v.Info, v.Schemas, v.Fields,
+            nil, nil, nil, v.End )
+    case *tree.EnumDecl: p.printEnum( v )
+    case *tree.UnionDecl: p.printUnion( v )
+    case *tree.ServiceDecl: p.printService( v )
+    case *tree.AliasDecl:
+        s := "alias " + v.Name.ExternalForm() + " " +
+            p.typeReferenceString( v.Target )
+        p.line( lineOf( v.Start ), s )
+    case *tree.PrototypeDecl:
+        head := "prototype " + v.Name.ExternalForm()
+        p.printCall( lineOf( v.Start ), head, v.Sig )
+    default: panic( libErrorf( "unhandled type decl: %T", td ) )
+    }
+}
+
+type typeListSort []*tree.TypeListEntry
+
+func ( s typeListSort ) Len() int { return len( s ) }
+
+func ( s typeListSort ) Swap( i, j int ) { s[ i ], s[ j ] = s[ j ], s[ i ] }
+
+func ( s typeListSort ) Less( i, j int ) bool {
+    return s[ i ].Name.ExternalForm() < s[ j ].Name.ExternalForm()
+}
+
+func sortedTypeList( l []*tree.TypeListEntry ) []*tree.TypeListEntry {
+    res := append( []*tree.TypeListEntry{}, l... )
+    sort.Sort( typeListSort( res ) )
+    return res
+}
+
+// imports paired with their printed forms, by which they are sorted
+type importSort struct {
+    imports []*tree.Import
+    strs []string
+}
+
+func ( s *importSort ) Len() int { return len( s.imports ) }
+
+func ( s *importSort ) Swap( i, j int ) { 
+    s.imports[ i ], s.imports[ j ] = s.imports[ j ], s.imports[ i ]
+    s.strs[ i ], s.strs[ j ] = s.strs[ j ], s.strs[ i ]
+}
+
+func ( s *importSort ) Less( i, j int ) bool {
+    return s.strs[ i ] < s.strs[ j ]
+}
+
+func ( p *printer ) printImports( imports []*tree.Import ) {
+    if len( imports ) == 0 { return }
+    is := &importSort{
+        imports: make( []*tree.Import, len( imports ) ),
+        strs: make( []string, len( imports ) ),
+    }
+    for i, imprt := range imports {
+        sorted := *imprt
+        sorted.Includes = sortedTypeList( imprt.Includes )
+        sorted.Excludes = sortedTypeList( imprt.Excludes )
+        is.imports[ i ], is.strs[ i ] = &sorted, p.importString( &sorted )
+    }
+    sort.Stable( is )
+    p.blank()
+    for i, imprt := range is.imports {
+        p.line( lineOf( imprt.Start ), is.strs[ i ] )
+    }
+}
+
+func ( p *printer ) printNsUnit( u *tree.NsUnit ) {
+    p.line( lineOf( u.VersionLoc ), "@version " + identifierString( p.ver ) )
+    p.printImports( u.Imports )
+    p.blank()
+    nsLine := lineOf( u.NsDecl.Start )
+    p.line( nsLine, "namespace " + p.namespaceString( u.NsDecl.Namespace ) )
+    for _, td := range u.TypeDecls {
+        p.blank()
+        p.printTypeDecl( td )
+    }
+    if len( p.comments ) > 0 {
+        p.blank()
+        p.flushBefore( int( ^uint( 0 ) >> 1 ) )
+    }
+}
+
+// Returns u printed in canonical form. The comments of u are expected to be
+// those collected by tree.ParseSource, and so to be in source order.
+func FormatNsUnit( u *tree.NsUnit ) []byte {
+    p := &printer{
+        ver: u.NsDecl.Namespace.Version,
+        comments: append( []*tree.Comment{}, u.Comments... ),
+    }
+    p.dropDocs( u )
+    p.printNsUnit( u )
+    buf := &bytes.Buffer{}
+    for _, ln := range p.lines {
+        buf.WriteString( strings.TrimRight( ln, " " ) )
+        buf.WriteByte( '\n' )
+    }
+    return buf.Bytes()
+}
+
+// Parses src, reporting errors as being in srcNm, and returns its canonical
+// form
+func FormatSource( srcNm string, src []byte ) ( []byte, error ) {
+    u, err := tree.ParseSource( srcNm, bytes.NewReader( src ) )
+    if err != nil { return nil, err }
+    return FormatNsUnit( u ), nil
+}
@@ -0,0 +1,225 @@
+package format
+
+import (
+    "bytes"
+    "fmt"
+    "strings"
+    mg "mingle"
+    "mingle/parser"
+    "mingle/parser/tree"
+)
+
+func identifierString( id *mg.Identifier ) string {
+    return id.Format( mg.LcCamelCapped )
+}
+
+// Renders strings using only the escapes understood by the lexer
+func quoteString( s string ) string {
+    buf := &bytes.Buffer{}
+    buf.WriteRune( '"' )
+    for _, r := range s {
+        switch r {
+        case '"': buf.WriteString( `\"` )
+        case '\\': buf.WriteString( `\\` )
+        case '\n': buf.WriteString( `\n` )
+        case '\r': buf.WriteString( `\r` )
+        case '\t': buf.WriteString( `\t` )
+        case '\f': buf.WriteString( `\f` )
+        case '\b': buf.WriteString( `\b` )
+        default:
+            if r < 32 {
+                fmt.Fprintf( buf, `\u%04X`, r )
+            } else { buf.WriteRune( r ) }
+        }
+    }
+    buf.WriteRune( '"' )
+    return buf.String()
+}
+
+// Namespaces and qualified type names are written without a version when
+// their version is that of the source itself
+func ( p *printer ) namespaceString( ns *mg.Namespace ) string {
+    strs := make( []string, len( ns.Parts ) )
+    for i, part := range ns.Parts { strs[ i ] = identifierString( part ) }
+    res := strings.Join( strs, ":" )
+    if ! ns.Version.Equals( p.ver ) {
+        res += "@" + identifierString( ns.Version )
+    }
+    return res
+}
+
+func ( p *printer ) typeNameString( nm mg.TypeName ) string {
+    switch v := nm.( type ) {
+    case *mg.DeclaredTypeName: return v.ExternalForm()
+    case *mg.QualifiedTypeName:
+        return p.namespaceString( v.Namespace ) + "/" + v.Name.ExternalForm()
+    }
+    panic( libErrorf( "unhandled type name: %T", nm ) )
+}
+
+func restrictionValueString( sx parser.RestrictionSyntax ) string {
+    switch v := sx.( type ) {
+    case nil: return ""
+    case *parser.StringRestrictionSyntax: return quoteString( v.Str )
+    case *parser.NumRestrictionSyntax: return v.LiteralString()
+    }
+    panic( libErrorf( "unhandled restriction value: %T", sx ) )
+}
+
+func restrictionString( sx parser.RestrictionSyntax ) string {
+    switch v := sx.( type ) {
+    case nil: return ""
+    case *parser.RegexRestrictionSyntax: return "~" + quoteString( v.Pat )
+    case *parser.RangeRestrictionSyntax:
+        open, close := "(", ")"
+        if v.LeftClosed { open = "[" }
+        if v.RightClosed { close = "]" }
+        return fmt.Sprintf( "~%s%s,%s%s", open,
+            restrictionValueString( v.Left ),
+            restrictionValueString( v.Right ),
+            close,
+        )
+    }
+    panic( libErrorf( "unhandled restriction: %T", sx ) )
+}
+
+func ( p *printer ) typeExpressionString( e interface{} ) string {
+    switch v := e.( type ) {
+    case *parser.AtomicTypeExpression:
+        return p.typeNameString( v.Name ) + restrictionString( v.Restriction )
+    case *parser.ListTypeExpression:
+        quant := "+"
+        if v.AllowsEmpty { quant = "*" }
+        return p.typeExpressionString( v.Expression ) + quant +
+            restrictionString( v.Restriction )
+    case *parser.MapTypeExpression:
+        return "{" + p.typeExpressionString( v.Expression ) + "}"
+    case *parser.NullableTypeExpression:
+        return p.typeExpressionString( v.Expression ) + "?"
+    case *parser.PointerTypeExpression:
+        s := p.typeExpressionString( v.Expression )
+        switch v.Expression.( type ) {
+        case *parser.ListTypeExpression, *parser.NullableTypeExpression:
+            s = "(" + s + ")"
+        }
+        return "&" + s
+    }
+    panic( libErrorf( "unhandled type expression: %T", e ) )
+}
+
+func ( p *printer ) typeReferenceString(
+    ref *parser.CompletableTypeReference ) string {
+
+    return p.typeExpressionString( ref.Expression )
+}
+
+func ( p *printer ) primaryString( pe *tree.PrimaryExpression ) string {
+    switch v := pe.Prim.( type ) {
+    case parser.StringToken: return quoteString( string( v ) )
+    case *parser.NumericToken: return v.String()
+    case parser.Keyword: return string( v )
+    case *mg.Identifier: return identifierString( v )
+    case *parser.CompletableTypeReference: return p.typeReferenceString( v )
+    }
+    panic( libErrorf( "unhandled primary expression: %T", pe.Prim ) )
+}
+
+func ( p *printer ) expressionsString( exps []tree.Expression ) string {
+    strs := make( []string, len( exps ) )
+    for i, e := range exps { strs[ i ] = p.expressionString( e ) }
+    return strings.Join( strs, ", " )
+}
+
+// Binary expressions are printed without grouping since the parser applies
+// arithmetic operators strictly left to right, and a comparison may only
+// appear outermost
+func ( p *printer ) expressionString( e tree.Expression ) string {
+    switch v := e.( type ) {
+    case *tree.PrimaryExpression: return p.primaryString( v )
+    case *tree.QualifiedExpression:
+        return p.expressionString( v.Lhs ) + "." + identifierString( v.Id )
+    case *tree.UnaryExpression:
+        return string( v.Op ) + p.expressionString( v.Exp )
+    case *tree.BinaryExpression:
+        return fmt.Sprintf( "%s %s %s", p.expressionString( v.Left ), v.Op,
+            p.expressionString( v.Right ) )
+    case *tree.CallExpression:
+        if len( v.Args ) == 0 { return identifierString( v.Function ) + "()" }
+        return identifierString( v.Function ) + 
+            "( " + p.expressionsString( v.Args ) + " )"
+    case *tree.ListExpression:
+        if len( v.Elements ) == 0 { return "[]" }
+        return "[ " + p.expressionsString( v.Elements ) + " ]"
+    }
+    panic( libErrorf( "unhandled expression: %T", e ) )
+}
+
+func ( p *printer ) annotationString( a *tree.Annotation ) string {
+    res := "@" + identifierString( a.Name )
+    if len( a.Args ) > 0 { res += "( " + p.expressionsString( a.Args ) + " )" }
+    return res
+}
+
+func ( p *printer ) constraintString( cd *tree.ConstraintDecl ) string {
+    res := "@constraint( " + p.expressionString( cd.Expression )
+    if cd.Message != nil { res += ", " + p.expressionString( cd.Message ) }
+    return res + " )"
+}
+
+func reservedString( rd *tree.ReservedDecl ) string {
+    strs := make( []string, len( rd.Names ) )
+    for i, rn := range rd.Names { strs[ i ] = identifierString( rn.Name ) }
+    return "@reserved( " + strings.Join( strs, ", " ) + " )"
+}
+
+func ( p *printer ) fieldString(
+    fld *tree.FieldDecl, nameWidth int, sep string ) string {
+
+    res := identifierString( fld.Name )
+    if len( res ) < nameWidth {
+        res += strings.Repeat( " ", nameWidth - len( res ) )
+    }
+    res += " " + p.typeReferenceString( fld.Type )
+    if fld.Default != nil {
+        res += " default " + p.expressionString( fld.Default )
+    }
+    return res + sep
+}
+
+func ( p *printer ) enumValueString( ev *tree.EnumValue ) string {
+    res := identifierString( ev.Value )
+    if ev.Ordinal != nil { res += ": " + p.expressionString( ev.Ordinal ) }
+    if l := len( ev.Aliases ); l > 0 {
+        strs := make( []string, l )
+        for i, al := range ev.Aliases { 
+            strs[ i ] = identifierString( al.Alias ) 
+        }
+        res += " alias( " + strings.Join( strs, ", " ) + " )"
+    }
+    return res
+}
+
+func typeListString( l []*tree.TypeListEntry ) string {
+    strs := make( []string, len( l ) )
+    for i, e := range l { strs[ i ] = e.Name.ExternalForm() }
+    return strings.Join( strs, ", " )
+}
+
+func ( p *printer ) importString( imprt *tree.Import ) string {
+    buf := &bytes.Buffer{}
+    buf.WriteString( "import " )
+    if ns := imprt.Namespace; ns != nil {
+        buf.WriteString( p.namespaceString( ns ) + "/" )
+    }
+    switch {
+    case imprt.IsGlob:
+        buf.WriteString( "*" )
+        if len( imprt.Excludes ) > 0 {
+            fmt.Fprintf( buf, " - [ %s ]", typeListString( imprt.Excludes ) )
+        }
+    case len( imprt.Includes ) == 1:
+        buf.WriteString( imprt.Includes[ 0 ].Name.ExternalForm() )
+    default: fmt.Fprintf( buf, "[ %s ]", typeListString( imprt.Includes ) )
+    }
+    return buf.String()
+}
@@ -0,0 +1,296 @@
+package format
+
+import (
+    "testing"
+    "strings"
+    "bitgirder/assert"
+    "mingle/parser"
+)
+
+func srcLines( lines ...string ) string {
+    return strings.Join( lines, "\n" ) + "\n"
+}
+
+func assertFormat( a *assert.PathAsserter, src, expct string ) {
+    act, err := FormatSource( "<>", []byte( src ) )
+    if err != nil { a.Fatal( err ) }
+    a.Equal( expct, string( act ) )
+    again, err := FormatSource( "<>", act )
+    if err != nil { a.Fatal( err ) }
+    a.Descend( "reformat" ).Equal( expct, string( again ) )
+}
+
+func TestFormat( t *testing.T ) {
+    a := assert.NewListPathAsserter( t )
+    for _, tt := range []struct { src, expct string } {
+        {
+            src: "@version v1; namespace ns1; struct S1 {}",
+            expct: srcLines( "@version v1", "", "namespace ns1", "",
+                "struct S1 {}" ),
+        },
+        // imports are sorted and names in the source version are unversioned
+        {
+            src: srcLines(
+                "@version v1",
+                "import ns2@v1/*",
+                "import ns1@v2/[ S2, S1 ]",
+                "import ns1@v1/* - [ S4,S3 ]",
+                "namespace ns1@v1",
+                "alias A1 ns1@v1/S1?",
+            ),
+            expct: srcLines(
+                "@version v1",
+                "",
+                "import ns1/* - [ S3, S4 ]",
+                "import ns1@v2/[ S1, S2 ]",
+                "import ns2/*",
+                "",
+                "namespace ns1",
+                "",
+                "alias A1 ns1/S1?",
+            ),
+        },
+        // field names are aligned, and defaults and restrictions are
+        // normalized
+        {
+            src: srcLines(
+                "@version v1",
+                "namespace ns1",
+                "struct S1 {",
+                "    f1 String~\"a*\"   default    \"a\"",
+                "    longField1 Int32~[ 0 , 10 ) default 1+2",
+                "    f2 Int32+ default [ 1,",
+                "        -2, ]; f3 &(S2?)",
+                "    f4 String~[1,2]*~[ 1 , 10 ]",
+                "    f5 &{ Int32* }",
+                "    @constructor( Int64 )",
+                "    @schema Sc1",
+                "}",
+            ),
+            expct: srcLines(
+                "@version v1",
+                "",
+                "namespace ns1",
+                "",
+                "struct S1 {",
+                "    @schema Sc1",
+                "",
+                "    f1         String~\"a*\" default \"a\"",
+                "    longField1 Int32~[0,10) default 1 + 2",
+                "    f2         Int32+ default [ 1, -2 ]",
+                "    f3         &(S2?)",
+                "    f4         String~[1,2]*~[1,10]",
+                "    f5         &{Int32*}",
+                "",
+                "    @constructor( Int64 )",
+                "}",
+            ),
+        },
+        // docs, free comments, trailing comments and comments inside blocks
+        // are kept
+        {
+            src: srcLines(
+                "# file comment",
+                "",
+                "@version v1",
+                "namespace ns1 # trailing",
+                "#S1 doc",
+                "@a1( 1,2 ) struct S1 {",
+                "    f1 String",
+                "    # f2 doc",
+                "    f2 String # f2 trailing",
+                "    f3 String",
+                "    # a comment at the end",
+                "}",
+                "# E1 doc",
+                "enum E1 { red, green, }",
+                "# at the end",
+            ),
+            expct: srcLines(
+                "# file comment",
+                "",
+                "@version v1",
+                "",
+                "namespace ns1 # trailing",
+                "",
+                "# S1 doc",
+                "@a1( 1, 2 )",
+                "struct S1 {",
+                "    f1 String",
+                "",
+                "    # f2 doc",
+                "    f2 String # f2 trailing",
+                "    f3 String",
+                "    # a comment at the end",
+                "}",
+                "",
+                "# E1 doc",
+                "enum E1 { red, green }",
+                "",
+                "# at the end",
+            ),
+        },
+        // calls and lists which don't fit on one line, or which have
+        // decorated elements, are printed one element per line
+        {
+            src: srcLines(
+                "@version v1",
+                "namespace ns1",
+                "prototype P1( aVeryVeryLongFieldName1 String, " +
+                    "aVeryVeryLongFieldName2 String ): String",
+                "service Svc1 { op op1(): String; op op2( f1 String, ",
+                "    # f2 doc",
+                "    f2 String ): String throws E1, E2",
+                "    op op3(): Int64; @security Sec1 }",
+                "enum E1 { red,",
+                "    # green doc",
+                "    green }",
+            ),
+            expct: srcLines(
+                "@version v1",
+                "",
+                "namespace ns1",
+                "",
+                "prototype P1(",
+                "    aVeryVeryLongFieldName1 String,",
+                "    aVeryVeryLongFieldName2 String,",
+                "): String",
+                "",
+                "service Svc1 {",
+                "    @security Sec1",
+                "",
+                "    op op1(): String",
+                "",
+                "    op op2(",
+                "        f1 String,",
+                "",
+                "        # f2 doc",
+                "        f2 String,",
+                "    ): String throws E1, E2",
+                "",
+                "    op op3(): Int64",
+                "}",
+                "",
+                "enum E1 {",
+                "    red,",
+                "",
+                "    # green doc",
+                "    green,",
+                "}",
+            ),
+        },
+        {
+            src: srcLines(
+                "@version v1",
+                "namespace ns1",
+                "enum E1 { red:1, green : -2 alias(verde,vert), " +
+                    "blue alias(azul) }",
+            ),
+            expct: srcLines(
+                "@version v1",
+                "",
+                "namespace ns1",
+                "",
+                "enum E1 { red: 1, green: -2 alias( verde, vert ), " +
+                    "blue alias( azul ) }",
+            ),
+        },
+        // a trailing comment stays with the last element on its line
+        {
+            src: srcLines(
+                "@version v1",
+                "namespace ns1",
+                "enum E1 { a, b, # c",
+                "}",
+                "union U1 { # u",
+                "    String, Int32 } # d",
+            ),
+            expct: srcLines(
+                "@version v1",
+                "",
+                "namespace ns1",
+                "",
+                "enum E1 {",
+                "    a,",
+                "    b, # c",
+                "}",
+                "",
+                "union U1 { # u",
+                "    String,",
+                "    Int32,",
+                "} # d",
+            ),
+        },
+        {
+            src: srcLines(
+                "@version v1",
+                "namespace ns1",
+                "union U1 { String tag(name), String tag( label ),",
+                "    Int32 tag(count) }",
+            ),
+            expct: srcLines(
+                "@version v1",
+                "",
+                "namespace ns1",
+                "",
+                "union U1 { String tag( name ), String tag( label ), " +
+                    "Int32 tag( count ) }",
+            ),
+        },
+        {
+            src: srcLines(
+                "@version v1",
+                "namespace ns1",
+                "struct S1 { @constraint(exactlyOne(f1,f2),\"msg\")",
+                "    f1 Int32?; f2 Int32?; @constraint(f1<=-f2+1)",
+                "    @constraint(noArgs()) }",
+            ),
+            expct: srcLines(
+                "@version v1",
+                "",
+                "namespace ns1",
+                "",
+                "struct S1 {",
+                "    f1 Int32?",
+                "    f2 Int32?",
+                "",
+                "    @constraint( exactlyOne( f1, f2 ), \"msg\" )",
+                "    @constraint( f1 <= -f2 + 1 )",
+                "    @constraint( noArgs() )",
+                "}",
+            ),
+        },
+        {
+            src: srcLines(
+                "@version v1",
+                "namespace ns1",
+                "struct S1 { @reserved(f2,f3)",
+                "    @deprecated(\"use f4\") f1 Int32; f4 Int32 }",
+            ),
+            expct: srcLines(
+                "@version v1",
+                "",
+                "namespace ns1",
+                "",
+                "struct S1 {",
+                "    @deprecated( \"use f4\" )",
+                "    f1 Int32",
+                "    f4 Int32",
+                "",
+                "    @reserved( f2, f3 )",
+                "}",
+            ),
+        },
+    } {
+        assertFormat( a, tt.src, tt.expct )
+        a = a.Next()
+    }
+}
+
+func TestFormatParseError( t *testing.T ) {
+    src := "@version v1; namespace ns1; struct"
+    _, err := FormatSource( "<>", []byte( src ) )
+    if _, ok := err.( *parser.ParseError ); ! ok {
+        t.Fatalf( "expected parse error, got %v", err )
+    }
+}
@@ -97,9 +97,14 @@ func ( c *JsonCodec ) asJsonValue( val mg.Value ) interface{} {
     case mg.Uint64: return uint64( v )
     case mg.Float32: return float32( v )
     case mg.Float64: return float64( v )
+    // json numbers are commonly read as doubles, so we send these as strings
+    // to keep their full precision; readers cast them back as needed
+    case mg.BigInt, mg.Decimal: return val.( fmt.Stringer ).String()
     case mg.Buffer: return base64.StdEncoding.EncodeToString( v )
     case *mg.Enum: return c.asJsonEnum( v )
     case mg.Timestamp: return v.Rfc3339Nano()
+    case mg.Date, mg.LocalTime, mg.Duration, mg.Uuid, mg.Uri: 
+        return val.( fmt.Stringer ).String()
     case *mg.Null: return nil
     }
     panic( libErrorf( "Unhandled mingle value: %T", val ) )
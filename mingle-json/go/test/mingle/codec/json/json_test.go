@@ -48,3 +48,35 @@ func TestCodecRegistration( t *testing.T ) {
         _ = cdc.( *JsonCodec )
     })
 }
+
+// A tagged union value is a map with a single field named by the tag, so it
+// should pass through the codec like any other map
+func TestTaggedUnionValueRoundtrip( t *testing.T ) {
+    s := mg.MustStruct( "ns1@v1/S1",
+        "f1", mg.MustSymbolMap( "tag1", "val1" ),
+        "f2", mg.MustList(
+            mg.MustSymbolMap( 
+                "tag2", mg.MustStruct( "ns1@v1/S2", "f1", "val2" ) ),
+        ),
+    )
+    c := NewJsonCodec()
+    str := toJsonStr( s, c, t )
+    assertJson(
+        []byte( str ),
+        mustGoJsonMap(
+            "$type", "ns1@v1/S1",
+            "f1", mustGoJsonMap( "tag1", "val1" ),
+            "f2", []interface{}{
+                mustGoJsonMap( "tag2",
+                    mustGoJsonMap( "$type", "ns1@v1/S2", "f1", "val2" ) ),
+            },
+        ),
+        &assert.Asserter{ t },
+    )
+    s2, err := fromJsonStr( str, c )
+    if err != nil { t.Fatal( err ) }
+    if ! mg.EqualValues( s, s2 ) {
+        t.Fatalf( "expected %s but got %s", 
+            mg.QuoteValue( s ), mg.QuoteValue( s2 ) )
+    }
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+    "log"
+    "os"
+    "mingle/lsp"
+)
+
+func main() {
+    srv := lsp.NewServer( &lsp.ServerOptions{ 
+        Reader: os.Stdin, 
+        Writer: os.Stdout,
+    })
+    if err := srv.Serve(); err != nil { log.Fatal( err ) }
+}
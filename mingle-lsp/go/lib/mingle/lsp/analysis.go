@@ -0,0 +1,180 @@
+package lsp
+
+import (
+    "fmt"
+    "bytes"
+    mg "mingle"
+    "mingle/types"
+    "mingle/compiler"
+)
+
+type declSite struct {
+    uri string
+    span span
+}
+
+func ( ds *declSite ) asLocation() *Location {
+    return &Location{ Uri: ds.uri, Range: ds.span.asRange() }
+}
+
+// The result of compiling all currently parseable documents together, along
+// with where in those documents each type and namespace is declared
+type analysis struct {
+    extTypes *types.DefinitionMap
+    comp *compiler.Compilation
+    res *compiler.CompilationResult
+    decls *mg.QnameMap
+    nsDecls *mg.NamespaceMap
+}
+
+func newAnalysis( extTypes *types.DefinitionMap ) *analysis {
+    return &analysis{
+        extTypes: extTypes,
+        comp: compiler.NewCompilation().SetExternalTypes( extTypes ),
+        decls: mg.NewQnameMap(),
+        nsDecls: mg.NewNamespaceMap(),
+    }
+}
+
+func ( an *analysis ) addSource( uri string, si *sourceIndex ) {
+    an.comp.AddSource( si.unit )
+    ns := si.namespace()
+    if ! an.nsDecls.HasKey( ns ) {
+        sp := spanIn( si.lines, si.unit.NsDecl.Start, isNamespaceRune )
+        an.nsDecls.Put( ns, &declSite{ uri: uri, span: sp } )
+    }
+    for _, td := range si.unit.TypeDecls {
+        qn := td.GetName().ResolveIn( ns )
+        if an.decls.HasKey( qn ) { continue }
+        sp := spanIn( si.lines, declNameLoc( td ), isTypeNameRune )
+        an.decls.Put( qn, &declSite{ uri: uri, span: sp } )
+    }
+}
+
+func ( an *analysis ) execute() ( err error ) {
+    an.res, err = an.comp.Execute()
+    return
+}
+
+func ( an *analysis ) errorsFor( uri string ) []*compiler.Error {
+    if an.res == nil { return nil }
+    res := make( []*compiler.Error, 0, len( an.res.Errors ) )
+    for _, err := range an.res.Errors {
+        if err.Location == nil || err.Location.Source == uri {
+            res = append( res, err )
+        }
+    }
+    return res
+}
+
+func ( an *analysis ) resolve(
+    si *sourceIndex, nm mg.TypeName ) *mg.QualifiedTypeName {
+
+    return an.comp.ResolveTypeName( si.namespace(), nm )
+}
+
+func ( an *analysis ) definitionFor(
+    qn *mg.QualifiedTypeName ) types.Definition {
+
+    if an.res != nil {
+        if def := an.res.BuiltTypes.Get( qn ); def != nil { return def }
+    }
+    return an.extTypes.Get( qn )
+}
+
+func ( an *analysis ) fieldDefinitionFor(
+    si *sourceIndex, fs *fieldSite ) *types.FieldDefinition {
+
+    var flds *types.FieldSet
+    def := an.definitionFor( fs.owner.ResolveIn( si.namespace() ) )
+    switch v := def.( type ) {
+    case *types.StructDefinition: flds = v.Fields
+    case *types.SchemaDefinition: flds = v.Fields
+    case *types.PrototypeDefinition: flds = v.Signature.Fields
+    case *types.ServiceDefinition:
+        for _, od := range v.Operations {
+            if od.Name.Equals( fs.op ) { flds = od.Signature.Fields }
+        }
+    }
+    if flds == nil { return nil }
+    return flds.Get( fs.decl.Name )
+}
+
+func definitionKind( def types.Definition ) string {
+    switch def.( type ) {
+    case *types.StructDefinition: return "struct"
+    case *types.SchemaDefinition: return "schema"
+    case *types.EnumDefinition: return "enum"
+    case *types.UnionDefinition: return "union"
+    case *types.AliasedTypeDefinition: return "alias"
+    case *types.PrototypeDefinition: return "prototype"
+    case *types.ServiceDefinition: return "service"
+    case *types.PrimitiveDefinition: return "primitive"
+    }
+    return "type"
+}
+
+func definitionDoc( def types.Definition ) string {
+    switch v := def.( type ) {
+    case *types.StructDefinition: return v.Doc
+    case *types.SchemaDefinition: return v.Doc
+    case *types.EnumDefinition: return v.Doc
+    case *types.UnionDefinition: return v.Doc
+    case *types.AliasedTypeDefinition: return v.Doc
+    case *types.PrototypeDefinition: return v.Doc
+    case *types.ServiceDefinition: return v.Doc
+    }
+    return ""
+}
+
+func completionItemKind( def types.Definition ) int {
+    switch def.( type ) {
+    case *types.StructDefinition: return CompletionItemKindStruct
+    case *types.SchemaDefinition: return CompletionItemKindInterface
+    case *types.EnumDefinition: return CompletionItemKindEnum
+    case *types.AliasedTypeDefinition: return CompletionItemKindTypeParameter
+    }
+    return CompletionItemKindClass
+}
+
+func appendDoc( buf *bytes.Buffer, doc string ) {
+    if doc != "" { fmt.Fprintf( buf, "\n\n%s", doc ) }
+}
+
+func ( an *analysis ) describeType( qn *mg.QualifiedTypeName ) string {
+    buf := &bytes.Buffer{}
+    if def := an.definitionFor( qn ); def == nil {
+        buf.WriteString( qn.ExternalForm() )
+    } else {
+        fmt.Fprintf( buf, "%s %s", definitionKind( def ), qn )
+        appendDoc( buf, definitionDoc( def ) )
+    }
+    return buf.String()
+}
+
+func describeField( fd *types.FieldDefinition ) string {
+    buf := &bytes.Buffer{}
+    fmt.Fprintf( buf, "%s %s", fd.Name, fd.Type )
+    if fd.Default != nil {
+        fmt.Fprintf( buf, "\ndefault: %s", mg.QuoteValue( fd.Default ) )
+    }
+    appendDoc( buf, fd.Doc )
+    return buf.String()
+}
+
+func ( an *analysis ) completionItems( si *sourceIndex ) []CompletionItem {
+    qns := an.comp.TypeNamesInScope( si.namespace() )
+    res := make( []CompletionItem, 0, len( qns ) )
+    for _, qn := range qns {
+        item := CompletionItem{
+            Label: qn.Name.ExternalForm(),
+            Detail: qn.ExternalForm(),
+            Kind: CompletionItemKindClass,
+        }
+        if def := an.definitionFor( qn ); def != nil {
+            item.Kind = completionItemKind( def )
+        }
+        res = append( res, item )
+    }
+    return res
+}
@@ -0,0 +1,158 @@
+package lsp
+
+import (
+    "io"
+    "bufio"
+    "fmt"
+    "strings"
+    "strconv"
+    "sync"
+    "encoding/json"
+)
+
+const jsonRpcVersion = "2.0"
+
+const (
+    ErrorCodeParseError = -32700
+    ErrorCodeInvalidRequest = -32600
+    ErrorCodeMethodNotFound = -32601
+    ErrorCodeInvalidParams = -32602
+    ErrorCodeInternalError = -32603
+)
+
+type ResponseError struct {
+    Code int `json:"code"`
+    Message string `json:"message"`
+}
+
+func ( e *ResponseError ) Error() string {
+    return fmt.Sprintf( "%s (code %d)", e.Message, e.Code )
+}
+
+func newResponseErrorf(
+    code int, tmpl string, argv ...interface{} ) *ResponseError {
+
+    return &ResponseError{ Code: code, Message: fmt.Sprintf( tmpl, argv... ) }
+}
+
+// A single JSON-RPC message. Requests have both an Id and a Method,
+// notifications only a Method, and responses an Id along with either a Result
+// or an Error.
+type Message struct {
+    JsonRpc string `json:"jsonrpc"`
+    Id json.RawMessage `json:"id,omitempty"`
+    Method string `json:"method,omitempty"`
+    Params json.RawMessage `json:"params,omitempty"`
+    Result json.RawMessage `json:"result,omitempty"`
+    Error *ResponseError `json:"error,omitempty"`
+}
+
+func ( m *Message ) IsRequest() bool { return m.Id != nil && m.Method != "" }
+
+func ( m *Message ) IsNotification() bool {
+    return m.Id == nil && m.Method != ""
+}
+
+func ( m *Message ) IsResponse() bool { return m.Id != nil && m.Method == "" }
+
+func marshalRaw( val interface{} ) ( json.RawMessage, error ) {
+    if val == nil { return json.RawMessage( "null" ), nil }
+    return json.Marshal( val )
+}
+
+func NewRequest(
+    id int, method string, params interface{} ) ( *Message, error ) {
+
+    res := &Message{ JsonRpc: jsonRpcVersion, Method: method }
+    res.Id = json.RawMessage( strconv.Itoa( id ) )
+    var err error
+    if res.Params, err = marshalRaw( params ); err != nil { return nil, err }
+    return res, nil
+}
+
+func NewNotification(
+    method string, params interface{} ) ( *Message, error ) {
+
+    res := &Message{ JsonRpc: jsonRpcVersion, Method: method }
+    var err error
+    if res.Params, err = marshalRaw( params ); err != nil { return nil, err }
+    return res, nil
+}
+
+func newResponse(
+    id json.RawMessage, result interface{} ) ( *Message, error ) {
+
+    res := &Message{ JsonRpc: jsonRpcVersion, Id: id }
+    var err error
+    if res.Result, err = marshalRaw( result ); err != nil { return nil, err }
+    return res, nil
+}
+
+func newErrorResponse( id json.RawMessage, err *ResponseError ) *Message {
+    if id == nil { id = json.RawMessage( "null" ) }
+    return &Message{ JsonRpc: jsonRpcVersion, Id: id, Error: err }
+}
+
+// Reads and writes messages framed as in the LSP base protocol: a header block
+// containing at least a Content-Length, followed by a blank line and then the
+// message body. Writes may be made from multiple goroutines; reads may not.
+type Conn struct {
+    r *bufio.Reader
+    w io.Writer
+    wMu *sync.Mutex
+}
+
+func NewConn( r io.Reader, w io.Writer ) *Conn {
+    return &Conn{ r: bufio.NewReader( r ), w: w, wMu: &sync.Mutex{} }
+}
+
+func ( c *Conn ) readHeaderLine() ( string, error ) {
+    ln, err := c.r.ReadString( '\n' )
+    if err == io.EOF && ln != "" { err = io.ErrUnexpectedEOF }
+    if err != nil { return "", err }
+    return strings.TrimRight( ln, "\r\n" ), nil
+}
+
+func ( c *Conn ) readContentLength() ( int, error ) {
+    sz := -1
+    for {
+        ln, err := c.readHeaderLine()
+        if err != nil { return 0, err }
+        if ln == "" { break }
+        pair := strings.SplitN( ln, ":", 2 )
+        if len( pair ) != 2 { return 0, libErrorf( "invalid header: %q", ln ) }
+        if ! strings.EqualFold( pair[ 0 ], "Content-Length" ) { continue }
+        szStr := strings.TrimSpace( pair[ 1 ] )
+        if sz, err = strconv.Atoi( szStr ); err != nil {
+            return 0, libErrorf( "invalid content length: %q", szStr )
+        }
+    }
+    if sz < 0 { return 0, libError( "message has no content length" ) }
+    return sz, nil
+}
+
+// Returns io.EOF if the underlying reader is exhausted at a message boundary.
+// If the message body is not a valid JSON-RPC message a *ResponseError is
+// returned, after which the connection may still be read.
+func ( c *Conn ) ReadMessage() ( *Message, error ) {
+    sz, err := c.readContentLength()
+    if err != nil { return nil, err }
+    buf := make( []byte, sz )
+    if _, err = io.ReadFull( c.r, buf ); err != nil { return nil, err }
+    res := new( Message )
+    if err = json.Unmarshal( buf, res ); err != nil {
+        return nil, newResponseErrorf( ErrorCodeParseError, "%s", err )
+    }
+    return res, nil
+}
+
+func ( c *Conn ) WriteMessage( m *Message ) error {
+    body, err := json.Marshal( m )
+    if err != nil { return err }
+    c.wMu.Lock()
+    defer c.wMu.Unlock()
+    _, err = fmt.Fprintf( c.w, "Content-Length: %d\r\n\r\n", len( body ) )
+    if err != nil { return err }
+    _, err = c.w.Write( body )
+    return err
+}
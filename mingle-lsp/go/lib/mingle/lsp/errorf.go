@@ -0,0 +1,14 @@
+package lsp
+
+import (
+    "fmt"
+    "errors"
+)
+
+func libError( msg string ) error {
+    return errors.New( "mingle/lsp: " + msg )
+}
+
+func libErrorf( tmpl string, argv ...interface{} ) error {
+    return fmt.Errorf( "mingle/lsp: " + tmpl, argv... )
+}
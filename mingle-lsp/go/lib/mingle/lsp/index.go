@@ -0,0 +1,212 @@
+package lsp
+
+import (
+    "strings"
+    "unicode"
+    mg "mingle"
+    "mingle/parser"
+    "mingle/parser/tree"
+)
+
+func isIdentifierRune( r rune ) bool {
+    return unicode.IsLetter( r ) || unicode.IsDigit( r ) ||
+        r == '-' || r == '_'
+}
+
+func isNamespaceRune( r rune ) bool {
+    return isIdentifierRune( r ) || r == '@' || r == ':'
+}
+
+func isTypeNameRune( r rune ) bool { return isNamespaceRune( r ) || r == '/' }
+
+func splitLines( text string ) []string { return strings.Split( text, "\n" ) }
+
+// A run of source text on a single line beginning at loc and spanning len
+// runes. Locations are one-based, as produced by the parser, while the
+// positions and ranges of the protocol are zero-based.
+type span struct {
+    loc *parser.Location
+    len int
+}
+
+// Returns a span beginning at loc and continuing for as long as the runes of
+// lines satisfy f, but always of at least length 1
+func spanIn( lines []string, loc *parser.Location, f func( rune ) bool ) span {
+    res := span{ loc: loc, len: 1 }
+    if loc == nil || loc.Line < 1 || loc.Line > len( lines ) { return res }
+    ln := []rune( lines[ loc.Line - 1 ] )
+    start := loc.Col - 1
+    end := start
+    for end >= 0 && end < len( ln ) && f( ln[ end ] ) { end++ }
+    if end > start { res.len = end - start }
+    return res
+}
+
+func ( s span ) contains( pos Position ) bool {
+    if s.loc == nil || s.loc.Line - 1 != pos.Line { return false }
+    start := s.loc.Col - 1
+    return pos.Character >= start && pos.Character < start + s.len
+}
+
+func ( s span ) asRange() Range {
+    if s.loc == nil { return Range{} }
+    start := Position{ Line: s.loc.Line - 1, Character: s.loc.Col - 1 }
+    end := Position{ Line: start.Line, Character: start.Character + s.len }
+    return Range{ Start: start, End: end }
+}
+
+// A reference to a type by name, whether from a type expression, schema mixin,
+// security declaration, import list, or the name of a type declaration itself
+type typeRef struct {
+    name mg.TypeName
+    span span
+}
+
+type nsRef struct {
+    ns *mg.Namespace
+    span span
+}
+
+// A field declared in the type named by owner or, when op is non-nil, in the
+// signature of operation op of the service named by owner
+type fieldSite struct {
+    owner *mg.DeclaredTypeName
+    op *mg.Identifier
+    decl *tree.FieldDecl
+    span span
+}
+
+// Positional information about a single successfully parsed source unit
+type sourceIndex struct {
+    unit *tree.NsUnit
+    lines []string
+    typeRefs []*typeRef
+    nsRefs []*nsRef
+    fields []*fieldSite
+}
+
+func declNameLoc( td tree.TypeDecl ) *parser.Location {
+    switch v := td.( type ) {
+    case *tree.StructDecl: return v.Info.NameLoc
+    case *tree.SchemaDecl: return v.Info.NameLoc
+    case *tree.ServiceDecl: return v.Info.NameLoc
+    case *tree.UnionDecl: return v.Info.NameLoc
+    case *tree.EnumDecl: return v.NameLoc
+    case *tree.AliasDecl: return v.NameLoc
+    case *tree.PrototypeDecl: return v.NameLoc
+    }
+    panic( libErrorf( "unhandled type decl: %T", td ) )
+}
+
+func atomicExpressionIn( e interface{} ) *parser.AtomicTypeExpression {
+    switch v := e.( type ) {
+    case *parser.AtomicTypeExpression: return v
+    case *parser.ListTypeExpression: return atomicExpressionIn( v.Expression )
+    case *parser.MapTypeExpression: return atomicExpressionIn( v.Expression )
+    case *parser.NullableTypeExpression:
+        return atomicExpressionIn( v.Expression )
+    case *parser.PointerTypeExpression:
+        return atomicExpressionIn( v.Expression )
+    }
+    panic( libErrorf( "unhandled type expression: %T", e ) )
+}
+
+func ( si *sourceIndex ) addTypeRef( nm mg.TypeName, loc *parser.Location ) {
+    sp := spanIn( si.lines, loc, isTypeNameRune )
+    si.typeRefs = append( si.typeRefs, &typeRef{ name: nm, span: sp } )
+}
+
+func ( si *sourceIndex ) addTypeReference(
+    typ *parser.CompletableTypeReference ) {
+
+    if typ == nil { return }
+    at := atomicExpressionIn( typ.Expression )
+    si.addTypeRef( at.Name, at.NameLoc )
+}
+
+func ( si *sourceIndex ) addFields(
+    owner *mg.DeclaredTypeName, op *mg.Identifier, flds []*tree.FieldDecl ) {
+
+    for _, fld := range flds {
+        fs := &fieldSite{ owner: owner, op: op, decl: fld }
+        fs.span = spanIn( si.lines, fld.NameLoc, isIdentifierRune )
+        si.fields = append( si.fields, fs )
+        si.addTypeReference( fld.Type )
+    }
+}
+
+func ( si *sourceIndex ) addCallSignature(
+    owner *mg.DeclaredTypeName, op *mg.Identifier, sig *tree.CallSignature ) {
+
+    si.addFields( owner, op, sig.Fields )
+    si.addTypeReference( sig.Return )
+    for _, tt := range sig.Throws { si.addTypeReference( tt.Type ) }
+}
+
+func ( si *sourceIndex ) addSchemaMixins( mixins []*tree.SchemaMixinDecl ) {
+    for _, mx := range mixins { si.addTypeRef( mx.Name, mx.NameLoc ) }
+}
+
+func ( si *sourceIndex ) addImport( imprt *tree.Import ) {
+    sp := spanIn( si.lines, imprt.NamespaceLoc, isNamespaceRune )
+    si.nsRefs = append( si.nsRefs, &nsRef{ ns: imprt.Namespace, span: sp } )
+    for _, l := range [][]*tree.TypeListEntry{
+        imprt.Includes, imprt.Excludes } {
+        for _, e := range l {
+            si.addTypeRef( e.Name.ResolveIn( imprt.Namespace ), e.Loc )
+        }
+    }
+}
+
+func ( si *sourceIndex ) addTypeDeclBody( td tree.TypeDecl ) {
+    nm := td.GetName()
+    switch v := td.( type ) {
+    case *tree.StructDecl:
+        si.addFields( nm, nil, v.Fields )
+        for _, c := range v.Constructors { si.addTypeReference( c.ArgType ) }
+        si.addSchemaMixins( v.Schemas )
+    case *tree.SchemaDecl:
+        si.addFields( nm, nil, v.Fields )
+        si.addSchemaMixins( v.Schemas )
+    case *tree.AliasDecl: si.addTypeReference( v.Target )
+    case *tree.PrototypeDecl: si.addCallSignature( nm, nil, v.Sig )
+    case *tree.UnionDecl:
+        for _, typ := range v.Types { si.addTypeReference( typ ) }
+    case *tree.ServiceDecl:
+        for _, od := range v.Operations {
+            si.addCallSignature( nm, od.Name, od.Call )
+        }
+        for _, sd := range v.SecurityDecls {
+            si.addTypeRef( sd.Name, sd.NameLoc )
+        }
+    }
+}
+
+func newSourceIndex( u *tree.NsUnit, text string ) *sourceIndex {
+    res := &sourceIndex{ unit: u, lines: splitLines( text ) }
+    for _, imprt := range u.Imports { res.addImport( imprt ) }
+    for _, td := range u.TypeDecls {
+        res.addTypeRef( td.GetName(), declNameLoc( td ) )
+        res.addTypeDeclBody( td )
+    }
+    return res
+}
+
+func ( si *sourceIndex ) namespace() *mg.Namespace {
+    return si.unit.NsDecl.Namespace
+}
+
+func ( si *sourceIndex ) typeRefAt( pos Position ) *typeRef {
+    for _, tr := range si.typeRefs { if tr.span.contains( pos ) { return tr } }
+    return nil
+}
+
+func ( si *sourceIndex ) nsRefAt( pos Position ) *nsRef {
+    for _, nr := range si.nsRefs { if nr.span.contains( pos ) { return nr } }
+    return nil
+}
+
+func ( si *sourceIndex ) fieldAt( pos Position ) *fieldSite {
+    for _, fs := range si.fields { if fs.span.contains( pos ) { return fs } }
+    return nil
+}
@@ -0,0 +1,143 @@
+package lsp
+
+// The subset of the Language Server Protocol types used by this server. Field
+// names and json tags follow the LSP specification.
+
+const (
+    MethodInitialize = "initialize"
+    MethodInitialized = "initialized"
+    MethodShutdown = "shutdown"
+    MethodExit = "exit"
+    MethodDidOpen = "textDocument/didOpen"
+    MethodDidChange = "textDocument/didChange"
+    MethodDidClose = "textDocument/didClose"
+    MethodDefinition = "textDocument/definition"
+    MethodHover = "textDocument/hover"
+    MethodCompletion = "textDocument/completion"
+    MethodPublishDiagnostics = "textDocument/publishDiagnostics"
+)
+
+const (
+    TextDocumentSyncKindFull = 1
+)
+
+const (
+    DiagnosticSeverityError = 1
+)
+
+const (
+    CompletionItemKindClass = 7
+    CompletionItemKindInterface = 8
+    CompletionItemKindEnum = 13
+    CompletionItemKindStruct = 22
+    CompletionItemKindTypeParameter = 25
+)
+
+const MarkupKindPlainText = "plaintext"
+
+// Line and Character are zero-based
+type Position struct {
+    Line int `json:"line"`
+    Character int `json:"character"`
+}
+
+type Range struct {
+    Start Position `json:"start"`
+    End Position `json:"end"`
+}
+
+type Location struct {
+    Uri string `json:"uri"`
+    Range Range `json:"range"`
+}
+
+type TextDocumentIdentifier struct {
+    Uri string `json:"uri"`
+}
+
+type TextDocumentItem struct {
+    Uri string `json:"uri"`
+    LanguageId string `json:"languageId"`
+    Version int `json:"version"`
+    Text string `json:"text"`
+}
+
+type VersionedTextDocumentIdentifier struct {
+    Uri string `json:"uri"`
+    Version int `json:"version"`
+}
+
+// Only full document sync is supported, so Text is always the full text of the
+// document
+type TextDocumentContentChangeEvent struct {
+    Text string `json:"text"`
+}
+
+type DidOpenTextDocumentParams struct {
+    TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+type DidChangeTextDocumentParams struct {
+    TextDocument VersionedTextDocumentIdentifier `json:"textDocument"`
+    ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+type DidCloseTextDocumentParams struct {
+    TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type TextDocumentPositionParams struct {
+    TextDocument TextDocumentIdentifier `json:"textDocument"`
+    Position Position `json:"position"`
+}
+
+type Diagnostic struct {
+    Range Range `json:"range"`
+    Severity int `json:"severity"`
+    Source string `json:"source"`
+    Message string `json:"message"`
+}
+
+type PublishDiagnosticsParams struct {
+    Uri string `json:"uri"`
+    Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+type MarkupContent struct {
+    Kind string `json:"kind"`
+    Value string `json:"value"`
+}
+
+type Hover struct {
+    Contents MarkupContent `json:"contents"`
+    Range *Range `json:"range,omitempty"`
+}
+
+type CompletionItem struct {
+    Label string `json:"label"`
+    Kind int `json:"kind,omitempty"`
+    Detail string `json:"detail,omitempty"`
+}
+
+type CompletionList struct {
+    IsIncomplete bool `json:"isIncomplete"`
+    Items []CompletionItem `json:"items"`
+}
+
+type CompletionOptions struct {}
+
+type ServerCapabilities struct {
+    TextDocumentSync int `json:"textDocumentSync"`
+    DefinitionProvider bool `json:"definitionProvider"`
+    HoverProvider bool `json:"hoverProvider"`
+    CompletionProvider *CompletionOptions `json:"completionProvider,omitempty"`
+}
+
+type ServerInfo struct {
+    Name string `json:"name"`
+}
+
+type InitializeResult struct {
+    Capabilities ServerCapabilities `json:"capabilities"`
+    ServerInfo *ServerInfo `json:"serverInfo,omitempty"`
+}
@@ -0,0 +1,397 @@
+package lsp
+
+import (
+    "io"
+    "log"
+    "sort"
+    "bytes"
+    "encoding/json"
+    "mingle/parser"
+    "mingle/parser/tree"
+    "mingle/types"
+    "mingle/types/builtin"
+)
+
+const diagnosticSource = "mingle"
+
+const serverName = "mingle-lsp"
+
+type document struct {
+    uri string
+    lines []string
+
+    // index of the most recent version of this document in which at least the
+    // version and namespace parsed, if any. A version with syntax errors is
+    // indexed by the declarations recovered from it.
+    index *sourceIndex
+
+    // syntax errors in the current version of this document
+    syntaxErrs []*parser.ParseError
+
+    // set when the current version of this document could not be read
+    parseErr error
+}
+
+// A panic from the parser is kept as the document's parse error, so that it is
+// reported to the client as a diagnostic rather than ending the session
+func ( d *document ) setText( text string ) {
+    d.lines = splitLines( text )
+    defer func() {
+        if r := recover(); r != nil {
+            d.syntaxErrs, d.parseErr = nil, libErrorf( "parse failed: %v", r )
+        }
+    }()
+    u, errs, err := 
+        tree.ParseSourceWithRecovery( d.uri, bytes.NewBufferString( text ) )
+    d.syntaxErrs, d.parseErr = errs, err
+    if err == nil && u != nil { d.index = newSourceIndex( u, text ) }
+}
+
+type ServerOptions struct {
+    Reader io.Reader
+    Writer io.Writer
+
+    // Types available to all documents in addition to those they declare. If
+    // nil, the mingle builtin types are used.
+    ExternalTypes *types.DefinitionMap
+}
+
+// Serves a single client over a stream, as with stdio. All documents opened by
+// the client are compiled together, so that types declared in one are visible
+// to the others, and the whole set is recompiled and diagnostics republished
+// for every open document each time any one changes.
+type Server struct {
+    conn *Conn
+    extTypes *types.DefinitionMap
+    docs map[ string ]*document
+    an *analysis
+    isShutdown bool
+}
+
+func NewServer( opts *ServerOptions ) *Server {
+    res := &Server{
+        conn: NewConn( opts.Reader, opts.Writer ),
+        extTypes: opts.ExternalTypes,
+        docs: make( map[ string ]*document ),
+    }
+    if res.extTypes == nil { res.extTypes = builtin.BuiltinTypes() }
+    res.an = newAnalysis( res.extTypes )
+    return res
+}
+
+func ( s *Server ) sortedDocs() []*document {
+    uris := make( []string, 0, len( s.docs ) )
+    for uri := range s.docs { uris = append( uris, uri ) }
+    sort.Strings( uris )
+    res := make( []*document, len( uris ) )
+    for i, uri := range uris { res[ i ] = s.docs[ uri ] }
+    return res
+}
+
+func ( s *Server ) analyze() {
+    s.an = newAnalysis( s.extTypes )
+    for _, doc := range s.sortedDocs() {
+        if doc.index != nil { s.an.addSource( doc.uri, doc.index ) }
+    }
+    if err := s.an.execute(); err != nil {
+        log.Printf( "%s: %s", serverName, err )
+    }
+}
+
+func newDiagnostic( r Range, msg string ) Diagnostic {
+    return Diagnostic{
+        Range: r,
+        Severity: DiagnosticSeverityError,
+        Source: diagnosticSource,
+        Message: msg,
+    }
+}
+
+// Orders diagnostics by position and then message, since compiler errors are
+// not themselves reported in any particular order
+type diagnosticSort []Diagnostic
+
+func ( s diagnosticSort ) Len() int { return len( s ) }
+
+func ( s diagnosticSort ) Swap( i, j int ) { s[ i ], s[ j ] = s[ j ], s[ i ] }
+
+func ( s diagnosticSort ) Less( i, j int ) bool {
+    pi, pj := s[ i ].Range.Start, s[ j ].Range.Start
+    if pi.Line != pj.Line { return pi.Line < pj.Line }
+    if pi.Character != pj.Character { return pi.Character < pj.Character }
+    return s[ i ].Message < s[ j ].Message
+}
+
+func ( s *Server ) diagnosticsFor( doc *document ) []Diagnostic {
+    if err := doc.parseErr; err != nil {
+        return []Diagnostic{ newDiagnostic( Range{}, err.Error() ) }
+    }
+    if len( doc.syntaxErrs ) > 0 {
+        res := make( []Diagnostic, len( doc.syntaxErrs ) )
+        for i, pe := range doc.syntaxErrs {
+            sp := spanIn( doc.lines, pe.Loc, isTypeNameRune )
+            res[ i ] = newDiagnostic( sp.asRange(), pe.Message )
+        }
+        sort.Sort( diagnosticSort( res ) )
+        return res
+    }
+    errs := s.an.errorsFor( doc.uri )
+    res := make( []Diagnostic, 0, len( errs ) )
+    for _, err := range errs {
+        sp := spanIn( doc.index.lines, err.Location, isTypeNameRune )
+        res = append( res, newDiagnostic( sp.asRange(), err.Message ) )
+    }
+    sort.Sort( diagnosticSort( res ) )
+    return res
+}
+
+func ( s *Server ) notify( method string, params interface{} ) error {
+    m, err := NewNotification( method, params )
+    if err != nil { return err }
+    return s.conn.WriteMessage( m )
+}
+
+func ( s *Server ) publishDiagnostics( doc *document ) error {
+    params := &PublishDiagnosticsParams{
+        Uri: doc.uri,
+        Diagnostics: s.diagnosticsFor( doc ),
+    }
+    return s.notify( MethodPublishDiagnostics, params )
+}
+
+func ( s *Server ) reanalyze() error {
+    s.analyze()
+    for _, doc := range s.sortedDocs() {
+        if err := s.publishDiagnostics( doc ); err != nil { return err }
+    }
+    return nil
+}
+
+func ( s *Server ) setDocumentText( uri, text string ) error {
+    doc := s.docs[ uri ]
+    if doc == nil {
+        doc = &document{ uri: uri }
+        s.docs[ uri ] = doc
+    }
+    doc.setText( text )
+    return s.reanalyze()
+}
+
+func ( s *Server ) didOpen( p *DidOpenTextDocumentParams ) error {
+    return s.setDocumentText( p.TextDocument.Uri, p.TextDocument.Text )
+}
+
+func ( s *Server ) didChange( p *DidChangeTextDocumentParams ) error {
+    if len( p.ContentChanges ) == 0 { return nil }
+    text := p.ContentChanges[ len( p.ContentChanges ) - 1 ].Text
+    return s.setDocumentText( p.TextDocument.Uri, text )
+}
+
+// Clears diagnostics for the closed document, since the client will otherwise
+// continue to display them
+func ( s *Server ) didClose( p *DidCloseTextDocumentParams ) error {
+    uri := p.TextDocument.Uri
+    if _, ok := s.docs[ uri ]; ! ok { return nil }
+    delete( s.docs, uri )
+    params := &PublishDiagnosticsParams{ Uri: uri, Diagnostics: []Diagnostic{} }
+    if err := s.notify( MethodPublishDiagnostics, params ); err != nil {
+        return err
+    }
+    return s.reanalyze()
+}
+
+func ( s *Server ) indexFor( id TextDocumentIdentifier ) *sourceIndex {
+    if doc := s.docs[ id.Uri ]; doc != nil { return doc.index }
+    return nil
+}
+
+func ( s *Server ) definition( p *TextDocumentPositionParams ) *Location {
+    si := s.indexFor( p.TextDocument )
+    if si == nil { return nil }
+    if tr := si.typeRefAt( p.Position ); tr != nil {
+        if qn := s.an.resolve( si, tr.name ); qn != nil {
+            if ds, ok := s.an.decls.Get( qn ).( *declSite ); ok {
+                return ds.asLocation()
+            }
+        }
+        return nil
+    }
+    if nr := si.nsRefAt( p.Position ); nr != nil {
+        if ds, ok := s.an.nsDecls.Get( nr.ns ).( *declSite ); ok {
+            return ds.asLocation()
+        }
+    }
+    return nil
+}
+
+func newPlainTextHover( s string, sp span ) *Hover {
+    r := sp.asRange()
+    return &Hover{
+        Contents: MarkupContent{ Kind: MarkupKindPlainText, Value: s },
+        Range: &r,
+    }
+}
+
+func ( s *Server ) hover( p *TextDocumentPositionParams ) *Hover {
+    si := s.indexFor( p.TextDocument )
+    if si == nil { return nil }
+    if tr := si.typeRefAt( p.Position ); tr != nil {
+        if qn := s.an.resolve( si, tr.name ); qn != nil {
+            return newPlainTextHover( s.an.describeType( qn ), tr.span )
+        }
+        return nil
+    }
+    if fs := si.fieldAt( p.Position ); fs != nil {
+        if fd := s.an.fieldDefinitionFor( si, fs ); fd != nil {
+            return newPlainTextHover( describeField( fd ), fs.span )
+        }
+    }
+    return nil
+}
+
+func ( s *Server ) completion( p *TextDocumentPositionParams ) *CompletionList {
+    res := &CompletionList{ Items: []CompletionItem{} }
+    if si := s.indexFor( p.TextDocument ); si != nil {
+        res.Items = s.an.completionItems( si )
+    }
+    return res
+}
+
+func initializeResult() *InitializeResult {
+    return &InitializeResult{
+        Capabilities: ServerCapabilities{
+            TextDocumentSync: TextDocumentSyncKindFull,
+            DefinitionProvider: true,
+            HoverProvider: true,
+            CompletionProvider: &CompletionOptions{},
+        },
+        ServerInfo: &ServerInfo{ Name: serverName },
+    }
+}
+
+func unmarshalParams( m *Message, dest interface{} ) *ResponseError {
+    if err := json.Unmarshal( m.Params, dest ); err != nil {
+        return newResponseErrorf( ErrorCodeInvalidParams, "%s", err )
+    }
+    return nil
+}
+
+func ( s *Server ) positionRequest(
+    m *Message,
+    f func( p *TextDocumentPositionParams ) interface{} ) ( interface{},
+                                                            *ResponseError ) {
+
+    p := new( TextDocumentPositionParams )
+    if err := unmarshalParams( m, p ); err != nil { return nil, err }
+    return f( p ), nil
+}
+
+func ( s *Server ) dispatchRequest(
+    m *Message ) ( interface{}, *ResponseError ) {
+
+    if s.isShutdown {
+        return nil, newResponseErrorf(
+            ErrorCodeInvalidRequest, "server is shut down" )
+    }
+    switch m.Method {
+    case MethodInitialize: return initializeResult(), nil
+    case MethodShutdown:
+        s.isShutdown = true
+        return nil, nil
+    case MethodDefinition:
+        return s.positionRequest( m,
+            func( p *TextDocumentPositionParams ) interface{} {
+                return s.definition( p )
+            },
+        )
+    case MethodHover:
+        return s.positionRequest( m,
+            func( p *TextDocumentPositionParams ) interface{} {
+                return s.hover( p )
+            },
+        )
+    case MethodCompletion:
+        return s.positionRequest( m,
+            func( p *TextDocumentPositionParams ) interface{} {
+                return s.completion( p )
+            },
+        )
+    }
+    return nil, newResponseErrorf(
+        ErrorCodeMethodNotFound, "method not found: %s", m.Method )
+}
+
+func ( s *Server ) handleRequest( m *Message ) error {
+    res, rErr := s.dispatchRequest( m )
+    if rErr != nil {
+        return s.conn.WriteMessage( newErrorResponse( m.Id, rErr ) )
+    }
+    resp, err := newResponse( m.Id, res )
+    if err != nil { return err }
+    return s.conn.WriteMessage( resp )
+}
+
+// Notifications with invalid params have no way to report an error to the
+// client, so are logged and otherwise ignored, as are unknown notifications
+func ( s *Server ) handleNotification( m *Message ) error {
+    var p interface{}
+    switch m.Method {
+    case MethodDidOpen: p = new( DidOpenTextDocumentParams )
+    case MethodDidChange: p = new( DidChangeTextDocumentParams )
+    case MethodDidClose: p = new( DidCloseTextDocumentParams )
+    default: return nil
+    }
+    if err := unmarshalParams( m, p ); err != nil {
+        log.Printf( "%s: %s: %s", serverName, m.Method, err )
+        return nil
+    }
+    switch v := p.( type ) {
+    case *DidOpenTextDocumentParams: return s.didOpen( v )
+    case *DidChangeTextDocumentParams: return s.didChange( v )
+    case *DidCloseTextDocumentParams: return s.didClose( v )
+    }
+    panic( libErrorf( "unhandled params: %T", p ) )
+}
+
+// A panic while handling a request is answered with an internal error, and one
+// while handling a notification is logged, so that a single bad message does
+// not end the session
+func ( s *Server ) recovered( m *Message, r interface{} ) error {
+    log.Printf( "%s: %s: %v", serverName, m.Method, r )
+    if ! m.IsRequest() { return nil }
+    rErr := newResponseErrorf( ErrorCodeInternalError, "%v", r )
+    return s.conn.WriteMessage( newErrorResponse( m.Id, rErr ) )
+}
+
+func ( s *Server ) handleMessage( m *Message ) ( err error ) {
+    defer func() {
+        if r := recover(); r != nil { err = s.recovered( m, r ) }
+    }()
+    switch {
+    case m.IsRequest(): return s.handleRequest( m )
+    case m.IsNotification(): return s.handleNotification( m )
+    }
+    return nil // responses are ignored since this server sends no requests
+}
+
+func ( s *Server ) exitError() error {
+    if s.isShutdown { return nil }
+    return libError( "exit before shutdown" )
+}
+
+// Reads and handles messages until the client sends the exit notification or
+// closes its input. The return value is nil only if the client had first
+// requested a shutdown.
+func ( s *Server ) Serve() error {
+    for {
+        m, err := s.conn.ReadMessage()
+        if err == io.EOF { return s.exitError() }
+        if rErr, ok := err.( *ResponseError ); ok {
+            err = s.conn.WriteMessage( newErrorResponse( nil, rErr ) )
+        } else if err == nil {
+            if m.Method == MethodExit { return s.exitError() }
+            err = s.handleMessage( m )
+        }
+        if err != nil { return err }
+    }
+}
@@ -0,0 +1,272 @@
+package lsp
+
+import (
+    "testing"
+    "strings"
+)
+
+const (
+    uriA = "file:///a.mg"
+    uriB = "file:///b.mg"
+)
+
+func srcLines( lines ...string ) string { return strings.Join( lines, "\n" ) }
+
+var srcA = srcLines(
+    "@version v1",
+    "namespace ns1",
+    "# A struct",
+    "struct S1 {",
+    "    f1 Int32 default 1",
+    "    f2 &S2?",
+    "}",
+    "struct S2 {}",
+)
+
+var srcB = srcLines(
+    "@version v1",
+    "import ns1/S1",
+    "namespace ns2",
+    "struct S3 {",
+    "    f1 S1",
+    "    f2 ns1@v1/S2",
+    "}",
+)
+
+func TestLifecycle( t *testing.T ) {
+    c := newScriptedClient( t )
+    res := c.initialize()
+    caps := res.Capabilities
+    c.Equal( TextDocumentSyncKindFull, caps.TextDocumentSync )
+    c.True( caps.DefinitionProvider )
+    c.True( caps.HoverProvider )
+    c.Truef( caps.CompletionProvider != nil, "no completion provider" )
+    err := c.call( "textDocument/formatting", nil, nil )
+    c.Equal( ErrorCodeMethodNotFound, err.Code )
+    c.mustCall( MethodShutdown, nil, nil )
+    err = c.call( MethodHover, positionParams( uriA, 0, 0 ), nil )
+    c.Equal( ErrorCodeInvalidRequest, err.Code )
+    c.notify( MethodExit, nil )
+    c.expectServed( nil )
+}
+
+func TestExitBeforeShutdown( t *testing.T ) {
+    c := newScriptedClient( t )
+    c.initialize()
+    c.notify( MethodExit, nil )
+    c.expectServed( libError( "exit before shutdown" ) )
+}
+
+func TestDiagnostics( t *testing.T ) {
+    c := newScriptedClient( t )
+    c.initialize()
+    uri := "file:///c.mg"
+    c.open( uri, srcLines(
+        "@version v1",
+        "namespace ns1",
+        "struct S1 { f1 Foo }",
+    ))
+    diags := c.expectDiagnostics( uri )
+    c.Equal( 1, len( diags ) )
+    c.Equal( "Unresolved type: Foo", diags[ 0 ].Message )
+    c.Equal( mkRange( 2, 15, 18 ), diags[ 0 ].Range )
+    c.Equal( DiagnosticSeverityError, diags[ 0 ].Severity )
+    c.Equal( "mingle", diags[ 0 ].Source )
+    c.change( uri, 2, srcLines(
+        "@version v1",
+        "namespace ns1",
+        "struct S1 { f1 Int32 default }",
+    ))
+    diags = c.expectDiagnostics( uri )
+    c.Equal( 1, len( diags ) )
+    c.Equal( mkRange( 2, 29, 30 ), diags[ 0 ].Range )
+    c.Equal( "Expected unary expression but found: }", diags[ 0 ].Message )
+    c.change( uri, 3, srcLines(
+        "@version v1",
+        "namespace ns1",
+        "struct S1 { f1 Int32 }",
+    ))
+    c.Equal( 0, len( c.expectDiagnostics( uri ) ) )
+    c.shutdown()
+}
+
+// All of the syntax errors in a document are reported at once
+func TestSyntaxDiagnostics( t *testing.T ) {
+    c := newScriptedClient( t )
+    c.initialize()
+    uri := "file:///c.mg"
+    c.open( uri, srcLines(
+        "@version v1",
+        "namespace ns1",
+        "struct S1 { f1 Int32 default }",
+        "struct S2 { f1 String~12; f2 String }",
+        "enum E1 { red, 1 }",
+    ))
+    diags := c.expectDiagnostics( uri )
+    c.Equal( 3, len( diags ) )
+    c.Equal( mkRange( 2, 29, 30 ), diags[ 0 ].Range )
+    c.Equal( "Expected unary expression but found: }", diags[ 0 ].Message )
+    c.Equal( "Expected type restriction but found: 12", diags[ 1 ].Message )
+    c.Equal( 3, diags[ 1 ].Range.Start.Line )
+    c.Equal( "Expected identifier but found: 1", diags[ 2 ].Message )
+    c.Equal( 4, diags[ 2 ].Range.Start.Line )
+    c.shutdown()
+}
+
+// Checks that documents are compiled together, so that fixing a type in one
+// clears an error reported in another, and that closing a document clears its
+// diagnostics
+func TestCrossDocumentDiagnostics( t *testing.T ) {
+    c := newScriptedClient( t )
+    c.initialize()
+    c.open( uriB, srcB )
+    diags := c.expectDiagnostics( uriB )
+    c.Equal( 3, len( diags ) )
+    c.Equal( "Unknown target namespace for import: ns1@v1", diags[ 0 ].Message )
+    c.Equal( mkRange( 1, 7, 13 ), diags[ 0 ].Range )
+    c.Equal( "Unresolved type: S1", diags[ 1 ].Message )
+    c.Equal( "Unresolved type: ns1@v1/S2", diags[ 2 ].Message )
+    c.Equal( mkRange( 5, 7, 16 ), diags[ 2 ].Range )
+    c.open( uriA, srcA )
+    c.Equal( 0, len( c.expectDiagnostics( uriA ) ) )
+    c.Equal( 0, len( c.expectDiagnostics( uriB ) ) )
+    c.notify( MethodDidClose, &DidCloseTextDocumentParams{
+        TextDocument: TextDocumentIdentifier{ Uri: uriB },
+    })
+    c.Equal( 0, len( c.expectDiagnostics( uriB ) ) )
+    c.shutdown()
+}
+
+func openAandB( c *scriptedClient ) {
+    c.initialize()
+    c.open( uriA, srcA )
+    c.expectDiagnostics( uriA )
+    c.open( uriB, srcB )
+    c.expectDiagnostics( uriB )
+}
+
+func TestDefinition( t *testing.T ) {
+    c := newScriptedClient( t )
+    openAandB( c )
+    chk := func( uri string, line, char int, expct *Location ) {
+        act := new( Location )
+        c.mustCall( MethodDefinition, positionParams( uri, line, char ), &act )
+        if expct == nil {
+            c.Truef( act == nil, "expected no location, got %#v", act )
+        } else { c.Equal( expct, act ) }
+    }
+    locS1 := &Location{ Uri: uriA, Range: mkRange( 3, 7, 9 ) }
+    locS2 := &Location{ Uri: uriA, Range: mkRange( 7, 7, 9 ) }
+    chk( uriA, 5, 7, nil ) // the '&' of &S2? in field f2
+    chk( uriA, 5, 8, locS2 )
+    chk( uriA, 5, 9, locS2 )
+    chk( uriA, 5, 10, nil ) // the '?'
+    chk( uriA, 3, 7, locS1 ) // a declaration is its own definition
+    chk( uriA, 4, 8, nil ) // Int32 is builtin and has no source location
+    chk( uriB, 1, 11, locS1 ) // import ns1/S1
+    chk( uriB, 1, 8, &Location{ Uri: uriA, Range: mkRange( 1, 10, 13 ) } )
+    chk( uriB, 4, 7, locS1 )
+    chk( uriB, 5, 12, locS2 ) // qualified name ns1@v1/S2
+    chk( "file:///unknown.mg", 0, 0, nil )
+    c.shutdown()
+}
+
+func TestHover( t *testing.T ) {
+    c := newScriptedClient( t )
+    openAandB( c )
+    chk := func( uri string, line, char int, expct *Hover ) {
+        act := new( Hover )
+        c.mustCall( MethodHover, positionParams( uri, line, char ), &act )
+        if expct == nil {
+            c.Truef( act == nil, "expected no hover, got %#v", act )
+        } else { c.Equal( expct, act ) }
+    }
+    hover := func( val string, r Range ) *Hover {
+        return &Hover{
+            Contents: MarkupContent{ Kind: MarkupKindPlainText, Value: val },
+            Range: &r,
+        }
+    }
+    s1Desc := "struct ns1@v1/S1\n\nA struct"
+    chk( uriA, 3, 8, hover( s1Desc, mkRange( 3, 7, 9 ) ) )
+    chk( uriA, 4, 9,
+        hover( "primitive mingle:core@v1/Int32", mkRange( 4, 7, 12 ) ) )
+    chk( uriA, 4, 4,
+        hover( "f1 mingle:core@v1/Int32\ndefault: 1", mkRange( 4, 4, 6 ) ) )
+    chk( uriA, 5, 5, hover( "f2 &(ns1@v1/S2)?", mkRange( 5, 4, 6 ) ) )
+    chk( uriB, 4, 7, hover( s1Desc, mkRange( 4, 7, 9 ) ) )
+    chk( uriA, 0, 0, nil )
+    c.shutdown()
+}
+
+func TestCompletion( t *testing.T ) {
+    c := newScriptedClient( t )
+    openAandB( c )
+    list := new( CompletionList )
+    c.mustCall( MethodCompletion, positionParams( uriB, 4, 7 ), list )
+    items := make( map[ string ]CompletionItem )
+    for _, item := range list.Items { items[ item.Label ] = item }
+    c.Equal(
+        CompletionItem{
+            Label: "S1",
+            Kind: CompletionItemKindStruct,
+            Detail: "ns1@v1/S1",
+        },
+        items[ "S1" ],
+    )
+    c.Equal( "ns2@v1/S3", items[ "S3" ].Detail )
+    c.Equal( "mingle:core@v1/Int32", items[ "Int32" ].Detail )
+    _, ok := items[ "S2" ]
+    c.Falsef( ok, "S2 is not imported but was offered" )
+    c.shutdown()
+}
+
+// A document which stops parsing keeps its last good index, so that navigation
+// continues to work while the user is mid-edit
+func TestStaleIndexUsedOnParseError( t *testing.T ) {
+    c := newScriptedClient( t )
+    openAandB( c )
+    c.change( uriA, 2, srcLines( "@version v1", "namespace" ) )
+    c.Equal( 1, len( c.expectDiagnostics( uriA ) ) )
+    act := new( Location )
+    c.mustCall( MethodDefinition, positionParams( uriB, 4, 7 ), &act )
+    c.Equal( &Location{ Uri: uriA, Range: mkRange( 3, 7, 9 ) }, act )
+    c.shutdown()
+}
+
+// The declarations which parse in a document with syntax errors replace those
+// of its previous version
+func TestRecoveredIndexUsedOnSyntaxError( t *testing.T ) {
+    c := newScriptedClient( t )
+    openAandB( c )
+    c.change( uriA, 2, "\n" + srcA + "\nstruct" )
+    c.Equal( 1, len( c.expectDiagnostics( uriA ) ) )
+    act := new( Location )
+    c.mustCall( MethodDefinition, positionParams( uriB, 4, 7 ), &act )
+    c.Equal( &Location{ Uri: uriA, Range: mkRange( 4, 7, 9 ) }, act )
+    hov := new( Hover )
+    c.mustCall( MethodHover, positionParams( uriA, 4, 8 ), &hov )
+    c.Truef( hov != nil, "no hover" )
+    c.shutdown()
+}
+
+// A document cut off mid-declaration, as while the user is typing, is reported
+// with diagnostics and leaves the server able to handle further messages
+func TestIncompleteDocument( t *testing.T ) {
+    c := newScriptedClient( t )
+    c.initialize()
+    uri := "file:///c.mg"
+    c.open( uri, srcLines(
+        "@version v1",
+        "namespace ns1",
+        "struct S1 {",
+        "    f",
+    ))
+    c.Truef( len( c.expectDiagnostics( uri ) ) > 0, "no diagnostics" )
+    c.change( uri, 2, srcA )
+    c.Equal( 0, len( c.expectDiagnostics( uri ) ) )
+    act := new( Hover )
+    c.mustCall( MethodHover, positionParams( uri, 5, 8 ), &act )
+    c.Truef( act != nil, "no hover" )
+    c.shutdown()
+}
@@ -0,0 +1,179 @@
+package lsp
+
+import (
+    "io"
+    "time"
+    "testing"
+    "encoding/json"
+    "bitgirder/assert"
+)
+
+const clientTimeout = 5 * time.Second
+
+// Drives a Server over in-memory pipes. Messages from the server are read on a
+// separate goroutine so that the server never blocks writing notifications
+// while the client is itself blocked writing its next message.
+type scriptedClient struct {
+    *assert.PathAsserter
+    conn *Conn
+    srvIn *io.PipeWriter
+    msgs chan *Message
+    served chan error
+    pending []*Message
+    nextId int
+}
+
+func newScriptedClient( t *testing.T ) *scriptedClient {
+    srvR, cliW := io.Pipe()
+    cliR, srvW := io.Pipe()
+    c := &scriptedClient{
+        PathAsserter: assert.NewPathAsserter( t ),
+        conn: NewConn( cliR, cliW ),
+        srvIn: cliW,
+        msgs: make( chan *Message, 16 ),
+        served: make( chan error, 1 ),
+        nextId: 1,
+    }
+    srv := NewServer( &ServerOptions{ Reader: srvR, Writer: srvW } )
+    go func() {
+        c.served <- srv.Serve()
+        srvW.Close()
+    }()
+    go func() {
+        defer close( c.msgs )
+        for {
+            m, err := c.conn.ReadMessage()
+            if err != nil { return }
+            c.msgs <- m
+        }
+    }()
+    return c
+}
+
+func ( c *scriptedClient ) nextMessage() *Message {
+    select {
+    case m, ok := <-c.msgs:
+        if ! ok { c.Fatal( "server closed its output" ) }
+        return m
+    case <-time.After( clientTimeout ):
+        c.Fatal( "timed out waiting for server" )
+    }
+    panic( libErrorf( "unreachable" ) )
+}
+
+func ( c *scriptedClient ) notify( method string, params interface{} ) {
+    m, err := NewNotification( method, params )
+    if err == nil { err = c.conn.WriteMessage( m ) }
+    if err != nil { c.Fatal( err ) }
+}
+
+// Sends a request and reads messages until its response, queuing any
+// notifications seen along the way. If the response is successful its result is
+// unmarshaled into result, which may be nil.
+func ( c *scriptedClient ) call(
+    method string, params, result interface{} ) *ResponseError {
+
+    id := c.nextId
+    c.nextId++
+    m, err := NewRequest( id, method, params )
+    if err == nil { err = c.conn.WriteMessage( m ) }
+    if err != nil { c.Fatal( err ) }
+    for {
+        resp := c.nextMessage()
+        if ! resp.IsResponse() {
+            c.pending = append( c.pending, resp )
+            continue
+        }
+        c.Equal( json.RawMessage( m.Id ), resp.Id )
+        if resp.Error != nil { return resp.Error }
+        if result != nil {
+            if err := json.Unmarshal( resp.Result, result ); err != nil {
+                c.Fatal( err )
+            }
+        }
+        return nil
+    }
+}
+
+func ( c *scriptedClient ) mustCall(
+    method string, params, result interface{} ) {
+
+    if err := c.call( method, params, result ); err != nil { c.Fatal( err ) }
+}
+
+func ( c *scriptedClient ) nextNotification() *Message {
+    if len( c.pending ) > 0 {
+        res := c.pending[ 0 ]
+        c.pending = c.pending[ 1 : ]
+        return res
+    }
+    return c.nextMessage()
+}
+
+// Returns the diagnostics from the next publishDiagnostics notification for
+// uri, discarding any for other documents
+func ( c *scriptedClient ) expectDiagnostics( uri string ) []Diagnostic {
+    for {
+        m := c.nextNotification()
+        if m.Method != MethodPublishDiagnostics { continue }
+        p := new( PublishDiagnosticsParams )
+        if err := json.Unmarshal( m.Params, p ); err != nil { c.Fatal( err ) }
+        if p.Uri == uri { return p.Diagnostics }
+    }
+}
+
+func ( c *scriptedClient ) initialize() *InitializeResult {
+    res := new( InitializeResult )
+    c.mustCall( MethodInitialize, map[ string ]interface{}{}, res )
+    c.notify( MethodInitialized, map[ string ]interface{}{} )
+    return res
+}
+
+func ( c *scriptedClient ) open( uri, text string ) {
+    c.notify( MethodDidOpen, &DidOpenTextDocumentParams{
+        TextDocument: TextDocumentItem{
+            Uri: uri,
+            LanguageId: "mingle",
+            Version: 1,
+            Text: text,
+        },
+    })
+}
+
+func ( c *scriptedClient ) change( uri string, version int, text string ) {
+    c.notify( MethodDidChange, &DidChangeTextDocumentParams{
+        TextDocument: VersionedTextDocumentIdentifier{
+            Uri: uri,
+            Version: version,
+        },
+        ContentChanges: []TextDocumentContentChangeEvent{ { Text: text } },
+    })
+}
+
+func positionParams( uri string, line, char int ) *TextDocumentPositionParams {
+    return &TextDocumentPositionParams{
+        TextDocument: TextDocumentIdentifier{ Uri: uri },
+        Position: Position{ Line: line, Character: char },
+    }
+}
+
+// Performs an orderly shutdown and checks that the server exited cleanly
+func ( c *scriptedClient ) shutdown() {
+    c.mustCall( MethodShutdown, nil, nil )
+    c.notify( MethodExit, nil )
+    c.expectServed( nil )
+}
+
+func ( c *scriptedClient ) expectServed( expct error ) {
+    select {
+    case err := <-c.served: c.EqualErrors( expct, err )
+    case <-time.After( clientTimeout ): c.Fatal( "server did not exit" )
+    }
+}
+
+func mkRange( line, startChar, endChar int ) Range {
+    return Range{
+        Start: Position{ Line: line, Character: startChar },
+        End: Position{ Line: line, Character: endChar },
+    }
+}
@@ -28,10 +28,29 @@ var (
     IdConstructor = mg.NewIdentifierUnsafe( []string{ "constructor" } )
     IdSecurity = mg.NewIdentifierUnsafe( []string{ "security" } )
     IdSchema = mg.NewIdentifierUnsafe( []string{ "schema" } )
-
-    structureElementKeys = []*mg.Identifier{ IdConstructor, IdSchema }
+    IdConstraint = mg.NewIdentifierUnsafe( []string{ "constraint" } )
+    IdReserved = mg.NewIdentifierUnsafe( []string{ "reserved" } )
+    idTag = mg.NewIdentifierUnsafe( []string{ "tag" } )
+
+    structureElementKeys = []*mg.Identifier{ 
+        IdConstructor, 
+        IdSchema, 
+        IdConstraint, 
+        IdReserved,
+    }
     serviceElementKeys = []*mg.Identifier{ IdSecurity }
 
+    // keys which may never be used as annotation names, whether or not they
+    // are allowed as keyed elements in a given context
+    reservedKeys = []*mg.Identifier{ 
+        idVersion, 
+        IdConstructor, 
+        IdSecurity, 
+        IdSchema,
+        IdConstraint,
+        IdReserved,
+    }
+
     typeDeclKwds = []parser.Keyword{ 
         kwdStruct, 
         kwdEnum,
@@ -49,6 +68,17 @@ var (
         parser.SpecialTokenForwardSlash,
     }
 
+    // comparisons bind more loosely than the ops in binaryOps and do not
+    // chain, so that 'a < b + 1' compares a with b + 1
+    comparisonOps = []parser.SpecialToken{
+        parser.SpecialTokenEqual,
+        parser.SpecialTokenNotEqual,
+        parser.SpecialTokenLessThan,
+        parser.SpecialTokenLessThanOrEqual,
+        parser.SpecialTokenGreaterThan,
+        parser.SpecialTokenGreaterThanOrEqual,
+    }
+
     unaryOps = []parser.SpecialToken{
         parser.SpecialTokenMinus,
         parser.SpecialTokenPlus,
@@ -56,6 +86,7 @@ var (
 
     tkOpenBracket = parser.SpecialTokenOpenBracket
     tkCloseParen = parser.SpecialTokenCloseParen
+    tkOpenBrace = parser.SpecialTokenOpenBrace
     tkCloseBrace = parser.SpecialTokenCloseBrace
     tkCloseBracket = parser.SpecialTokenCloseBracket
     tkComma = parser.SpecialTokenComma
@@ -171,6 +202,14 @@ func ( be *BinaryExpression ) Locate() *parser.Location {
     return be.Left.Locate() 
 }
 
+type CallExpression struct {
+    Function *mg.Identifier
+    FunctionLoc *parser.Location
+    Args []Expression
+}
+
+func ( ce *CallExpression ) Locate() *parser.Location { return ce.FunctionLoc }
+
 type ListExpression struct {
     Elements []Expression
     Start *parser.Location
@@ -178,6 +217,15 @@ type ListExpression struct {
 
 func ( le *ListExpression ) Locate() *parser.Location { return le.Start }
 
+type Annotation struct {
+    Start *parser.Location
+    Name *mg.Identifier
+    NameLoc *parser.Location
+    Args []Expression
+}
+
+func ( a *Annotation ) Locate() *parser.Location { return a.Start }
+
 type ConstructorDecl struct {
     Start *parser.Location
     ArgType *parser.CompletableTypeReference
@@ -185,11 +233,35 @@ type ConstructorDecl struct {
 
 func ( cd *ConstructorDecl ) Locate() *parser.Location { return cd.Start }
 
+type ConstraintDecl struct {
+    Start *parser.Location
+    Expression Expression
+    Message Expression // nil if no message was given
+}
+
+func ( cd *ConstraintDecl ) Locate() *parser.Location { return cd.Start }
+
+type ReservedName struct {
+    Name *mg.Identifier
+    NameLoc *parser.Location
+}
+
+// field names, such as those of removed fields, which the struct declaring
+// them may not use
+type ReservedDecl struct {
+    Start *parser.Location
+    Names []*ReservedName
+}
+
+func ( rd *ReservedDecl ) Locate() *parser.Location { return rd.Start }
+
 type FieldDecl struct {
     Name *mg.Identifier
     NameLoc *parser.Location
     Type *parser.CompletableTypeReference
     Default Expression
+    Annotations []*Annotation
+    Doc string
 }
 
 func ( fd *FieldDecl ) Locate() *parser.Location { return fd.NameLoc }
@@ -204,6 +276,8 @@ func ( sd *SchemaMixinDecl ) Locate() *parser.Location { return sd.Start }
 
 type TypeDecl interface {
     GetName() *mg.DeclaredTypeName
+    GetAnnotations() []*Annotation
+    GetDoc() string
     Locatable
 }
 
@@ -222,38 +296,71 @@ type StructDecl struct {
     Fields []*FieldDecl
     Constructors []*ConstructorDecl
     Schemas []*SchemaMixinDecl
+    Constraints []*ConstraintDecl
+    Reserved []*ReservedDecl
+    Annotations []*Annotation
+    Doc string
+    End *parser.Location // the closing '}'
 }
 
 func ( sd *StructDecl ) GetTypeInfo() *TypeDeclInfo { return sd.Info }
 func ( sd *StructDecl ) GetName() *mg.DeclaredTypeName { return sd.Info.Name }
 func ( sd *StructDecl ) Locate() *parser.Location { return sd.Start }
 func ( sd *StructDecl ) GetFields() []*FieldDecl { return sd.Fields }
+func ( sd *StructDecl ) GetAnnotations() []*Annotation { return sd.Annotations }
+
+func ( sd *StructDecl ) setAnnotations( annots []*Annotation ) {
+    sd.Annotations = annots
+}
+
+func ( sd *StructDecl ) GetDoc() string { return sd.Doc }
+
+func ( sd *StructDecl ) setDoc( doc string ) { sd.Doc = doc }
 
 func ( sd *StructDecl ) createKeyedEltsAcc() *mg.IdentifierMap {
     res := mg.NewIdentifierMap()
     res.Put( IdConstructor, make( []*ConstructorDecl, 0, 2 ) )
     res.Put( IdSchema, make( []*SchemaMixinDecl, 0, 2 ) )
+    res.Put( IdConstraint, make( []*ConstraintDecl, 0, 2 ) )
+    res.Put( IdReserved, make( []*ReservedDecl, 0, 1 ) )
     return res
 }
 
 func ( sd *StructDecl ) initKeyedElts( ke *mg.IdentifierMap ) {
     sd.Schemas = ke.Get( IdSchema ).( []*SchemaMixinDecl )
     sd.Constructors = ke.Get( IdConstructor ).( []*ConstructorDecl )
+    sd.Constraints = ke.Get( IdConstraint ).( []*ConstraintDecl )
+    sd.Reserved = ke.Get( IdReserved ).( []*ReservedDecl )
 }
 
 func ( sd *StructDecl ) setFields( flds []*FieldDecl ) { sd.Fields = flds }
 
 func ( sd *StructDecl ) setInfo( inf *TypeDeclInfo ) { sd.Info = inf }
 
+func ( sd *StructDecl ) setEnd( end *parser.Location ) { sd.End = end }
+
 type SchemaDecl struct {
     Start *parser.Location
     Info *TypeDeclInfo
     Fields []*FieldDecl
     Schemas []*SchemaMixinDecl
+    Annotations []*Annotation
+    Doc string
+    End *parser.Location // the closing '}'
 }
 
 func ( sd *SchemaDecl ) Locate() *parser.Location { return sd.Start }
 
+func ( sd *SchemaDecl ) GetAnnotations() []*Annotation { return sd.Annotations }
+
+func ( sd *SchemaDecl ) setAnnotations( annots []*Annotation ) {
+    sd.Annotations = annots
+}
+
+func ( sd *SchemaDecl ) GetDoc() string { return sd.Doc }
+
+func ( sd *SchemaDecl ) setDoc( doc string ) { sd.Doc = doc }
+
 func ( sd *SchemaDecl ) GetFields() []*FieldDecl { return sd.Fields }
 
 func ( sd *SchemaDecl ) GetName() *mg.DeclaredTypeName { return sd.Info.Name }
@@ -272,16 +379,28 @@ func ( sd *SchemaDecl ) initKeyedElts( ke *mg.IdentifierMap ) {
 
 func ( sd *SchemaDecl ) setFields( flds []*FieldDecl ) { sd.Fields = flds }
 
+func ( sd *SchemaDecl ) setEnd( end *parser.Location ) { sd.End = end }
+
 type structureDecl interface {
     createKeyedEltsAcc() *mg.IdentifierMap
     initKeyedElts( *mg.IdentifierMap )
     setFields( []*FieldDecl )
     setInfo( *TypeDeclInfo )
+    setEnd( *parser.Location )
+}
+
+type EnumValueAlias struct {
+    Alias *mg.Identifier
+    AliasLoc *parser.Location
 }
 
 type EnumValue struct {
     Value *mg.Identifier
     ValueLoc *parser.Location
+    Ordinal Expression // nil if no explicit ordinal was given
+    Aliases []*EnumValueAlias
+    Annotations []*Annotation
+    Doc string
 }
 
 func ( ev *EnumValue ) Locate() *parser.Location { return ev.ValueLoc }
@@ -291,20 +410,43 @@ type EnumDecl struct {
     Name *mg.DeclaredTypeName
     NameLoc *parser.Location
     Values []*EnumValue
+    Annotations []*Annotation
+    Doc string
+    End *parser.Location // the closing '}'
 }
 
 func ( ed *EnumDecl ) GetName() *mg.DeclaredTypeName { return ed.Name }
 func ( ed *EnumDecl ) Locate() *parser.Location { return ed.Start }
+func ( ed *EnumDecl ) GetAnnotations() []*Annotation { return ed.Annotations }
+
+func ( ed *EnumDecl ) setAnnotations( annots []*Annotation ) {
+    ed.Annotations = annots
+}
+
+func ( ed *EnumDecl ) GetDoc() string { return ed.Doc }
+
+func ( ed *EnumDecl ) setDoc( doc string ) { ed.Doc = doc }
 
 type AliasDecl struct {
     Start *parser.Location
     Name *mg.DeclaredTypeName
     NameLoc *parser.Location
     Target *parser.CompletableTypeReference
+    Annotations []*Annotation
+    Doc string
 }
 
 func ( ad *AliasDecl ) GetName() *mg.DeclaredTypeName { return ad.Name }
 func ( ad *AliasDecl ) Locate() *parser.Location { return ad.Start }
+func ( ad *AliasDecl ) GetAnnotations() []*Annotation { return ad.Annotations }
+
+func ( ad *AliasDecl ) setAnnotations( annots []*Annotation ) {
+    ad.Annotations = annots
+}
+
+func ( ad *AliasDecl ) GetDoc() string { return ad.Doc }
+
+func ( ad *AliasDecl ) setDoc( doc string ) { ad.Doc = doc }
 
 type ThrownType struct {
     Type *parser.CompletableTypeReference
@@ -315,6 +457,7 @@ func ( tt *ThrownType ) Locate() *parser.Location { return tt.Type.Location() }
 type CallSignature struct {
     Start *parser.Location
     Fields []*FieldDecl
+    FieldsEnd *parser.Location // the ')' closing the fields
     Return *parser.CompletableTypeReference
     Throws []*ThrownType
 }
@@ -325,15 +468,31 @@ type PrototypeDecl struct {
     Name *mg.DeclaredTypeName
     NameLoc *parser.Location
     Sig *CallSignature
+    Annotations []*Annotation
+    Doc string
 }
 
 func ( pd *PrototypeDecl ) GetName() *mg.DeclaredTypeName { return pd.Name }
 func ( pd *PrototypeDecl ) Locate() *parser.Location { return pd.Start }
 
+func ( pd *PrototypeDecl ) GetAnnotations() []*Annotation { 
+    return pd.Annotations
+}
+
+func ( pd *PrototypeDecl ) setAnnotations( annots []*Annotation ) {
+    pd.Annotations = annots
+}
+
+func ( pd *PrototypeDecl ) GetDoc() string { return pd.Doc }
+
+func ( pd *PrototypeDecl ) setDoc( doc string ) { pd.Doc = doc }
+
 type OperationDecl struct {
     Name *mg.Identifier
     NameLoc *parser.Location
     Call *CallSignature
+    Annotations []*Annotation
+    Doc string
 }
 
 func ( od *OperationDecl ) Locate() *parser.Location { return od.NameLoc }
@@ -351,12 +510,27 @@ type ServiceDecl struct {
     Info *TypeDeclInfo
     Operations []*OperationDecl
     SecurityDecls []*SecurityDecl
+    Annotations []*Annotation
+    Doc string
+    End *parser.Location // the closing '}'
 }
 
 func ( sd *ServiceDecl ) GetTypeInfo() *TypeDeclInfo { return sd.Info }
 func ( sd *ServiceDecl ) GetName() *mg.DeclaredTypeName { return sd.Info.Name }
 func ( sd *ServiceDecl ) Locate() *parser.Location { return sd.Start }
 
+func ( sd *ServiceDecl ) GetAnnotations() []*Annotation { 
+    return sd.Annotations 
+}
+
+func ( sd *ServiceDecl ) setAnnotations( annots []*Annotation ) {
+    sd.Annotations = annots
+}
+
+func ( sd *ServiceDecl ) GetDoc() string { return sd.Doc }
+
+func ( sd *ServiceDecl ) setDoc( doc string ) { sd.Doc = doc }
+
 func ( sd *ServiceDecl ) createKeyedEltsAcc() *mg.IdentifierMap {
     res := mg.NewIdentifierMap()
     res.Put( IdSecurity, make( []*SecurityDecl, 0, 2 ) )
@@ -367,29 +541,208 @@ func ( sd *ServiceDecl ) initKeyedElts( ke *mg.IdentifierMap ) {
     sd.SecurityDecls = ke.Get( IdSecurity ).( []*SecurityDecl )
 }
 
+type UnionTag struct {
+    Tag *mg.Identifier
+    TagLoc *parser.Location
+}
+
 type UnionDecl struct {
     Start *parser.Location
     Info *TypeDeclInfo
     Types []*parser.CompletableTypeReference
+
+    // parallel to Types, with a nil entry for each type declared without a
+    // tag; nil if no type was declared with a tag
+    Tags []*UnionTag
+
+    Annotations []*Annotation
+    Doc string
+    End *parser.Location // the closing '}'
 }
 
 func ( ud *UnionDecl ) GetName() *mg.DeclaredTypeName { return ud.Info.Name }
 
 func ( ud *UnionDecl ) Locate() *parser.Location { return ud.Start }
 
+func ( ud *UnionDecl ) GetAnnotations() []*Annotation { return ud.Annotations }
+
+func ( ud *UnionDecl ) setAnnotations( annots []*Annotation ) {
+    ud.Annotations = annots
+}
+
+func ( ud *UnionDecl ) GetDoc() string { return ud.Doc }
+
+func ( ud *UnionDecl ) setDoc( doc string ) { ud.Doc = doc }
+
+type decoratedTypeDecl interface { 
+    setAnnotations( []*Annotation ) 
+    setDoc( string )
+}
+
+// A comment as it appeared in the source. Text is everything following the
+// '#' up to but not including the end of the line.
+type Comment struct {
+    Text string
+    Loc *parser.Location
+    Trailing bool // true if the comment follows some other token on its line
+}
+
+func ( c *Comment ) Locate() *parser.Location { return c.Loc }
+
 type NsUnit struct {
     SourceName string
+    VersionLoc *parser.Location // the '@' of the @version declaration
     Imports []*Import
     NsDecl *NamespaceDecl
     TypeDecls []TypeDecl
+
+    // All comments in the source in order, including those which also became
+    // the doc of some declaration
+    Comments []*Comment
+}
+
+// Comments which are not trailing some other token on their line, keyed by
+// line number. A run of these ending on the line before a declaration becomes
+// that declaration's doc.
+type docComments struct { 
+    lines map[ int ]string 
+    all []*Comment
+}
+
+func newDocComments() *docComments {
+    return &docComments{ 
+        lines: make( map[ int ]string ),
+        all: make( []*Comment, 0, 8 ),
+    }
+}
+
+func ( dc *docComments ) add( 
+    c parser.CommentToken, lc *parser.Location, trailing bool ) {
+
+    s := strings.TrimRight( string( c ), "\r\n" )
+    dc.all = append( dc.all, &Comment{ Text: s, Loc: lc, Trailing: trailing } )
+    if trailing { return }
+    dc.lines[ lc.Line ] = strings.TrimPrefix( s, " " )
+}
+
+// removes and returns the run of comments ending on the line before line, or
+// the empty string if there is none
+func ( dc *docComments ) take( line int ) string {
+    start := line
+    for {
+        if _, ok := dc.lines[ start - 1 ]; ! ok { break }
+        start--
+    }
+    strs := make( []string, 0, line - start )
+    for i := start; i < line; i++ {
+        strs = append( strs, dc.lines[ i ] )
+        delete( dc.lines, i )
+    }
+    return strings.Join( strs, "\n" )
 }
 
 type parse struct {
 
     *parser.Builder
 
+    docs *docComments
+
     // set before parsing anything else
     verDefl *mg.Identifier
+
+    // when set, syntax errors are collected in errs and parsing resumes after
+    // them
+    recovering bool
+    errs []*parser.ParseError
+}
+
+// If recovering, records err and returns nil when err is a syntax error, so
+// that the caller can skip past it and continue. Returns err otherwise.
+func ( p *parse ) recordError( err error ) error {
+    if pe, ok := err.( *parser.ParseError ); ok && p.recovering {
+        p.errs = append( p.errs, pe )
+        return nil
+    }
+    return err
+}
+
+func isTypeDeclKeyword( tn *parser.TokenNode ) bool {
+    if kwd, ok := tn.Token.( parser.Keyword ); ok {
+        for _, k := range typeDeclKwds { if k == kwd { return true } }
+    }
+    return false
+}
+
+// keywords which only begin top level statements, and so are never skipped
+// when resuming after an error
+func isResyncKeyword( tn *parser.TokenNode ) bool {
+    return isTypeDeclKeyword( tn ) ||
+        tn.IsKeyword( kwdImport ) ||
+        tn.IsKeyword( kwdNamespace )
+}
+
+// Peeks at the next token, discarding any input which can't be read as one.
+// Returns nil at the end of input.
+func ( p *parse ) resyncPeek() ( *parser.TokenNode, error ) {
+    for {
+        tn, err := p.PeekToken()
+        if err == nil { return tn, nil }
+        if _, ok := err.( *parser.ParseError ); ! ok { return nil, err }
+        if err = p.SkipRune(); err == io.EOF {
+            return nil, nil
+        } else if err != nil { return nil, err }
+    }
+    panic( libErrorf( "unreachable" ) )
+}
+
+// Skips the rest of the statement in which an error occurred, returning true if
+// the enclosing block continues after it, or false if the input ended or a
+// top level statement began first. A closing brace which would end the
+// enclosing block is left to be read by the caller.
+func ( p *parse ) skipStatement() ( bool, error ) {
+    depth := 0
+    for {
+        tn, err := p.resyncPeek()
+        if err != nil || tn == nil { return false, err }
+        if isResyncKeyword( tn ) { return false, nil }
+        if depth == 0 && tn.IsSpecial( tkCloseBrace ) { return true, nil }
+        p.MustNextToken()
+        spec, _ := tn.Token.( parser.SpecialToken )
+        switch spec {
+        case parser.SpecialTokenOpenParen, tkOpenBracket, tkOpenBrace: depth++
+        case tkCloseParen, tkCloseBracket, tkCloseBrace:
+            if depth > 0 { depth-- }
+        case tkSemicolon, tkSynthEnd: if depth == 0 { return true, nil }
+        }
+    }
+    panic( libErrorf( "unreachable" ) )
+}
+
+// Records err and skips the rest of the statement in which it occurred,
+// returning true if the enclosing block continues after it. Returns err itself
+// if it can't be recovered from.
+func ( p *parse ) recoverStatement( err error ) ( bool, error ) {
+    if err = p.recordError( err ); err != nil { return false, err }
+    return p.skipStatement()
+}
+
+// Records err and skips to the next type declaration, if any. Returns err
+// itself if it can't be recovered from.
+func ( p *parse ) recoverTypeDecl( err error ) error {
+    if err = p.recordError( err ); err != nil { return err }
+    for {
+        tn, err := p.resyncPeek()
+        if err != nil || tn == nil || isTypeDeclKeyword( tn ) { return err }
+        p.MustNextToken()
+    }
+    panic( libErrorf( "unreachable" ) )
+}
+
+// returns the doc comment immediately preceding the next token, if any
+func ( p *parse ) pollDoc() ( string, error ) {
+    tn, err := p.PeekToken()
+    if err != nil || tn == nil { return "", err }
+    return p.docs.take( tn.Loc.Line ), nil
 }
 
 func ( p *parse ) pollKeywordLoc( 
@@ -483,10 +836,9 @@ func ( p *parse ) passStatementEnd() ( *parser.Location, error ) {
 }
 
 func ( p *parse ) peekSpecial( s parser.SpecialToken ) ( bool, error ) {
-    if tn, err := p.PeekToken(); err == nil {
-        return tn.IsSpecial( s ), nil
-    } else { return false, err }
-    return false, nil
+    tn, err := p.PeekToken()
+    if tn == nil || err != nil { return false, err }
+    return tn.IsSpecial( s ), nil
 }
 
 func ( p *parse ) expectIdentifier() ( *mg.Identifier, 
@@ -519,8 +871,9 @@ func ( p *parse ) expectTypeReference() (
     return p.ExpectTypeReference( p.verDefl )
 }
 
+// endLoc is the location of end if it was seen, nil otherwise
 func ( p *parse ) expectCommaOrEnd( 
-    end parser.SpecialToken ) ( sawEnd bool, err error ) {
+    end parser.SpecialToken ) ( endLoc *parser.Location, err error ) {
     var sawComma bool
     var tn *parser.TokenNode
     tn, err = p.PollSpecial( parser.SpecialTokenComma, end )
@@ -528,35 +881,31 @@ func ( p *parse ) expectCommaOrEnd(
     if tn != nil {
         if sawComma = tn.SpecialToken() == parser.SpecialTokenComma; sawComma {
             if tn, err = p.PollSpecial( end ); err != nil { return }
-            sawEnd = tn != nil
-        } else { sawEnd = true }
+        }
+        if tn != nil { endLoc = tn.Loc }
     } 
-    if ! ( sawComma || sawEnd ) {
+    if ! ( sawComma || endLoc != nil ) {
         err = p.ErrorTokenUnexpected( ", or " + string( end ), nil )
     }
     return
 }
 
-func ( p *parse ) setNsVersion() error {
-    if _, err := p.passSpecial( parser.SpecialTokenAsperand ); err != nil {
-        return err
-    }
-    if id, _, err := p.expectIdentifier(); err == nil {
-        if id.Equals( idVersion ) {
-            var tn *parser.TokenNode
-            if tn, err = p.ExpectIdentifier(); err == nil { 
-                p.verDefl = tn.Identifier()
-            } else { return err }
-        } else { return p.ParseError( "Expected @version" ) }
-    }
-    if _, err := p.passStatementEnd(); err != nil { return err }
-    return nil
+func ( p *parse ) setNsVersion( u *NsUnit ) ( err error ) {
+    if u.VersionLoc, err = p.passSpecial( tkAsperand ); err != nil { return }
+    var id *mg.Identifier
+    if id, _, err = p.expectIdentifier(); err != nil { return }
+    if ! id.Equals( idVersion ) { return p.ParseError( "Expected @version" ) }
+    var tn *parser.TokenNode
+    if tn, err = p.ExpectIdentifier(); err != nil { return }
+    p.verDefl = tn.Identifier()
+    _, err = p.passStatementEnd()
+    return
 }
 
 func ( p *parse ) pollImportNs() ( 
     ns *mg.Namespace, lc *parser.Location, err error ) {
     var tn *parser.TokenNode
-    if tn, err = p.PeekToken(); err == nil {
+    if tn, err = p.PeekToken(); err == nil && tn != nil {
         if _, ok := tn.Token.( *mg.Identifier ); ok {
             if ns, lc, err = p.expectNamespace(); err != nil { return }
             _, err = p.passForwardSlash()
@@ -604,12 +953,17 @@ func ( p *parse ) readTypeListEntries( names *[]*TypeListEntry ) ( err error ) {
 func ( p *parse ) completeImport( imprt *Import ) ( err error ) {
     var tn *parser.TokenNode
     if tn, err = p.PeekToken(); err != nil { return }
-    if parser.IsSpecial( tn.Token, tkAsterisk ) {
+    if tn == nil {
+        return p.ErrorTokenUnexpected( "* or type name", nil )
+    } else if parser.IsSpecial( tn.Token, tkAsterisk ) {
         imprt.IsGlob = true
         p.MustNextToken()
         p.SetSynthEnd()
-        if tn, err := p.PollSpecial( tkMinus ); err == nil {
-            if tn != nil { err = p.readTypeListEntries( &imprt.Excludes ) }
+        if tn, err = p.PollSpecial( tkMinus ); err != nil { return }
+        if tn != nil {
+            if err = p.readTypeListEntries( &imprt.Excludes ); err != nil {
+                return
+            }
         }
     } else if parser.IsSpecial( tn.Token, tkOpenBracket ) {
         if err = p.readTypeListEntries( &imprt.Includes ); err != nil { return }
@@ -618,7 +972,7 @@ func ( p *parse ) completeImport( imprt *Import ) ( err error ) {
         if e.Name, e.Loc, err = p.expectDeclaredTypeName(); err == nil { 
             imprt.Includes = append( imprt.Includes, e )
         } else { return }
-    } else { err = p.ErrorTokenUnexpected( "* or type name", tn ) }
+    } else { return p.ErrorTokenUnexpected( "* or type name", tn ) }
     _, err = p.passStatementEnd()
     return
 }
@@ -654,7 +1008,9 @@ func ( p *parse ) pollImports() ( []*Import, error ) {
         if imprt, err := p.pollImport(); err == nil {
             if imprt == nil { return res, nil }
             res = append( res, imprt )
-        } else { return nil, err }
+        } else if _, err = p.recoverStatement( err ); err != nil {
+            return nil, err
+        }
     }
     panic( libErrorf( "unreachable" ) )
 }
@@ -662,7 +1018,9 @@ func ( p *parse ) pollImports() ( []*Import, error ) {
 func ( p *parse ) expectNsUnitNs() ( decl *NamespaceDecl, err error ) {
     if err = p.expectKeyword( kwdNamespace ); err != nil { return }
     decl = new( NamespaceDecl )
-    decl.Namespace, decl.Start, err = p.expectNamespace()
+    if decl.Namespace, decl.Start, err = p.expectNamespace(); err != nil {
+        return
+    }
     if declVer := decl.Namespace.Version; ! declVer.Equals( p.verDefl ) {
         tmpl := "Source version is '%s' but namespace declared '%s'"
         err = p.ParseError( tmpl, declVer, p.verDefl )
@@ -699,6 +1057,44 @@ func ( p *parse ) addConstructorDecl(
     return
 }
 
+func ( p *parse ) addConstraintDecl(
+    elts *mg.IdentifierMap, lc *parser.Location ) ( err error ) {
+
+    cd := &ConstraintDecl{ Start: lc }
+    if _, err = p.passOpenParen(); err != nil { return }
+    if cd.Expression, err = p.expectExpression(); err != nil { return }
+    var tn *parser.TokenNode
+    if tn, err = p.PollSpecial( tkComma ); err != nil { return }
+    if tn != nil {
+        if cd.Message, err = p.expectExpression(); err != nil { return }
+    }
+    if _, err = p.passCloseParen(); err != nil { return }
+    s := append( elts.Get( IdConstraint ).( []*ConstraintDecl ), cd )
+    elts.Put( IdConstraint, s )
+    return
+}
+
+func ( p *parse ) addReservedDecl(
+    elts *mg.IdentifierMap, lc *parser.Location ) ( err error ) {
+
+    rd := &ReservedDecl{ Start: lc }
+    if _, err = p.passOpenParen(); err != nil { return }
+    for {
+        rn := new( ReservedName )
+        if rn.Name, rn.NameLoc, err = p.expectIdentifier(); err != nil { 
+            return 
+        }
+        rd.Names = append( rd.Names, rn )
+        var endLoc *parser.Location
+        endLoc, err = p.expectCommaOrEnd( tkCloseParen )
+        if err != nil { return }
+        if endLoc != nil { break }
+    }
+    s := append( elts.Get( IdReserved ).( []*ReservedDecl ), rd )
+    elts.Put( IdReserved, s )
+    return
+}
+
 func ( p *parse ) addSecurityDecl( 
     elts *mg.IdentifierMap, lc *parser.Location ) ( err error ) {
 
@@ -724,18 +1120,83 @@ func isAllowedKeyedElement( key *mg.Identifier, allow []*mg.Identifier ) bool {
     return false
 }
 
-func ( p *parse ) expectKeyedElement( 
-    elts *mg.IdentifierMap, allow []*mg.Identifier ) ( err error ) {
+// reads the '@' and the identifier following it, returning the identifier,
+// its location, and the location of the '@'
+func ( p *parse ) expectElementKey() ( key *mg.Identifier,
+                                       keyLoc *parser.Location,
+                                       lc *parser.Location,
+                                       err error ) {
 
-    var lc *parser.Location
     if lc, err = p.passSpecial( tkAsperand ); err != nil { return }
-    var key *mg.Identifier
-    if key, _, err = p.expectIdentifier(); err != nil { return }
-    if ! isAllowedKeyedElement( key, allow ) {
-        return &parser.ParseError{ unexpectedKeyedElementMsg( key ), lc }
+    key, keyLoc, err = p.expectIdentifier()
+    return
+}
+
+func ( p *parse ) completeAnnotationArgs( a *Annotation ) ( err error ) {
+    var tn *parser.TokenNode
+    tn, err = p.PollSpecial( parser.SpecialTokenOpenParen )
+    if tn == nil || err != nil { return }
+    if tn, err = p.PollSpecial( tkCloseParen ); tn != nil || err != nil {
+        return
+    }
+    for {
+        var arg Expression
+        if arg, err = p.expectExpression(); err != nil { return }
+        a.Args = append( a.Args, arg )
+        var endLoc *parser.Location
+        endLoc, err = p.expectCommaOrEnd( tkCloseParen )
+        if err != nil || endLoc != nil { return }
+    }
+    panic( libErrorf( "unreachable" ) )
+}
+
+// key and keyLoc are the already-read annotation name and its location; lc is
+// the location of the leading '@'. Any synthetic end following the annotation
+// is consumed, so that annotations may be placed on lines of their own.
+func ( p *parse ) completeAnnotation( key *mg.Identifier, 
+                                      keyLoc *parser.Location,
+                                      lc *parser.Location ) ( *Annotation, 
+                                                              error ) {
+
+    if isAllowedKeyedElement( key, reservedKeys ) {
+        return nil, &parser.ParseError{ unexpectedKeyedElementMsg( key ), lc }
     }
+    res := &Annotation{ Start: lc, Name: key, NameLoc: keyLoc }
+    if err := p.completeAnnotationArgs( res ); err != nil { return nil, err }
+    if _, err := p.PollSpecial( tkSynthEnd ); err != nil { return nil, err }
+    return res, nil
+}
+
+func ( p *parse ) expectAnnotation() ( *Annotation, error ) {
+    key, keyLoc, lc, err := p.expectElementKey()
+    if err != nil { return nil, err }
+    return p.completeAnnotation( key, keyLoc, lc )
+}
+
+// returns nil if there are no annotations at the current position
+func ( p *parse ) pollAnnotations() ( annots []*Annotation, err error ) {
+    for {
+        var tn *parser.TokenNode
+        if tn, err = p.PeekToken(); err != nil || tn == nil { return }
+        if ! tn.IsSpecial( tkAsperand ) { return }
+        var a *Annotation
+        if a, err = p.expectAnnotation(); err != nil { return }
+        annots = append( annots, a )
+    }
+    panic( libErrorf( "unreachable" ) )
+}
+
+func ( p *parse ) completeKeyedElement( elts *mg.IdentifierMap, 
+                                        key *mg.Identifier,
+                                        lc *parser.Location ) ( err error ) {
     switch {
+    // elts lacks an accumulator for keys not declared by this kind of type,
+    // such as @constraint in a schema
+    case ! elts.HasKey( key ): 
+        err = &parser.ParseError{ unexpectedKeyedElementMsg( key ), lc }
     case key.Equals( IdConstructor ): err = p.addConstructorDecl( elts, lc )
+    case key.Equals( IdConstraint ): err = p.addConstraintDecl( elts, lc )
+    case key.Equals( IdReserved ): err = p.addReservedDecl( elts, lc )
     case key.Equals( IdSecurity ): err = p.addSecurityDecl( elts, lc )
     case key.Equals( IdSchema ): err = p.addSchemaDecl( elts, lc )
     default: err = p.errorUnexpectedKeyedElement( key )
@@ -748,6 +1209,27 @@ func ( p *parse ) expectKeyedElement(
     return
 }
 
+// Parses either a keyed element in allow, which is added to elts, or the run of
+// annotations preceding a field or operation, which is returned. Returns nil
+// annotations if a keyed element was parsed.
+func ( p *parse ) expectKeyedElementOrAnnotations( 
+    elts *mg.IdentifierMap, 
+    allow []*mg.Identifier ) ( annots []*Annotation, err error ) {
+
+    var key *mg.Identifier
+    var keyLoc, lc *parser.Location
+    if key, keyLoc, lc, err = p.expectElementKey(); err != nil { return }
+    if isAllowedKeyedElement( key, allow ) {
+        return nil, p.completeKeyedElement( elts, key, lc )
+    }
+    var a *Annotation
+    if a, err = p.completeAnnotation( key, keyLoc, lc ); err != nil { return }
+    var rest []*Annotation
+    if rest, err = p.pollAnnotations(); err != nil { return }
+    annots = append( []*Annotation{ a }, rest... )
+    return
+}
+
 func isUnaryOp( t parser.SpecialToken ) bool {
     for _, op := range unaryOps { if t == op { return true } }
     return false
@@ -782,14 +1264,42 @@ func ( p *parse ) expectQualifiedAccessExpression(
     return res, nil
 }
 
+// returns pe unchanged unless it is followed by an argument list, in which case
+// pe is the name of the function called
+func ( p *parse ) pollCallExpression( 
+    pe *PrimaryExpression ) ( Expression, error ) {
+
+    tn, err := p.PollSpecial( parser.SpecialTokenOpenParen )
+    if tn == nil || err != nil { return pe, err }
+    res := &CallExpression{ 
+        Function: pe.Prim.( *mg.Identifier ), 
+        FunctionLoc: pe.PrimLoc,
+        Args: []Expression{},
+    }
+    if tn, err = p.PollSpecial( tkCloseParen ); tn != nil || err != nil {
+        return res, err
+    }
+    for {
+        arg, err := p.expectExpression()
+        if err != nil { return nil, err }
+        res.Args = append( res.Args, arg )
+        endLoc, err := p.expectCommaOrEnd( tkCloseParen )
+        if err != nil { return nil, err }
+        if endLoc != nil { return res, nil }
+    }
+    panic( libErrorf( "unreachable" ) )
+}
+
 func ( p *parse ) expectIdentifiedExpression() ( Expression, error ) {
     tn, err := p.PeekToken()
     if err != nil { return nil, err }
     switch tn.Token.( type ) {
     case *mg.Identifier:
         pe := new( PrimaryExpression )
-        pe.Prim, pe.PrimLoc, err = p.expectIdentifier()
-        return pe, nil
+        if pe.Prim, pe.PrimLoc, err = p.expectIdentifier(); err != nil {
+            return nil, err
+        }
+        return p.pollCallExpression( pe )
     case *mg.DeclaredTypeName: 
         if typ, err := p.expectTypeReference(); err == nil {
             return p.expectQualifiedAccessExpression( typ )
@@ -826,6 +1336,7 @@ func ( p *parse ) expectListExpression() ( e *ListExpression, err error ) {
     if e.Start, err = p.passOpenBracket(); err != nil { return }
     for {
         var tn *parser.TokenNode 
+        if err = p.CheckUnexpectedEnd(); err != nil { return }
         if tn, err = p.PeekToken(); err != nil { return }
         if parser.IsSpecial( tn.Token, tkCloseBracket ) { 
             p.MustNextToken() // consume ']'
@@ -834,14 +1345,30 @@ func ( p *parse ) expectListExpression() ( e *ListExpression, err error ) {
         var elt Expression
         if elt, err = p.expectExpression(); err != nil { return }
         e.Elements = append( e.Elements, elt )
-        var sawEnd bool
-        sawEnd, err = p.expectCommaOrEnd( tkCloseBracket )
-        if err != nil || sawEnd { return }
+        var endLoc *parser.Location
+        endLoc, err = p.expectCommaOrEnd( tkCloseBracket )
+        if err != nil || endLoc != nil { return }
     }
     panic( libErrorf( "unreachable" ) )
 }
 
 func ( p *parse ) expectExpression() ( e Expression, err error ) {
+    if e, err = p.expectArithmeticExpression(); err != nil { return }
+    var tn *parser.TokenNode
+    if tn, err = p.PollSpecial( comparisonOps... ); err != nil { return }
+    if tn == nil { return }
+    var right Expression
+    if right, err = p.expectArithmeticExpression(); err != nil { return }
+    e = &BinaryExpression{ 
+        Left: e, 
+        Op: tn.SpecialToken(), 
+        OpLoc: tn.Loc, 
+        Right: right,
+    }
+    return
+}
+
+func ( p *parse ) expectArithmeticExpression() ( e Expression, err error ) {
     if e, err = p.expectUnaryExpression(); err != nil { return }
     for loop := true; loop; {
         var tn *parser.TokenNode
@@ -860,18 +1387,25 @@ func ( p *parse ) expectExpression() ( e Expression, err error ) {
     return
 }
 
-func ( p *parse ) expectFieldEnd( ends *fieldEnds ) ( sawEnd bool, err error ) {
+// endLoc is the location of ends.enclEnd if it was seen, nil otherwise
+func ( p *parse ) expectFieldEnd( 
+    ends *fieldEnds ) ( endLoc *parser.Location, err error ) {
+
     var tn *parser.TokenNode
     if tn, err = p.ExpectSpecial( ends.seps... ); err != nil { return }
     if tn == nil {
         err = p.ErrorTokenUnexpected( "field end", nil )
-    } else { sawEnd = tn.SpecialToken() == ends.enclEnd }
+    } else if tn.SpecialToken() == ends.enclEnd { endLoc = tn.Loc }
     return
 }
 
 func ( p *parse ) expectFieldDecl(
-    ends *fieldEnds ) ( fd *FieldDecl, sawEnd bool, err error ) {
-    fd = new( FieldDecl )
+    ends *fieldEnds, 
+    annots []*Annotation ) ( fd *FieldDecl, 
+                             endLoc *parser.Location, 
+                             err error ) {
+
+    fd = &FieldDecl{ Annotations: annots }
     if fd.Name, fd.NameLoc, err = p.expectIdentifier(); err != nil { return }
     if fd.Type, err = p.expectTypeReference(); err != nil { return }
     var kwd parser.Keyword
@@ -879,36 +1413,60 @@ func ( p *parse ) expectFieldDecl(
     if kwd != "" {
         if fd.Default, err = p.expectExpression(); err != nil { return }
     }
-    sawEnd, err = p.expectFieldEnd( ends )
+    endLoc, err = p.expectFieldEnd( ends )
     return
 }
 
 func ( p *parse ) expectStructBody( sd structureDecl ) error {
     flds := make( []*FieldDecl, 0, 4 )
     ke := sd.createKeyedEltsAcc()
-    for loop := true; loop; {
+    closed := false
+    addField := func( annots []*Annotation, doc string ) error {
+        fld, endLoc, err := p.expectFieldDecl( fldEndsStruct, annots )
+        if err != nil { return err }
+        fld.Doc = doc
+        flds = append( flds, fld )
+        if closed = endLoc != nil; closed { sd.setEnd( endLoc ) }
+        return nil
+    }
+    // parses the next element of the body or its closing brace
+    next := func() error {
+        doc, err := p.pollDoc()
+        if err != nil { return err }
+        if err = p.CheckUnexpectedEnd(); err != nil { return err }
         tn, err := p.PeekToken()
         if err != nil { return err }
         switch {
         case parser.IsSpecial( tn.Token, parser.SpecialTokenAsperand ):
-            if err = p.expectKeyedElement( ke, structureElementKeys ); 
-               err != nil {
-                return err
-            }
+            var annots []*Annotation
+            annots, err = 
+                p.expectKeyedElementOrAnnotations( ke, structureElementKeys )
+            if err != nil || annots == nil { return err }
+            return addField( annots, doc )
         case parser.IsSpecial( tn.Token, parser.SpecialTokenCloseBrace ):
-            loop, _ = false, p.MustNextToken()
-        default:
-            var fld *FieldDecl
-            var sawEnd bool
-            fld, sawEnd, err = p.expectFieldDecl( fldEndsStruct )
+            closed, _ = true, p.MustNextToken()
+            sd.setEnd( tn.Loc )
+            return nil
+        }
+        // a declaration before the closing brace is left for the caller
+        if isResyncKeyword( tn ) { return p.ErrorTokenUnexpected( "}", tn ) }
+        return addField( nil, doc )
+    }
+    for ! closed {
+        if err := next(); err != nil {
+            cont, err := p.recoverStatement( err )
             if err != nil { return err }
-            flds = append( flds, fld )
-            loop = ! sawEnd
+            if ! cont { break }
         }
     }
     sd.setFields( flds )
     sd.initKeyedElts( ke )
-    if _, err := p.passStatementEnd(); err != nil { return err }
+    // if the body was cut off by the end of input or by another declaration
+    // then the error has already been recorded
+    if ! closed { return nil }
+    if _, err := p.passStatementEnd(); err != nil {
+        return p.recoverTypeDecl( err )
+    }
     return nil
 }
 
@@ -934,17 +1492,48 @@ func ( p *parse ) expectSchemaDecl(
     return sd, p.expectStructureDecl( sd )
 }
 
+func ( p *parse ) expectEnumValueAliases( ev *EnumValue ) error {
+    if _, err := p.passOpenParen(); err != nil { return err }
+    for {
+        al := new( EnumValueAlias )
+        var err error
+        if al.Alias, al.AliasLoc, err = p.expectIdentifier(); err != nil { 
+            return err 
+        }
+        ev.Aliases = append( ev.Aliases, al )
+        endLoc, err := p.expectCommaOrEnd( tkCloseParen )
+        if err != nil || endLoc != nil { return err }
+    }
+    panic( libErrorf( "unreachable" ) )
+}
+
+// parses the optional ': <ordinal>' and 'alias( <id>, ... )' following an
+// enum value
+func ( p *parse ) expectEnumValueTail( ev *EnumValue ) error {
+    tn, err := p.PollSpecial( tkColon )
+    if err != nil { return err }
+    if tn != nil {
+        if ev.Ordinal, err = p.expectUnaryExpression(); err != nil { 
+            return err 
+        }
+    }
+    kwd, err := p.pollKeyword( kwdAlias )
+    if err != nil || kwd == "" { return err }
+    return p.expectEnumValueAliases( ev )
+}
+
 func ( p *parse ) completeEnumDecl( ed *EnumDecl ) ( err error ) {
     for {
-        var val *mg.Identifier
-        var lc *parser.Location
-        if val, lc, err = p.expectIdentifier(); err == nil {
-            ed.Values = append( ed.Values, &EnumValue{ val, lc } )
+        ev := new( EnumValue )
+        if ev.Doc, err = p.pollDoc(); err != nil { return }
+        if ev.Annotations, err = p.pollAnnotations(); err != nil { return }
+        if ev.Value, ev.ValueLoc, err = p.expectIdentifier(); err == nil {
+            ed.Values = append( ed.Values, ev )
         } else { return }
-        var sawEnd bool
-        sawEnd, err = p.expectCommaOrEnd( parser.SpecialTokenCloseBrace )
+        if err = p.expectEnumValueTail( ev ); err != nil { return }
+        ed.End, err = p.expectCommaOrEnd( parser.SpecialTokenCloseBrace )
         if err != nil { return }
-        if sawEnd { 
+        if ed.End != nil { 
             _, err = p.passStatementEnd()
             return 
         }
@@ -970,7 +1559,7 @@ func ( p *parse ) expectAliasDecl(
     if ad.Name, ad.NameLoc, err = p.expectDeclaredTypeName(); err != nil { 
         return
     }
-    ad.Target, err = p.expectTypeReference()
+    if ad.Target, err = p.expectTypeReference(); err != nil { return }
     _, err = p.passStatementEnd()
     return
 }
@@ -984,14 +1573,19 @@ func ( p *parse ) collectCallFields( cs *CallSignature ) ( err error ) {
     for {
         var tn *parser.TokenNode
         if tn, err = p.PollSpecial( tkCloseParen ); tn != nil || err != nil { 
+            if tn != nil { cs.FieldsEnd = tn.Loc }
             return p.completeCallFields()
         }
+        var doc string
+        if doc, err = p.pollDoc(); err != nil { return }
+        var annots []*Annotation
+        if annots, err = p.pollAnnotations(); err != nil { return }
         var fld *FieldDecl
-        var sawEnd bool
-        fld, sawEnd, err = p.expectFieldDecl( fldEndsCall )
+        fld, cs.FieldsEnd, err = p.expectFieldDecl( fldEndsCall, annots )
         if err != nil { return }
+        fld.Doc = doc
         cs.Fields = append( cs.Fields, fld )
-        if sawEnd { return p.completeCallFields() }
+        if cs.FieldsEnd != nil { return p.completeCallFields() }
     }
     panic( libErrorf( "unreachable" ) )
 }
@@ -1026,7 +1620,7 @@ func ( p *parse ) expectCallSignature() ( cs *CallSignature, err error ) {
     if err = p.collectCallFields( cs ); err != nil { return }
     if cs.Return, err = p.expectTypeReference(); err != nil { return }
     if _, err = p.PollSpecial( tkComma ); err != nil { return }
-    err = p.collectCallThrownTypes( cs )
+    if err = p.collectCallThrownTypes( cs ); err != nil { return }
     _, err = p.passStatementEnd()
     return
 }
@@ -1041,10 +1635,12 @@ func ( p *parse ) expectPrototypeDecl(
     return
 }
 
-func ( p *parse ) collectCallSignature( sd *ServiceDecl ) ( err error ) {
-    od := &OperationDecl{}
+func ( p *parse ) collectCallSignature( 
+    sd *ServiceDecl, annots []*Annotation, doc string ) ( err error ) {
+
+    od := &OperationDecl{ Annotations: annots, Doc: doc }
     if od.Name, od.NameLoc, err = p.expectIdentifier(); err != nil { return }
-    od.Call, err = p.expectCallSignature()
+    if od.Call, err = p.expectCallSignature(); err != nil { return }
     sd.Operations = append( sd.Operations, od )
     return
 }
@@ -1057,34 +1653,83 @@ func ( p *parse ) expectServiceDecl(
     ke := sd.createKeyedEltsAcc()
     if sd.Info, err = p.expectTypeDeclInfo(); err != nil { return }
     if _, err = p.passOpenBrace(); err != nil { return }
-    for err == nil {
-        var tn *parser.TokenNode
-        if tn, err = p.PeekToken(); err != nil { return }
-        if parser.IsSpecial( tn.Token, tkCloseBrace ) {
+    closed := false
+    // parses the next element of the body or its closing brace
+    next := func() error {
+        doc, err := p.pollDoc()
+        if err != nil { return err }
+        if err = p.CheckUnexpectedEnd(); err != nil { return err }
+        tn, err := p.PeekToken()
+        if err != nil { return err }
+        switch {
+        case parser.IsSpecial( tn.Token, tkCloseBrace ):
             p.MustNextToken()
-            if _, err = p.passStatementEnd(); err != nil { return }
-            sd.initKeyedElts( ke )
-            return
-        } else if parser.IsSpecial( tn.Token, tkAsperand ) {
-            err = p.expectKeyedElement( ke, serviceElementKeys )
-        } else if tn.IsKeyword( parser.KeywordOp ) {
+            sd.End, closed = tn.Loc, true
+            return nil
+        case parser.IsSpecial( tn.Token, tkAsperand ):
+            var annots []*Annotation
+            annots, err = 
+                p.expectKeyedElementOrAnnotations( ke, serviceElementKeys )
+            if err != nil || annots == nil { return err }
+            if err = p.expectKeyword( parser.KeywordOp ); err != nil {
+                return err
+            }
+            return p.collectCallSignature( sd, annots, doc )
+        case tn.IsKeyword( parser.KeywordOp ):
             p.MustNextToken()
-            err = p.collectCallSignature( sd )
-        } else { err = p.ErrorTokenUnexpected( "operation or keyed def", tn ) }
+            return p.collectCallSignature( sd, nil, doc )
+        }
+        return p.ErrorTokenUnexpected( "operation or keyed def", tn )
+    }
+    for ! closed {
+        if err = next(); err != nil {
+            var cont bool
+            if cont, err = p.recoverStatement( err ); err != nil { return }
+            if ! cont { break }
+        }
+    }
+    sd.initKeyedElts( ke )
+    if ! closed { return }
+    if _, err = p.passStatementEnd(); err != nil {
+        err = p.recoverTypeDecl( err )
     }
     return
 }
 
-func ( p *parse ) passUnionTypeElement() ( bool, error ) {
+// returns the location of the closing brace if it was seen, nil otherwise
+func ( p *parse ) passUnionTypeElement() ( *parser.Location, error ) {
     tn, err := p.PollSpecial( tkComma )
-    if err != nil { return false, err }
+    if err != nil { return nil, err }
     tn, err = p.PollSpecial( tkCloseBrace )
-    if err != nil { return false, err }
-    sawEnd := tn != nil
-    if sawEnd {
-        if _, err = p.passStatementEnd(); err != nil { return false, err }
+    if err != nil || tn == nil { return nil, err }
+    if _, err = p.passStatementEnd(); err != nil { return nil, err }
+    return tn.Loc, nil
+}
+
+// parses the optional 'tag( <id> )' following a union type, returning nil if
+// there is none; 'tag' is only special in this position and remains usable as
+// an identifier elsewhere
+func ( p *parse ) pollUnionTag() ( *UnionTag, error ) {
+    tn, err := p.PeekToken()
+    if tn == nil || err != nil { return nil, err }
+    if id, ok := tn.Token.( *mg.Identifier ); ! ( ok && id.Equals( idTag ) ) {
+        return nil, nil
+    }
+    p.MustNextToken()
+    if _, err = p.passOpenParen(); err != nil { return nil, err }
+    res := new( UnionTag )
+    if res.Tag, res.TagLoc, err = p.expectIdentifier(); err != nil { 
+        return nil, err 
+    }
+    if _, err = p.passCloseParen(); err != nil { return nil, err }
+    return res, nil
+}
+
+func ( ud *UnionDecl ) addTag( tag *UnionTag ) {
+    if tag != nil && ud.Tags == nil {
+        ud.Tags = make( []*UnionTag, len( ud.Types ) - 1, len( ud.Types ) )
     }
-    return sawEnd, nil
+    if ud.Tags != nil { ud.Tags = append( ud.Tags, tag ) }
 }
 
 func ( p *parse ) expectUnionDecl( 
@@ -1094,12 +1739,15 @@ func ( p *parse ) expectUnionDecl(
     ud.Types = make( []*parser.CompletableTypeReference, 0, 4 )
     if ud.Info, err = p.expectTypeDeclInfo(); err != nil { return }
     if _, err = p.passOpenBrace(); err != nil { return }
-    for sawClose := false; ! sawClose; {
+    for ud.End == nil {
         var typ *parser.CompletableTypeReference
         if typ, err = p.expectTypeReference(); err != nil { 
             return 
         } else { ud.Types = append( ud.Types, typ ) }
-        if sawClose, err = p.passUnionTypeElement(); err != nil { return }
+        var tag *UnionTag
+        if tag, err = p.pollUnionTag(); err != nil { return }
+        ud.addTag( tag )
+        if ud.End, err = p.passUnionTypeElement(); err != nil { return }
     }
     return
 }
@@ -1119,17 +1767,25 @@ func ( p *parse ) expectTypeDecl(
 }
 
 func ( p *parse ) pollTypeDecl() ( td TypeDecl, err error ) {
+    var doc string
+    if doc, err = p.pollDoc(); err != nil { return }
+    var annots []*Annotation
+    if annots, err = p.pollAnnotations(); err != nil { return }
     var kwd parser.Keyword
     var lc *parser.Location
     if kwd, lc, err = p.pollKeywordLoc( typeDeclKwds... ); err == nil {
         if kwd == "" {
-            if p.HasTokens() {
+            if p.HasTokens() || annots != nil {
                 strs := make( []string, len( typeDeclKwds ) )
                 for i, k := range( typeDeclKwds ) { strs[ i ] = string( k ) }
                 kwdExpctStr := strings.Join( strs, "|" )
                 err = p.ErrorTokenUnexpected( kwdExpctStr, nil )
             }
-        } else { td, err = p.expectTypeDecl( kwd, lc ) }
+        } else if td, err = p.expectTypeDecl( kwd, lc ); err == nil {
+            dtd := td.( decoratedTypeDecl )
+            if annots != nil { dtd.setAnnotations( annots ) }
+            if doc != "" { dtd.setDoc( doc ) }
+        }
     }
     return 
 }
@@ -1140,28 +1796,60 @@ func ( p *parse ) pollTypeDecls() ( decls []TypeDecl, err error ) {
         var decl TypeDecl
         if decl, err = p.pollTypeDecl(); err == nil {
             if decl == nil { 
-                if p.HasTokens() {
-                    err = p.ErrorTokenUnexpected( "end of source", nil )
-                }
-                return 
-            }
-            decls = append( decls, decl )
-        } else { return }
+                if ! p.HasTokens() { return }
+                err = p.ErrorTokenUnexpected( "end of source", nil )
+            } else { decls = append( decls, decl ) }
+        }
+        if err != nil {
+            if err = p.recoverTypeDecl( err ); err != nil { return }
+        }
     }
     panic( libErrorf( "unreachable" ) )
 }
 
 func ( p *parse ) expectNsUnit( srcNm string ) ( u *NsUnit, err error ) {
-    if err = p.setNsVersion(); err != nil { return }
     u = &NsUnit{ SourceName: srcNm }
+    if err = p.setNsVersion( u ); err != nil { return }
     if u.Imports, err = p.pollImports(); err != nil { return }
-    if u.NsDecl, err = p.expectNsUnitNs(); err != nil { return }
+    if u.NsDecl, err = p.expectNsUnitNs(); err != nil {
+        if _, err = p.recoverStatement( err ); err != nil { return }
+        u.NsDecl = nil
+    }
     if u.TypeDecls, err = p.pollTypeDecls(); err != nil { return }
+    u.Comments = p.docs.all
     return
 }
 
+func newParse( srcNm string, r io.Reader ) *parse {
+    p := &parse{ docs: newDocComments() }
+    opts := &parser.LexerOptions{ 
+        Reader: r, 
+        SourceName: srcNm, 
+        Strip: true,
+        CommentHandler: p.docs.add,
+    }
+    p.Builder = parser.NewBuilder( parser.NewLexer( opts ) )
+    return p
+}
+
 func ParseSource( srcNm string, r io.Reader ) ( *NsUnit, error ) {
-    opts := &parser.LexerOptions{ Reader: r, SourceName: srcNm, Strip: true }
-    lx := parser.NewLexer( opts )
-    return (&parse{ Builder: parser.NewBuilder( lx ) }).expectNsUnit( srcNm )
+    return newParse( srcNm, r ).expectNsUnit( srcNm )
+}
+
+// Parses a source as ParseSource does, except that after a syntax error parsing
+// resumes at the next statement or type declaration, so that all of the syntax
+// errors in the source are found. The unit returned holds what could be parsed,
+// and is nil only if the version or namespace of the source could not be. errs
+// holds the syntax errors in source order, and err is any other error, such as
+// one from reading r.
+func ParseSourceWithRecovery( 
+    srcNm string, 
+    r io.Reader ) ( u *NsUnit, errs []*parser.ParseError, err error ) {
+
+    p := newParse( srcNm, r )
+    p.recovering = true
+    u, err = p.expectNsUnit( srcNm )
+    if err = p.recordError( err ); err != nil { return nil, nil, err }
+    if u.NsDecl == nil { u = nil }
+    return u, p.errs, nil
 }
@@ -6,6 +6,7 @@ import (
     "testing"
     "bytes"
     "sort"
+    "strings"
     "bitgirder/assert"
     "bitgirder/objpath"
     mg "mingle"
@@ -134,11 +135,32 @@ func ( t *treeCheck ) equalExpression( e1, e2 Expression ) {
     }
 }
 
+func ( t *treeCheck ) equalAnnotation( a1, a2 *Annotation ) {
+    t.descend( "Start" ).Equal( a1.Start, a2.Start )
+    t.descend( "Name" ).Equal( a1.Name, a2.Name )
+    t.descend( "NameLoc" ).Equal( a1.NameLoc, a2.NameLoc )
+    lt := t.descend( "Args" ).equalLen( len( a1.Args ), len( a2.Args ) )
+    for i, arg := range a1.Args {
+        lt.equalExpression( arg, a2.Args[ i ] )
+        lt = lt.next()
+    }
+}
+
+func ( t *treeCheck ) equalAnnotations( arr1, arr2 []*Annotation ) {
+    lt := t.equalLen( len( arr1 ), len( arr2 ) )
+    for i, a1 := range arr1 {
+        lt.equalAnnotation( a1, arr2[ i ] )
+        lt = lt.next()
+    }
+}
+
 func ( t *treeCheck ) equalField( f1, f2 *FieldDecl ) {
     t.descend( "Name" ).Equal( f1.Name, f2.Name )
     t.descend( "NameLoc" ).Equal( f1.NameLoc, f2.NameLoc )
     t.descend( "Type" ).equalType( f1.Type, f2.Type )
     t.descend( "Default" ).equalExpression( f1.Default, f2.Default )
+    t.descend( "Annotations" ).equalAnnotations( f1.Annotations, f2.Annotations )
+    t.descend( "Doc" ).Equal( f1.Doc, f2.Doc )
 }
 
 func ( t *treeCheck ) equalFields( arr1, arr2 []*FieldDecl ) {
@@ -263,6 +285,9 @@ func ( t *treeCheck ) equalOperations( arr1, arr2 []*OperationDecl ) {
         lt.descend( "Name" ).Equal( od1.Name, od2.Name )
         lt.descend( "NameLoc" ).Equal( od1.NameLoc, od2.NameLoc )
         lt.descend( "Call" ).equalSig( od1.Call, od2.Call )
+        lt.descend( "Annotations" ).
+            equalAnnotations( od1.Annotations, od2.Annotations )
+        lt.descend( "Doc" ).Equal( od1.Doc, od2.Doc )
         lt = lt.next()
     }
 }
@@ -283,6 +308,7 @@ func ( t *treeCheck ) equalUnionDecl( ud1, ud2 *UnionDecl ) {
         l.equalType( typ1, ud2.Types[ idx ] )
         l = l.next()
     }
+    t.descend( "Tags" ).Equal( ud1.Tags, ud2.Tags )
 }
 
 func ( t *treeCheck ) equalTypeDecl( td1, td2 TypeDecl ) {
@@ -296,6 +322,9 @@ func ( t *treeCheck ) equalTypeDecl( td1, td2 TypeDecl ) {
     case *UnionDecl: t.equalUnionDecl( v, td2.( *UnionDecl ) )
     default: t.Fatalf( "Unhandled type decl type: %T", td1 )
     }
+    t.descend( "Annotations" ).
+        equalAnnotations( td1.GetAnnotations(), td2.GetAnnotations() )
+    t.descend( "Doc" ).Equal( td1.GetDoc(), td2.GetDoc() )
 }    
 
 func ( t *treeCheck ) equalTypeDecls( arr1, arr2 []TypeDecl ) {
@@ -337,6 +366,296 @@ func TestParseSource( t *testing.T ) {
     }
 }
 
+func TestDocComments( t *testing.T ) {
+    src := `# file comment, not a doc
+@version v1
+namespace ns1
+
+# not a doc since followed by a blank line
+
+# Struct doc,
+# on two lines
+@doc( 1 )
+struct Struct1 {
+    # f1 doc
+    f1 String # trailing comment, not a doc
+    f2 String
+    # f3 doc
+    #
+    # more f3
+    @x f3 String
+}
+
+enum Enum1 {
+    # red doc
+    red,
+    green }
+
+# service doc
+service Service1 {
+    # op doc
+    op op1(
+        # p1 doc
+        p1 String, p2 String ): String
+}
+`
+    u, err := parseSource( "<>", src )
+    if err != nil { t.Fatal( err ) }
+    a := assert.NewPathAsserter( t )
+    sd := u.TypeDecls[ 0 ].( *StructDecl )
+    a.Descend( "struct" ).Equal( "Struct doc,\non two lines", sd.Doc )
+    for i, doc := range []string{ "f1 doc", "", "f3 doc\n\nmore f3" } {
+        a.Descend( sd.Fields[ i ].Name ).Equal( doc, sd.Fields[ i ].Doc )
+    }
+    ed := u.TypeDecls[ 1 ].( *EnumDecl )
+    a.Descend( "enum" ).Equal( "", ed.Doc )
+    a.Descend( "red" ).Equal( "red doc", ed.Values[ 0 ].Doc )
+    a.Descend( "green" ).Equal( "", ed.Values[ 1 ].Doc )
+    svc := u.TypeDecls[ 2 ].( *ServiceDecl )
+    a.Descend( "service" ).Equal( "service doc", svc.Doc )
+    op := svc.Operations[ 0 ]
+    a.Descend( "op" ).Equal( "op doc", op.Doc )
+    a.Descend( "p1" ).Equal( "p1 doc", op.Call.Fields[ 0 ].Doc )
+    a.Descend( "p2" ).Equal( "", op.Call.Fields[ 1 ].Doc )
+}
+
+func TestEnumOrdinalsAndAliases( t *testing.T ) {
+    src := `@version v1
+namespace ns1
+enum E1 { 
+    red: 1, 
+    green: -2 alias( verde, vert ), 
+    blue alias( azul ),
+}
+`
+    u, err := parseSource( "<>", src )
+    if err != nil { t.Fatal( err ) }
+    a := assert.NewPathAsserter( t )
+    ed := u.TypeDecls[ 0 ].( *EnumDecl )
+    a.Descend( "len" ).Equal( 3, len( ed.Values ) )
+    mkLoc := func( line, col int ) *parser.Location {
+        return &parser.Location{ Line: line, Col: col, Source: "<>" }
+    }
+    red, green, blue := ed.Values[ 0 ], ed.Values[ 1 ], ed.Values[ 2 ]
+    a.Descend( "red" ).Equal( 
+        &PrimaryExpression{ 
+            Prim: &parser.NumericToken{ Int: "1" }, 
+            PrimLoc: mkLoc( 4, 10 ),
+        },
+        red.Ordinal,
+    )
+    a.Descend( "red" ).Equal( 0, len( red.Aliases ) )
+    a.Descend( "green" ).Equal(
+        &UnaryExpression{
+            Op: parser.SpecialTokenMinus,
+            OpLoc: mkLoc( 5, 12 ),
+            Exp: &PrimaryExpression{
+                Prim: &parser.NumericToken{ Int: "2" },
+                PrimLoc: mkLoc( 5, 13 ),
+            },
+        },
+        green.Ordinal,
+    )
+    a.Descend( "green" ).Equal(
+        []*EnumValueAlias{
+            { Alias: mgId( "verde" ), AliasLoc: mkLoc( 5, 22 ) },
+            { Alias: mgId( "vert" ), AliasLoc: mkLoc( 5, 29 ) },
+        },
+        green.Aliases,
+    )
+    a.Descend( "blue" ).Equal( nil, blue.Ordinal )
+    a.Descend( "blue" ).Equal(
+        []*EnumValueAlias{ 
+            { Alias: mgId( "azul" ), AliasLoc: mkLoc( 6, 17 ) },
+        },
+        blue.Aliases,
+    )
+}
+
+func TestStructConstraints( t *testing.T ) {
+    src := `@version v1
+namespace ns1
+struct S1 {
+    f1 Int32
+    @constraint( f1 <= f2 + 1 )
+    @constraint( exactlyOne( f1, f2 ), "msg" )
+    @constraint( noArgs() )
+}
+`
+    u, err := parseSource( "<>", src )
+    if err != nil { t.Fatal( err ) }
+    a := assert.NewPathAsserter( t )
+    sd := u.TypeDecls[ 0 ].( *StructDecl )
+    a.Descend( "len" ).Equal( 3, len( sd.Constraints ) )
+    mkLoc := func( line, col int ) *parser.Location {
+        return &parser.Location{ Line: line, Col: col, Source: "<>" }
+    }
+    idExp := func( s string, line, col int ) *PrimaryExpression {
+        return &PrimaryExpression{ 
+            Prim: mgId( s ), 
+            PrimLoc: mkLoc( line, col ),
+        }
+    }
+    a.Descend( "c1" ).Equal(
+        &ConstraintDecl{
+            Start: mkLoc( 5, 5 ),
+            Expression: &BinaryExpression{
+                Left: idExp( "f1", 5, 18 ),
+                Op: parser.SpecialTokenLessThanOrEqual,
+                OpLoc: mkLoc( 5, 21 ),
+                Right: &BinaryExpression{
+                    Left: idExp( "f2", 5, 24 ),
+                    Op: parser.SpecialTokenPlus,
+                    OpLoc: mkLoc( 5, 27 ),
+                    Right: &PrimaryExpression{
+                        Prim: &parser.NumericToken{ Int: "1" },
+                        PrimLoc: mkLoc( 5, 29 ),
+                    },
+                },
+            },
+        },
+        sd.Constraints[ 0 ],
+    )
+    a.Descend( "c2" ).Equal(
+        &ConstraintDecl{
+            Start: mkLoc( 6, 5 ),
+            Expression: &CallExpression{
+                Function: mgId( "exactlyOne" ),
+                FunctionLoc: mkLoc( 6, 18 ),
+                Args: []Expression{ 
+                    idExp( "f1", 6, 30 ), 
+                    idExp( "f2", 6, 34 ),
+                },
+            },
+            Message: &PrimaryExpression{
+                Prim: parser.StringToken( "msg" ),
+                PrimLoc: mkLoc( 6, 40 ),
+            },
+        },
+        sd.Constraints[ 1 ],
+    )
+    a.Descend( "c3" ).Equal(
+        &CallExpression{
+            Function: mgId( "noArgs" ),
+            FunctionLoc: mkLoc( 7, 18 ),
+            Args: []Expression{},
+        },
+        sd.Constraints[ 2 ].Expression,
+    )
+}
+
+func TestReservedNames( t *testing.T ) {
+    src := `@version v1
+namespace ns1
+struct S1 {
+    f1 Int32
+    @reserved( f2, f3 )
+    @reserved( f4 )
+}
+`
+    u, err := parseSource( "<>", src )
+    if err != nil { t.Fatal( err ) }
+    mkLoc := func( line, col int ) *parser.Location {
+        return &parser.Location{ Line: line, Col: col, Source: "<>" }
+    }
+    assert.Equal(
+        []*ReservedDecl{
+            {
+                Start: mkLoc( 5, 5 ),
+                Names: []*ReservedName{
+                    { Name: mgId( "f2" ), NameLoc: mkLoc( 5, 16 ) },
+                    { Name: mgId( "f3" ), NameLoc: mkLoc( 5, 20 ) },
+                },
+            },
+            {
+                Start: mkLoc( 6, 5 ),
+                Names: []*ReservedName{
+                    { Name: mgId( "f4" ), NameLoc: mkLoc( 6, 16 ) },
+                },
+            },
+        },
+        u.TypeDecls[ 0 ].( *StructDecl ).Reserved,
+    )
+}
+
+func TestUnionTags( t *testing.T ) {
+    src := `@version v1
+namespace ns1
+union U1 {
+    String tag( name ),
+    Int32,
+    String tag( label ),
+}
+`
+    u, err := parseSource( "<>", src )
+    if err != nil { t.Fatal( err ) }
+    a := assert.NewPathAsserter( t )
+    ud := u.TypeDecls[ 0 ].( *UnionDecl )
+    a.Descend( "len" ).Equal( 3, len( ud.Types ) )
+    mkLoc := func( line, col int ) *parser.Location {
+        return &parser.Location{ Line: line, Col: col, Source: "<>" }
+    }
+    a.Equal(
+        []*UnionTag{
+            { Tag: mgId( "name" ), TagLoc: mkLoc( 4, 17 ) },
+            nil,
+            { Tag: mgId( "label" ), TagLoc: mkLoc( 6, 17 ) },
+        },
+        ud.Tags,
+    )
+}
+
+// Checks that all comments are kept in source order, and the locations of the
+// closing delimiters of blocks and call fields
+func TestCommentsAndEndLocations( t *testing.T ) {
+    src := `# c1
+@version v1
+namespace ns1
+struct S1 {
+    f1 String # c2
+    # c3
+}
+enum E1 { red } # c4
+service Svc1 {
+    op op1( p1 String,
+    ): String
+}
+`
+    u, err := parseSource( "<>", src )
+    if err != nil { t.Fatal( err ) }
+    a := assert.NewPathAsserter( t )
+    chkLoc := func(
+        a *assert.PathAsserter, line, col int, l *parser.Location ) {
+
+        a.Descend( "line" ).Equal( line, l.Line )
+        a.Descend( "col" ).Equal( col, l.Col )
+    }
+    chkLoc( a.Descend( "version" ), 2, 1, u.VersionLoc )
+    mkLoc := func( line, col int ) *parser.Location {
+        return &parser.Location{ Line: line, Col: col }
+    }
+    expct := []*Comment{
+        { Text: " c1", Loc: mkLoc( 1, 1 ) },
+        { Text: " c2", Loc: mkLoc( 5, 15 ), Trailing: true },
+        { Text: " c3", Loc: mkLoc( 6, 5 ) },
+        { Text: " c4", Loc: mkLoc( 8, 17 ), Trailing: true },
+    }
+    a.Descend( "comments" ).Equal( len( expct ), len( u.Comments ) )
+    la := a.Descend( "comments" ).StartList()
+    for i, c := range expct {
+        act := u.Comments[ i ]
+        la.Descend( "text" ).Equal( c.Text, act.Text )
+        la.Descend( "trailing" ).Equal( c.Trailing, act.Trailing )
+        chkLoc( la, c.Loc.Line, c.Loc.Col, act.Loc )
+        la = la.Next()
+    }
+    chkLoc( a.Descend( "S1" ), 7, 1, u.TypeDecls[ 0 ].( *StructDecl ).End )
+    chkLoc( a.Descend( "E1" ), 8, 15, u.TypeDecls[ 1 ].( *EnumDecl ).End )
+    sd := u.TypeDecls[ 2 ].( *ServiceDecl )
+    chkLoc( a.Descend( "Svc1" ), 12, 1, sd.End )
+    chkLoc( a.Descend( "op1" ), 11, 5, sd.Operations[ 0 ].Call.FieldsEnd )
+}
+
 func TestParseErrors( t *testing.T ) {
     a := assert.NewListPathAsserter( t )
     for _, tt := range []struct { errMsg string; line, col int; src string } {
@@ -361,9 +680,43 @@ import ns1@v1/[ S1 ] - [ S2 ]`,
         { "Unexpected keyed definition @constructor", 1, 42,
             "@version v1; namespace ns1; service S1 { @constructor C1 }",
         },
+        { "Unexpected keyed definition @constraint", 1, 41,
+            "@version v1; namespace ns1; schema S1 { @constraint( a < b ) }",
+        },
+        { "Unexpected keyed definition @reserved", 1, 41,
+            "@version v1; namespace ns1; schema S1 { @reserved( f1 ) }",
+        },
+        { "Illegal start of identifier part: \")\" (U+0029)", 1, 51,
+            "@version v1; namespace ns1; struct S1 { @reserved() }",
+        },
+        { "Expected ) but found: <", 1, 60,
+            "@version v1; namespace ns1; " +
+                "struct S1 { @constraint( a < b < c ) }",
+        },
         { "Expected type reference but found: }", 1, 39, 
             "@version v1; namespace ns1; union U1 {}",
         },
+        { "Illegal start of identifier part: \")\" (U+0029)", 1, 47, 
+            "@version v1; namespace ns1; union U1 { T1 tag() }",
+        },
+        { "Unexpected keyed definition @schema", 1, 29,
+            "@version v1; namespace ns1; @schema S1 struct S2 {}",
+        },
+        { "Expected struct|enum|prototype|service|alias|schema|union but found: END", 1, 38,
+            "@version v1; namespace ns1; @doc( 1 )",
+        },
+        { `Expected keyword "op" but found: f1`, 1, 52,
+            "@version v1; namespace ns1; service S1 { @doc( 1 ) f1 String }",
+        },
+        { "Expected , or ) but found: f1", 1, 49,
+            "@version v1; namespace ns1; struct S1 { @doc( 1 f1 String }",
+        },
+        { "Expected ( but found: verde", 1, 49,
+            "@version v1; namespace ns1; enum E1 { red alias verde }",
+        },
+        { `Illegal start of identifier part: ")" (U+0029)`, 1, 49,
+            "@version v1; namespace ns1; enum E1 { red alias() }",
+        },
     } {
         if i, err := parseSource( "test-source", tt.src ); err == nil {
             a.Fatalf( "%d: Expected error %q in %q", i, tt.errMsg, tt.src )
@@ -376,6 +729,157 @@ import ns1@v1/[ S1 ] - [ S2 ]`,
     }
 }
 
+func parseWithRecovery( 
+    t *testing.T, nm, src string ) ( *NsUnit, []*parser.ParseError ) {
+
+    u, errs, err := ParseSourceWithRecovery( nm, bytes.NewBufferString( src ) )
+    if err != nil { t.Fatal( err ) }
+    return u, errs
+}
+
+func assertParseErrors( 
+    t *testing.T, errs []*parser.ParseError, expct ...string ) {
+
+    act := make( []string, len( errs ) )
+    for i, pe := range errs {
+        act[ i ] = fmt.Sprintf( "%d:%d: %s", pe.Loc.Line, pe.Loc.Col, 
+            pe.Message )
+    }
+    assert.Equal( expct, act )
+}
+
+func TestParseSourceWithRecovery( t *testing.T ) {
+    u, errs := parseWithRecovery( t, "test-source", `@version v1
+import ns2@v1/[ S1 ] - [ S2 ]
+import ns3@v1/*
+namespace ns1
+struct S1 {
+    f1 String
+    f2 String~12
+    f3 Int32 default ( 1, 2 )
+    f4 Int32
+}
+enum E1 { red, 1 }
+struct S2 { f1 $ String; f2 Int64 }
+service Svc1 {
+    op op1( f1 ): String
+    op op2(): String
+}
+struct S3 {
+    f1 String
+struct S4 { f1 Int32 }
+`,
+    )
+    assertParseErrors( t, errs,
+        "2:22: Expected one of [ \";\", \"<;>\" ] but found: -",
+        "7:15: Expected type restriction but found: 12",
+        "8:22: Expected unary expression but found: (",
+        "11:16: Expected identifier but found: 1",
+        "12:16: Unexpected char: \"$\" (U+0024)",
+        "14:16: Expected type reference but found: )",
+        "19:1: Expected } but found: struct",
+    )
+    assert.Equal( 1, len( u.Imports ) )
+    assert.Equal( "ns1@v1", u.NsDecl.Namespace.ExternalForm() )
+    decls := make( []string, len( u.TypeDecls ) )
+    for i, td := range u.TypeDecls {
+        decls[ i ] = td.GetName().ExternalForm()
+    }
+    assert.Equal( []string{ "S1", "S2", "Svc1", "S3", "S4" }, decls )
+    fldNames := func( flds []*FieldDecl ) []string {
+        res := make( []string, len( flds ) )
+        for i, fd := range flds { res[ i ] = fd.Name.ExternalForm() }
+        return res
+    }
+    s1 := u.TypeDecls[ 0 ].( *StructDecl )
+    assert.Equal( []string{ "f1", "f4" }, fldNames( s1.Fields ) )
+    s2 := u.TypeDecls[ 1 ].( *StructDecl )
+    assert.Equal( []string{ "f2" }, fldNames( s2.Fields ) )
+    svc := u.TypeDecls[ 2 ].( *ServiceDecl )
+    assert.Equal( 1, len( svc.Operations ) )
+    assert.Equal( "op2", svc.Operations[ 0 ].Name.ExternalForm() )
+    assert.Equal( []string{ "f1" }, 
+        fldNames( u.TypeDecls[ 3 ].( *StructDecl ).Fields ) )
+}
+
+func TestParseSourceWithRecoveryNoNamespace( t *testing.T ) {
+    u, errs := parseWithRecovery( t, "test-source",
+        "@version v1; namespace; struct S1 { f1 }" )
+    if u != nil { t.Fatalf( "expected no unit, got %v", u ) }
+    assertParseErrors( t, errs,
+        "1:23: Illegal start of identifier part: \";\" (U+003B)",
+        "1:40: Expected type reference but found: }",
+    )
+}
+
+func TestParseSourceWithRecoveryNoErrors( t *testing.T ) {
+    u, errs := parseWithRecovery( 
+        t, "testSource1", testSources[ "testSource1" ] )
+    assert.Equal( 0, len( errs ) )
+    assertParse( "testSource1", u, t )
+}
+
+// Returns the error from parsing src, or from recovering parsing of it, failing
+// a if parsing panics
+func parsePanicFree(
+    a *assert.PathAsserter, src string, recovering bool ) ( err error ) {
+
+    defer func() {
+        if r := recover(); r != nil {
+            a.Fatalf( "panic parsing %q: %v", src, r )
+        }
+    }()
+    if ! recovering { _, err = parseSource( "test-source", src ); return }
+    var errs []*parser.ParseError
+    _, errs, err = ParseSourceWithRecovery( 
+        "test-source", bytes.NewBufferString( src ) )
+    if err == nil && len( errs ) > 0 { err = errs[ 0 ] }
+    return
+}
+
+// Sources cut off at any point, as while an editor is open on them, give parse
+// errors rather than panics
+func TestParseSourcePrefixes( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    chkErr := func( a *assert.PathAsserter, err error ) {
+        if _, ok := err.( *parser.ParseError ); ! ok && err != nil {
+            a.Fatalf( "not a parse error: %s", err )
+        }
+    }
+    for _, src := range []string{
+        "@version v1; namespace ns1; struct S1 {\n    f",
+        "@version v1; namespace ns1; struct S1 { @constructor(",
+        "@version v1; namespace ns1; service Svc1 { op op1( p",
+        "@version v1; namespace ns1; union U1 { String",
+        "@version v1; namespace ns1; alias A1",
+        "@version v1; namespace ns1; prototype P1( f1 Int32 ):",
+    } {
+        a2 := a.Descend( src )
+        for _, recovering := range []bool{ false, true } {
+            err := parsePanicFree( a2, src, recovering )
+            if err == nil {
+                a2.Fatalf( "no error (recovering: %t)", recovering )
+            }
+            chkErr( a2, err )
+        }
+    }
+    nms := make( []string, 0, len( testSources ) )
+    for nm, _ := range testSources { nms = append( nms, nm ) }
+    sort.Strings( nms )
+    for _, nm := range nms {
+        src := testSources[ nm ]
+        a2 := a.Descend( nm ).StartList()
+        for i := 0; i < len( src ); i, a2 = i + 1, a2.Next() {
+            err := parsePanicFree( a2, src[ : i ], false )
+            chkErr( a2, err )
+            err2 := parsePanicFree( a2, src[ : i ], true )
+            chkErr( a2, err2 )
+            a2.Equalf( err == nil, err2 == nil, 
+                "recovering parse disagrees on %q", src[ : i ] )
+        }
+    }
+}
+
 var testSources = map[ string ]string{
 
     "testSource1":
@@ -494,6 +998,29 @@ union Union1 {
 
 union Union2 { Type1, Type2 } # no trailing comma
 
+`,
+    "testSource3":
+`@version v1
+namespace ns1
+
+@doc( "a struct" )
+@wireName( "s1" ) struct Struct1 {
+    @deprecated
+    f1 String
+    @range( 1, -2 ) @flags( [ true, false ] ) f2 Int32
+    @constructor( String )
+}
+
+@doc( "an enum" )
+enum Enum1 { @wireName( "RED" ) red, green }
+
+@flag alias Alias1 String
+
+service Service1 {
+    @deprecated( "use op2" )
+    op op1( @doc( "f1" ) f1 String ): String
+    @security Sec1
+}
 `,
     "testSource2":
 `@version v1
@@ -525,6 +1052,14 @@ func sxAtomicTyp( nm mg.TypeName,
     return &parser.CompletableTypeReference{ Expression: at }
 }
 
+// doc expected from a run of the placeholder comments in testSource1
+func blankLinesDoc( n int ) string {
+    line := "left blank to preserve line nums below (previous test text removed)"
+    lines := make( []string, n )
+    for i := range lines { lines[ i ] = line }
+    return strings.Join( lines, "\n" )
+}
+
 func initResultTestSource1() {
     lc1 := func( line, col int ) *parser.Location {
         return &parser.Location{ Source: "testSource1", Line: line, Col: col }
@@ -639,6 +1174,7 @@ func initResultTestSource1() {
                     { Name: mgId( "int2" ),
                       Type: sxAtomicTyp( mgDn( "Int64" ), nil, lc1( 25, 10 ) ),
                       NameLoc: lc1( 25, 5 ),
+                      Doc: blankLinesDoc( 2 ),
                       Default: &BinaryExpression{
                         Left: &PrimaryExpression{
                             Prim: &parser.NumericToken{ "1234", "", "", 0 },
@@ -808,6 +1344,7 @@ func initResultTestSource1() {
                     },
                     { Name: mgId( "float5" ), 
                       NameLoc: lc1( 39, 5 ),
+                      Doc: blankLinesDoc( 4 ),
                       Type: 
                         sxAtomicTyp( mgDn( "Float32" ), nil, lc1( 39, 12 ) ),
                     },
@@ -953,9 +1490,9 @@ func initResultTestSource1() {
                 Name: mgDn( "Enum1" ),
                 NameLoc: lc1( 64, 6 ),
                 Values: []*EnumValue{ 
-                    { mgId( "red" ), lc1( 64, 14 ) },
-                    { mgId( "green" ), lc1( 64, 19 ) },
-                    { mgId( "lightGrey" ), lc1( 64, 26 ) },
+                    { Value: mgId( "red" ), ValueLoc: lc1( 64, 14 ) },
+                    { Value: mgId( "green" ), ValueLoc: lc1( 64, 19 ) },
+                    { Value: mgId( "lightGrey" ), ValueLoc: lc1( 64, 26 ) },
                 },
             },
             &AliasDecl{
@@ -1371,6 +1908,169 @@ func initResultTestSource1() {
     }
 }
 
+func initResultTestSource3() {
+    lc := func( line, col int ) *parser.Location {
+        return &parser.Location{ Source: "testSource3", Line: line, Col: col }
+    }
+    str := func( s string, line, col int ) *PrimaryExpression {
+        return &PrimaryExpression{ 
+            Prim: parser.StringToken( s ), 
+            PrimLoc: lc( line, col ),
+        }
+    }
+    annot := func( 
+        nm string, line, col int, args ...Expression ) *Annotation {
+
+        res := &Annotation{
+            Start: lc( line, col ),
+            Name: mgId( nm ),
+            NameLoc: lc( line, col + 1 ),
+        }
+        if len( args ) > 0 { res.Args = args }
+        return res
+    }
+    testParseResults[ "testSource3" ] = &NsUnit{
+        SourceName: "testSource3",
+        NsDecl: &NamespaceDecl{ 
+            Namespace: mgNs( "ns1@v1" ), 
+            Start: lc( 2, 11 ),
+        },
+        TypeDecls: []TypeDecl{
+            &StructDecl{
+                Start: lc( 5, 19 ),
+                Info: &TypeDeclInfo{ 
+                    Name: mgDn( "Struct1" ), 
+                    NameLoc: lc( 5, 26 ),
+                },
+                Annotations: []*Annotation{
+                    annot( "doc", 4, 1, str( "a struct", 4, 7 ) ),
+                    annot( "wireName", 5, 1, str( "s1", 5, 12 ) ),
+                },
+                Fields: []*FieldDecl{
+                    { 
+                        Name: mgId( "f1" ),
+                        NameLoc: lc( 7, 5 ),
+                        Type: sxAtomicTyp( mgDn( "String" ), nil, lc( 7, 8 ) ),
+                        Annotations: []*Annotation{ 
+                            annot( "deprecated", 6, 5 ),
+                        },
+                    },
+                    {
+                        Name: mgId( "f2" ),
+                        NameLoc: lc( 8, 47 ),
+                        Type: sxAtomicTyp( mgDn( "Int32" ), nil, lc( 8, 50 ) ),
+                        Annotations: []*Annotation{
+                            annot( "range", 8, 5,
+                                &PrimaryExpression{
+                                    Prim: &parser.NumericToken{ Int: "1" },
+                                    PrimLoc: lc( 8, 13 ),
+                                },
+                                &UnaryExpression{
+                                    Op: parser.SpecialTokenMinus,
+                                    OpLoc: lc( 8, 16 ),
+                                    Exp: &PrimaryExpression{
+                                        Prim: &parser.NumericToken{ Int: "2" },
+                                        PrimLoc: lc( 8, 17 ),
+                                    },
+                                },
+                            ),
+                            annot( "flags", 8, 21,
+                                &ListExpression{
+                                    Start: lc( 8, 29 ),
+                                    Elements: []Expression{
+                                        &PrimaryExpression{
+                                            Prim: parser.KeywordTrue,
+                                            PrimLoc: lc( 8, 31 ),
+                                        },
+                                        &PrimaryExpression{
+                                            Prim: parser.KeywordFalse,
+                                            PrimLoc: lc( 8, 37 ),
+                                        },
+                                    },
+                                },
+                            ),
+                        },
+                    },
+                },
+                Constructors: []*ConstructorDecl{
+                    { 
+                        Start: lc( 9, 5 ), 
+                        ArgType: 
+                            sxAtomicTyp( mgDn( "String" ), nil, lc( 9, 19 ) ),
+                    },
+                },
+            },
+            &EnumDecl{
+                Start: lc( 13, 1 ),
+                Name: mgDn( "Enum1" ),
+                NameLoc: lc( 13, 6 ),
+                Values: []*EnumValue{
+                    { 
+                        Value: mgId( "red" ), 
+                        ValueLoc: lc( 13, 33 ),
+                        Annotations: []*Annotation{
+                            annot( "wireName", 13, 14, str( "RED", 13, 25 ) ),
+                        },
+                    },
+                    { Value: mgId( "green" ), ValueLoc: lc( 13, 38 ) },
+                },
+                Annotations: []*Annotation{
+                    annot( "doc", 12, 1, str( "an enum", 12, 7 ) ),
+                },
+            },
+            &AliasDecl{
+                Start: lc( 15, 7 ),
+                Name: mgDn( "Alias1" ),
+                NameLoc: lc( 15, 13 ),
+                Target: sxAtomicTyp( mgDn( "String" ), nil, lc( 15, 20 ) ),
+                Annotations: []*Annotation{ annot( "flag", 15, 1 ) },
+            },
+            &ServiceDecl{
+                Start: lc( 17, 1 ),
+                Info: &TypeDeclInfo{
+                    Name: mgDn( "Service1" ),
+                    NameLoc: lc( 17, 9 ),
+                },
+                Operations: []*OperationDecl{
+                    {
+                        Name: mgId( "op1" ),
+                        NameLoc: lc( 19, 8 ),
+                        Call: &CallSignature{
+                            Start: lc( 19, 11 ),
+                            Fields: []*FieldDecl{
+                                {
+                                    Name: mgId( "f1" ),
+                                    NameLoc: lc( 19, 26 ),
+                                    Type: sxAtomicTyp( 
+                                        mgDn( "String" ), nil, lc( 19, 29 ) ),
+                                    Annotations: []*Annotation{
+                                        annot( "doc", 19, 13, 
+                                            str( "f1", 19, 19 ) ),
+                                    },
+                                },
+                            },
+                            Return: sxAtomicTyp( 
+                                mgDn( "String" ), nil, lc( 19, 39 ) ),
+                        },
+                        Annotations: []*Annotation{
+                            annot( "deprecated", 18, 5, 
+                                str( "use op2", 18, 18 ) ),
+                        },
+                    },
+                },
+                SecurityDecls: []*SecurityDecl{
+                    {
+                        Start: lc( 20, 5 ),
+                        Name: mgDn( "Sec1" ),
+                        NameLoc: lc( 20, 15 ),
+                    },
+                },
+            },
+        },
+    }
+}
+
 func init() {
     initResultTestSource1()
+    initResultTestSource3()
 }
@@ -6,7 +6,7 @@ import (
     "mingle/cast"
     "mingle/types"
     "bitgirder/objpath"
-//    "log"
+    "log"
 )
 
 type RequestDefinition struct {
@@ -15,9 +15,26 @@ type RequestDefinition struct {
     AuthenticationType mg.TypeReference
 }
 
+// called with the context of each request for a deprecated operation and the
+// message, possibly empty, with which the operation was deprecated
+type DeprecatedOperationHandler func( ctx *RequestContext, msg string )
+
+// a DeprecatedOperationHandler which writes each call to the standard logger
+func LogDeprecatedOperation( ctx *RequestContext, msg string ) {
+    inst := FormatInstanceId( ctx.Namespace, ctx.Service )
+    if msg == "" {
+        log.Printf( "call to deprecated operation %s.%s", inst, ctx.Operation )
+        return
+    }
+    log.Printf( "call to deprecated operation %s.%s: %s", 
+        inst, ctx.Operation, msg )
+}
+
 type OperationMap struct {
     instMap *InstanceMap
     defs *types.DefinitionMap
+
+    HandleDeprecatedOperation DeprecatedOperationHandler
 }
 
 func NewOperationMap( defs *types.DefinitionMap ) *OperationMap {
@@ -58,8 +75,13 @@ func ( m *OperationMap ) ExpectOperationForRequest(
     ctx *RequestContext, path objpath.PathNode ) ( *RequestDefinition, error ) {
 
     def, err := m.instMap.getRequestValue( ctx, path )
-    if err == nil { return def.( *RequestDefinition ), nil }
-    return nil, err
+    if err != nil { return nil, err }
+    reqDef := def.( *RequestDefinition )
+    if h := m.HandleDeprecatedOperation; h != nil {
+        annots := reqDef.Operation.Annotations
+        if msg, ok := types.DeprecationMessage( annots ); ok { h( ctx, msg ) }
+    }
+    return reqDef, nil
 }
 
 type typedReqIface struct {
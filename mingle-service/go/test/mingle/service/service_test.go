@@ -4,6 +4,8 @@ import (
     "testing"
     mg "mingle"
     mgRct "mingle/reactor"
+    "mingle/types"
+    "mingle/types/builtin"
     "bitgirder/assert"
 )
 
@@ -39,3 +41,34 @@ func TestInstanceMap( t *testing.T ) {
     chk( ns1, svc2, nil, IdService )
     chk( ns2, svc1, nil, IdNamespace )
 }
+
+func TestDeprecatedOperationHandler( t *testing.T ) {
+    mkOp := func( nm string ) *types.OperationDefinition {
+        sig := types.MakeCallSig( []*types.FieldDefinition{}, "Null", nil )
+        return types.MakeOpDef( nm, sig )
+    }
+    op1, op2 := mkOp( "op1" ), mkOp( "op2" )
+    op2.Annotations = []*types.Annotation{
+        types.MakeAnnotation( "deprecated", "use op1" ),
+    }
+    defs := builtin.BuiltinTypes()
+    defs.MustAdd( types.MakeServiceDef( "ns1@v1/Service1", "", op1, op2 ) )
+    m := NewOperationMap( defs )
+    m.MustAddServiceInstance( mkNs( "ns1@v1" ), mkId( "svc1" ), 
+        mkQn( "ns1@v1/Service1" ) )
+    act := []string{}
+    m.HandleDeprecatedOperation = func( ctx *RequestContext, msg string ) {
+        act = append( act, ctx.Operation.ExternalForm() + ": " + msg )
+    }
+    for _, op := range []string{ "op1", "op2" } {
+        ctx := &RequestContext{ 
+            Namespace: mkNs( "ns1@v1" ),
+            Service: mkId( "svc1" ),
+            Operation: mkId( op ),
+        }
+        if _, err := m.ExpectOperationForRequest( ctx, nil ); err != nil {
+            t.Fatal( err )
+        }
+    }
+    assert.Equal( []string{ "op2: use op1" }, act )
+}
@@ -6,6 +6,8 @@ import (
     mg "mingle"
     mgRct "mingle/reactor"
     "time"
+    "net/url"
+    "math/big"
 //    "log"
 )
 
@@ -168,7 +170,7 @@ func NewFunctionsBuilderFactory() *mgRct.FunctionsBuilderFactory {
 func visitPrimValueOk( val interface{}, vc VisitContext ) ( error, bool ) {
     switch v := val.( type ) {
     case bool, []byte, string, int32, int64, uint32, uint64, float32, float64,
-         time.Time, nil: 
+         *big.Int, time.Time, time.Duration, *url.URL, nil: 
         return visitPrimValueOk( mg.MustValue( v ), vc )
     case mg.Value: 
         return mgRct.VisitValuePath( v, vc.Destination, vc.Path ), true
@@ -271,6 +273,21 @@ func addPrimBindings( reg *Registry ) {
             return nil, nil, false
         },
     )
+    addPrim(
+        mg.QnameBigInt,
+        func( ve *mgRct.ValueEvent ) ( interface{}, error, bool ) {
+            if v, ok := ve.Val.( mg.BigInt ); ok { return v.Int(), nil, true }
+            return nil, nil, false
+        },
+    )
+    // go has no standard decimal type, so we bind the mingle value itself
+    addPrim(
+        mg.QnameDecimal,
+        func( ve *mgRct.ValueEvent ) ( interface{}, error, bool ) {
+            if v, ok := ve.Val.( mg.Decimal ); ok { return v, nil, true }
+            return nil, nil, false
+        },
+    )
     addPrim(
         mg.QnameTimestamp,
         func( ve *mgRct.ValueEvent ) ( interface{}, error, bool ) {
@@ -280,6 +297,47 @@ func addPrimBindings( reg *Registry ) {
             return nil, nil, false
         },
     )
+    // as with Decimal, go has no standard date or time-of-day types
+    addPrim(
+        mg.QnameDate,
+        func( ve *mgRct.ValueEvent ) ( interface{}, error, bool ) {
+            if v, ok := ve.Val.( mg.Date ); ok { return v, nil, true }
+            return nil, nil, false
+        },
+    )
+    addPrim(
+        mg.QnameLocalTime,
+        func( ve *mgRct.ValueEvent ) ( interface{}, error, bool ) {
+            if v, ok := ve.Val.( mg.LocalTime ); ok { return v, nil, true }
+            return nil, nil, false
+        },
+    )
+    addPrim(
+        mg.QnameDuration,
+        func( ve *mgRct.ValueEvent ) ( interface{}, error, bool ) {
+            if v, ok := ve.Val.( mg.Duration ); ok {
+                return time.Duration( v ), nil, true
+            }
+            return nil, nil, false
+        },
+    )
+    addPrim(
+        mg.QnameUuid,
+        func( ve *mgRct.ValueEvent ) ( interface{}, error, bool ) {
+            if v, ok := ve.Val.( mg.Uuid ); ok { return v, nil, true }
+            return nil, nil, false
+        },
+    )
+    addPrim(
+        mg.QnameUri,
+        func( ve *mgRct.ValueEvent ) ( interface{}, error, bool ) {
+            if v, ok := ve.Val.( mg.Uri ); ok {
+                u, err := v.Url()
+                return u, err, true
+            }
+            return nil, nil, false
+        },
+    )
     reg.AddVisitValueOkFunc( visitPrimValueOk )
 }
 
@@ -11,6 +11,10 @@ import (
     "unicode"
     "strings"
     "strconv"
+    "math/big"
+    "unicode/utf8"
+    "net/url"
+    "bitgirder/uuid"
 )
 
 // values declared and accepted by this package are always > 0; 0 may be used
@@ -307,6 +311,8 @@ const (
     rxErrNameRegex = "regex"
 
     rxErrNameRange = "range"
+
+    rxErrNameLength = "length"
 )
 
 type RestrictionError struct { msg string }
@@ -400,10 +406,12 @@ func ( rr *RangeRestriction ) MaxClosed() bool { return rr.maxClosed }
 
 func quoteRangeValue( val Value, must bool ) string {
     switch v := val.( type ) {
-    case Int32, Int64, Uint32, Uint64, Float32, Float64: 
+    case Int32, Int64, Uint32, Uint64, Float32, Float64, BigInt, Decimal: 
         return val.( fmt.Stringer ).String()
     case String: return fmt.Sprintf( "%q", string( v ) )
     case Timestamp: return fmt.Sprintf( "%q", v.Rfc3339Nano() )
+    case Date, LocalTime, Duration: 
+        return fmt.Sprintf( "%q", val.( fmt.Stringer ).String() )
     }
     if must { panic( libErrorf( "unhandled range val for quote: %T", val ) ) }
     return fmt.Sprintf( "<range val: %T>", val )
@@ -488,6 +496,9 @@ func areAdjacentInts( min, max Value ) bool {
     case Uint32: return uint32( max.( Uint32 ) ) - uint32( minV ) == uint32( 1 )
     case Int64: return int64( max.( Int64 ) ) - int64( minV ) == int64( 1 )
     case Uint64: return uint64( max.( Uint64 ) ) - uint64( minV ) == uint64( 1 )
+    case BigInt: 
+        diff := new( big.Int ).Sub( max.( BigInt ).bigInt(), minV.bigInt() )
+        return diff.Cmp( big.NewInt( 1 ) ) == 0
     }
     return false
 }
@@ -516,6 +527,99 @@ func ( b *RangeRestrictionBuilder ) Build() ( *RangeRestriction, error ) {
     return &RangeRestriction{ b.MinClosed, b.Min, b.Max, b.MaxClosed }, nil
 }
 
+// Restricts the length of a value to a range: the number of characters in a
+// String, the number of bytes in a Buffer or the number of elements in a List
+type LengthRestriction struct {
+    rng *RangeRestriction // a range of Int64 lengths
+}
+
+func ( lr *LengthRestriction ) MinClosed() bool { return lr.rng.minClosed }
+func ( lr *LengthRestriction ) Min() Value { return lr.rng.min }
+func ( lr *LengthRestriction ) Max() Value { return lr.rng.max }
+func ( lr *LengthRestriction ) MaxClosed() bool { return lr.rng.maxClosed }
+
+func ( lr *LengthRestriction ) ExternalForm() string {
+    return lr.rng.ExternalForm()
+}
+
+func ( lr *LengthRestriction ) equalsRestriction( vr ValueRestriction ) bool {
+    if vr == nil { return false }
+    if lr == vr { return true }
+    if lr2, ok := vr.( *LengthRestriction ); ok {
+        return lr.rng.equalsRestriction( lr2.rng )
+    }
+    return false
+}
+
+func ( lr *LengthRestriction ) AcceptsLength( n int ) bool {
+    return lr.rng.AcceptsValue( Int64( int64( n ) ) )
+}
+
+func ( lr *LengthRestriction ) AcceptsValue( val Value ) bool {
+    switch v := val.( type ) {
+    case nil: panic( errNilVal )
+    case String: 
+        return lr.AcceptsLength( utf8.RuneCountInString( string( v ) ) )
+    case Buffer: return lr.AcceptsLength( len( v ) )
+    case *List: return lr.AcceptsLength( v.Len() )
+    }
+    panic( libErrorf( "value has no length: %T", val ) )
+}
+
+func equalLengthRestrictions( lr1, lr2 *LengthRestriction ) bool {
+    if lr1 == nil { return lr2 == nil }
+    return lr1.equalsRestriction( lr2 )
+}
+
+// Min and Max may be of any integer type, and are stored as Int64
+type LengthRestrictionBuilder struct {
+    MinClosed bool
+    Min Value 
+    Max Value
+    MaxClosed bool
+}
+
+func asLengthBound( bound string, val Value ) ( Value, error ) {
+    var n int64
+    switch v := val.( type ) {
+    case nil: return nil, nil
+    case Int32: n = int64( v )
+    case Int64: n = int64( v )
+    case Uint32: n = int64( v )
+    case Uint64: 
+        if n = int64( v ); n >= 0 { break }
+        msg := fmt.Sprintf( "%s length out of range: %s", bound, v )
+        return nil, &RestrictionError{ msg }
+    default:
+        msg := fmt.Sprintf( "illegal %s value of type %s in length range",
+            bound, TypeOf( val ) )
+        return nil, &RestrictionError{ msg }
+    }
+    if n < 0 {
+        msg := fmt.Sprintf( "negative %s length: %d", bound, n )
+        return nil, &RestrictionError{ msg }
+    }
+    return Int64( n ), nil
+}
+
+func ( b *LengthRestrictionBuilder ) Build() ( *LengthRestriction, error ) {
+    rb := &RangeRestrictionBuilder{ 
+        Type: QnameInt64, 
+        MinClosed: b.MinClosed, 
+        MaxClosed: b.MaxClosed,
+    }
+    var err error
+    if rb.Min, err = asLengthBound( "min", b.Min ); err != nil { 
+        return nil, err 
+    }
+    if rb.Max, err = asLengthBound( "max", b.Max ); err != nil { 
+        return nil, err 
+    }
+    rr, err := rb.Build()
+    if err != nil { return nil, err }
+    return &LengthRestriction{ rr }, nil
+}
+
 type AtomicTypeReference struct {
     name *QualifiedTypeName
     restriction ValueRestriction
@@ -540,6 +644,9 @@ func atomicCheckRestrictionApplicable(
         msg := fmt.Sprintf( "cannot apply %s range to base type %s", 
             rngTyp, nm )
         return &RestrictionError{ msg }
+    case *LengthRestriction:
+        if nm.Equals( QnameString ) || nm.Equals( QnameBuffer ) { return nil }
+        return newRestrictionErrorInapplicable( rxErrNameLength, nm )
     }
     panic( libErrorf( "unhandled restriction: %T", vr ) )
 }
@@ -587,6 +694,9 @@ func ( t *AtomicTypeReference ) Equals( t2 TypeReference ) bool {
 type ListTypeReference struct {
     ElementType TypeReference
     AllowsEmpty bool
+
+    // restricts the number of elements in the list; may be nil
+    Restriction *LengthRestriction
 }
 
 func ( t *ListTypeReference ) typeRefImpl() {}
@@ -594,7 +704,9 @@ func ( t *ListTypeReference ) typeRefImpl() {}
 func ( t *ListTypeReference ) ExternalForm() string {
     var quant string
     if t.AllowsEmpty { quant = "*" } else { quant = "+" }
-    return t.ElementType.ExternalForm() + quant
+    res := t.ElementType.ExternalForm() + quant
+    if t.Restriction == nil { return res }
+    return res + "~" + t.Restriction.ExternalForm()
 }
 
 func ( t *ListTypeReference ) String() string { return t.ExternalForm() }
@@ -603,11 +715,37 @@ func ( t *ListTypeReference ) Equals( ref TypeReference ) bool {
     if ref == nil { return false }
     if t2, ok := ref.( *ListTypeReference ); ok {
         return t.AllowsEmpty == t2.AllowsEmpty &&
+               equalLengthRestrictions( t.Restriction, t2.Restriction ) &&
                t.ElementType.Equals( t2.ElementType )
     }
     return false
 }
 
+// A SymbolMap whose field values are each of type ValueType
+type MapTypeReference struct {
+    ValueType TypeReference
+}
+
+func NewMapTypeReference( valTyp TypeReference ) *MapTypeReference {
+    return &MapTypeReference{ ValueType: valTyp }
+}
+
+func ( t *MapTypeReference ) typeRefImpl() {}
+
+func ( t *MapTypeReference ) ExternalForm() string {
+    return "{" + t.ValueType.ExternalForm() + "}"
+}
+
+func ( t *MapTypeReference ) String() string { return t.ExternalForm() }
+
+func ( t *MapTypeReference ) Equals( ref TypeReference ) bool {
+    if ref == nil { return false }
+    if t2, ok := ref.( *MapTypeReference ); ok {
+        return t.ValueType.Equals( t2.ValueType )
+    }
+    return false
+}
+
 type NullableTypeReference struct {
     Type TypeReference
 }
@@ -624,12 +762,17 @@ func NewNullableTypeError( typ TypeReference ) *NullableTypeError {
 func IsNullableType( typ TypeReference ) bool {
     switch v := typ.( type ) {
     case *ListTypeReference: return true;
+    case *MapTypeReference: return true
     case *NullableTypeReference: return false;
     case *PointerTypeReference: return true;
     case *AtomicTypeReference:
         if ! v.Name().Namespace.Equals( CoreNsV1 ) { return false }
         return ! ( v.Name().Equals( QnameBoolean ) || 
                    v.Name().Equals( QnameTimestamp ) || 
+                   v.Name().Equals( QnameDate ) || 
+                   v.Name().Equals( QnameLocalTime ) || 
+                   v.Name().Equals( QnameDuration ) || 
+                   v.Name().Equals( QnameUuid ) || 
                    IsNumericTypeName( v.Name() ) )
     }
     panic( libErrorf( "unhandled type: %T", typ ) )
@@ -684,6 +827,7 @@ func AtomicTypeIn( ref TypeReference ) *AtomicTypeReference {
     switch v := ref.( type ) {
     case *AtomicTypeReference: return v
     case *ListTypeReference: return AtomicTypeIn( v.ElementType )
+    case *MapTypeReference: return AtomicTypeIn( v.ValueType )
     case *NullableTypeReference: return AtomicTypeIn( v.Type )
     case *PointerTypeReference: return AtomicTypeIn( v.Type )
     }
@@ -846,6 +990,90 @@ func ( f Float32 ) Compare( val interface{} ) int {
     return Float64( f ).Compare( Float64( val.( Float32 ) ) )
 }
 
+var bigZero = new( big.Int )
+
+// An integer of arbitrary size. The zero BigInt is 0.
+type BigInt struct { i *big.Int }
+
+func NewBigInt( i *big.Int ) BigInt { return BigInt{ new( big.Int ).Set( i ) } }
+
+func ( i BigInt ) valImpl() {}
+
+func ( i BigInt ) bigInt() *big.Int {
+    if i.i == nil { return bigZero }
+    return i.i
+}
+
+// returns a copy, so callers are free to modify the result
+func ( i BigInt ) Int() *big.Int { return new( big.Int ).Set( i.bigInt() ) }
+
+func ( i BigInt ) String() string { return i.bigInt().String() }
+
+func ( i BigInt ) Compare( val interface{} ) int {
+    return i.bigInt().Cmp( val.( BigInt ).bigInt() )
+}
+
+// An arbitrary-precision decimal with value Unscaled() * 10^-Scale(). The scale
+// is kept as given, so 1.5 and 1.50 compare as equal but have different string
+// forms. The zero Decimal is 0.
+type Decimal struct {
+    unscaled *big.Int
+    scale int32
+}
+
+func NewDecimal( unscaled *big.Int, scale int32 ) Decimal {
+    return Decimal{ new( big.Int ).Set( unscaled ), scale }
+}
+
+func ( d Decimal ) valImpl() {}
+
+func ( d Decimal ) unscaledInt() *big.Int {
+    if d.unscaled == nil { return bigZero }
+    return d.unscaled
+}
+
+// returns a copy, so callers are free to modify the result
+func ( d Decimal ) Unscaled() *big.Int { 
+    return new( big.Int ).Set( d.unscaledInt() ) 
+}
+
+func ( d Decimal ) Scale() int32 { return d.scale }
+
+func pow10( n int64 ) *big.Int {
+    return new( big.Int ).Exp( big.NewInt( 10 ), big.NewInt( n ), nil )
+}
+
+func ( d Decimal ) Rat() *big.Rat {
+    // with a non-positive scale d is an integer
+    if d.scale <= 0 { return new( big.Rat ).SetInt( d.Truncate().bigInt() ) }
+    return new( big.Rat ).SetFrac( d.unscaledInt(), pow10( int64( d.scale ) ) )
+}
+
+// Returns the integer part of d, discarding any fraction
+func ( d Decimal ) Truncate() BigInt {
+    res := new( big.Int )
+    if d.scale < 0 { 
+        res.Mul( d.unscaledInt(), pow10( -int64( d.scale ) ) ) 
+    } else { res.Quo( d.unscaledInt(), pow10( int64( d.scale ) ) ) }
+    return BigInt{ res }
+}
+
+func ( d Decimal ) String() string {
+    if d.scale <= 0 { return d.Truncate().String() }
+    digs := new( big.Int ).Abs( d.unscaledInt() ).String()
+    if pad := int( d.scale ) + 1 - len( digs ); pad > 0 {
+        digs = strings.Repeat( "0", pad ) + digs
+    }
+    pt := len( digs ) - int( d.scale )
+    res := digs[ : pt ] + "." + digs[ pt : ]
+    if d.unscaledInt().Sign() < 0 { res = "-" + res }
+    return res
+}
+
+func ( d Decimal ) Compare( val interface{} ) int {
+    return d.Rat().Cmp( val.( Decimal ).Rat() )
+}
+
 type Buffer []byte
 func ( b Buffer ) valImpl() {}
 
@@ -880,6 +1108,183 @@ func ( t Timestamp ) Compare( val interface{} ) int {
     return 0
 }
 
+func compareInts( i1, i2 int64 ) int {
+    switch {
+    case i1 < i2: return -1
+    case i1 > i2: return 1
+    }
+    return 0
+}
+
+// A calendar date with no time of day or time zone. String() gives the ISO
+// 8601 form YYYY-MM-DD.
+type Date struct {
+    Year int
+    Month time.Month
+    Day int
+}
+
+func ( d Date ) valImpl() {}
+
+// Returns the Date with the given fields, or an error if they do not name a
+// day of the proleptic Gregorian calendar, such as February 30
+func NewDate( year int, month time.Month, day int ) ( Date, error ) {
+    res := Date{ Year: year, Month: month, Day: day }
+    t := time.Date( year, month, day, 0, 0, 0, 0, time.UTC )
+    if t.Year() == year && t.Month() == month && t.Day() == day { 
+        return res, nil
+    }
+    return Date{}, fmt.Errorf( "invalid date: %s", res )
+}
+
+func ( d Date ) String() string {
+    return fmt.Sprintf( "%04d-%02d-%02d", d.Year, int( d.Month ), d.Day )
+}
+
+func ( d Date ) Compare( val interface{} ) int {
+    d2 := val.( Date )
+    if res := compareInts( int64( d.Year ), int64( d2.Year ) ); res != 0 {
+        return res
+    }
+    if res := compareInts( int64( d.Month ), int64( d2.Month ) ); res != 0 {
+        return res
+    }
+    return compareInts( int64( d.Day ), int64( d2.Day ) )
+}
+
+const nanosPerDay = int64( 24 * time.Hour )
+
+// A time of day with no date or time zone. String() gives the ISO 8601 form
+// HH:MM:SS, followed by a fractional second when Nanosecond is non-zero.
+type LocalTime struct {
+    Hour int
+    Minute int
+    Second int
+    Nanosecond int
+}
+
+func ( t LocalTime ) valImpl() {}
+
+// Returns the LocalTime which is nanos nanoseconds after midnight; nanos must
+// be in [0,nanosPerDay)
+func LocalTimeOfNanos( nanos int64 ) ( LocalTime, error ) {
+    if nanos < 0 || nanos >= nanosPerDay {
+        return LocalTime{}, fmt.Errorf( "time of day out of range: %d", nanos )
+    }
+    d := time.Duration( nanos )
+    return LocalTime{
+        Hour: int( d / time.Hour ),
+        Minute: int( d % time.Hour / time.Minute ),
+        Second: int( d % time.Minute / time.Second ),
+        Nanosecond: int( d % time.Second ),
+    }, nil
+}
+
+// Returns the number of nanoseconds after midnight at which t falls
+func ( t LocalTime ) NanoOfDay() int64 {
+    return int64( time.Duration( t.Hour ) * time.Hour +
+                  time.Duration( t.Minute ) * time.Minute +
+                  time.Duration( t.Second ) * time.Second +
+                  time.Duration( t.Nanosecond ) )
+}
+
+func ( t LocalTime ) String() string {
+    res := fmt.Sprintf( "%02d:%02d:%02d", t.Hour, t.Minute, t.Second )
+    if t.Nanosecond == 0 { return res }
+    frac := strings.TrimRight( fmt.Sprintf( "%09d", t.Nanosecond ), "0" )
+    return res + "." + frac
+}
+
+func ( t LocalTime ) Compare( val interface{} ) int {
+    return compareInts( t.NanoOfDay(), val.( LocalTime ).NanoOfDay() )
+}
+
+// An exact span of time. String() gives the ISO 8601 form PnDTnHnMn.nS, in
+// which a day is always 24 hours, with a leading '-' for negative durations;
+// years and months are not used since their lengths vary.
+type Duration time.Duration
+
+func ( d Duration ) valImpl() {}
+
+func ( d Duration ) String() string {
+    if d == 0 { return "PT0S" }
+    buf := &bytes.Buffer{}
+    // work with an unsigned magnitude so that math.MinInt64 negates safely
+    n := uint64( d )
+    if d < 0 {
+        buf.WriteString( "-" )
+        n = -n
+    }
+    buf.WriteString( "P" )
+    if days := n / uint64( nanosPerDay ); days > 0 {
+        fmt.Fprintf( buf, "%dD", days )
+    }
+    n %= uint64( nanosPerDay )
+    if n == 0 { return buf.String() }
+    buf.WriteString( "T" )
+    if h := n / uint64( time.Hour ); h > 0 { fmt.Fprintf( buf, "%dH", h ) }
+    if m := n % uint64( time.Hour ) / uint64( time.Minute ); m > 0 {
+        fmt.Fprintf( buf, "%dM", m )
+    }
+    n %= uint64( time.Minute )
+    if n == 0 { return buf.String() }
+    fmt.Fprintf( buf, "%d", n / uint64( time.Second ) )
+    if ns := n % uint64( time.Second ); ns > 0 {
+        frac := strings.TrimRight( fmt.Sprintf( "%09d", ns ), "0" )
+        buf.WriteString( "." + frac )
+    }
+    buf.WriteString( "S" )
+    return buf.String()
+}
+
+func ( d Duration ) Compare( val interface{} ) int {
+    return compareInts( int64( d ), int64( val.( Duration ) ) )
+}
+
+// A 128-bit universally unique identifier. String() gives the canonical
+// lower-case form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+type Uuid [ 16 ]byte
+
+func ( u Uuid ) valImpl() {}
+
+func ( u Uuid ) String() string { return uuid.Format( u ) }
+
+// Returns a new random (version 4) Uuid
+func NewUuid() ( Uuid, error ) {
+    b, err := uuid.Type4Bytes()
+    return Uuid( b ), err
+}
+
+func MustNewUuid() Uuid {
+    res, err := NewUuid()
+    if err != nil { panic( err ) }
+    return res
+}
+
+// An absolute URI in canonical form, meaning that it has a scheme and that its
+// scheme and host are lower-case. Values should be obtained from
+// parser.ParseUri() or UriOf(), which ensure this.
+type Uri string
+
+func ( u Uri ) valImpl() {}
+
+func ( u Uri ) String() string { return string( u ) }
+
+// Returns an error if u is not absolute
+func UriOf( u *url.URL ) ( Uri, error ) {
+    if ! u.IsAbs() { 
+        return "", fmt.Errorf( "uri is not absolute: %q", u.String() ) 
+    }
+    canon := *u
+    canon.Scheme = strings.ToLower( canon.Scheme )
+    canon.Host = strings.ToLower( canon.Host )
+    return Uri( canon.String() ), nil
+}
+
+// Returns the parsed form of u, which will fail only if u was not created by
+// one of the functions named in the type doc
+func ( u Uri ) Url() ( *url.URL, error ) { return url.Parse( string( u ) ) }
+
 func equalMaps( m1, m2 *SymbolMap ) bool {
     if m1.Len() != m2.Len() { return false }
     res := true
@@ -915,10 +1320,17 @@ func EqualValues( v1, v2 Value ) bool {
     case Uint64: if i, ok := v2.( Uint64 ); ok { return v == i }
     case Float32: if i, ok := v2.( Float32 ); ok { return v == i }
     case Float64: if i, ok := v2.( Float64 ); ok { return v == i }
+    case BigInt: if i, ok := v2.( BigInt ); ok { return v.Compare( i ) == 0 }
+    case Decimal: if d, ok := v2.( Decimal ); ok { return v.Compare( d ) == 0 }
     case Buffer: if b, ok := v2.( Buffer ); ok { return bytes.Equal( v, b ) }
     case String: if s, ok := v2.( String ); ok { return v == s }
     case Timestamp:
         if t, ok := v2.( Timestamp ); ok { return v.Compare( t ) == 0 }
+    case Date: if d, ok := v2.( Date ); ok { return v == d }
+    case LocalTime: if t, ok := v2.( LocalTime ); ok { return v == t }
+    case Duration: if d, ok := v2.( Duration ); ok { return v == d }
+    case Uuid: if u, ok := v2.( Uuid ); ok { return v == u }
+    case Uri: if u, ok := v2.( Uri ); ok { return v == u }
     case *Enum: 
         if e, ok := v2.( *Enum ); ok { 
             return v.Type.Equals( e.Type ) && v.Value.Equals( e.Value )
@@ -969,8 +1381,21 @@ func asAtomicValue(
     case Float32: val = v
     case float64: val = Float64( v )
     case Float64: val = v
+    case *big.Int: val = NewBigInt( v )
+    case BigInt: val = v
+    case Decimal: val = v
     case Timestamp: val = v
     case time.Time: val = Timestamp( v )
+    case Date: val = v
+    case LocalTime: val = v
+    case Duration: val = v
+    case time.Duration: val = Duration( v )
+    case Uuid: val = v
+    case Uri: val = v
+    case *url.URL: 
+        if val, err = UriOf( v ); err != nil {
+            err = &asValueLocatedError{ path, err.Error() }
+        }
     case *List: val = v
     case *SymbolMap: val = v
     case *Enum: val = v
@@ -1202,10 +1627,22 @@ func init() {
     idPathRootVal = objpath.RootedAt( NewIdentifierUnsafe( []string{ "val" } ) )
 }
 
+type idPathWildcard struct {}
+
+// IdPathWildcard is the path element produced when parsing '*' as part of an
+// identifier path pattern. It is not itself a field name and is only
+// meaningful to consumers that treat identifier paths as patterns, in which it
+// matches any single field, map key, or list index
+var IdPathWildcard interface{} = idPathWildcard{}
+
 var idPathFormatter objpath.Formatter
 
 func init() {
     f := func( elt interface{}, apnd objpath.AppendFunc ) {
+        if elt == IdPathWildcard { 
+            apnd( "*" ) 
+            return
+        }
         apnd( elt.( *Identifier ).ExternalForm() )
     }
     idPathFormatter = objpath.DotFormatter( f )
@@ -1215,6 +1652,51 @@ func FormatIdPath( p objpath.PathNode ) string {
     return objpath.Format( p, idPathFormatter )
 }
 
+type idPathEltVisitor struct { elts []interface{} }
+
+func ( v *idPathEltVisitor ) Descend( elt interface{} ) error {
+    v.elts = append( v.elts, elt )
+    return nil
+}
+
+func ( v *idPathEltVisitor ) List( idx uint64 ) error {
+    v.elts = append( v.elts, idx )
+    return nil
+}
+
+// Returns the elements of p from its root: an *Identifier or IdPathWildcard
+// for each field or map key, and a uint64 for each list index. The result is
+// empty if p is nil.
+func IdPathElements( p objpath.PathNode ) []interface{} {
+    v := &idPathEltVisitor{ elts: make( []interface{}, 0, 4 ) }
+    if p != nil { objpath.Visit( p, v ) }
+    return v.elts
+}
+
+// Returns true if act, an element of some path as returned by
+// IdPathElements(), is matched by the pattern element pat, in which
+// IdPathWildcard matches any single field, map key, or list index
+func IdPathElementMatches( pat, act interface{} ) bool {
+    if pat == IdPathWildcard { return true }
+    switch v := pat.( type ) {
+    case *Identifier:
+        if id, ok := act.( *Identifier ); ok { return v.Equals( id ) }
+    case uint64:
+        if idx, ok := act.( uint64 ); ok { return v == idx }
+    }
+    return false
+}
+
+// Returns true if the path elements act are matched element for element by
+// the pattern elements pat
+func IdPathMatches( pat, act []interface{} ) bool {
+    if len( pat ) != len( act ) { return false }
+    for i, elt := range act {
+        if ! IdPathElementMatches( pat[ i ], elt ) { return false }
+    }
+    return true
+}
+
 var (
     QnameBoolean *QualifiedTypeName
     TypeBoolean *AtomicTypeReference
@@ -1234,8 +1716,22 @@ var (
     TypeFloat32 *AtomicTypeReference
     QnameFloat64 *QualifiedTypeName
     TypeFloat64 *AtomicTypeReference
+    QnameBigInt *QualifiedTypeName
+    TypeBigInt *AtomicTypeReference
+    QnameDecimal *QualifiedTypeName
+    TypeDecimal *AtomicTypeReference
     QnameTimestamp *QualifiedTypeName
     TypeTimestamp *AtomicTypeReference
+    QnameDate *QualifiedTypeName
+    TypeDate *AtomicTypeReference
+    QnameLocalTime *QualifiedTypeName
+    TypeLocalTime *AtomicTypeReference
+    QnameDuration *QualifiedTypeName
+    TypeDuration *AtomicTypeReference
+    QnameUuid *QualifiedTypeName
+    TypeUuid *AtomicTypeReference
+    QnameUri *QualifiedTypeName
+    TypeUri *AtomicTypeReference
     QnameSymbolMap *QualifiedTypeName
     TypeSymbolMap *AtomicTypeReference
     QnameNull *QualifiedTypeName
@@ -1260,10 +1756,14 @@ var (
     TypeRangeRestriction *AtomicTypeReference
     QnameRegexRestriction *QualifiedTypeName
     TypeRegexRestriction *AtomicTypeReference
+    QnameLengthRestriction *QualifiedTypeName
+    TypeLengthRestriction *AtomicTypeReference
     QnameValueRestriction *QualifiedTypeName
     TypeValueRestriction *AtomicTypeReference
     QnameListTypeReference *QualifiedTypeName
     TypeListTypeReference *AtomicTypeReference
+    QnameMapTypeReference *QualifiedTypeName
+    TypeMapTypeReference *AtomicTypeReference
     QnameNullableTypeReference *QualifiedTypeName
     TypeNullableTypeReference *AtomicTypeReference
     QnamePointerTypeReference *QualifiedTypeName
@@ -1314,7 +1814,14 @@ func init() {
     QnameUint64, TypeUint64 = f1( "Uint64" )
     QnameFloat32, TypeFloat32 = f1( "Float32" )
     QnameFloat64, TypeFloat64 = f1( "Float64" )
+    QnameBigInt, TypeBigInt = f1( "BigInt" )
+    QnameDecimal, TypeDecimal = f1( "Decimal" )
     QnameTimestamp, TypeTimestamp = f1( "Timestamp" )
+    QnameDate, TypeDate = f1( "Date" )
+    QnameLocalTime, TypeLocalTime = f1( "LocalTime" )
+    QnameDuration, TypeDuration = f1( "Duration" )
+    QnameUuid, TypeUuid = f1( "Uuid" )
+    QnameUri, TypeUri = f1( "Uri" )
     QnameValue, TypeValue = f1( "Value" )
     QnameSymbolMap, TypeSymbolMap = f1( "SymbolMap" )
     QnameNull, TypeNull = f1( "Null" )
@@ -1327,13 +1834,23 @@ func init() {
         TypeInt32,
         TypeUint32,
         TypeUint64,
+        TypeBigInt,
+        TypeDecimal,
         TypeBoolean,
         TypeTimestamp,
+        TypeDate,
+        TypeLocalTime,
+        TypeDuration,
+        TypeUuid,
+        TypeUri,
         TypeBuffer,
         TypeSymbolMap,
     }
     TypeNullableValue = &NullableTypeReference{ TypeValue }
-    TypeOpaqueList = &ListTypeReference{ TypeNullableValue, true }
+    TypeOpaqueList = &ListTypeReference{ 
+        ElementType: TypeNullableValue, 
+        AllowsEmpty: true,
+    }
     NumericTypeNames = []*QualifiedTypeName{
         QnameInt32,
         QnameInt64,
@@ -1341,16 +1858,23 @@ func init() {
         QnameUint64,
         QnameFloat32,
         QnameFloat64,
+        QnameBigInt,
+        QnameDecimal,
     }
     restrictableRangeTypeNames = []*QualifiedTypeName{
         QnameString,
         QnameTimestamp,
+        QnameDate,
+        QnameLocalTime,
+        QnameDuration,
         QnameInt32,
         QnameUint32,
         QnameInt64,
         QnameUint64,
         QnameFloat32,
         QnameFloat64,
+        QnameBigInt,
+        QnameDecimal,
     }
     QnameIdentifier, TypeIdentifier = f1( "Identifier" )
     QnameIdentifierPart, TypeIdentifierPart = f1( "IdentifierPart" )
@@ -1361,8 +1885,10 @@ func init() {
         f1( "AtomicTypeReference" )
     QnameRangeRestriction, TypeRangeRestriction = f1( "RangeRestriction" )
     QnameRegexRestriction, TypeRegexRestriction = f1( "RegexRestriction" )
+    QnameLengthRestriction, TypeLengthRestriction = f1( "LengthRestriction" )
     QnameValueRestriction, TypeValueRestriction = f1( "ValueRestriction" )
     QnameListTypeReference, TypeListTypeReference = f1( "ListTypeReference" )
+    QnameMapTypeReference, TypeMapTypeReference = f1( "MapTypeReference" )
     QnameNullableTypeReference, TypeNullableTypeReference = 
         f1( "NullableTypeReference" )
     QnamePointerTypeReference, TypePointerTypeReference = 
@@ -1386,7 +1912,8 @@ func IsIntegerTypeName( qn *QualifiedTypeName ) bool {
     return qn.Equals( QnameInt32 ) || 
            qn.Equals( QnameInt64 ) ||
            qn.Equals( QnameUint32 ) ||
-           qn.Equals( QnameUint64 )
+           qn.Equals( QnameUint64 ) ||
+           qn.Equals( QnameBigInt )
 }
 
 func IsNumericTypeName( qn *QualifiedTypeName ) bool {
@@ -1405,7 +1932,14 @@ func TypeOf( mgVal Value ) TypeReference {
     case Uint64: return TypeUint64
     case Float32: return TypeFloat32
     case Float64: return TypeFloat64
+    case BigInt: return TypeBigInt
+    case Decimal: return TypeDecimal
     case Timestamp: return TypeTimestamp
+    case Date: return TypeDate
+    case LocalTime: return TypeLocalTime
+    case Duration: return TypeDuration
+    case Uuid: return TypeUuid
+    case Uri: return TypeUri
     case *Enum: return v.Type.AsAtomicType()
     case *SymbolMap: return TypeSymbolMap
     case *Struct: return v.Type.AsAtomicType()
@@ -1430,6 +1964,16 @@ func canAssignAtomic(
     return false
 }
 
+func canAssignMap( 
+    m *SymbolMap, typ *MapTypeReference, useRestriction bool ) bool {
+
+    res := true
+    m.EachPair( func( _ *Identifier, val Value ) {
+        res = res && CanAssign( val, typ.ValueType, useRestriction )
+    })
+    return res
+}
+
 func CanAssign( val Value, typ TypeReference, useRestriction bool ) bool {
     switch t := typ.( type ) {
     case *AtomicTypeReference: return canAssignAtomic( val, t, useRestriction )
@@ -1439,6 +1983,10 @@ func CanAssign( val Value, typ TypeReference, useRestriction bool ) bool {
         return CanAssign( val, t.Type, useRestriction )
     case *ListTypeReference:
         if l, ok := val.( *List ); ok { return t.Equals( l.Type ) }
+    case *MapTypeReference:
+        if m, ok := val.( *SymbolMap ); ok { 
+            return canAssignMap( m, t, useRestriction ) 
+        }
     default: panic( libErrorf( "unhandled type for assign: %T", typ ) )
     }
     return false
@@ -1479,6 +2027,12 @@ func canAssignListType( from TypeReference, to *ListTypeReference ) bool {
     return from.Equals( to )
 }
 
+// Maps are mutable as well, so for the same reason as with lists we require
+// that the value types match
+func canAssignMapType( from TypeReference, to *MapTypeReference ) bool {
+    return from.Equals( to )
+}
+
 func canAssignType( from, to TypeReference, relaxRestrictions bool ) bool {
     switch t := to.( type ) {
     case *AtomicTypeReference: 
@@ -1487,6 +2041,7 @@ func canAssignType( from, to TypeReference, relaxRestrictions bool ) bool {
         return canAssignNullableType( from, t, relaxRestrictions )
     case *PointerTypeReference: return canAssignPointerType( from, t )
     case *ListTypeReference: return canAssignListType( from, t )
+    case *MapTypeReference: return canAssignMapType( from, t )
     default: panic( libErrorf( "unhandled type: %T", to ) )
     }
     return false
@@ -1556,6 +2111,52 @@ func parseFloatNumber(
     panic( libErrorf( "unhandled num type: %s", numTyp ) )
 }
 
+func parseBigIntNumber( s string ) ( Value, error ) {
+    if i, ok := new( big.Int ).SetString( s, 10 ); ok { 
+        return BigInt{ i }, nil 
+    }
+    return nil, newNumberSyntaxError( QnameBigInt, s )
+}
+
+// bounds the scale accepted when parsing or reading a Decimal so that short
+// input such as "1e-999999999" can't be used to force huge expansions in later
+// arithmetic
+const maxParsedDecimalScale = 4096
+
+func isDigitString( s string ) bool {
+    for _, r := range s { if r < '0' || r > '9' { return false } }
+    return true
+}
+
+// accepts the same forms as strconv.ParseFloat, except for special values such
+// as "Inf" and "NaN"
+func parseDecimalNumber( s string ) ( Value, error ) {
+    mant, exp := s, int64( 0 )
+    if i := strings.IndexAny( s, "eE" ); i >= 0 {
+        var err error
+        if exp, err = strconv.ParseInt( s[ i + 1 : ], 10, 32 ); err != nil {
+            return nil, err
+        }
+        mant = s[ : i ]
+    }
+    whole, frac := mant, ""
+    if i := strings.IndexRune( mant, '.' ); i >= 0 {
+        whole, frac = mant[ : i ], mant[ i + 1 : ]
+    }
+    digs := strings.TrimLeft( whole, "+-" )
+    if len( whole ) - len( digs ) > 1 || 
+       len( digs ) + len( frac ) == 0 ||
+       ! ( isDigitString( digs ) && isDigitString( frac ) ) {
+        return nil, newNumberSyntaxError( QnameDecimal, s )
+    }
+    scale := int64( len( frac ) ) - exp
+    if scale > maxParsedDecimalScale || scale < -maxParsedDecimalScale {
+        return nil, newNumberRangeError( s )
+    }
+    unscaled, _ := new( big.Int ).SetString( whole + frac, 10 )
+    return Decimal{ unscaled, int32( scale ) }, nil
+}
+
 func asParseNumberError( qn *QualifiedTypeName, s string, err error ) error {
     ne, ok := err.( *strconv.NumError )
     if ! ok { return err }
@@ -1574,6 +2175,8 @@ func ParseNumber( s string, qn *QualifiedTypeName ) ( val Value, err error ) {
     case qn.Equals( QnameUint64 ): val, err = parseIntNumber( s, 64, qn )
     case qn.Equals( QnameFloat32 ): val, err = parseFloatNumber( s, 32, qn )
     case qn.Equals( QnameFloat64 ): val, err = parseFloatNumber( s, 64, qn )
+    case qn.Equals( QnameBigInt ): val, err = parseBigIntNumber( s )
+    case qn.Equals( QnameDecimal ): val, err = parseDecimalNumber( s )
     default: panic( libErrorf( "unhandled number type: %s", qn ) )
     }
     if err != nil { err = asParseNumberError( qn, s, err ) }
@@ -5,6 +5,8 @@ import (
     "bytes"
     "io"
     "time"
+    "math/big"
+    "net/url"
 //    "log"
     bgio "bitgirder/io"
 )
@@ -39,6 +41,20 @@ const (
     IoTypeCodeStruct = IoTypeCode( uint8( 0x18 ) )
     IoTypeCodeList = IoTypeCode( uint8( 0x19 ) )
     IoTypeCodeEnd = IoTypeCode( uint8( 0x1a ) )
+    IoTypeCodeLengthRestrict = IoTypeCode( uint8( 0x1b ) )
+    IoTypeCodeMapTyp = IoTypeCode( uint8( 0x1c ) )
+    IoTypeCodeBigInt = IoTypeCode( uint8( 0x1d ) )
+    IoTypeCodeDecimal = IoTypeCode( uint8( 0x1e ) )
+    IoTypeCodeDate = IoTypeCode( uint8( 0x1f ) )
+    IoTypeCodeLocalTime = IoTypeCode( uint8( 0x20 ) )
+    IoTypeCodeDuration = IoTypeCode( uint8( 0x21 ) )
+    IoTypeCodeUuid = IoTypeCode( uint8( 0x22 ) )
+    IoTypeCodeUri = IoTypeCode( uint8( 0x23 ) )
+
+    // a list type with a length restriction, which follows the fields of an
+    // IoTypeCodeListTyp, so that list types without one are written as they
+    // were before length restrictions were added
+    IoTypeCodeRestrictedListTyp = IoTypeCode( uint8( 0x24 ) )
 )
 
 type BinIoError struct { msg string }
@@ -136,6 +152,12 @@ func ( w *BinWriter ) writeEnum( en *Enum ) ( err error ) {
     return
 }
 
+// written as a sign flag followed by the big-endian bytes of the absolute value
+func ( w *BinWriter ) writeBigInt( i *big.Int ) error {
+    if err := w.WriteBool( i.Sign() < 0 ); err != nil { return err }
+    return w.WriteBuffer32( i.Bytes() )
+}
+
 func ( w *BinWriter ) WriteScalarValue( val Value ) error {
     switch v := val.( type ) {
     case nil: return w.WriteNull()
@@ -171,6 +193,15 @@ func ( w *BinWriter ) WriteScalarValue( val Value ) error {
             return err 
         }
         return w.WriteFloat64( float64( v ) )
+    case BigInt:
+        if err := w.WriteTypeCode( IoTypeCodeBigInt ); err != nil { return err }
+        return w.writeBigInt( v.bigInt() )
+    case Decimal:
+        if err := w.WriteTypeCode( IoTypeCodeDecimal ); err != nil { 
+            return err 
+        }
+        if err := w.writeBigInt( v.unscaledInt() ); err != nil { return err }
+        return w.WriteInt32( v.scale )
     case Timestamp:
         if err := w.WriteTypeCode( IoTypeCodeTimestamp ); err != nil { 
             return err 
@@ -179,6 +210,27 @@ func ( w *BinWriter ) WriteScalarValue( val Value ) error {
             return err
         }
         return w.WriteInt32( int32( time.Time( v ).Nanosecond() ) )
+    case Date:
+        if err := w.WriteTypeCode( IoTypeCodeDate ); err != nil { return err }
+        if err := w.WriteInt32( int32( v.Year ) ); err != nil { return err }
+        if err := w.WriteUint8( uint8( v.Month ) ); err != nil { return err }
+        return w.WriteUint8( uint8( v.Day ) )
+    case LocalTime:
+        if err := w.WriteTypeCode( IoTypeCodeLocalTime ); err != nil { 
+            return err 
+        }
+        return w.WriteInt64( v.NanoOfDay() )
+    case Duration:
+        if err := w.WriteTypeCode( IoTypeCodeDuration ); err != nil { 
+            return err 
+        }
+        return w.WriteInt64( int64( v ) )
+    case Uuid:
+        if err := w.WriteTypeCode( IoTypeCodeUuid ); err != nil { return err }
+        return w.WriteBin( [ 16 ]byte( v ) )
+    case Uri:
+        if err := w.WriteTypeCode( IoTypeCodeUri ); err != nil { return err }
+        return w.WriteUtf8( string( v ) )
     case *Enum: return w.writeEnum( v )
     }
     panic( libErrorf( "unhandled value: %T", val ) )
@@ -187,7 +239,7 @@ func ( w *BinWriter ) WriteScalarValue( val Value ) error {
 func ( w *BinWriter ) writeRangeValue( val Value ) error {
     switch val.( type ) {
     case nil, Null, String, Int32, Int64, Uint32, Uint64, Float32, Float64,
-         Timestamp:
+         BigInt, Decimal, Timestamp, Date, LocalTime, Duration:
         return w.WriteScalarValue( val )
     }
     panic( libErrorf( "unhandled range val: %T", val ) )
@@ -202,6 +254,15 @@ func ( w *BinWriter ) writeRangeRestriction(
     return w.writeBool( rr.MaxClosed() )
 }
 
+func ( w *BinWriter ) writeLengthRestriction( 
+    lr *LengthRestriction ) ( err error ) {
+    if err = w.WriteTypeCode( IoTypeCodeLengthRestrict ); err != nil { return }
+    if err = w.writeBool( lr.MinClosed() ); err != nil { return }
+    if err = w.writeRangeValue( lr.Min() ); err != nil { return }
+    if err = w.writeRangeValue( lr.Max() ); err != nil { return }
+    return w.writeBool( lr.MaxClosed() )
+}
+
 func ( w *BinWriter ) WriteAtomicTypeReference( 
     at *AtomicTypeReference ) ( err error ) {
 
@@ -211,6 +272,7 @@ func ( w *BinWriter ) WriteAtomicTypeReference(
     case nil: return w.WriteNull()
     case *RegexRestriction: return w.writeRegexRestriction( r )
     case *RangeRestriction: return w.writeRangeRestriction( r )
+    case *LengthRestriction: return w.writeLengthRestriction( r )
     default: panic( libErrorf( "unhandled restriction: %T", r ) )
     }
     return
@@ -218,9 +280,18 @@ func ( w *BinWriter ) WriteAtomicTypeReference(
 
 func ( w *BinWriter ) WriteListTypeReference( 
     lt *ListTypeReference ) ( err error ) {
-    if err = w.WriteTypeCode( IoTypeCodeListTyp ); err != nil { return }
+    tc := IoTypeCodeListTyp
+    if lt.Restriction != nil { tc = IoTypeCodeRestrictedListTyp }
+    if err = w.WriteTypeCode( tc ); err != nil { return }
     if err = w.WriteTypeReference( lt.ElementType ); err != nil { return }
-    return w.writeBool( lt.AllowsEmpty )
+    if err = w.writeBool( lt.AllowsEmpty ); err != nil { return }
+    if lt.Restriction == nil { return }
+    return w.writeLengthRestriction( lt.Restriction )
+}
+
+func ( w *BinWriter ) WriteMapTypeReference( mt *MapTypeReference ) error {
+    if err := w.WriteTypeCode( IoTypeCodeMapTyp ); err != nil { return err }
+    return w.WriteTypeReference( mt.ValueType )
 }
 
 func ( w *BinWriter ) WriteNullableTypeReference( 
@@ -240,6 +311,7 @@ func ( w *BinWriter ) WriteTypeReference( typ TypeReference ) error {
     switch v := typ.( type ) {
     case *AtomicTypeReference: return w.WriteAtomicTypeReference( v )
     case *ListTypeReference: return w.WriteListTypeReference( v )
+    case *MapTypeReference: return w.WriteMapTypeReference( v )
     case *NullableTypeReference: return w.WriteNullableTypeReference( v )
     case *PointerTypeReference: return w.WritePointerTypeReference( v )
     }
@@ -334,8 +406,9 @@ func ( r *BinReader ) ReadTypeCode() ( IoTypeCode, error ) {
 // non-nil error
 func ( r *BinReader ) PeekTypeCode() ( IoTypeCode, error ) {
     res, err := r.ReadTypeCode()
+    if err != nil { return 0, err }
     if err2 := r.ot.UnreadByte(); err2 != nil { panic( err2 ) }
-    return res, err
+    return res, nil
 }
 
 func ( r *BinReader ) ExpectTypeCode( expct IoTypeCode ) ( IoTypeCode, error ) {
@@ -425,6 +498,59 @@ func ( r *BinReader ) readEnum() ( en *Enum, err error ) {
     return
 }
 
+func ( r *BinReader ) readBigInt() ( *big.Int, error ) {
+    neg, err := r.ReadBool()
+    if err != nil { return nil, err }
+    buf, err := r.ReadBuffer32()
+    if err != nil { return nil, err }
+    res := new( big.Int ).SetBytes( buf )
+    if neg { res.Neg( res ) }
+    return res, nil
+}
+
+func ( r *BinReader ) readDecimal() ( Value, error ) {
+    unscaled, err := r.readBigInt()
+    if err != nil { return nil, err }
+    off := r.offset()
+    scale, err := r.ReadInt32()
+    if err != nil { return nil, err }
+    if scale > maxParsedDecimalScale || scale < -maxParsedDecimalScale {
+        msg := fmt.Sprintf( "Decimal scale out of range: %d", scale )
+        return nil, NewBinIoErrorOffset( off, msg )
+    }
+    return Decimal{ unscaled, scale }, nil
+}
+
+func ( r *BinReader ) readDate() ( d Date, err error ) {
+    off := r.offset()
+    var y int32
+    if y, err = r.ReadInt32(); err != nil { return }
+    var m, day uint8
+    if m, err = r.ReadUint8(); err != nil { return }
+    if day, err = r.ReadUint8(); err != nil { return }
+    if d, err = NewDate( int( y ), time.Month( m ), int( day ) ); err != nil {
+        err = NewBinIoErrorOffset( off, err.Error() )
+    }
+    return
+}
+
+func ( r *BinReader ) readLocalTime() ( LocalTime, error ) {
+    nanos, err := r.ReadInt64()
+    if err != nil { return LocalTime{}, err }
+    return LocalTimeOfNanos( nanos )
+}
+
+// the uri is parsed and canonicalized as by parser.ParseUri()
+func ( r *BinReader ) readUri() ( Uri, error ) {
+    off := r.offset()
+    s, err := r.ReadUtf8()
+    if err != nil { return "", err }
+    if u, err := url.Parse( s ); err == nil {
+        if res, err := UriOf( u ); err == nil { return res, nil }
+    }
+    return "", NewBinIoErrorOffset( off, fmt.Sprintf( "Invalid URI: %q", s ) )
+}
+
 // tc is already read when this is called
 func ( r *BinReader ) ReadScalarValue( tc IoTypeCode ) ( Value, error ) {
     switch tc {
@@ -467,6 +593,22 @@ func ( r *BinReader ) ReadScalarValue( tc IoTypeCode ) ( Value, error ) {
         if f, err := r.ReadFloat64(); err == nil { 
             return Float64( f ), nil
         } else { return nil, err }
+    case IoTypeCodeBigInt:
+        if i, err := r.readBigInt(); err == nil {
+            return BigInt{ i }, nil
+        } else { return nil, err }
+    case IoTypeCodeDecimal: return r.readDecimal()
+    case IoTypeCodeDate: return r.readDate()
+    case IoTypeCodeLocalTime: return r.readLocalTime()
+    case IoTypeCodeDuration:
+        if i, err := r.ReadInt64(); err == nil { 
+            return Duration( i ), nil
+        } else { return nil, err }
+    case IoTypeCodeUuid:
+        var u Uuid
+        if err := r.ReadBin( &u ); err != nil { return nil, err }
+        return u, nil
+    case IoTypeCodeUri: return r.readUri()
     case IoTypeCodeBool:
         if b, err := r.ReadBool(); err == nil { 
             return Boolean( b ), nil
@@ -490,7 +632,8 @@ func ( r *BinReader ) readRangeVal( bound string ) ( Value, error ) {
     switch tc {
     case IoTypeCodeString, IoTypeCodeTimestamp, IoTypeCodeInt32, 
          IoTypeCodeInt64, IoTypeCodeUint32, IoTypeCodeUint64, IoTypeCodeFloat32,
-         IoTypeCodeFloat64: 
+         IoTypeCodeFloat64, IoTypeCodeBigInt, IoTypeCodeDecimal,
+         IoTypeCodeDate, IoTypeCodeLocalTime, IoTypeCodeDuration: 
         return r.ReadScalarValue( tc )
     case IoTypeCodeNull: 
         if _, err := r.ReadScalarValue( tc ); err != nil { return nil, err }
@@ -511,6 +654,17 @@ func ( r *BinReader ) readRangeRestriction(
     return rb.Build()
 }
 
+// Note: type code is already read
+func ( r *BinReader ) readLengthRestriction() ( lr *LengthRestriction,
+                                                err error ) {
+    lb := &LengthRestrictionBuilder{}
+    if lb.MinClosed, err = r.readBool(); err != nil { return }
+    if lb.Min, err = r.readRangeVal( "min" ); err != nil { return }
+    if lb.Max, err = r.readRangeVal( "max" ); err != nil { return }
+    if lb.MaxClosed, err = r.readBool(); err != nil { return }
+    return lb.Build()
+}
+
 func ( r *BinReader ) readRestriction( 
     qn *QualifiedTypeName ) ( vr ValueRestriction, err error ) {
 
@@ -520,6 +674,7 @@ func ( r *BinReader ) readRestriction(
     case IoTypeCodeNull: return nil, nil
     case IoTypeCodeRegexRestrict: return r.readRegexRestriction()
     case IoTypeCodeRangeRestrict: return r.readRangeRestriction( qn )
+    case IoTypeCodeLengthRestrict: return r.readLengthRestriction()
     }
     err = fmt.Errorf( "mingle: Unrecognized restriction type code: 0x%02x", tc )
     return
@@ -546,13 +701,30 @@ func ( r *BinReader ) ReadAtomicTypeReference() ( at *AtomicTypeReference,
 
 func ( r *BinReader ) ReadListTypeReference() ( lt *ListTypeReference,
                                                err error ) {
-    if _, err = r.ExpectTypeCode( IoTypeCodeListTyp ); err != nil { return }
+    var tc IoTypeCode
+    if tc, err = r.ReadTypeCode(); err != nil { return }
+    if ! ( tc == IoTypeCodeListTyp || tc == IoTypeCodeRestrictedListTyp ) {
+        tmpl := "Expected type code 0x%02x but got 0x%02x"
+        return nil, r.IoErrorf( tmpl, IoTypeCodeListTyp, tc )
+    }
     lt = &ListTypeReference{}
     if lt.ElementType, err = r.ReadTypeReference(); err != nil { return }
     if lt.AllowsEmpty, err = r.readBool(); err != nil { return }
+    if tc == IoTypeCodeListTyp { return }
+    _, err = r.ExpectTypeCode( IoTypeCodeLengthRestrict )
+    if err == nil { lt.Restriction, err = r.readLengthRestriction() }
     return
 }
 
+func ( r *BinReader ) ReadMapTypeReference() ( 
+    mt *MapTypeReference, err error ) {
+
+    if _, err = r.ExpectTypeCode( IoTypeCodeMapTyp ); err != nil { return }
+    var typ TypeReference
+    if typ, err = r.ReadTypeReference(); err != nil { return }
+    return NewMapTypeReference( typ ), nil
+}
+
 func ( r *BinReader ) ReadNullableTypeReference() ( nt *NullableTypeReference,
                                                     err error ) {
     if _, err = r.ExpectTypeCode( IoTypeCodeNullableTyp ); err != nil { return }
@@ -576,7 +748,9 @@ func ( r *BinReader ) ReadTypeReference() ( typ TypeReference, err error ) {
     if tc, err = r.PeekTypeCode(); err != nil { return }
     switch tc {
     case IoTypeCodeAtomTyp: return r.ReadAtomicTypeReference()
-    case IoTypeCodeListTyp: return r.ReadListTypeReference()
+    case IoTypeCodeListTyp, IoTypeCodeRestrictedListTyp: 
+        return r.ReadListTypeReference()
+    case IoTypeCodeMapTyp: return r.ReadMapTypeReference()
     case IoTypeCodeNullableTyp: return r.ReadNullableTypeReference()
     case IoTypeCodePointerTyp: return r.ReadPointerTypeReference()
     }
@@ -0,0 +1,245 @@
+package diff
+
+import (
+    mg "mingle"
+    "mingle/parser"
+    "bitgirder/objpath"
+    "fmt"
+    "sort"
+)
+
+type OperationKind string
+
+const (
+    OperationAdd = OperationKind( "add" )
+    OperationRemove = OperationKind( "remove" )
+    OperationReplace = OperationKind( "replace" )
+)
+
+// A single change to a value. Path is nil for an operation on the root value,
+// and Value is nil for OperationRemove.
+//
+// An add at a field requires that the field be absent, and an add at a list
+// index inserts ahead of the element currently at that index (or appends if
+// the index is the length of the list). Remove and replace require that the
+// addressed field or element exist.
+type Operation struct {
+    Kind OperationKind
+    Path objpath.PathNode
+    Value mg.Value
+}
+
+func ( op *Operation ) String() string {
+    path := "<root>"
+    if op.Path != nil { path = mg.FormatIdPath( op.Path ) }
+    if op.Value == nil { return fmt.Sprintf( "%s %s", op.Kind, path ) }
+    return fmt.Sprintf( "%s %s %s", op.Kind, path, mg.QuoteValue( op.Value ) )
+}
+
+type differ struct { ops []*Operation }
+
+func ( d *differ ) add(
+    kind OperationKind, path objpath.PathNode, val mg.Value ) {
+
+    d.ops = append( d.ops, &Operation{ Kind: kind, Path: path, Value: val } )
+}
+
+func sortedKeys( m1, m2 *mg.SymbolMap ) []*mg.Identifier {
+    seen := make( map[ string ]*mg.Identifier )
+    f := func( fld *mg.Identifier, _ mg.Value ) {
+        seen[ fld.ExternalForm() ] = fld
+    }
+    m1.EachPair( f )
+    m2.EachPair( f )
+    strs := make( []string, 0, len( seen ) )
+    for s := range seen { strs = append( strs, s ) }
+    sort.Strings( strs )
+    res := make( []*mg.Identifier, len( strs ) )
+    for i, s := range strs { res[ i ] = seen[ s ] }
+    return res
+}
+
+func ( d *differ ) diffMaps( path objpath.PathNode, from, to *mg.SymbolMap ) {
+    for _, fld := range sortedKeys( from, to ) {
+        fldPath := objpath.Descend( path, fld )
+        fromVal, inFrom := from.GetOk( fld )
+        toVal, inTo := to.GetOk( fld )
+        switch {
+        case ! inTo: d.add( OperationRemove, fldPath, nil )
+        case ! inFrom: d.add( OperationAdd, fldPath, toVal )
+        default: d.diffValues( fldPath, fromVal, toVal )
+        }
+    }
+}
+
+// elements past the common length are removed from the end backwards or added
+// in order, so that each operation's index is valid at the time it is applied
+func ( d *differ ) diffLists( path objpath.PathNode, from, to *mg.List ) {
+    n := from.Len()
+    if to.Len() < n { n = to.Len() }
+    elt := func( i int ) objpath.PathNode {
+        return objpath.StartList( path ).SetIndex( uint64( i ) )
+    }
+    for i := 0; i < n; i++ { 
+        d.diffValues( elt( i ), from.Get( i ), to.Get( i ) ) 
+    }
+    for i := from.Len() - 1; i >= n; i-- { 
+        d.add( OperationRemove, elt( i ), nil ) 
+    }
+    for i := n; i < to.Len(); i++ { 
+        d.add( OperationAdd, elt( i ), to.Get( i ) ) 
+    }
+}
+
+func ( d *differ ) diffValues( path objpath.PathNode, from, to mg.Value ) {
+    if mg.EqualValues( from, to ) { return }
+    switch f := from.( type ) {
+    case *mg.Struct:
+        if t, ok := to.( *mg.Struct ); ok && f.Type.Equals( t.Type ) {
+            d.diffMaps( path, f.Fields, t.Fields )
+            return
+        }
+    case *mg.SymbolMap:
+        if t, ok := to.( *mg.SymbolMap ); ok {
+            d.diffMaps( path, f, t )
+            return
+        }
+    case *mg.List:
+        if t, ok := to.( *mg.List ); ok && f.Type.Equals( t.Type ) {
+            d.diffLists( path, f, t )
+            return
+        }
+    }
+    d.add( OperationReplace, path, to )
+}
+
+// Returns the operations which, when applied in order to from by Apply(), yield
+// a value equal to to. Structs of the same type, symbol maps, and lists of the
+// same type are compared member by member; any other pair of values which are
+// not equal according to mg.EqualValues() produces a single replace. Struct and
+// map fields are visited in order of their external form, so the result is
+// deterministic. The result is empty if from and to are equal.
+func Diff( from, to mg.Value ) []*Operation {
+    d := &differ{}
+    d.diffValues( nil, from, to )
+    return d.ops
+}
+
+var (
+    NsDiff *mg.Namespace
+    QnameAdd *mg.QualifiedTypeName
+    QnameRemove *mg.QualifiedTypeName
+    QnameReplace *mg.QualifiedTypeName
+    IdPath *mg.Identifier
+    IdValue *mg.Identifier
+)
+
+func init() {
+    mkId := func( parts ...string ) *mg.Identifier {
+        return mg.NewIdentifierUnsafe( parts )
+    }
+    NsDiff = &mg.Namespace{
+        Parts: []*mg.Identifier{ mkId( "mingle" ), mkId( "diff" ) },
+        Version: mkId( "v1" ),
+    }
+    mkQn := func( nm string ) *mg.QualifiedTypeName {
+        return mg.NewDeclaredTypeNameUnsafe( nm ).ResolveIn( NsDiff )
+    }
+    QnameAdd = mkQn( "Add" )
+    QnameRemove = mkQn( "Remove" )
+    QnameReplace = mkQn( "Replace" )
+    IdPath = mkId( "path" )
+    IdValue = mkId( "value" )
+}
+
+func qnameForKind( kind OperationKind ) *mg.QualifiedTypeName {
+    switch kind {
+    case OperationAdd: return QnameAdd
+    case OperationRemove: return QnameRemove
+    case OperationReplace: return QnameReplace
+    }
+    panic( libErrorf( "unhandled operation kind: %s", kind ) )
+}
+
+// Returns op as a struct of type QnameAdd, QnameRemove, or QnameReplace, with
+// the operation's path (absent for the root) as the formatted String field
+// 'path' and its value, if any, as field 'value'
+func OperationAsValue( op *Operation ) *mg.Struct {
+    flds := mg.NewSymbolMap()
+    if p := op.Path; p != nil { 
+        flds.Put( IdPath, mg.String( mg.FormatIdPath( p ) ) ) 
+    }
+    if op.Value != nil { flds.Put( IdValue, op.Value ) }
+    return &mg.Struct{ Type: qnameForKind( op.Kind ), Fields: flds }
+}
+
+func OperationsAsList( ops []*Operation ) *mg.List {
+    vals := make( []mg.Value, len( ops ) )
+    for i, op := range ops { vals[ i ] = OperationAsValue( op ) }
+    return mg.NewListValues( vals )
+}
+
+func operationKindOf( qn *mg.QualifiedTypeName ) ( OperationKind, bool ) {
+    switch {
+    case qn.Equals( QnameAdd ): return OperationAdd, true
+    case qn.Equals( QnameRemove ): return OperationRemove, true
+    case qn.Equals( QnameReplace ): return OperationReplace, true
+    }
+    return "", false
+}
+
+func operationFromValue( 
+    v mg.Value, path objpath.PathNode ) ( *Operation, error ) {
+
+    s, ok := v.( *mg.Struct )
+    if ! ok { 
+        tmpl := "not an operation: %s"
+        return nil, mg.NewInputErrorf( path, tmpl, mg.TypeOf( v ) ) 
+    }
+    res := &Operation{}
+    if res.Kind, ok = operationKindOf( s.Type ); ! ok {
+        tmpl := "unknown operation type: %s"
+        return nil, mg.NewInputErrorf( path, tmpl, s.Type )
+    }
+    if pv, ok := s.Fields.GetOk( IdPath ); ok {
+        fldPath := objpath.Descend( path, IdPath )
+        str, ok := pv.( mg.String )
+        if ! ok { 
+            tmpl := "invalid path: %s"
+            return nil, mg.NewInputErrorf( fldPath, tmpl, mg.TypeOf( pv ) ) 
+        }
+        var err error
+        res.Path, err = parser.ParseIdentifierPath( string( str ) )
+        if err != nil { 
+            tmpl := "invalid path %q: %s"
+            return nil, mg.NewInputErrorf( fldPath, tmpl, str, err ) 
+        }
+    }
+    res.Value, ok = s.Fields.GetOk( IdValue )
+    if ok == ( res.Kind == OperationRemove ) {
+        tmpl := "invalid value for %s operation"
+        return nil, mg.NewInputErrorf( path, tmpl, res.Kind )
+    }
+    return res, nil
+}
+
+// Inverse of OperationAsValue(). Errors resulting from a malformed operation
+// value are returned as *mg.InputError.
+func OperationFromValue( v mg.Value ) ( *Operation, error ) {
+    return operationFromValue( v, nil )
+}
+
+// Inverse of OperationsAsList(). Errors resulting from a malformed operation
+// value are returned as *mg.InputError located at the operation's list index.
+func OperationsFromList( l *mg.List ) ( []*Operation, error ) {
+    if l == nil { return nil, libError( "nil list" ) }
+    res := make( []*Operation, l.Len() )
+    lp := objpath.RootedAtList()
+    for i, v := range l.Values() {
+        op, err := operationFromValue( v, lp )
+        if err != nil { return nil, err }
+        res[ i ] = op
+        lp = lp.Next()
+    }
+    return res, nil
+}
@@ -0,0 +1,14 @@
+package diff
+
+import (
+    "fmt"
+    "errors"
+)
+
+func libError( msg string ) error {
+    return errors.New( "mingle/diff: " + msg )
+}
+
+func libErrorf( tmpl string, argv ...interface{} ) error {
+    return fmt.Errorf( "mingle/diff: " + tmpl, argv... )
+}
@@ -0,0 +1,142 @@
+package diff
+
+import (
+    mg "mingle"
+    "bitgirder/objpath"
+    "fmt"
+)
+
+type PatchError struct {
+    Path objpath.PathNode
+    Message string
+}
+
+func ( e *PatchError ) Error() string {
+    return mg.FormatError( e.Path, e.Message )
+}
+
+func NewPatchError( path objpath.PathNode, msg string ) *PatchError {
+    return &PatchError{ Path: path, Message: msg }
+}
+
+func NewPatchErrorf(
+    path objpath.PathNode, tmpl string, argv ...interface{} ) *PatchError {
+
+    return NewPatchError( path, fmt.Sprintf( tmpl, argv... ) )
+}
+
+type opApplier struct {
+    op *Operation
+    elts []interface{}
+}
+
+func ( a *opApplier ) isLast( i int ) bool { return i == len( a.elts ) - 1 }
+
+func copyMap( m *mg.SymbolMap, omit *mg.Identifier ) *mg.SymbolMap {
+    res := mg.NewSymbolMap()
+    m.EachPair( func( fld *mg.Identifier, val mg.Value ) {
+        if omit == nil || ! fld.Equals( omit ) { res.Put( fld, val ) }
+    })
+    return res
+}
+
+func ( a *opApplier ) applyMap(
+    m *mg.SymbolMap, i int, path objpath.PathNode ) ( *mg.SymbolMap, error ) {
+
+    fld, ok := a.elts[ i ].( *mg.Identifier )
+    if ! ok {
+        return nil, NewPatchError( path, "expected a field name in path" )
+    }
+    fldPath := objpath.Descend( path, fld )
+    cur, has := m.GetOk( fld )
+    switch {
+    case has && a.isLast( i ) && a.op.Kind == OperationAdd:
+        return nil, NewPatchError( fldPath, "field already present" )
+    case ! has && ! ( a.isLast( i ) && a.op.Kind == OperationAdd ):
+        return nil, NewPatchError( fldPath, "no such field" )
+    case a.isLast( i ) && a.op.Kind == OperationRemove:
+        return copyMap( m, fld ), nil
+    }
+    val := a.op.Value
+    if ! a.isLast( i ) {
+        var err error
+        if val, err = a.apply( cur, i + 1, fldPath ); err != nil {
+            return nil, err
+        }
+    }
+    res := copyMap( m, nil )
+    res.Put( fld, val )
+    return res, nil
+}
+
+func ( a *opApplier ) applyList(
+    l *mg.List, i int, path objpath.PathNode ) ( *mg.List, error ) {
+
+    idx, ok := a.elts[ i ].( uint64 )
+    if ! ok {
+        return nil, NewPatchError( path, "expected a list index in path" )
+    }
+    eltPath := objpath.StartList( path ).SetIndex( idx )
+    vals := l.Values()
+    lim := uint64( len( vals ) )
+    if a.isLast( i ) && a.op.Kind == OperationAdd { lim++ }
+    if idx >= lim {
+        return nil, NewPatchError( eltPath, "list index out of range" )
+    }
+    res := mg.NewList( l.Type )
+    for _, v := range vals[ : idx ] { res.AddUnsafe( v ) }
+    switch {
+    case ! a.isLast( i ):
+        val, err := a.apply( vals[ idx ], i + 1, eltPath )
+        if err != nil { return nil, err }
+        res.AddUnsafe( val )
+        idx++
+    case a.op.Kind == OperationAdd: res.AddUnsafe( a.op.Value )
+    case a.op.Kind == OperationReplace:
+        res.AddUnsafe( a.op.Value )
+        idx++
+    case a.op.Kind == OperationRemove: idx++
+    }
+    for _, v := range vals[ idx : ] { res.AddUnsafe( v ) }
+    return res, nil
+}
+
+// i is the index into a.elts of the element which selects from v, the value at
+// path
+func ( a *opApplier ) apply(
+    v mg.Value, i int, path objpath.PathNode ) ( mg.Value, error ) {
+
+    switch c := v.( type ) {
+    case *mg.Struct:
+        flds, err := a.applyMap( c.Fields, i, path )
+        if err != nil { return nil, err }
+        return &mg.Struct{ Type: c.Type, Fields: flds }, nil
+    case *mg.SymbolMap: return a.applyMap( c, i, path )
+    case *mg.List: return a.applyList( c, i, path )
+    }
+    return nil, NewPatchErrorf( path, "cannot apply %s to member of %s",
+        a.op.Kind, mg.TypeOf( v ) )
+}
+
+func applyOp( v mg.Value, op *Operation ) ( mg.Value, error ) {
+    if op.Path == nil {
+        if op.Kind == OperationRemove {
+            return nil, NewPatchError( nil, "cannot remove root value" )
+        }
+        return op.Value, nil
+    }
+    elts := mg.IdPathElements( op.Path )
+    return ( &opApplier{ op: op, elts: elts } ).apply( v, 0, nil )
+}
+
+// Returns the result of applying ops in order to v. Values along the path of
+// each operation are copied as needed; v itself is not modified. The returned
+// error will be a *PatchError if some operation does not fit the value it is
+// applied to.
+func Apply( v mg.Value, ops []*Operation ) ( mg.Value, error ) {
+    for _, op := range ops {
+        var err error
+        if v, err = applyOp( v, op ); err != nil { return nil, err }
+    }
+    return v, nil
+}
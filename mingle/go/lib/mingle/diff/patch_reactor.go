@@ -0,0 +1,254 @@
+package diff
+
+import (
+    mg "mingle"
+    mgRct "mingle/reactor"
+    "bitgirder/objpath"
+    "bitgirder/pipeline"
+    "bitgirder/stack"
+    "sort"
+)
+
+func pathKey( p objpath.PathNode ) string {
+    if p == nil { return "" }
+    return mg.FormatIdPath( p )
+}
+
+func lastPathElt( p objpath.PathNode ) interface{} {
+    elts := mg.IdPathElements( p )
+    return elts[ len( elts ) - 1 ]
+}
+
+type patchContainer struct {
+    list bool
+    ops []*Operation // all ops on direct children of this container, in order
+    targets map[ string ]*Operation // ops not yet matched to a child, by path
+    adds []*Operation // for lists, ordered by index
+    next uint64 // index of the next list element in the source
+}
+
+func ( c *patchContainer ) take( p objpath.PathNode ) *Operation {
+    k := pathKey( p )
+    if op, ok := c.targets[ k ]; ok {
+        delete( c.targets, k )
+        return op
+    }
+    return nil
+}
+
+type listAddSort []*Operation
+
+func ( s listAddSort ) Len() int { return len( s ) }
+
+func ( s listAddSort ) Less( i, j int ) bool {
+    return lastPathElt( s[ i ].Path ).( uint64 ) <
+        lastPathElt( s[ j ].Path ).( uint64 )
+}
+
+func ( s listAddSort ) Swap( i, j int ) { s[ i ], s[ j ] = s[ j ], s[ i ] }
+
+// Applies a set of operations to an event stream as it passes through, dropping
+// the source events for removed and replaced values and inserting the events
+// for added and replacement values, so that only the values carried by the
+// operations themselves are ever held in memory.
+//
+// Unlike Apply(), which applies operations in order, a PatchReactor treats
+// every operation path as a location in the source stream, with an add to a
+// list inserting its value ahead of the source element at its index, or at the
+// end of the list if there is no such element. Operation paths must not lie
+// beneath one another. Operations produced by Diff() meet these requirements
+// and give the same result either way.
+type PatchReactor struct {
+    ops []*Operation
+    root *Operation
+    children map[ string ][]*Operation // unreached ops, by parent path
+    stack *stack.Stack
+    skip *mgRct.DepthTracker
+}
+
+func NewPatchReactor( ops []*Operation ) *PatchReactor {
+    res := &PatchReactor{
+        ops: ops,
+        children: make( map[ string ][]*Operation ),
+        stack: stack.NewStack(),
+    }
+    for _, op := range ops {
+        if op.Path == nil {
+            res.root = op
+            continue
+        }
+        k := pathKey( objpath.ParentOf( op.Path ) )
+        res.children[ k ] = append( res.children[ k ], op )
+    }
+    return res
+}
+
+func ( r *PatchReactor ) InitializePipeline( pip *pipeline.Pipeline ) {
+    mgRct.EnsurePathSettingProcessor( pip )
+}
+
+func ( r *PatchReactor ) startSkip( ev mgRct.Event ) {
+    r.skip = mgRct.NewDepthTracker()
+    if ev != nil { r.skipEvent( ev ) }
+}
+
+func ( r *PatchReactor ) skipEvent( ev mgRct.Event ) {
+    r.skip.ProcessEvent( ev )
+    if r.skip.Depth() == 0 { r.skip = nil }
+}
+
+func ( r *PatchReactor ) pushContainer( ev mgRct.Event ) error {
+    c := &patchContainer{ targets: make( map[ string ]*Operation ) }
+    _, c.list = ev.( *mgRct.ListStartEvent )
+    k := pathKey( ev.GetPath() )
+    c.ops = r.children[ k ]
+    delete( r.children, k )
+    for _, op := range c.ops {
+        if _, ok := op.Path.( *objpath.ListNode ); ok != c.list {
+            msg := "expected a field name in path"
+            if c.list { msg = "expected a list index in path" }
+            return NewPatchError( objpath.CopyOf( ev.GetPath() ), msg )
+        }
+        if c.list && op.Kind == OperationAdd {
+            c.adds = append( c.adds, op )
+            continue
+        }
+        c.targets[ pathKey( op.Path ) ] = op
+        if op.Kind == OperationAdd { c.adds = append( c.adds, op ) }
+    }
+    if c.list { sort.Stable( listAddSort( c.adds ) ) }
+    r.stack.Push( c )
+    return nil
+}
+
+func ( r *PatchReactor ) emitListAdds(
+    c *patchContainer, all bool, rep mgRct.EventProcessor ) error {
+
+    for len( c.adds ) > 0 {
+        op := c.adds[ 0 ]
+        if ! all && lastPathElt( op.Path ).( uint64 ) > c.next { break }
+        err := mgRct.VisitValuePath( op.Value, rep, op.Path )
+        if err != nil { return err }
+        c.adds = c.adds[ 1 : ]
+    }
+    return nil
+}
+
+func ( r *PatchReactor ) emitFieldAdds(
+    c *patchContainer, rep mgRct.EventProcessor ) error {
+
+    for _, op := range c.adds {
+        fld := lastPathElt( op.Path ).( *mg.Identifier )
+        fs := mgRct.NewFieldStartEvent( fld )
+        fs.SetPath( op.Path )
+        if err := rep.ProcessEvent( fs ); err != nil { return err }
+        err := mgRct.VisitValuePath( op.Value, rep, op.Path )
+        if err != nil { return err }
+    }
+    return nil
+}
+
+func ( r *PatchReactor ) processValueStart(
+    ev mgRct.Event, rep mgRct.EventProcessor ) error {
+
+    var op *Operation
+    if r.stack.IsEmpty() {
+        op = r.root
+    } else if c := r.stack.Peek().( *patchContainer ); c.list {
+        if err := r.emitListAdds( c, false, rep ); err != nil { return err }
+        op = c.take( ev.GetPath() )
+        c.next++
+    }
+    if op == nil {
+        switch ev.( type ) {
+        case *mgRct.ListStartEvent, *mgRct.MapStartEvent,
+             *mgRct.StructStartEvent:
+            if err := r.pushContainer( ev ); err != nil { return err }
+        }
+        return rep.ProcessEvent( ev )
+    }
+    if op.Kind == OperationRemove {
+        if r.stack.IsEmpty() {
+            return NewPatchError( nil, "cannot remove root value" )
+        }
+    } else {
+        err := mgRct.VisitValuePath( op.Value, rep, ev.GetPath() )
+        if err != nil { return err }
+    }
+    r.startSkip( ev )
+    return nil
+}
+
+func ( r *PatchReactor ) processFieldStart(
+    fs *mgRct.FieldStartEvent, rep mgRct.EventProcessor ) error {
+
+    op := r.stack.Peek().( *patchContainer ).take( fs.GetPath() )
+    if op == nil { return rep.ProcessEvent( fs ) }
+    switch op.Kind {
+    case OperationAdd:
+        path := objpath.CopyOf( fs.GetPath() )
+        return NewPatchError( path, "field already present" )
+    case OperationReplace:
+        if err := rep.ProcessEvent( fs ); err != nil { return err }
+        err := mgRct.VisitValuePath( op.Value, rep, fs.GetPath() )
+        if err != nil { return err }
+    }
+    r.startSkip( nil ) // skips the field's value
+    return nil
+}
+
+func ( r *PatchReactor ) processEnd(
+    ev *mgRct.EndEvent, rep mgRct.EventProcessor ) error {
+
+    c := r.stack.Pop().( *patchContainer )
+    for _, op := range c.ops {
+        if _, ok := c.targets[ pathKey( op.Path ) ]; ! ok { continue }
+        switch {
+        case op.Kind == OperationAdd: continue
+        case c.list: return NewPatchError( op.Path, "list index out of range" )
+        default: return NewPatchError( op.Path, "no such field" )
+        }
+    }
+    var err error
+    if c.list {
+        err = r.emitListAdds( c, true, rep )
+    } else {
+        err = r.emitFieldAdds( c, rep )
+    }
+    if err != nil { return err }
+    return rep.ProcessEvent( ev )
+}
+
+func ( r *PatchReactor ) processEvent(
+    ev mgRct.Event, rep mgRct.EventProcessor ) error {
+
+    if r.skip != nil {
+        r.skipEvent( ev )
+        return nil
+    }
+    switch v := ev.( type ) {
+    case *mgRct.FieldStartEvent: return r.processFieldStart( v, rep )
+    case *mgRct.EndEvent: return r.processEnd( v, rep )
+    }
+    return r.processValueStart( ev, rep )
+}
+
+// called once the root value has been processed to fail if some operation was
+// never reached
+func ( r *PatchReactor ) checkComplete() error {
+    for _, op := range r.ops {
+        if op.Path == nil { continue }
+        if _, ok := r.children[ pathKey( objpath.ParentOf( op.Path ) ) ]; ok {
+            return NewPatchError( op.Path, "no value at path" )
+        }
+    }
+    return nil
+}
+
+func ( r *PatchReactor ) ProcessEvent(
+    ev mgRct.Event, rep mgRct.EventProcessor ) error {
+
+    if err := r.processEvent( ev, rep ); err != nil { return err }
+    if r.stack.IsEmpty() && r.skip == nil { return r.checkComplete() }
+    return nil
+}
@@ -142,7 +142,10 @@ func ( r *BinReader ) implReadValue( rep mgRct.EventProcessor ) error {
     case mg.IoTypeCodeNull, mg.IoTypeCodeString, mg.IoTypeCodeBuffer, 
          mg.IoTypeCodeTimestamp, mg.IoTypeCodeInt32, mg.IoTypeCodeInt64, 
          mg.IoTypeCodeUint32, mg.IoTypeCodeUint64, mg.IoTypeCodeFloat32,
-         mg.IoTypeCodeFloat64, mg.IoTypeCodeBool, mg.IoTypeCodeEnum:
+         mg.IoTypeCodeFloat64, mg.IoTypeCodeBool, mg.IoTypeCodeEnum,
+         mg.IoTypeCodeBigInt, mg.IoTypeCodeDecimal, mg.IoTypeCodeDate,
+         mg.IoTypeCodeLocalTime, mg.IoTypeCodeDuration, mg.IoTypeCodeUuid,
+         mg.IoTypeCodeUri:
         return r.readScalarValue( tc, rep )
     case mg.IoTypeCodeSymMap: return r.readSymbolMap( rep )
     case mg.IoTypeCodeStruct: return r.readStruct( rep )
@@ -0,0 +1,36 @@
+package io
+
+import (
+    mgRct "mingle/reactor"
+    "io"
+)
+
+// Records the events it processes in their binary encoding to an underlying
+// writer, such as a bytes.Buffer or a file, for later replay by Replay(). The
+// events must form a sequence of complete values; paths are not recorded, but
+// will be set again by any PathSettingProcessor the events are replayed to.
+type Recorder struct {
+    rct mgRct.EventProcessor
+}
+
+func NewRecorder( w io.Writer ) *Recorder {
+    return &Recorder{ rct: NewWriter( w ).AsReactor() }
+}
+
+func ( r *Recorder ) ProcessEvent( ev mgRct.Event ) error {
+    return r.rct.ProcessEvent( ev )
+}
+
+// Sends the events of each value recorded to r by a Recorder to rep, returning
+// when r is exhausted or at the first error
+func Replay( r io.Reader, rep mgRct.EventProcessor ) error {
+    rd := NewReader( r )
+    for {
+        if _, err := rd.PeekTypeCode(); err != nil {
+            if err == io.EOF { return nil }
+            return err
+        }
+        if err := rd.ReadReactorValue( rep ); err != nil { return err }
+    }
+    panic( libErrorf( "unreachable" ) )
+}
@@ -0,0 +1,14 @@
+package merge
+
+import (
+    "fmt"
+    "errors"
+)
+
+func libError( msg string ) error {
+    return errors.New( "mingle/merge: " + msg )
+}
+
+func libErrorf( tmpl string, argv ...interface{} ) error {
+    return fmt.Errorf( "mingle/merge: " + tmpl, argv... )
+}
@@ -0,0 +1,262 @@
+package merge
+
+import (
+    mg "mingle"
+    mgRct "mingle/reactor"
+    "bitgirder/objpath"
+    "fmt"
+)
+
+type MergeError struct {
+    Path objpath.PathNode
+    Message string
+}
+
+func ( e *MergeError ) Error() string {
+    return mg.FormatError( e.Path, e.Message )
+}
+
+func NewMergeError( path objpath.PathNode, msg string ) *MergeError {
+    return &MergeError{ Path: path, Message: msg }
+}
+
+func NewMergeErrorf(
+    path objpath.PathNode, tmpl string, argv ...interface{} ) *MergeError {
+
+    return NewMergeError( path, fmt.Sprintf( tmpl, argv... ) )
+}
+
+// How a list in one layer is combined with the list at the same path in the
+// layers beneath it
+type ListStrategy int
+
+const (
+
+    // the list in the higher layer is used as-is
+    ListStrategyReplace = ListStrategy( iota )
+
+    // the elements of the list in the higher layer follow those beneath it
+    ListStrategyAppend
+
+    // elements are maps or structs identified by a key field; an element of
+    // the higher list is merged into the element beneath it with an equal key,
+    // if any, and otherwise follows the elements beneath it
+    ListStrategyMergeByKey
+)
+
+type listRule struct {
+    path []interface{}
+    strategy ListStrategy
+    key *mg.Identifier
+}
+
+// Combines layers of values, such as the layers of a configuration, into a
+// single value. Layers are given in order of increasing priority, so that each
+// layer overrides those before it.
+//
+// Maps are merged field by field, as are structs of the same type. Lists are
+// combined according to the strategy set for their path with
+// SetListStrategy(), or according to ListStrategy and ListKey if none is set.
+// Any other value replaces the value beneath it if both have the same type. A
+// null value replaces any value beneath it, and any value replaces a null
+// beneath it.
+//
+// Values of any other differing types conflict, failing the merge with a
+// *MergeError located at the conflict.
+type Merger struct {
+    ListStrategy ListStrategy
+    ListKey *mg.Identifier // for ListStrategyMergeByKey
+    rules []*listRule
+}
+
+func NewMerger() *Merger { return &Merger{} }
+
+// Sets the strategy for lists at path, which may contain mg.IdPathWildcard
+// elements matching any single field, map key, or list index. Strategies set
+// later take precedence over those set earlier for paths matching both. Key
+// is ignored unless strategy is ListStrategyMergeByKey.
+func ( m *Merger ) SetListStrategy(
+    path objpath.PathNode, strategy ListStrategy, key *mg.Identifier ) {
+
+    rule := &listRule{
+        path: mg.IdPathElements( path ),
+        strategy: strategy,
+        key: key,
+    }
+    m.rules = append( m.rules, rule )
+}
+
+func ( m *Merger ) listStrategyFor(
+    path objpath.PathNode ) ( ListStrategy, *mg.Identifier ) {
+
+    elts := mg.IdPathElements( path )
+    for i := len( m.rules ) - 1; i >= 0; i-- {
+        r := m.rules[ i ]
+        if mg.IdPathMatches( r.path, elts ) { return r.strategy, r.key }
+    }
+    return m.ListStrategy, m.ListKey
+}
+
+type layerMerge struct {
+    m *Merger
+    layer int
+}
+
+func ( lm *layerMerge ) conflict(
+    path objpath.PathNode, lo, hi mg.Value ) error {
+
+    return NewMergeErrorf( path, "layer %d has %s where lower layers have %s",
+        lm.layer, mg.TypeOf( hi ), mg.TypeOf( lo ) )
+}
+
+func ( lm *layerMerge ) mergeFields(
+    path objpath.PathNode, lo, hi *mg.SymbolMap ) ( *mg.SymbolMap, error ) {
+
+    res := mg.NewSymbolMap()
+    lo.EachPair( func( fld *mg.Identifier, val mg.Value ) {
+        res.Put( fld, val )
+    })
+    for _, fld := range mg.SortIds( hi.GetKeys() ) {
+        val := hi.Get( fld )
+        if loVal, ok := lo.GetOk( fld ); ok {
+            var err error
+            val, err = lm.merge( objpath.Descend( path, fld ), loVal, val )
+            if err != nil { return nil, err }
+        }
+        res.Put( fld, val )
+    }
+    return res, nil
+}
+
+func ( lm *layerMerge ) keyOf(
+    path objpath.PathNode,
+    val mg.Value,
+    key *mg.Identifier ) ( mg.Value, error ) {
+
+    var flds *mg.SymbolMap
+    switch v := val.( type ) {
+    case *mg.SymbolMap: flds = v
+    case *mg.Struct: flds = v.Fields
+    default:
+        tmpl := "cannot merge by key list element of type %s"
+        return nil, NewMergeErrorf( path, tmpl, mg.TypeOf( val ) )
+    }
+    if res, ok := flds.GetOk( key ); ok { return res, nil }
+    return nil, NewMergeErrorf( path, "list element has no key field %s", key )
+}
+
+func eltPath( path objpath.PathNode, i int ) objpath.PathNode {
+    return objpath.StartList( path ).SetIndex( uint64( i ) )
+}
+
+func ( lm *layerMerge ) mergeByKey(
+    path objpath.PathNode,
+    lo, hi *mg.List,
+    key *mg.Identifier ) ( []mg.Value, error ) {
+
+    if key == nil {
+        return nil, NewMergeError( path, "no key field set for list" )
+    }
+    res := append( []mg.Value{}, lo.Values()... )
+    keys := make( []mg.Value, len( res ) )
+    for i, val := range res {
+        k, err := lm.keyOf( eltPath( path, i ), val, key )
+        if err != nil { return nil, err }
+        keys[ i ] = k
+    }
+    LOOP: for j, val := range hi.Values() {
+        k, err := lm.keyOf( eltPath( path, j ), val, key )
+        if err != nil { return nil, err }
+        for i, k2 := range keys {
+            if ! mg.EqualValues( k, k2 ) { continue }
+            res[ i ], err = lm.merge( eltPath( path, i ), res[ i ], val )
+            if err != nil { return nil, err }
+            continue LOOP
+        }
+        res = append( res, val )
+        keys = append( keys, k )
+    }
+    return res, nil
+}
+
+func ( lm *layerMerge ) mergeLists(
+    path objpath.PathNode, lo, hi *mg.List ) ( mg.Value, error ) {
+
+    strategy, key := lm.m.listStrategyFor( path )
+    if strategy == ListStrategyReplace { return hi, nil }
+    if ! lo.Type.Equals( hi.Type ) { return nil, lm.conflict( path, lo, hi ) }
+    var vals []mg.Value
+    switch strategy {
+    case ListStrategyAppend:
+        vals = append( append( vals, lo.Values()... ), hi.Values()... )
+    case ListStrategyMergeByKey:
+        var err error
+        if vals, err = lm.mergeByKey( path, lo, hi, key ); err != nil {
+            return nil, err
+        }
+    default: panic( libErrorf( "unhandled list strategy: %d", strategy ) )
+    }
+    res := mg.NewList( lo.Type )
+    for _, val := range vals { res.AddUnsafe( val ) }
+    return res, nil
+}
+
+func ( lm *layerMerge ) merge(
+    path objpath.PathNode, lo, hi mg.Value ) ( mg.Value, error ) {
+
+    _, loNull := lo.( *mg.Null )
+    _, hiNull := hi.( *mg.Null )
+    if loNull || hiNull { return hi, nil }
+    switch v := lo.( type ) {
+    case *mg.SymbolMap:
+        if m2, ok := hi.( *mg.SymbolMap ); ok {
+            return lm.mergeFields( path, v, m2 )
+        }
+    case *mg.Struct:
+        if s2, ok := hi.( *mg.Struct ); ok && v.Type.Equals( s2.Type ) {
+            flds, err := lm.mergeFields( path, v.Fields, s2.Fields )
+            if err != nil { return nil, err }
+            return &mg.Struct{ Type: v.Type, Fields: flds }, nil
+        }
+    case *mg.List:
+        if l2, ok := hi.( *mg.List ); ok { return lm.mergeLists( path, v, l2 ) }
+    default:
+        if mg.TypeOf( lo ).Equals( mg.TypeOf( hi ) ) { return hi, nil }
+    }
+    return nil, lm.conflict( path, lo, hi )
+}
+
+// Merges layers. Since any value replaces a null beneath it, merging no layers
+// gives null.
+func ( m *Merger ) Merge( layers ...mg.Value ) ( mg.Value, error ) {
+    if len( layers ) == 0 { return mg.NullVal, nil }
+    res := layers[ 0 ]
+    for i, layer := range layers[ 1 : ] {
+        lm := &layerMerge{ m: m, layer: i + 1 }
+        var err error
+        if res, err = lm.merge( nil, res, layer ); err != nil {
+            return nil, err
+        }
+    }
+    return res, nil
+}
+
+// Builds the value of each source in turn and merges them as layers
+func ( m *Merger ) MergeSources(
+    srcs ...mgRct.EventSource ) ( mg.Value, error ) {
+
+    layers := make( []mg.Value, len( srcs ) )
+    for i, src := range srcs {
+        br := mgRct.NewBuildReactor( mgRct.ValueBuilderFactory )
+        if err := src( mgRct.InitReactorPipeline( br ) ); err != nil {
+            return nil, err
+        }
+        layers[ i ] = br.GetValue().( mg.Value )
+    }
+    return m.Merge( layers... )
+}
+
+// Merges layers using a Merger with default settings
+func Merge( layers ...mg.Value ) ( mg.Value, error ) {
+    return NewMerger().Merge( layers... )
+}
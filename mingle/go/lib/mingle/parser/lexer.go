@@ -88,8 +88,10 @@ type Lexer struct {
     newlineUnreadCol int
     isExternal bool
     RejectComments bool
+    commentHandler CommentHandler
     strip bool
     sawEof bool
+    lastTokLine int
     synthLoc *Location
     unread *lxUnreadElt
     stack [ 2 ]lxStackElt
@@ -140,6 +142,19 @@ func ( lx *Lexer ) SetSynthEnd() {
     }
 }
 
+// Discards the next token saved for rereading if there is one, and otherwise
+// the next rune of input, so that a caller recovering from an error in the
+// input can make progress past it. Returns io.EOF at the end of input.
+func ( lx *Lexer ) SkipRune() error {
+    lx.unread = nil
+    if ! lx.stackEmpty() {
+        lx.pop()
+        return nil
+    }
+    _, err := lx.readRune()
+    return err
+}
+
 var lxUnreadNoValErr = libError( "no value to unread" )
 
 func ( lx *Lexer ) UnreadToken() {
@@ -371,7 +386,7 @@ func ( lx *Lexer ) readWhitespace() ( tok WhitespaceToken, err error ) {
 }
 
 var specialTokChars []byte
-func init() { specialTokChars = []byte( ":;{}~()[],?<->/.*+@&" ); }
+func init() { specialTokChars = []byte( ":;{}~()[],?<->/.*+@&=!" ); }
 
 func isSpecialTokChar( r rune ) bool {
     return bytes.IndexRune( specialTokChars, r ) >= 0
@@ -399,6 +414,10 @@ const SpecialTokenAsterisk = SpecialToken( "*" )
 const SpecialTokenPlus = SpecialToken( "+" )
 const SpecialTokenLessThan = SpecialToken( "<" )
 const SpecialTokenGreaterThan = SpecialToken( ">" )
+const SpecialTokenLessThanOrEqual = SpecialToken( "<=" )
+const SpecialTokenGreaterThanOrEqual = SpecialToken( ">=" )
+const SpecialTokenEqual = SpecialToken( "==" )
+const SpecialTokenNotEqual = SpecialToken( "!=" )
 const SpecialTokenAsperand = SpecialToken( "@" )
 const SpecialTokenAmpersand = SpecialToken( "&" )
 
@@ -425,6 +444,10 @@ func init() {
     allSpecialToks.PushFront( SpecialTokenPlus )
     allSpecialToks.PushFront( SpecialTokenLessThan )
     allSpecialToks.PushFront( SpecialTokenGreaterThan )
+    allSpecialToks.PushFront( SpecialTokenLessThanOrEqual )
+    allSpecialToks.PushFront( SpecialTokenGreaterThanOrEqual )
+    allSpecialToks.PushFront( SpecialTokenEqual )
+    allSpecialToks.PushFront( SpecialTokenNotEqual )
     allSpecialToks.PushFront( SpecialTokenAsperand )
     allSpecialToks.PushFront( SpecialTokenAmpersand )
 }
@@ -466,10 +489,15 @@ func ( lx *Lexer ) matchSpecialToks() ( *list.List, error ) {
 }
 
 func ( lx *Lexer ) readSpecialTok() ( Token, error ) {
+    r, err := lx.peekRune()
+    if err != nil { return nil, err }
     m, err := lx.matchSpecialToks()
     if ! isLexErr( err ) {
         switch m.Len() {
-        case 0: return nil, lx.parseError( "Unrecognized op or delimiter" )
+        // only the first rune will have been read, as for a lone '='
+        case 0: 
+            tmpl := "Unexpected char: %q (%U)"
+            return nil, lx.prevError( tmpl, string( r ), r )
         case 1: return m.Front().Value, nil
         default: panic( lx.parseError( "Ambiguous op or delimiter" ) )
         }
@@ -481,6 +509,11 @@ func isCommentStart( r rune ) bool { return r == '#' }
 
 type CommentToken string
 
+// Called for each comment passed over when stripping. lc is the location of the
+// leading '#', and trailing is true if the comment follows some other token on
+// the same line.
+type CommentHandler func( c CommentToken, lc *Location, trailing bool )
+
 func ( lx *Lexer ) readComment() ( tok Token, err error ) {
     if lx.RejectComments {
         return nil, lx.parseError( "Unexpected comment start" ) 
@@ -807,7 +840,13 @@ func ( lx *Lexer ) passWsAndComments() ( sawEol bool, err error ) {
                 sawEol = sawEol || ws.hasNewline()
             }
         case r == '#': 
-            _, err = lx.readComment()
+            lc := lx.makeLocation()
+            var tok Token
+            if tok, err = lx.readComment(); tok != nil {
+                if h := lx.commentHandler; h != nil {
+                    h( tok.( CommentToken ), lc, lc.Line == lx.lastTokLine )
+                }
+            }
             sawEol = true
         default: return
         }
@@ -922,6 +961,7 @@ func ( lx *Lexer ) implReadToken(
         if ! ( tok == nil && err == nil ) { return }
     }
     if tok, lc, err = lx.callTokenRead( f, lc ); err != nil { return }
+    lx.lastTokLine = lc.Line
     if ! lx.strip { eol = hasEol( tok ) }
     if ! eol { lx.updateSynthLoc( tok ) }
     if eol && lx.synthLoc != nil {
@@ -972,6 +1012,7 @@ type LexerOptions struct {
     Reader io.Reader
     IsExternal bool
     RejectComments bool
+    CommentHandler CommentHandler
     Strip bool
 }
 
@@ -984,6 +1025,7 @@ func NewLexer( opts *LexerOptions ) *Lexer {
         isExternal: opts.IsExternal,
         SourceName: opts.SourceName,
         RejectComments: opts.RejectComments,
+        commentHandler: opts.CommentHandler,
         strip: opts.Strip,
     }
 }
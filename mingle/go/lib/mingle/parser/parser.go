@@ -7,6 +7,11 @@ import (
     "bitgirder/objpath"
 //    "log"
     "strconv"
+    "strings"
+    "regexp"
+    "math"
+    "net/url"
+    "bitgirder/uuid"
 )
 
 type parseStringFunc func( b *Builder ) ( *TokenNode, error )
@@ -38,31 +43,50 @@ func idPathParseParseIndexNum( tn *TokenNode ) ( uint64, error ) {
     return 0, err
 }
 
-// pre: will be positioned at opening '['
-func idPathParseExpectIndex( b *Builder ) ( res uint64, err error ) {
+// pre: will be positioned at opening '['. wild will be true and res undefined
+// if pat is true and the index is the wildcard '*'
+func idPathParseExpectIndex( 
+    b *Builder, pat bool ) ( res uint64, wild bool, err error ) {
+
     b.mustSpecial( SpecialTokenOpenBracket )
     if err = b.SkipWs(); err != nil { return }
     var tn *TokenNode
-    if tn, err = b.PollSpecial( SpecialTokenMinus ); tn != nil {
-        return 0, NewParseErrorf( tn.Loc, "negative list index" )
+    if pat {
+        if tn, err = b.PollSpecial( SpecialTokenAsterisk ); err != nil { 
+            return 
+        }
+        wild = tn != nil
+    }
+    if ! wild {
+        if tn, err = b.PollSpecial( SpecialTokenMinus ); tn != nil {
+            return 0, false, NewParseErrorf( tn.Loc, "negative list index" )
+        }
+        if tn, err = b.ExpectNumericToken(); err != nil { return }
+        if res, err = idPathParseParseIndexNum( tn ); err != nil { return }
     }
-    if tn, err = b.ExpectNumericToken(); err != nil { return }
-    if res, err = idPathParseParseIndexNum( tn ); err != nil { return }
     if err = b.SkipWs(); err != nil { return }
     _, err = b.ExpectSpecial( SpecialTokenCloseBracket )
     return
 }
 
-func idPathParseBegin( b *Builder ) ( objpath.PathNode, error ) {
+func idPathParseBeginList( b *Builder, pat bool ) ( objpath.PathNode, error ) {
+    idx, wild, err := idPathParseExpectIndex( b, pat )
+    if err != nil { return nil, err }
+    if wild { return objpath.RootedAt( mg.IdPathWildcard ), nil }
+    return objpath.RootedAtList().SetIndex( idx ), nil
+}
+
+func idPathParseBegin( b *Builder, pat bool ) ( objpath.PathNode, error ) {
     if err := b.SkipWs(); err != nil { return nil, err }
     if err := b.CheckUnexpectedEnd(); err != nil { return nil, err }    
     tn, err := b.PeekToken()
     if err != nil { return nil, err }
     switch {
     case tn.IsSpecial( SpecialTokenOpenBracket ): 
-        idx, err := idPathParseExpectIndex( b )
-        if err == nil { return objpath.RootedAtList().SetIndex( idx ), nil }
-        return nil, err
+        return idPathParseBeginList( b, pat )
+    case pat && tn.IsSpecial( SpecialTokenAsterisk ):
+        b.MustNextToken()
+        return objpath.RootedAt( mg.IdPathWildcard ), nil
     case tn.IsIdentifier(): 
         b.MustNextToken()
         return objpath.RootedAt( tn.Identifier() ), nil
@@ -72,48 +96,71 @@ func idPathParseBegin( b *Builder ) ( objpath.PathNode, error ) {
 
 // next token will be '.'
 func idPathParseDescend( 
-    p objpath.PathNode, b *Builder ) ( objpath.PathNode, error ) {
+    p objpath.PathNode, b *Builder, pat bool ) ( objpath.PathNode, error ) {
 
     b.mustSpecial( SpecialTokenPeriod )
     if err := b.SkipWs(); err != nil { return nil, err }
+    if pat {
+        tn, err := b.PollSpecial( SpecialTokenAsterisk )
+        if err != nil { return nil, err }
+        if tn != nil { return p.Descend( mg.IdPathWildcard ), nil }
+    }
     tn, err := b.ExpectIdentifier()
     if err != nil { return nil, err }
     return p.Descend( tn.Identifier() ), nil
 }
 
 func idPathParseStartList(
-    p objpath.PathNode, b *Builder ) ( objpath.PathNode, error ) {
+    p objpath.PathNode, b *Builder, pat bool ) ( objpath.PathNode, error ) {
 
-    idx, err := idPathParseExpectIndex( b )
+    idx, wild, err := idPathParseExpectIndex( b, pat )
     if err != nil { return nil, err }
+    if wild { return p.Descend( mg.IdPathWildcard ), nil }
     return p.StartList().SetIndex( idx ), nil
 }
 
 // precondition: ws has been skipped and b.HasMoreTokens()
 func idPathParseBuildNext( 
-    p objpath.PathNode, b *Builder ) ( objpath.PathNode, error ) {
+    p objpath.PathNode, b *Builder, pat bool ) ( objpath.PathNode, error ) {
 
     tn, err := b.PeekToken()
     if err != nil { return nil, err }
     switch {
-    case tn.IsSpecial( SpecialTokenPeriod ): return idPathParseDescend( p, b )
+    case tn.IsSpecial( SpecialTokenPeriod ): 
+        return idPathParseDescend( p, b, pat )
     case tn.IsSpecial( SpecialTokenOpenBracket ): 
-        return idPathParseStartList( p, b )
+        return idPathParseStartList( p, b, pat )
     }
     return nil, idPathParseErrorUnexpectedToken( tn, b )
 }
 
-func ParseIdentifierPath( s string ) ( res objpath.PathNode, err error ) {
+func parseIdentifierPath( 
+    s string, pat bool ) ( res objpath.PathNode, err error ) {
+
     b := newSyntaxBuilderExt( s )
-    if res, err = idPathParseBegin( b ); err != nil { return }
+    if res, err = idPathParseBegin( b, pat ); err != nil { return }
     for {
         if err = b.SkipWs(); err != nil { return }
         if ! b.HasTokens() { break }
-        if res, err = idPathParseBuildNext( res, b ); err != nil { return }
+        if res, err = idPathParseBuildNext( res, b, pat ); err != nil { 
+            return 
+        }
     }
     return
 }
 
+func ParseIdentifierPath( s string ) ( objpath.PathNode, error ) {
+    return parseIdentifierPath( s, false )
+}
+
+// Parses s as an identifier path in which '*' may also appear in place of any
+// identifier or list index, producing an mg.IdPathWildcard element. The result
+// is a pattern, as used by field masks, redaction policies and merge
+// strategies, rather than the location of any single value.
+func ParseIdentifierPathPattern( s string ) ( objpath.PathNode, error ) {
+    return parseIdentifierPath( s, true )
+}
+
 func ParseNamespace( s string ) ( *mg.Namespace, error ) {
     sb := newSyntaxBuilderExt( s )
     ns, _, err := sb.ExpectNamespace( nil )
@@ -157,3 +204,102 @@ func ParseTimestamp( str string ) ( mg.Timestamp, error ) {
     }
     return mg.Timestamp( t ), nil
 }
+
+func newInputParseErrorf( tmpl string, args ...interface{} ) *ParseError {
+    return &ParseError{
+        Message: fmt.Sprintf( tmpl, args... ),
+        Loc: &Location{ 1, 1, ParseSourceInput },
+    }
+}
+
+// Parses an ISO 8601 calendar date of the form YYYY-MM-DD
+func ParseDate( str string ) ( mg.Date, error ) {
+    t, err := time.Parse( "2006-01-02", str )
+    if err != nil {
+        msg := "Invalid ISO 8601 date: %q"
+        return mg.Date{}, newInputParseErrorf( msg, str )
+    }
+    return mg.Date{ Year: t.Year(), Month: t.Month(), Day: t.Day() }, nil
+}
+
+var localTimeRegexp = 
+    regexp.MustCompile( `^\d{2}:\d{2}:\d{2}(?:\.\d{1,9})?$` )
+
+// Parses an ISO 8601 time of day of the form HH:MM:SS[.fffffffff]
+func ParseLocalTime( str string ) ( mg.LocalTime, error ) {
+    errInvalid := newInputParseErrorf( "Invalid ISO 8601 time: %q", str )
+    // time.Parse is lenient about the width of the hour and accepts a
+    // fractional second not in the layout, so we check the form first
+    if ! localTimeRegexp.MatchString( str ) { 
+        return mg.LocalTime{}, errInvalid 
+    }
+    t, err := time.Parse( "15:04:05", str )
+    if err != nil { return mg.LocalTime{}, errInvalid }
+    return mg.LocalTime{
+        Hour: t.Hour(), 
+        Minute: t.Minute(), 
+        Second: t.Second(),
+        Nanosecond: t.Nanosecond(),
+    }, nil
+}
+
+var durationRegexp = regexp.MustCompile(
+    `^(-)?P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)(?:\.(\d{1,9}))?S)?)?$`,
+)
+
+// Parses an ISO 8601 duration of the form PnDTnHnMn.nS, optionally preceded
+// by '-'. At least one component must be present, only the seconds may have a
+// fraction, and years and months are not accepted since they have no fixed
+// length.
+func ParseDuration( str string ) ( mg.Duration, error ) {
+    errInvalid := newInputParseErrorf( "Invalid ISO 8601 duration: %q", str )
+    m := durationRegexp.FindStringSubmatch( str )
+    if m == nil || strings.HasSuffix( str, "T" ) { return 0, errInvalid }
+    if m[ 2 ] == "" && m[ 3 ] == "" && m[ 4 ] == "" && m[ 5 ] == "" {
+        return 0, errInvalid
+    }
+    // accumulate as a non-positive value since its range is the larger one
+    res := int64( 0 )
+    add := func( s string, unit time.Duration ) bool {
+        if s == "" { return true }
+        n, err := strconv.ParseInt( s, 10, 64 )
+        if err != nil || n > math.MaxInt64 / int64( unit ) { return false }
+        if res < math.MinInt64 + n * int64( unit ) { return false }
+        res -= n * int64( unit )
+        return true
+    }
+    frac := m[ 6 ]
+    if frac != "" { frac += strings.Repeat( "0", 9 - len( frac ) ) }
+    ok := add( m[ 2 ], 24 * time.Hour ) &&
+          add( m[ 3 ], time.Hour ) &&
+          add( m[ 4 ], time.Minute ) &&
+          add( m[ 5 ], time.Second ) &&
+          add( frac, time.Nanosecond )
+    if ok && m[ 1 ] == "" {
+        if res == math.MinInt64 { ok = false } else { res = -res }
+    }
+    if ! ok {
+        msg := "ISO 8601 duration out of range: %q"
+        return 0, newInputParseErrorf( msg, str )
+    }
+    return mg.Duration( res ), nil
+}
+
+// Parses a uuid in its hyphenated form, accepting hex digits in either case
+func ParseUuid( str string ) ( mg.Uuid, error ) {
+    b, err := uuid.Parse( str )
+    if err != nil { 
+        return mg.Uuid{}, newInputParseErrorf( "Invalid UUID: %q", str ) 
+    }
+    return mg.Uuid( b ), nil
+}
+
+// Parses an absolute uri, returning it in canonical form
+func ParseUri( str string ) ( mg.Uri, error ) {
+    errInvalid := newInputParseErrorf( "Invalid URI: %q", str )
+    u, err := url.Parse( str )
+    if err != nil { return "", errInvalid }
+    res, err := mg.UriOf( u )
+    if err != nil { return "", errInvalid }
+    return res, nil
+}
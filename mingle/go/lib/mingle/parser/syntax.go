@@ -76,6 +76,8 @@ const (
 
 func ( sb *Builder ) SetSynthEnd() { sb.lx.SetSynthEnd() }
 
+func ( sb *Builder ) SkipRune() error { return sb.lx.SkipRune() }
+
 func ( sb * Builder ) readToken(
     et tokenExpectType ) ( tn *TokenNode, err error ) {
     var lxTok Token
@@ -121,9 +123,13 @@ func ( sb *Builder ) errorUnexpectedEnd() error {
     return sb.ParseError( msgUnexpectedEndOfInput )
 }
 
+// Returns any error reading the next token, rather than dropping it as
+// HasTokens() does, since the lexer may not report it again
 func ( sb *Builder ) CheckUnexpectedEnd() error {
-    if sb.HasTokens() { return nil }
-    return sb.errorUnexpectedEnd()
+    tn, err := sb.PeekToken()
+    if err != nil { return err }
+    if tn == nil { return sb.errorUnexpectedEnd() }
+    return nil
 }
 
 func ( sb *Builder ) PeekToken() ( *TokenNode, error ) {
@@ -389,6 +395,13 @@ type ListTypeExpression struct {
     Loc *Location
     Expression interface{}
     AllowsEmpty bool
+    Restriction RestrictionSyntax // a range of lengths, if non-nil
+}
+
+// a map type, as in "{Int64}", with Expression as the type of its values
+type MapTypeExpression struct {
+    Loc *Location
+    Expression interface{}
 }
 
 type NullableTypeExpression struct {
@@ -400,6 +413,7 @@ func atomicExpressionIn( e interface{} ) *AtomicTypeExpression {
     switch v := e.( type ) {
     case *AtomicTypeExpression: return v
     case *ListTypeExpression: return atomicExpressionIn( v.Expression )
+    case *MapTypeExpression: return atomicExpressionIn( v.Expression )
     case *NullableTypeExpression: return atomicExpressionIn( v.Expression )
     case *PointerTypeExpression: return atomicExpressionIn( v.Expression )
     }
@@ -413,6 +427,7 @@ func headLocationOfExpression( e interface{} ) *Location {
     case *NullableTypeExpression: 
         return headLocationOfExpression( v.Expression )
     case *PointerTypeExpression: return v.Loc
+    case *MapTypeExpression: return v.Loc
     }
     panic( libErrorf( "unhandled type exp: %T", e ) )
 }
@@ -597,6 +612,27 @@ func ( sb *Builder ) pollTypeRefRestriction() (
     return
 }
 
+// polls for a restriction on the length of list type e, as in "String*~[1,10]"
+func ( sb *Builder ) pollListRestriction( e *ListTypeExpression ) error {
+    sb.SetSynthEnd()
+    if err := sb.SkipWsOrComments(); err != nil { return err }
+    tn, err := sb.PollSpecial( SpecialTokenTilde )
+    if err != nil || tn == nil { return err }
+    if err = sb.SkipWsOrComments(); err != nil { return err }
+    if tn, err = sb.nextTokenNode(); err != nil { return err }
+    // the synth end set after the quantifier survives "~" when the lexer is
+    // external, in which case it marks the end of input
+    if tn == nil || IsSpecial( tn.Token, SpecialTokenSynthEnd ) {
+        return sb.errorUnexpectedEnd()
+    }
+    if IsSpecial( tn.Token, SpecialTokenOpenParen ) || 
+       IsSpecial( tn.Token, SpecialTokenOpenBracket ) {
+        e.Restriction, err = sb.completeRangeRestriction( tn )
+        return err
+    }
+    return sb.ErrorTokenUnexpected( "length range", tn )
+}
+
 func canStartAtomicType( tn *TokenNode ) bool {
     switch tn.Token.( type ) {
     case *mg.Identifier, *mg.DeclaredTypeName: return true
@@ -618,9 +654,8 @@ func ( sb *Builder ) expectAtomicTypeExpression(
 
 func ( sb *Builder ) pollCloseParen() ( bool, error ) {
     if err := sb.SkipWsOrComments(); err != nil { return false, err }
-    if ! sb.HasTokens() { return false, nil }
     tn, err := sb.PeekToken()
-    if err != nil { return false, err }
+    if tn == nil || err != nil { return false, err }
     res := tn.IsSpecial( SpecialTokenCloseParen )
     if res { sb.mustNextTokenNode() }
     return res, nil
@@ -639,11 +674,29 @@ func ( sb *Builder ) expectGroupedTypeExpression(
     return nil, &ParseError{ `Unmatched "("`, openNode.Loc }
 }
 
+func ( sb *Builder ) expectMapTypeExpression(
+    verDefl *mg.Identifier ) ( interface{}, error ) {
+
+    openNode := sb.mustNextTokenNode() // '{'
+    if err := sb.SkipWsOrComments(); err != nil { return nil, err }
+    if err := sb.CheckUnexpectedEnd(); err != nil { return nil, err }
+    valTyp, err := sb.pollTypeExpression( verDefl )
+    if err != nil { return nil, err }
+    if err := sb.SkipWsOrComments(); err != nil { return nil, err }
+    if _, err := sb.ExpectSpecial( SpecialTokenCloseBrace ); err != nil {
+        return nil, err
+    }
+    return &MapTypeExpression{ Loc: openNode.Loc, Expression: valTyp }, nil
+}
+
 func ( sb *Builder ) expectTypeExpressionBase(
     verDefl *mg.Identifier ) ( interface{}, error ) {
 
     tn, err := sb.PeekToken()
     if err != nil { return nil, err }
+    if tn == nil {
+        return nil, sb.ErrorTokenUnexpected( "type reference", nil )
+    }
     if tn.IsSpecial( SpecialTokenAmpersand ) {
         return sb.expectPointerTypeExpression( verDefl )
     }
@@ -653,6 +706,9 @@ func ( sb *Builder ) expectTypeExpressionBase(
     if tn.IsSpecial( SpecialTokenOpenParen ) {
         return sb.expectGroupedTypeExpression( verDefl )
     }
+    if tn.IsSpecial( SpecialTokenOpenBrace ) {
+        return sb.expectMapTypeExpression( verDefl )
+    }
     return nil, sb.ErrorTokenUnexpected( "type reference", tn )
 }
 
@@ -660,9 +716,14 @@ func ( sb *Builder ) applyTypeQuantifier(
     e interface{}, tn *TokenNode ) ( interface{}, error ) {
 
     var err error
-    switch tn.SpecialToken() {
-    case SpecialTokenAsterisk: e = &ListTypeExpression{ tn.Loc, e, true }
-    case SpecialTokenPlus: e = &ListTypeExpression{ tn.Loc, e, false }
+    switch tok := tn.SpecialToken(); tok {
+    case SpecialTokenAsterisk, SpecialTokenPlus: 
+        le := &ListTypeExpression{ 
+            Loc: tn.Loc, 
+            Expression: e, 
+            AllowsEmpty: tok == SpecialTokenAsterisk,
+        }
+        e, err = le, sb.pollListRestriction( le )
     case SpecialTokenQuestionMark:
         if _, ok := e.( *NullableTypeExpression ); ok {
             msg := "a nullable type cannot itself be made nullable"
@@ -710,6 +771,55 @@ func ( sb *Builder ) ExpectTypeReference(
     return 
 }
 
+func setLengthBound( 
+    valPtr *mg.Value, rx RestrictionSyntax, bound string ) error {
+
+    switch v := rx.( type ) {
+    case nil: return nil
+    case *NumRestrictionSyntax:
+        num, err := mg.ParseNumber( v.LiteralString(), mg.QnameInt64 )
+        if err == nil { *valPtr = num }
+        return err
+    case *StringRestrictionSyntax:
+        msg := fmt.Sprintf( "got string as %s value for length", bound )
+        return &ParseError{ msg, v.Loc }
+    }
+    panic( libErrorf( "unhandled rx: %T", rx ) )
+}
+
+// Returns true if rx, applied to a value of type qn, restricts the length of
+// the value rather than the value itself, as a numeric range applied to a
+// String or any range applied to a Buffer does
+func IsLengthRange( 
+    qn *mg.QualifiedTypeName, rx *RangeRestrictionSyntax ) bool {
+
+    if qn.Equals( mg.QnameBuffer ) { return true }
+    if ! qn.Equals( mg.QnameString ) { return false }
+    _, lNum := rx.Left.( *NumRestrictionSyntax )
+    _, rNum := rx.Right.( *NumRestrictionSyntax )
+    _, lStr := rx.Left.( *StringRestrictionSyntax )
+    _, rStr := rx.Right.( *StringRestrictionSyntax )
+    return ( lNum || rNum ) && ! ( lStr || rStr )
+}
+
+// Creates the length restriction described by rx. An error about a particular
+// bound of the range is a *ParseError at that bound.
+func CreateLengthRestriction( 
+    rx *RangeRestrictionSyntax ) ( *mg.LengthRestriction, error ) {
+
+    lb := &mg.LengthRestrictionBuilder{ 
+        MinClosed: rx.LeftClosed, 
+        MaxClosed: rx.RightClosed,
+    }
+    if err := setLengthBound( &( lb.Min ), rx.Left, "min" ); err != nil {
+        return nil, err
+    }
+    if err := setLengthBound( &( lb.Max ), rx.Right, "max" ); err != nil {
+        return nil, err
+    }
+    return lb.Build()
+}
+
 func applyListTypeCompletion(
     e *ListTypeExpression, 
     atRepl mg.TypeReference ) ( mg.TypeReference, error ) {
@@ -717,9 +827,23 @@ func applyListTypeCompletion(
     et, err := applyTypeCompletion( e.Expression, atRepl )
     if err != nil { return nil, err }
     lt := &mg.ListTypeReference{ ElementType: et, AllowsEmpty: e.AllowsEmpty }
+    if rx, ok := e.Restriction.( *RangeRestrictionSyntax ); ok {
+        if lt.Restriction, err = CreateLengthRestriction( rx ); err != nil {
+            return nil, err
+        }
+    }
     return lt, nil
 }
 
+func applyMapTypeCompletion(
+    e *MapTypeExpression,
+    atRepl mg.TypeReference ) ( mg.TypeReference, error ) {
+
+    vt, err := applyTypeCompletion( e.Expression, atRepl )
+    if err != nil { return nil, err }
+    return mg.NewMapTypeReference( vt ), nil
+}
+
 func applyNullableTypeCompletion( 
     e *NullableTypeExpression, 
     atRepl mg.TypeReference ) ( mg.TypeReference, error ) {
@@ -747,6 +871,7 @@ func applyTypeCompletion(
     switch v := e.( type ) {
     case *AtomicTypeExpression: return atRepl, nil
     case *ListTypeExpression: return applyListTypeCompletion( v, atRepl )
+    case *MapTypeExpression: return applyMapTypeCompletion( v, atRepl )
     case *NullableTypeExpression: 
         return applyNullableTypeCompletion( v, atRepl )
     case *PointerTypeExpression: return applyPointerTypeCompletion( v, atRepl )
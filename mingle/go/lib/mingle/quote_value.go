@@ -51,7 +51,8 @@ func ( vq valueQuote ) appendValue( val Value ) {
     case Buffer: fmt.Fprintf( vq.buf, "buf[%d]", len( []byte( v ) ) )
     case Timestamp: fmt.Fprintf( vq.buf, "%s", v.Rfc3339Nano() )
     case *Null: vq.buf.WriteString( "null" )
-    case Boolean, Int32, Int64, Uint32, Uint64, Float32, Float64:
+    case Boolean, Int32, Int64, Uint32, Uint64, Float32, Float64, BigInt,
+         Decimal, Date, LocalTime, Duration, Uuid, Uri:
         vq.buf.WriteString( val.( fmt.Stringer ).String() )
     case *Enum: vq.appendEnum( v )
     case *List: vq.appendList( v )
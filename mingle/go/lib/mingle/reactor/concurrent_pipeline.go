@@ -0,0 +1,149 @@
+package reactor
+
+import (
+    "bitgirder/objpath"
+    "bitgirder/pipeline"
+    "context"
+    "sync"
+)
+
+// Marks a point in a pipeline built by InitConcurrentReactorPipeline() at
+// which the elements that follow are run on their own goroutine, receiving
+// events through a channel holding at most BufferSize of them. An upstream
+// stage which gets that far ahead blocks until the downstream stage catches up.
+//
+// In a pipeline built by InitReactorPipeline() a StageBoundary simply passes
+// events through.
+type StageBoundary struct {
+    BufferSize int
+}
+
+func NewStageBoundary( bufSize int ) *StageBoundary {
+    return &StageBoundary{ BufferSize: bufSize }
+}
+
+func ( sb *StageBoundary ) ProcessEvent( ev Event, rep EventProcessor ) error {
+    return rep.ProcessEvent( ev )
+}
+
+func copyEventAndPath( ev Event ) Event {
+    res := CopyEvent( ev, false )
+    if p := ev.GetPath(); p != nil { res.SetPath( objpath.CopyOf( p ) ) }
+    return res
+}
+
+type concurrentStage struct {
+    cp *ConcurrentPipeline
+    events chan Event
+    next EventProcessor
+    done chan struct{}
+}
+
+// events are copied since upstream processors may reuse or modify an event or
+// its path once it has been sent
+func ( s *concurrentStage ) ProcessEvent( ev Event ) error {
+    select {
+    case s.events <- copyEventAndPath( ev ): return nil
+    case <-s.cp.ctx.Done(): return s.cp.failure()
+    }
+}
+
+func ( s *concurrentStage ) run() {
+    defer close( s.done )
+    for {
+        if s.cp.ctx.Err() != nil { return }
+        select {
+        case ev, ok := <-s.events:
+            if ! ok { return }
+            if err := s.next.ProcessEvent( ev ); err != nil {
+                s.cp.fail( err )
+                return
+            }
+        case <-s.cp.ctx.Done(): return
+        }
+    }
+}
+
+// An EventProcessor whose elements are split into stages at each StageBoundary,
+// with each stage after the first running on its own goroutine. Events reach
+// each stage in the order in which they were sent to the pipeline.
+//
+// The first error returned by any stage, or the cancellation of the pipeline's
+// context, stops all stages. Calls to ProcessEvent() made after that return
+// the error, as does Close(). Since later stages run asynchronously,
+// ProcessEvent() may return nil for an event which later fails downstream;
+// callers must check the result of Close(), which is also required in order
+// to wait for all stages to finish processing.
+type ConcurrentPipeline struct {
+    head EventProcessor
+    stages []*concurrentStage
+    ctx context.Context
+    cancel context.CancelFunc
+    mu sync.Mutex
+    err error
+}
+
+func InitConcurrentReactorPipeline(
+    ctx context.Context, elts ...interface{} ) *ConcurrentPipeline {
+
+    pip := pipeline.NewPipeline()
+    for _, elt := range elts { pip.Add( elt ) }
+    res := &ConcurrentPipeline{}
+    res.ctx, res.cancel = context.WithCancel( ctx )
+    var next EventProcessor = DiscardProcessor
+    pip.VisitReverse( func( elt interface{} ) {
+        if sb, ok := elt.( *StageBoundary ); ok {
+            s := &concurrentStage{
+                cp: res,
+                events: make( chan Event, sb.BufferSize ),
+                next: next,
+                done: make( chan struct{} ),
+            }
+            res.stages = append( []*concurrentStage{ s }, res.stages... )
+            next = s
+            return
+        }
+        next = makePipelineReactor( elt, next )
+    })
+    res.head = next
+    for _, s := range res.stages { go s.run() }
+    return res
+}
+
+func ( cp *ConcurrentPipeline ) fail( err error ) {
+    cp.mu.Lock()
+    defer cp.mu.Unlock()
+    if cp.err == nil { cp.err = err }
+    cp.cancel()
+}
+
+// returns the first error recorded, or the context's error if the context was
+// cancelled from outside
+func ( cp *ConcurrentPipeline ) failure() error {
+    cp.mu.Lock()
+    defer cp.mu.Unlock()
+    if cp.err != nil { return cp.err }
+    return cp.ctx.Err()
+}
+
+func ( cp *ConcurrentPipeline ) ProcessEvent( ev Event ) error {
+    if err := cp.failure(); err != nil { return err }
+    if err := cp.head.ProcessEvent( ev ); err != nil {
+        cp.fail( err )
+        return cp.failure()
+    }
+    return nil
+}
+
+// Signals that no more events will be sent, waits for all stages to finish,
+// and returns the first error encountered by any stage, if any. Must be called
+// exactly once.
+func ( cp *ConcurrentPipeline ) Close() error {
+    for _, s := range cp.stages {
+        close( s.events )
+        <-s.done
+    }
+    err := cp.failure()
+    cp.cancel()
+    return err
+}
@@ -0,0 +1,243 @@
+package reactor
+
+import (
+    mg "mingle"
+    "bitgirder/objpath"
+    "fmt"
+    "io"
+    "sync"
+)
+
+// Produces the events of a single value, sending them to rep. Functions such
+// as (*io.BinReader).ReadReactorValue are EventSources as they stand.
+type EventSource func( rep EventProcessor ) error
+
+// Returns an EventSource which visits val
+func ValueEventSource( val mg.Value ) EventSource {
+    return func( rep EventProcessor ) error { return VisitValue( val, rep ) }
+}
+
+var errEventReaderClosed = libError( "event reader is closed" )
+
+type eventReaderItem struct {
+    ev Event
+    err error
+}
+
+// Turns an EventSource into a sequence of events which is read by calling
+// Next(), for code which is easier to write by pulling events one at a time
+// than by having them pushed to an EventProcessor.
+//
+// The source runs on its own goroutine, and each event sent by it is passed to
+// the reader's caller only when asked for, so the source never runs ahead of
+// the reader by more than one event. Events are checked for structure and are
+// given paths before being read, and may be kept by the caller.
+//
+// Once the source has sent its last event, Next() returns io.EOF; if the
+// source fails, Next() returns its error. A reader which is abandoned before
+// either happens must be closed in order to stop the source.
+type EventReader struct {
+    items chan eventReaderItem
+    done chan struct{}
+    finished chan struct{}
+    closeOnce sync.Once
+    peeked Event
+    last Event
+    err error
+}
+
+func NewEventReader( src EventSource ) *EventReader {
+    r := &EventReader{
+        items: make( chan eventReaderItem ),
+        done: make( chan struct{} ),
+        finished: make( chan struct{} ),
+    }
+    go r.run( src )
+    return r
+}
+
+func ( r *EventReader ) run( src EventSource ) {
+    defer close( r.finished )
+    send := EventProcessorFunc( func( ev Event ) error {
+        select {
+        case r.items <- eventReaderItem{ ev: copyEventAndPath( ev ) }:
+            return nil
+        case <-r.done: return errEventReaderClosed
+        }
+    })
+    err := src( InitReactorPipeline( NewPathSettingProcessor(), send ) )
+    if err == nil { err = io.EOF }
+    select {
+    case r.items <- eventReaderItem{ err: err }:
+    case <-r.done:
+    }
+}
+
+// Stops the source if it is still running and waits for it to return. Calls
+// to Next() made after Close() fail.
+func ( r *EventReader ) Close() {
+    r.closeOnce.Do( func() { close( r.done ) } )
+    <-r.finished
+    if r.err == nil { r.err = errEventReaderClosed }
+}
+
+func ( r *EventReader ) read() ( Event, error ) {
+    if r.err != nil { return nil, r.err }
+    item := <-r.items
+    if item.err != nil {
+        r.err = item.err
+        return nil, r.err
+    }
+    return item.ev, nil
+}
+
+func ( r *EventReader ) Next() ( ev Event, err error ) {
+    if ev, r.peeked = r.peeked, nil; ev == nil {
+        if ev, err = r.read(); err != nil { return nil, err }
+    }
+    r.last = ev
+    return ev, nil
+}
+
+// Returns the event which the next call to Next() will return, without
+// consuming it
+func ( r *EventReader ) Peek() ( Event, error ) {
+    if r.peeked == nil {
+        ev, err := r.read()
+        if err != nil { return nil, err }
+        r.peeked = ev
+    }
+    return r.peeked, nil
+}
+
+// reads up to and including the end of a container whose start has been read
+func ( r *EventReader ) skipContainer() error {
+    dt := NewDepthTracker()
+    dt.ProcessEvent( r.last )
+    for dt.Depth() > 0 {
+        ev, err := r.Next()
+        if err != nil { return err }
+        dt.ProcessEvent( ev )
+    }
+    return nil
+}
+
+// Skips the rest of the subtree begun by the event most recently returned by
+// Next(): if that event began a list, map, or struct, the reader skips to just
+// after its end, and if it began a field, the reader skips the field's value.
+// Otherwise Skip() does nothing.
+func ( r *EventReader ) Skip() error {
+    switch r.last.( type ) {
+    case *ListStartEvent, *MapStartEvent, *StructStartEvent:
+        return r.skipContainer()
+    case *FieldStartEvent:
+        ev, err := r.Next()
+        if err != nil { return err }
+        if _, ok := ev.( *ValueEvent ); ok { return nil }
+        return r.skipContainer()
+    }
+    return nil
+}
+
+// Sends the complete value beginning with the next event to rep
+func ( r *EventReader ) SendValue( rep EventProcessor ) error {
+    dt := NewDepthTracker()
+    for {
+        ev, err := r.expectNext()
+        if err != nil { return err }
+        dt.ProcessEvent( ev )
+        if err := rep.ProcessEvent( ev ); err != nil { return err }
+        if dt.Depth() == 0 { return nil }
+    }
+}
+
+// Reads the complete value beginning with the next event
+func ( r *EventReader ) ReadValue() ( mg.Value, error ) {
+    br := NewBuildReactor( ValueBuilderFactory )
+    if err := r.SendValue( br ); err != nil { return nil, err }
+    return br.GetValue().( mg.Value ), nil
+}
+
+func eventReaderDesc( ev Event ) string {
+    switch v := ev.( type ) {
+    case *ValueEvent: return mg.TypeOf( v.Val ).ExternalForm()
+    case *ListStartEvent: return "start of " + v.Type.ExternalForm()
+    case *MapStartEvent: return "start of map"
+    case *StructStartEvent: return "start of struct " + v.Type.ExternalForm()
+    case *FieldStartEvent:
+        return fmt.Sprintf( "start of field '%s'", v.Field.ExternalForm() )
+    case *EndEvent: return "end"
+    }
+    panic( libErrorf( "unhandled event: %T", ev ) )
+}
+
+// like Next(), but treats the end of the events as an error
+func ( r *EventReader ) expectNext() ( Event, error ) {
+    ev, err := r.Next()
+    if err == io.EOF { err = io.ErrUnexpectedEOF }
+    return ev, err
+}
+
+func ( r *EventReader ) unexpected( ev Event, expct string ) error {
+    var path objpath.PathNode
+    if p := ev.GetPath(); p != nil { path = objpath.CopyOf( p ) }
+    return NewReactorErrorf(
+        path, "expected %s but saw %s", expct, eventReaderDesc( ev ) )
+}
+
+// Reads the start of a struct of type qn, or of any type if qn is nil
+func ( r *EventReader ) ExpectStruct(
+    qn *mg.QualifiedTypeName ) ( *StructStartEvent, error ) {
+
+    ev, err := r.expectNext()
+    if err != nil { return nil, err }
+    if ss, ok := ev.( *StructStartEvent ); ok {
+        if qn == nil || ss.Type.Equals( qn ) { return ss, nil }
+    }
+    if qn == nil { return nil, r.unexpected( ev, "start of struct" ) }
+    return nil, r.unexpected( ev, "start of struct " + qn.ExternalForm() )
+}
+
+func ( r *EventReader ) ExpectList() ( *ListStartEvent, error ) {
+    ev, err := r.expectNext()
+    if err != nil { return nil, err }
+    if ls, ok := ev.( *ListStartEvent ); ok { return ls, nil }
+    return nil, r.unexpected( ev, "start of list" )
+}
+
+func ( r *EventReader ) ExpectMap() error {
+    ev, err := r.expectNext()
+    if err != nil { return err }
+    if _, ok := ev.( *MapStartEvent ); ok { return nil }
+    return r.unexpected( ev, "start of map" )
+}
+
+func ( r *EventReader ) ExpectField( fld *mg.Identifier ) error {
+    ev, err := r.expectNext()
+    if err != nil { return err }
+    if fs, ok := ev.( *FieldStartEvent ); ok && fs.Field.Equals( fld ) {
+        return nil
+    }
+    return r.unexpected(
+        ev, fmt.Sprintf( "start of field '%s'", fld.ExternalForm() ) )
+}
+
+func ( r *EventReader ) ExpectEnd() error {
+    ev, err := r.expectNext()
+    if err != nil { return err }
+    if _, ok := ev.( *EndEvent ); ok { return nil }
+    return r.unexpected( ev, "end" )
+}
+
+// Reads the start of the next field of a map or struct and returns its name,
+// or reads the end of the map or struct and returns nil if it has no more
+// fields. This is useful for reading fields which may arrive in any order.
+func ( r *EventReader ) NextField() ( *mg.Identifier, error ) {
+    ev, err := r.expectNext()
+    if err != nil { return nil, err }
+    switch v := ev.( type ) {
+    case *FieldStartEvent: return v.Field, nil
+    case *EndEvent: return nil, nil
+    }
+    return nil, r.unexpected( ev, "field or end" )
+}
@@ -0,0 +1,37 @@
+package reactor
+
+// Records a copy of each event it processes so that the stream can be replayed
+// any number of times, such as to send a single decoded value to more than one
+// destination without building it. The events held are independent of those
+// processed, so upstream processors may reuse their events freely.
+//
+// See package mingle/io for a recorder which holds events in their binary
+// encoding, in memory or on disk.
+type EventRecorder struct {
+    events []Event
+}
+
+func NewEventRecorder() *EventRecorder {
+    return &EventRecorder{ events: make( []Event, 0, 16 ) }
+}
+
+func ( r *EventRecorder ) ProcessEvent( ev Event ) error {
+    r.events = append( r.events, copyEventAndPath( ev ) )
+    return nil
+}
+
+func ( r *EventRecorder ) Len() int { return len( r.events ) }
+
+// Discards all recorded events
+func ( r *EventRecorder ) Reset() { r.events = r.events[ : 0 ] }
+
+// Sends copies of the recorded events in order to rep, stopping at the first
+// error.
+func ( r *EventRecorder ) Replay( rep EventProcessor ) error {
+    for _, ev := range r.events {
+        if err := rep.ProcessEvent( copyEventAndPath( ev ) ); err != nil {
+            return err
+        }
+    }
+    return nil
+}
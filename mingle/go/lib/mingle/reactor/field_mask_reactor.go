@@ -0,0 +1,81 @@
+package reactor
+
+import (
+    mg "mingle"
+    "bitgirder/objpath"
+    "bitgirder/pipeline"
+)
+
+// Forwards to its downstream processor only those events which lie along or
+// beneath one of a set of mask paths, such as would be obtained from
+// parser.ParseIdentifierPathPattern(). A mask path element of mg.IdPathWildcard
+// matches any single field, map key, or list index.
+//
+// Containers along the way to a mask path are forwarded (with only their
+// matching contents), and the entire value at a mask path is forwarded as-is.
+// All other fields and list elements are dropped as they arrive, so no part of
+// the stream is ever buffered. Because list elements may be dropped, indexes in
+// the output stream are not necessarily the same as those in the input.
+//
+// Mask paths are relative to the root of the event stream, and a value which
+// is not a container but lies along the way to a mask path (a null value, for
+// instance) is forwarded as well.
+type FieldMaskReactor struct {
+    masks [][]interface{}
+    skip *DepthTracker
+}
+
+func NewFieldMaskReactor( paths []objpath.PathNode ) *FieldMaskReactor {
+    res := &FieldMaskReactor{ masks: make( [][]interface{}, len( paths ) ) }
+    for i, p := range paths { res.masks[ i ] = mg.IdPathElements( p ) }
+    return res
+}
+
+func ( r *FieldMaskReactor ) InitializePipeline( pip *pipeline.Pipeline ) {
+    EnsurePathSettingProcessor( pip )
+}
+
+// returns true if p is along the way to some mask path or is at or beneath it
+func ( r *FieldMaskReactor ) matches( p objpath.PathNode ) bool {
+    if p == nil { return true }
+    elts := mg.IdPathElements( p )
+    for _, mask := range r.masks {
+        n := len( mask )
+        if len( elts ) < n { n = len( elts ) }
+        i := 0
+        for i < n && mg.IdPathElementMatches( mask[ i ], elts[ i ] ) { i++ }
+        if i == n { return true }
+    }
+    return false
+}
+
+func ( r *FieldMaskReactor ) selects( ev Event ) bool {
+    switch ev.( type ) {
+    case *FieldStartEvent: return r.matches( ev.GetPath() )
+    case *EndEvent: return true
+    }
+    // field values were already decided by their field start, leaving only list
+    // elements to check here
+    if _, ok := ev.GetPath().( *objpath.ListNode ); ok {
+        return r.matches( ev.GetPath() )
+    }
+    return true
+}
+
+func ( r *FieldMaskReactor ) skipEvent( ev Event ) {
+    r.skip.ProcessEvent( ev )
+    if r.skip.Depth() == 0 { r.skip = nil }
+}
+
+func ( r *FieldMaskReactor ) ProcessEvent(
+    ev Event, rep EventProcessor ) error {
+
+    if r.skip != nil {
+        r.skipEvent( ev )
+        return nil
+    }
+    if r.selects( ev ) { return rep.ProcessEvent( ev ) }
+    r.skip = NewDepthTracker()
+    if _, ok := ev.( *FieldStartEvent ); ! ok { r.skipEvent( ev ) }
+    return nil
+}
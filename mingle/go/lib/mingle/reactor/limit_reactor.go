@@ -0,0 +1,136 @@
+package reactor
+
+import (
+    mg "mingle"
+    "bitgirder/objpath"
+    "bitgirder/pipeline"
+)
+
+// Limits enforced by a LimitReactor. A limit which is zero is not enforced.
+type Limits struct {
+
+    // maximum number of lists, maps, and structs open at one time
+    MaxDepth int
+
+    // maximum number of elements in any one list
+    MaxListLength int
+
+    // maximum number of fields in any one map or struct
+    MaxFields int
+
+    // maximum length in bytes of any String value
+    MaxStringBytes int
+
+    // maximum length of any Buffer value
+    MaxBufferBytes int
+
+    // maximum number of events in the stream
+    MaxEvents int
+}
+
+type limitContainer struct {
+    list bool
+    size int
+}
+
+// Fails with a *ReactorError located at the offending event as soon as the
+// stream it processes exceeds one of its limits, before the event is sent
+// downstream, making it suitable for use directly after a decoder of untrusted
+// input.
+type LimitReactor struct {
+    limits Limits
+    depth *DepthTracker
+    stack []*limitContainer
+    events int
+}
+
+func NewLimitReactor( limits Limits ) *LimitReactor {
+    return &LimitReactor{ limits: limits, depth: NewDepthTracker() }
+}
+
+func ( r *LimitReactor ) InitializePipeline( pip *pipeline.Pipeline ) {
+    EnsurePathSettingProcessor( pip )
+}
+
+func limitErrorf(
+    ev Event, tmpl string, args ...interface{} ) *ReactorError {
+
+    var path objpath.PathNode
+    if p := ev.GetPath(); p != nil { path = objpath.CopyOf( p ) }
+    return NewReactorErrorf( path, tmpl, args... )
+}
+
+func exceeds( n, limit int ) bool { return limit > 0 && n > limit }
+
+func ( r *LimitReactor ) top() *limitContainer {
+    if len( r.stack ) == 0 { return nil }
+    return r.stack[ len( r.stack ) - 1 ]
+}
+
+func ( r *LimitReactor ) checkListElement( ev Event ) error {
+    c := r.top()
+    if c == nil || ! c.list { return nil }
+    c.size++
+    if max := r.limits.MaxListLength; exceeds( c.size, max ) {
+        return limitErrorf( ev, "list length exceeds limit of %d", max )
+    }
+    return nil
+}
+
+func ( r *LimitReactor ) checkValue( ve *ValueEvent ) error {
+    if err := r.checkListElement( ve ); err != nil { return err }
+    switch v := ve.Val.( type ) {
+    case mg.String:
+        if max := r.limits.MaxStringBytes; exceeds( len( v ), max ) {
+            tmpl := "string of %d bytes exceeds limit of %d"
+            return limitErrorf( ve, tmpl, len( v ), max )
+        }
+    case mg.Buffer:
+        if max := r.limits.MaxBufferBytes; exceeds( len( v ), max ) {
+            tmpl := "buffer of %d bytes exceeds limit of %d"
+            return limitErrorf( ve, tmpl, len( v ), max )
+        }
+    }
+    return nil
+}
+
+func ( r *LimitReactor ) checkStart( ev Event ) error {
+    if err := r.checkListElement( ev ); err != nil { return err }
+    r.depth.ProcessEvent( ev )
+    if max := r.limits.MaxDepth; exceeds( r.depth.Depth(), max ) {
+        return limitErrorf( ev, "nesting depth exceeds limit of %d", max )
+    }
+    _, isList := ev.( *ListStartEvent )
+    r.stack = append( r.stack, &limitContainer{ list: isList } )
+    return nil
+}
+
+func ( r *LimitReactor ) checkFieldStart( fs *FieldStartEvent ) error {
+    c := r.top()
+    if c == nil { return nil }
+    c.size++
+    if max := r.limits.MaxFields; exceeds( c.size, max ) {
+        return limitErrorf( fs, "field count exceeds limit of %d", max )
+    }
+    return nil
+}
+
+func ( r *LimitReactor ) processEnd( ev *EndEvent ) {
+    r.depth.ProcessEvent( ev )
+    if len( r.stack ) > 0 { r.stack = r.stack[ : len( r.stack ) - 1 ] }
+}
+
+func ( r *LimitReactor ) ProcessEvent( ev Event ) error {
+    r.events++
+    if max := r.limits.MaxEvents; exceeds( r.events, max ) {
+        return limitErrorf( ev, "event count exceeds limit of %d", max )
+    }
+    switch v := ev.( type ) {
+    case *ValueEvent: return r.checkValue( v )
+    case *ListStartEvent, *MapStartEvent, *StructStartEvent:
+        return r.checkStart( ev )
+    case *FieldStartEvent: return r.checkFieldStart( v )
+    case *EndEvent: r.processEnd( v )
+    }
+    return nil
+}
@@ -0,0 +1,99 @@
+package reactor
+
+import (
+    mg "mingle"
+    "bitgirder/objpath"
+    "bitgirder/pipeline"
+    "context"
+)
+
+// Receives each element built by a ListElementReactor along with its location
+// in the stream. Returning an error stops the stream.
+type ListElementHandler func( path objpath.PathNode, val interface{} ) error
+
+// Returns a ListElementHandler which sends each element to ch, blocking until
+// it is received or until ctx is done, in which case the handler returns the
+// context's error.
+func ListElementChannel(
+    ctx context.Context, ch chan<- interface{} ) ListElementHandler {
+
+    return func( _ objpath.PathNode, val interface{} ) error {
+        select {
+        case ch <- val: return nil
+        case <-ctx.Done(): return ctx.Err()
+        }
+    }
+}
+
+// Builds each element of the lists at a given path independently, passing it
+// to a ListElementHandler as soon as it is complete, so that a list of any
+// length may be processed while holding only one of its elements in memory.
+//
+// The path is relative to the root of the stream, with nil indicating a
+// top-level list, and may contain mg.IdPathWildcard elements as with a
+// FieldMaskReactor. The events of a handled element are not sent downstream,
+// and so downstream processors see each matching list as empty.
+type ListElementReactor struct {
+    path []interface{}
+    bf BuilderFactory
+    h ListElementHandler
+    depth *DepthTracker
+    listDepth int // depth inside the current matching list, or 0 if none
+    elt *BuildReactor
+    eltDepth *DepthTracker
+    eltPath objpath.PathNode
+}
+
+func NewListElementReactor(
+    path objpath.PathNode,
+    bf BuilderFactory,
+    h ListElementHandler ) *ListElementReactor {
+
+    return &ListElementReactor{
+        path: mg.IdPathElements( path ),
+        bf: bf,
+        h: h,
+        depth: NewDepthTracker(),
+    }
+}
+
+func ( r *ListElementReactor ) InitializePipeline( pip *pipeline.Pipeline ) {
+    EnsurePathSettingProcessor( pip )
+}
+
+func ( r *ListElementReactor ) matches( p objpath.PathNode ) bool {
+    return mg.IdPathMatches( r.path, mg.IdPathElements( p ) )
+}
+
+func ( r *ListElementReactor ) startElement( ev Event ) {
+    r.elt = NewBuildReactor( r.bf )
+    r.eltDepth = NewDepthTracker()
+    r.eltPath = objpath.CopyOf( ev.GetPath() )
+}
+
+func ( r *ListElementReactor ) elementEvent( ev Event ) error {
+    r.eltDepth.ProcessEvent( ev )
+    if err := r.elt.ProcessEvent( ev ); err != nil { return err }
+    if r.eltDepth.Depth() > 0 { return nil }
+    val, path := r.elt.GetValue(), r.eltPath
+    r.elt, r.eltDepth, r.eltPath = nil, nil, nil
+    return r.h( path, val )
+}
+
+func ( r *ListElementReactor ) ProcessEvent(
+    ev Event, rep EventProcessor ) error {
+
+    if r.elt != nil { return r.elementEvent( ev ) }
+    if r.listDepth > 0 && r.depth.Depth() == r.listDepth {
+        if _, ok := ev.( *EndEvent ); ! ok {
+            r.startElement( ev )
+            return r.elementEvent( ev )
+        }
+        r.listDepth = 0
+    }
+    r.depth.ProcessEvent( ev )
+    if _, ok := ev.( *ListStartEvent ); ok && r.matches( ev.GetPath() ) {
+        r.listDepth = r.depth.Depth()
+    }
+    return rep.ProcessEvent( ev )
+}
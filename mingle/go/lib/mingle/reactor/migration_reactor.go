@@ -0,0 +1,372 @@
+package reactor
+
+import (
+    mg "mingle"
+    "bitgirder/objpath"
+    "bitgirder/pipeline"
+)
+
+// Moves a field of a migrated struct into a nested struct of type IntoType,
+// held in field Into of the migrated struct. The moved value is given the name
+// As in the nested struct, or keeps its name if As is nil.
+type FieldMove struct {
+    Field *mg.Identifier
+    Into *mg.Identifier
+    IntoType *mg.QualifiedTypeName
+    As *mg.Identifier
+}
+
+// A field supplied with Value when a migrated struct would otherwise not have
+// it
+type FieldDefault struct {
+    Field *mg.Identifier
+    Value mg.Value
+}
+
+// Describes how values of a single struct type are migrated. Any member may be
+// left nil or empty.
+type StructMigration struct {
+
+    // the new name of the struct type
+    Type *mg.QualifiedTypeName
+
+    // maps source field names to their *mg.Identifier replacements
+    RenameFields *mg.IdentifierMap
+
+    DropFields []*mg.Identifier
+
+    MoveFields []*FieldMove
+
+    // applied in order after all other changes
+    Defaults []*FieldDefault
+}
+
+func ( sm *StructMigration ) drops( fld *mg.Identifier ) bool {
+    for _, id := range sm.DropFields { if id.Equals( fld ) { return true } }
+    return false
+}
+
+func ( sm *StructMigration ) moveOf( fld *mg.Identifier ) *FieldMove {
+    for _, mv := range sm.MoveFields { if mv.Field.Equals( fld ) { return mv } }
+    return nil
+}
+
+func ( sm *StructMigration ) renamed( fld *mg.Identifier ) *mg.Identifier {
+    if sm.RenameFields == nil { return nil }
+    if id, ok := sm.RenameFields.GetOk( fld ); ok {
+        return id.( *mg.Identifier )
+    }
+    return nil
+}
+
+// Describes how values of a single enum type are migrated. Either member may
+// be nil.
+type EnumMigration struct {
+
+    // the new name of the enum type
+    Type *mg.QualifiedTypeName
+
+    // maps source enum values to their *mg.Identifier replacements
+    Values *mg.IdentifierMap
+}
+
+// A set of struct and enum migrations, keyed by source type name
+type Migration struct {
+    structs *mg.QnameMap
+    enums *mg.QnameMap
+}
+
+func NewMigration() *Migration {
+    return &Migration{ structs: mg.NewQnameMap(), enums: mg.NewQnameMap() }
+}
+
+func ( m *Migration ) PutStruct(
+    qn *mg.QualifiedTypeName, sm *StructMigration ) {
+
+    m.structs.Put( qn, sm )
+}
+
+func ( m *Migration ) PutEnum( qn *mg.QualifiedTypeName, em *EnumMigration ) {
+    m.enums.Put( qn, em )
+}
+
+func ( m *Migration ) structMigration(
+    qn *mg.QualifiedTypeName ) *StructMigration {
+
+    if sm, ok := m.structs.GetOk( qn ); ok { return sm.( *StructMigration ) }
+    return nil
+}
+
+func ( m *Migration ) enumMigration(
+    qn *mg.QualifiedTypeName ) *EnumMigration {
+
+    if em, ok := m.enums.GetOk( qn ); ok { return em.( *EnumMigration ) }
+    return nil
+}
+
+func ( m *Migration ) typeName(
+    qn *mg.QualifiedTypeName ) *mg.QualifiedTypeName {
+
+    if sm := m.structMigration( qn ); sm != nil && sm.Type != nil {
+        return sm.Type
+    }
+    if em := m.enumMigration( qn ); em != nil && em.Type != nil {
+        return em.Type
+    }
+    return qn
+}
+
+// returns typ with any renamed struct or enum types replaced, which only
+// matters for the element types of lists
+func ( m *Migration ) typeRef( typ mg.TypeReference ) mg.TypeReference {
+    switch v := typ.( type ) {
+    case *mg.AtomicTypeReference:
+        nm := m.typeName( v.Name() )
+        if nm == v.Name() { return v }
+        return mg.NewAtomicTypeReference( nm, v.Restriction() )
+    case *mg.ListTypeReference:
+        res := *v
+        res.ElementType = m.typeRef( v.ElementType )
+        return &res
+    case *mg.NullableTypeReference:
+        return &mg.NullableTypeReference{ Type: m.typeRef( v.Type ) }
+    case *mg.PointerTypeReference:
+        return mg.NewPointerTypeReference( m.typeRef( v.Type ) )
+    case *mg.MapTypeReference:
+        return mg.NewMapTypeReference( m.typeRef( v.ValueType ) )
+    }
+    return typ
+}
+
+func ( m *Migration ) enumValue( en *mg.Enum ) *mg.Enum {
+    em := m.enumMigration( en.Type )
+    if em == nil { return en }
+    res := &mg.Enum{ Type: en.Type, Value: en.Value }
+    if em.Type != nil { res.Type = em.Type }
+    if em.Values != nil {
+        if id, ok := em.Values.GetOk( en.Value ); ok {
+            res.Value = id.( *mg.Identifier )
+        }
+    }
+    return res
+}
+
+type migratedField struct {
+    move *FieldMove
+    rec *EventRecorder
+}
+
+type migrationFrame struct {
+    out EventProcessor // destination of the container's own events
+    valOut EventProcessor // destination of the current field value or element
+    spec *StructMigration // nil unless a struct being migrated
+    fields *mg.IdentifierMap // fields written so far, when spec is set
+    moved []*migratedField
+    path objpath.PathNode // location of the struct, when spec is set
+}
+
+// errors are located at the struct rather than at either of its duplicate
+// fields, since the order in which fields arrive is unspecified
+func ( f *migrationFrame ) addField( fld *mg.Identifier ) error {
+    if f.fields.HasKey( fld ) {
+        tmpl := "field %s is already present in migrated value"
+        return NewReactorErrorf( f.path, tmpl, fld )
+    }
+    f.fields.Put( fld, true )
+    return nil
+}
+
+// Rewrites a stream of values stored under an older schema into their form
+// under a newer one, as described by a Migration. Migrations apply to structs
+// and enums at any depth, including within the values of moved fields.
+//
+// Fields added by the migration, whether defaults or the nested structs
+// holding moved fields, follow a migrated struct's own fields. A nested struct
+// for a move is written only if at least one of the fields moved into it is
+// present, and it is an error for a migrated struct to end up with the same
+// field twice. The values of moved fields are held in memory until the end of
+// their struct; all other events pass through as they arrive.
+//
+// Events which are passed through keep their source paths and events created
+// by the reactor have none, so a PathSettingProcessor should follow a
+// MigrationReactor in any pipeline which needs the paths of the migrated
+// stream.
+type MigrationReactor struct {
+    m *Migration
+    stack []*migrationFrame
+}
+
+func NewMigrationReactor( m *Migration ) *MigrationReactor {
+    return &MigrationReactor{ m: m }
+}
+
+func ( r *MigrationReactor ) InitializePipeline( pip *pipeline.Pipeline ) {
+    EnsurePathSettingProcessor( pip )
+}
+
+func ( r *MigrationReactor ) top() *migrationFrame {
+    if len( r.stack ) == 0 { return nil }
+    return r.stack[ len( r.stack ) - 1 ]
+}
+
+func ( r *MigrationReactor ) valueOut( rep EventProcessor ) EventProcessor {
+    if f := r.top(); f != nil { return f.valOut }
+    return rep
+}
+
+// called once a field value or list element is complete
+func ( r *MigrationReactor ) valueDone() {
+    if f := r.top(); f != nil { f.valOut = f.out }
+}
+
+func ( r *MigrationReactor ) push( out EventProcessor ) *migrationFrame {
+    f := &migrationFrame{ out: out, valOut: out }
+    r.stack = append( r.stack, f )
+    return f
+}
+
+func ( r *MigrationReactor ) processValue(
+    ve *ValueEvent, rep EventProcessor ) error {
+
+    out := r.valueOut( rep )
+    var ev Event = ve
+    if en, ok := ve.Val.( *mg.Enum ); ok {
+        if en2 := r.m.enumValue( en ); en2 != en {
+            ev = NewValueEvent( en2 )
+            ev.SetPath( ve.GetPath() )
+        }
+    }
+    if err := out.ProcessEvent( ev ); err != nil { return err }
+    r.valueDone()
+    return nil
+}
+
+func ( r *MigrationReactor ) processListStart(
+    ls *ListStartEvent, rep EventProcessor ) error {
+
+    out := r.valueOut( rep )
+    r.push( out )
+    typ := r.m.typeRef( ls.Type ).( *mg.ListTypeReference )
+    ev := NewListStartEvent( typ )
+    ev.SetPath( ls.GetPath() )
+    return out.ProcessEvent( ev )
+}
+
+func ( r *MigrationReactor ) processMapStart(
+    ms *MapStartEvent, rep EventProcessor ) error {
+
+    out := r.valueOut( rep )
+    r.push( out )
+    return out.ProcessEvent( ms )
+}
+
+func ( r *MigrationReactor ) processStructStart(
+    ss *StructStartEvent, rep EventProcessor ) error {
+
+    out := r.valueOut( rep )
+    f := r.push( out )
+    var ev Event = ss
+    if f.spec = r.m.structMigration( ss.Type ); f.spec != nil {
+        f.fields = mg.NewIdentifierMap()
+        if p := ss.GetPath(); p != nil { f.path = objpath.CopyOf( p ) }
+        if f.spec.Type != nil {
+            ev = NewStructStartEvent( f.spec.Type )
+            ev.SetPath( ss.GetPath() )
+        }
+    }
+    return out.ProcessEvent( ev )
+}
+
+func ( r *MigrationReactor ) processFieldStart( fs *FieldStartEvent ) error {
+    f := r.top()
+    if f.spec == nil { return f.out.ProcessEvent( fs ) }
+    if f.spec.drops( fs.Field ) {
+        f.valOut = DiscardProcessor
+        return nil
+    }
+    if mv := f.spec.moveOf( fs.Field ); mv != nil {
+        mf := &migratedField{ move: mv, rec: NewEventRecorder() }
+        f.moved = append( f.moved, mf )
+        f.valOut = mf.rec
+        return nil
+    }
+    var ev Event = fs
+    fld := fs.Field
+    if id := f.spec.renamed( fld ); id != nil {
+        fld = id
+        ev = NewFieldStartEvent( fld )
+        ev.SetPath( fs.GetPath() )
+    }
+    if err := f.addField( fld ); err != nil { return err }
+    return f.out.ProcessEvent( ev )
+}
+
+// writes the nested struct for the fields moved into field into, if any
+func ( r *MigrationReactor ) writeMove(
+    f *migrationFrame,
+    into *mg.Identifier,
+    typ *mg.QualifiedTypeName ) error {
+
+    var moved []*migratedField
+    for _, mf := range f.moved {
+        if mf.move.Into.Equals( into ) { moved = append( moved, mf ) }
+    }
+    if len( moved ) == 0 { return nil }
+    if err := f.addField( into ); err != nil { return err }
+    starts := []Event{ NewFieldStartEvent( into ), NewStructStartEvent( typ ) }
+    for _, start := range starts {
+        if err := f.out.ProcessEvent( start ); err != nil { return err }
+    }
+    for _, mf := range moved {
+        fld := mf.move.As
+        if fld == nil { fld = mf.move.Field }
+        if err := f.out.ProcessEvent( NewFieldStartEvent( fld ) ); err != nil {
+            return err
+        }
+        if err := mf.rec.Replay( f.out ); err != nil { return err }
+    }
+    return f.out.ProcessEvent( NewEndEvent() )
+}
+
+func ( r *MigrationReactor ) completeStruct( f *migrationFrame ) error {
+    if f.spec == nil { return nil }
+    written := mg.NewIdentifierMap()
+    for _, mv := range f.spec.MoveFields {
+        if written.HasKey( mv.Into ) { continue }
+        written.Put( mv.Into, true )
+        if err := r.writeMove( f, mv.Into, mv.IntoType ); err != nil {
+            return err
+        }
+    }
+    for _, fd := range f.spec.Defaults {
+        if f.fields.HasKey( fd.Field ) { continue }
+        f.fields.Put( fd.Field, true )
+        err := f.out.ProcessEvent( NewFieldStartEvent( fd.Field ) )
+        if err != nil { return err }
+        if err := VisitValue( fd.Value, f.out ); err != nil { return err }
+    }
+    return nil
+}
+
+func ( r *MigrationReactor ) processEnd( ee *EndEvent ) error {
+    f := r.top()
+    r.stack = r.stack[ : len( r.stack ) - 1 ]
+    if err := r.completeStruct( f ); err != nil { return err }
+    if err := f.out.ProcessEvent( ee ); err != nil { return err }
+    r.valueDone()
+    return nil
+}
+
+func ( r *MigrationReactor ) ProcessEvent(
+    ev Event, rep EventProcessor ) error {
+
+    switch v := ev.( type ) {
+    case *ValueEvent: return r.processValue( v, rep )
+    case *ListStartEvent: return r.processListStart( v, rep )
+    case *MapStartEvent: return r.processMapStart( v, rep )
+    case *StructStartEvent: return r.processStructStart( v, rep )
+    case *FieldStartEvent: return r.processFieldStart( v )
+    case *EndEvent: return r.processEnd( v )
+    }
+    panic( libErrorf( "unhandled event: %T", ev ) )
+}
@@ -0,0 +1,221 @@
+package reactor
+
+import (
+    mg "mingle"
+    "bitgirder/objpath"
+    "bitgirder/pipeline"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "hash"
+    "io"
+)
+
+type RedactionMode int
+
+const (
+    RedactionModePlaceholder = RedactionMode( iota )
+    RedactionModeHash
+)
+
+// Used in place of redacted values when a RedactionPolicy has no Placeholder
+var DefaultRedactionPlaceholder mg.Value = mg.String( "<redacted>" )
+
+// Marks the values which a RedactionReactor replaces, either as fields of some
+// struct type or as locations matching some path pattern.
+//
+// Under RedactionModePlaceholder each sensitive value is replaced by
+// Placeholder, or by DefaultRedactionPlaceholder if that is nil. Under
+// RedactionModeHash each is replaced by a String of the form "sha256:<hex>"
+// which is the same for equal values, allowing redacted values to be
+// correlated across log entries. Since secrets such as passwords may be
+// guessed from an unkeyed hash, HashKey should be set to a secret when hashing,
+// in which case an HMAC is used.
+type RedactionPolicy struct {
+    Mode RedactionMode
+    Placeholder mg.Value
+    HashKey []byte
+    fields *mg.QnameMap // *mg.IdentifierMap values used as sets
+    paths [][]interface{}
+}
+
+func NewRedactionPolicy() *RedactionPolicy {
+    return &RedactionPolicy{ fields: mg.NewQnameMap() }
+}
+
+// Marks field fld of every struct of type qn as sensitive, wherever it occurs
+func ( p *RedactionPolicy ) AddField(
+    qn *mg.QualifiedTypeName, fld *mg.Identifier ) {
+
+    flds, ok := p.fields.GetOk( qn )
+    if ! ok {
+        flds = mg.NewIdentifierMap()
+        p.fields.Put( qn, flds )
+    }
+    flds.( *mg.IdentifierMap ).Put( fld, true )
+}
+
+// Marks the value at path as sensitive, with path relative to the root of the
+// stream. As with a FieldMaskReactor, mg.IdPathWildcard matches any single
+// field, map key, or list index.
+func ( p *RedactionPolicy ) AddPath( path objpath.PathNode ) {
+    p.paths = append( p.paths, mg.IdPathElements( path ) )
+}
+
+func ( p *RedactionPolicy ) markedField(
+    qn *mg.QualifiedTypeName, fld *mg.Identifier ) bool {
+
+    if flds, ok := p.fields.GetOk( qn ); ok {
+        return flds.( *mg.IdentifierMap ).HasKey( fld )
+    }
+    return false
+}
+
+func ( p *RedactionPolicy ) markedPath( path objpath.PathNode ) bool {
+    if path == nil || len( p.paths ) == 0 { return false }
+    elts := mg.IdPathElements( path )
+    for _, pat := range p.paths {
+        if mg.IdPathMatches( pat, elts ) { return true }
+    }
+    return false
+}
+
+// writes val such that equal values are written identically, regardless of
+// field order
+func writeRedactionHashInput( w io.Writer, val mg.Value ) {
+    writeFields := func( m *mg.SymbolMap ) {
+        io.WriteString( w, "{" )
+        for _, fld := range mg.SortIds( m.GetKeys() ) {
+            fmt.Fprintf( w, "%s:", fld.ExternalForm() )
+            writeRedactionHashInput( w, m.Get( fld ) )
+        }
+        io.WriteString( w, "}" )
+    }
+    switch v := val.( type ) {
+    case *mg.Struct:
+        io.WriteString( w, v.Type.ExternalForm() )
+        writeFields( v.Fields )
+    case *mg.SymbolMap: writeFields( v )
+    case *mg.List:
+        io.WriteString( w, "[" )
+        for _, elt := range v.Values() {
+            writeRedactionHashInput( w, elt )
+            io.WriteString( w, "," )
+        }
+        io.WriteString( w, "]" )
+    case mg.Buffer: fmt.Fprintf( w, "buf:%x;", []byte( v ) )
+    default:
+        fmt.Fprintf( w, "%s:%s;", mg.TypeOf( val ), mg.QuoteValue( val ) )
+    }
+}
+
+func ( p *RedactionPolicy ) hashOf( val mg.Value ) mg.Value {
+    var h hash.Hash
+    if p.HashKey == nil {
+        h = sha256.New()
+    } else {
+        h = hmac.New( sha256.New, p.HashKey )
+    }
+    writeRedactionHashInput( h, val )
+    return mg.String( "sha256:" + hex.EncodeToString( h.Sum( nil ) ) )
+}
+
+func ( p *RedactionPolicy ) placeholder() mg.Value {
+    if p.Placeholder == nil { return DefaultRedactionPlaceholder }
+    return p.Placeholder
+}
+
+// Replaces each value marked as sensitive by a RedactionPolicy with a single
+// value as the stream passes through, so that it may be placed ahead of a
+// DebugReactor, an encoder, or anything else which might expose the stream.
+// Under RedactionModeHash a sensitive value is built in memory in order to
+// hash it; otherwise its events are dropped as they arrive.
+type RedactionReactor struct {
+    p *RedactionPolicy
+    types []*mg.QualifiedTypeName // enclosing struct types, nil for others
+    pending bool // true if the next value is the value of a marked field
+    skip *DepthTracker
+    bld *BuildReactor
+    path objpath.PathNode // location of the value being redacted
+}
+
+func NewRedactionReactor( p *RedactionPolicy ) *RedactionReactor {
+    return &RedactionReactor{ p: p }
+}
+
+func ( r *RedactionReactor ) InitializePipeline( pip *pipeline.Pipeline ) {
+    EnsurePathSettingProcessor( pip )
+}
+
+func ( r *RedactionReactor ) marksField( fs *FieldStartEvent ) bool {
+    if len( r.types ) > 0 {
+        qn := r.types[ len( r.types ) - 1 ]
+        if qn != nil && r.p.markedField( qn, fs.Field ) { return true }
+    }
+    return r.p.markedPath( fs.GetPath() )
+}
+
+func ( r *RedactionReactor ) track( ev Event ) {
+    switch v := ev.( type ) {
+    case *StructStartEvent: r.types = append( r.types, v.Type )
+    case *ListStartEvent, *MapStartEvent: r.types = append( r.types, nil )
+    case *EndEvent: r.types = r.types[ : len( r.types ) - 1 ]
+    }
+}
+
+func ( r *RedactionReactor ) startRedact( ev Event ) {
+    r.pending = false
+    r.path = nil
+    if p := ev.GetPath(); p != nil { r.path = objpath.CopyOf( p ) }
+    r.skip = NewDepthTracker()
+    if r.p.Mode == RedactionModeHash {
+        r.bld = NewBuildReactor( ValueBuilderFactory )
+    }
+}
+
+func ( r *RedactionReactor ) redactEvent( ev Event, rep EventProcessor ) error {
+    r.skip.ProcessEvent( ev )
+    if r.bld != nil {
+        if err := r.bld.ProcessEvent( ev ); err != nil { return err }
+    }
+    if r.skip.Depth() > 0 { return nil }
+    val := r.p.placeholder()
+    if r.bld != nil { val = r.p.hashOf( r.bld.GetValue().( mg.Value ) ) }
+    ve := NewValueEvent( val )
+    ve.SetPath( r.path )
+    r.skip, r.bld, r.path = nil, nil, nil
+    return rep.ProcessEvent( ve )
+}
+
+func ( r *RedactionReactor ) ProcessEvent(
+    ev Event, rep EventProcessor ) error {
+
+    if r.skip != nil { return r.redactEvent( ev, rep ) }
+    switch v := ev.( type ) {
+    case *FieldStartEvent:
+        r.pending = r.marksField( v )
+        return rep.ProcessEvent( ev )
+    case *EndEvent:
+        r.track( ev )
+        return rep.ProcessEvent( ev )
+    }
+    // ev starts a field value or list element, or is the root value
+    _, isElt := ev.GetPath().( *objpath.ListNode )
+    if r.pending || ( isElt && r.p.markedPath( ev.GetPath() ) ) {
+        r.startRedact( ev )
+        return r.redactEvent( ev, rep )
+    }
+    r.pending = false
+    r.track( ev )
+    return rep.ProcessEvent( ev )
+}
+
+// Returns a copy of val with the values marked by p redacted, such as for use
+// in an error message
+func RedactValue( val mg.Value, p *RedactionPolicy ) ( mg.Value, error ) {
+    br := NewBuildReactor( ValueBuilderFactory )
+    pip := InitReactorPipeline( NewRedactionReactor( p ), br )
+    if err := VisitValue( val, pip ); err != nil { return nil, err }
+    return br.GetValue().( mg.Value ), nil
+}
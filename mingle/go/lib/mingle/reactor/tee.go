@@ -0,0 +1,59 @@
+package reactor
+
+type TeeErrorPolicy int
+
+const (
+
+    // The first error from any branch is returned and no further events are
+    // sent to any branch
+    TeeErrorPolicyFailFast = TeeErrorPolicy( iota )
+
+    // A branch which returns an error is sent no further events, while the
+    // remaining branches continue
+    TeeErrorPolicyIsolate
+)
+
+// Sends each event it processes to each of a fixed set of branches, in order.
+// Each branch is sent its own copy of the event, so that changes one branch
+// makes to an event (such as setting its path) are not seen by the others.
+type TeeProcessor struct {
+    branches []EventProcessor
+    policy TeeErrorPolicy
+    errs []error
+    failed int
+    firstErr error
+}
+
+func NewTeeProcessor( 
+    policy TeeErrorPolicy, branches ...EventProcessor ) *TeeProcessor {
+
+    return &TeeProcessor{
+        branches: branches,
+        policy: policy,
+        errs: make( []error, len( branches ) ),
+    }
+}
+
+// Returns the error returned by each branch, indexed by the position of the
+// branch as given to NewTeeProcessor(), with a nil element for each branch
+// which has not failed
+func ( t *TeeProcessor ) BranchErrors() []error { return t.errs }
+
+// Under TeeErrorPolicyIsolate returns an error only once every branch has
+// failed, in which case the error is that of the first branch to fail.
+func ( t *TeeProcessor ) ProcessEvent( ev Event ) error {
+    if t.failed > 0 && t.policy == TeeErrorPolicyFailFast { 
+        return t.firstErr 
+    }
+    for i, b := range t.branches {
+        if t.errs[ i ] != nil { continue }
+        if err := b.ProcessEvent( copyEventAndPath( ev ) ); err != nil {
+            t.errs[ i ] = err
+            if t.failed == 0 { t.firstErr = err }
+            t.failed++
+            if t.policy == TeeErrorPolicyFailFast { return err }
+        }
+    }
+    if t.failed == len( t.branches ) { return t.firstErr }
+    return nil
+}
@@ -273,6 +273,22 @@ func VisitRegexRestriction(
     })
 }
 
+func VisitLengthRestriction(
+    rx *mg.LengthRestriction, vc bind.VisitContext ) error {
+
+    return bind.VisitStruct( vc, mg.QnameLengthRestriction, func() error {
+        err := bind.VisitFieldValue( vc, identifierMinClosed, rx.MinClosed() )
+        if err != nil { return err }
+        optVis := func( val mg.Value, id *mg.Identifier ) error {
+            if val == nil { return nil }
+            return bind.VisitFieldValue( vc, id, val )
+        }
+        if err = optVis( rx.Min(), identifierMin ); err != nil { return err }
+        if err = optVis( rx.Max(), identifierMax ); err != nil { return err }
+        return bind.VisitFieldValue( vc, identifierMaxClosed, rx.MaxClosed() )
+    })
+}
+
 func VisitAtomicTypeReference(
     at *mg.AtomicTypeReference, vc bind.VisitContext ) error {
 
@@ -303,6 +319,7 @@ func ( b *atomicBuilder ) buildInitial() ( *mg.AtomicTypeReference, error ) {
             v.Type = b.name
             rx, err = v.Build()
         case *regexBuilder: rx, err = mg.CreateRegexRestriction( v.pat )
+        case *mg.LengthRestriction: rx = v
         default: panic( libErrorf( "unhandled restriction: %T", b.rx ) )
         }
         if err != nil { return nil, err }
@@ -369,6 +386,40 @@ func newRangeBuilderBuilder( reg *bind.Registry ) mgRct.FieldSetBuilder {
         reg, &mg.RangeRestrictionBuilder{}, nil, rangeSetters... )
 }
 
+// length restrictions have the same fields as range restrictions, and so are
+// read with the same setters
+func buildLengthRestriction( 
+    val interface{}, path objpath.PathNode ) ( interface{}, error ) {
+
+    rb := val.( *mg.RangeRestrictionBuilder )
+    lb := &mg.LengthRestrictionBuilder{ 
+        MinClosed: rb.MinClosed,
+        Min: rb.Min,
+        Max: rb.Max,
+        MaxClosed: rb.MaxClosed,
+    }
+    lr, err := lb.Build()
+    if err == nil { return lr, nil }
+    if re, ok := err.( *mg.RestrictionError ); ok {
+        err = mg.NewInputError( path, re.Error() )
+    }
+    return nil, err
+}
+
+func newLengthBuilderBuilder( reg *bind.Registry ) mgRct.FieldSetBuilder {
+    return bind.CheckedFunctionsFieldSetBuilder(
+        reg, &mg.RangeRestrictionBuilder{}, buildLengthRestriction, 
+        rangeSetters... )
+}
+
+func newLengthRestrictionBuilderFactory( 
+    reg *bind.Registry ) mgRct.BuilderFactory {
+
+    res := bind.NewFunctionsBuilderFactory()
+    setStructFunc( res, reg, newLengthBuilderBuilder )
+    return res
+}
+
 var regexSetters = []*bind.CheckedFieldSetter {
     &bind.CheckedFieldSetter{
         Field: identifierPattern,
@@ -394,6 +445,8 @@ func newAtomicRestrictionBuilder( reg *bind.Registry ) mgRct.BuilderFactory {
             return newRangeBuilderBuilder( reg ), nil
         case t.Equals( mg.QnameRegexRestriction ):
             return newRegexBuilder( reg ), nil
+        case t.Equals( mg.QnameLengthRestriction ):
+            return newLengthBuilderBuilder( reg ), nil
         }
         return nil, nil
     }
@@ -432,7 +485,9 @@ func VisitListTypeReference(
     return bind.VisitStruct( vc, mg.QnameListTypeReference, func() error {
         err := bind.VisitFieldValue( vc, identifierElementType, lt.ElementType )
         if err != nil { return err }
-        return bind.VisitFieldValue( vc, identifierAllowsEmpty, lt.AllowsEmpty )
+        err = bind.VisitFieldValue( vc, identifierAllowsEmpty, lt.AllowsEmpty )
+        if err != nil || lt.Restriction == nil { return err }
+        return bind.VisitFieldValue( vc, identifierRestriction, lt.Restriction )
     })
 }
 
@@ -456,6 +511,38 @@ func newListTypeBuilderFactory( reg *bind.Registry ) mgRct.BuilderFactory {
                 obj.( *mg.ListTypeReference ).AllowsEmpty = val.( bool )
             },
         },
+        &bind.CheckedFieldSetter{
+            Field: identifierRestriction,
+            Type: mg.TypeLengthRestriction,
+            Assign: func( obj, val interface{} ) {
+                obj.( *mg.ListTypeReference ).Restriction = 
+                    val.( *mg.LengthRestriction )
+            },
+        },
+    )
+}
+
+func VisitMapTypeReference(
+    mt *mg.MapTypeReference, vc bind.VisitContext ) error {
+
+    return bind.VisitStruct( vc, mg.QnameMapTypeReference, func() error {
+        return bind.VisitFieldValue( vc, identifierValueType, mt.ValueType )
+    })
+}
+
+func newMapTypeBuilderFactory( reg *bind.Registry ) mgRct.BuilderFactory {
+    return bind.CheckedStructFactory(
+        reg,
+        func() interface{} { return &mg.MapTypeReference{} },
+        nil,
+        &bind.CheckedFieldSetter{
+            Field: identifierValueType,
+            Type: mg.TypeValue,
+            Assign: func( obj, val interface{} ) {
+                obj.( *mg.MapTypeReference ).ValueType = 
+                    val.( mg.TypeReference )
+            },
+        },
     )
 }
 
@@ -757,6 +844,199 @@ func newPrimitiveDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
     )
 }
 
+var valueSliceBuilderFactory = bind.CheckedListFieldStarter(
+    func() interface{} { return make( []mg.Value, 0, 2 ) },
+    bind.ListElementFactoryFuncForType( mg.TypeValue ),
+    func( l, val interface{} ) interface{} {
+        return append( l.( []mg.Value ), mg.MustValue( val ) )
+    },
+)
+
+func VisitAnnotation( a *types.Annotation, vc bind.VisitContext ) error {
+    return bind.VisitStruct( vc, QnameAnnotation, func() error {
+        err := bind.VisitFieldValue( vc, identifierName, a.Name )
+        if err != nil { return err }
+        if len( a.Arguments ) == 0 { return nil }
+        return bind.VisitFieldFunc( vc, identifierArguments, func() error {
+            ln := len( a.Arguments )
+            f := func( i int ) interface{} { return a.Arguments[ i ] }
+            return bind.VisitListValue( vc, typeValueList, ln, f )
+        })
+    })
+}
+
+func newAnnotationFactory( reg *bind.Registry ) mgRct.BuilderFactory {
+    return bind.CheckedStructFactory(
+        reg,
+        func() interface{} { return &types.Annotation{} },
+        nil,
+        &bind.CheckedFieldSetter{
+            Field: identifierName,
+            Type: mg.TypeIdentifier,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.Annotation ).Name = val.( *mg.Identifier )
+            },
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierArguments,
+            StartField: valueSliceBuilderFactory,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.Annotation ).Arguments = val.( []mg.Value )
+            },
+        },
+    )
+}
+
+// annotations are only sent when present so that definitions without any
+// round-trip with a nil slice
+func visitOptAnnotations( 
+    annots []*types.Annotation, vc bind.VisitContext ) error {
+
+    if len( annots ) == 0 { return nil }
+    return bind.VisitFieldFunc( vc, identifierAnnotations, func() error {
+        ln := len( annots )
+        f := func( i int ) interface{} { return annots[ i ] }
+        return bind.VisitListValue( vc, typeAnnotationList, ln, f )
+    })
+}
+
+var annotationSliceBuilderFactory = bind.CheckedListFieldStarter(
+    func() interface{} { return make( []*types.Annotation, 0, 2 ) },
+    bind.ListElementFactoryFuncForType( TypeAnnotation ),
+    func( l, val interface{} ) interface{} {
+        return append( l.( []*types.Annotation ), val.( *types.Annotation ) )
+    },
+)
+
+type annotationsAssignFunc func( obj interface{}, a []*types.Annotation )
+
+func annotationsFieldSetter( 
+    assign annotationsAssignFunc ) *bind.CheckedFieldSetter {
+
+    return &bind.CheckedFieldSetter{
+        Field: identifierAnnotations,
+        StartField: annotationSliceBuilderFactory,
+        Assign: func( obj, val interface{} ) {
+            assign( obj, val.( []*types.Annotation ) )
+        },
+    }
+}
+
+func visitOptDoc( doc string, vc bind.VisitContext ) error {
+    if doc == "" { return nil }
+    return bind.VisitFieldValue( vc, identifierDoc, mg.String( doc ) )
+}
+
+type docAssignFunc func( obj interface{}, doc string )
+
+func docFieldSetter( assign docAssignFunc ) *bind.CheckedFieldSetter {
+    return &bind.CheckedFieldSetter{
+        Field: identifierDoc,
+        Type: mg.TypeString,
+        Assign: func( obj, val interface{} ) { assign( obj, val.( string ) ) },
+    }
+}
+
+func VisitEnumValueAnnotations( 
+    eva *types.EnumValueAnnotations, vc bind.VisitContext ) error {
+
+    return bind.VisitStruct( vc, QnameEnumValueAnnotations, func() error {
+        err := bind.VisitFieldValue( vc, identifierValue, eva.Value )
+        if err != nil { return err }
+        err = visitOptAnnotations( eva.Annotations, vc )
+        if err != nil { return err }
+        return visitOptDoc( eva.Doc, vc )
+    })
+}
+
+func newEnumValueAnnotationsFactory( 
+    reg *bind.Registry ) mgRct.BuilderFactory {
+
+    return bind.CheckedStructFactory(
+        reg,
+        func() interface{} { return &types.EnumValueAnnotations{} },
+        nil,
+        &bind.CheckedFieldSetter{
+            Field: identifierValue,
+            Type: mg.TypeIdentifier,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.EnumValueAnnotations ).Value = 
+                    val.( *mg.Identifier )
+            },
+        },
+        annotationsFieldSetter( func( obj interface{}, a []*types.Annotation ) {
+            obj.( *types.EnumValueAnnotations ).Annotations = a
+        }),
+        docFieldSetter( func( obj interface{}, doc string ) {
+            obj.( *types.EnumValueAnnotations ).Doc = doc
+        }),
+    )
+}
+
+func VisitEnumValueOrdinal(
+    eo *types.EnumValueOrdinal, vc bind.VisitContext ) error {
+
+    return bind.VisitStruct( vc, QnameEnumValueOrdinal, func() error {
+        err := bind.VisitFieldValue( vc, identifierValue, eo.Value )
+        if err != nil { return err }
+        return bind.VisitFieldValue( vc, identifierOrdinal, eo.Ordinal )
+    })
+}
+
+func newEnumValueOrdinalFactory( reg *bind.Registry ) mgRct.BuilderFactory {
+    return bind.CheckedStructFactory(
+        reg,
+        func() interface{} { return &types.EnumValueOrdinal{} },
+        nil,
+        &bind.CheckedFieldSetter{
+            Field: identifierValue,
+            Type: mg.TypeIdentifier,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.EnumValueOrdinal ).Value = val.( *mg.Identifier )
+            },
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierOrdinal,
+            Type: mg.TypeInt32,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.EnumValueOrdinal ).Ordinal = val.( int32 )
+            },
+        },
+    )
+}
+
+func VisitEnumValueAlias(
+    ea *types.EnumValueAlias, vc bind.VisitContext ) error {
+
+    return bind.VisitStruct( vc, QnameEnumValueAlias, func() error {
+        err := bind.VisitFieldValue( vc, identifierAlias, ea.Alias )
+        if err != nil { return err }
+        return bind.VisitFieldValue( vc, identifierValue, ea.Value )
+    })
+}
+
+func newEnumValueAliasFactory( reg *bind.Registry ) mgRct.BuilderFactory {
+    return bind.CheckedStructFactory(
+        reg,
+        func() interface{} { return &types.EnumValueAlias{} },
+        nil,
+        &bind.CheckedFieldSetter{
+            Field: identifierAlias,
+            Type: mg.TypeIdentifier,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.EnumValueAlias ).Alias = val.( *mg.Identifier )
+            },
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierValue,
+            Type: mg.TypeIdentifier,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.EnumValueAlias ).Value = val.( *mg.Identifier )
+            },
+        },
+    )
+}
+
 func VisitFieldDefinition(
     def *types.FieldDefinition, vc bind.VisitContext ) error {
 
@@ -769,7 +1049,9 @@ func VisitFieldDefinition(
             err = bind.VisitFieldValue( vc, identifierDefault, def.Default )
             if err != nil { return err }
         }
-        return nil
+        err = visitOptAnnotations( def.Annotations, vc )
+        if err != nil { return err }
+        return visitOptDoc( def.Doc, vc )
     })
 }
 
@@ -799,6 +1081,12 @@ func newFieldDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
                 obj.( *types.FieldDefinition ).Default = mg.MustValue( val )
             },
         },
+        annotationsFieldSetter( func( obj interface{}, a []*types.Annotation ) {
+            obj.( *types.FieldDefinition ).Annotations = a
+        }),
+        docFieldSetter( func( obj interface{}, doc string ) {
+            obj.( *types.FieldDefinition ).Doc = doc
+        }),
     )
 }
 
@@ -873,23 +1161,49 @@ func VisitUnionTypeDefinition(
     return bind.VisitStruct( vc, QnameUnionTypeDefinition, func() error {
         ln := len( utd.Types )
         f := func( i int ) interface{} { return utd.Types[ i ] }
-        return bind.VisitFieldFunc( vc, identifierTypes, func() error {
+        err := bind.VisitFieldFunc( vc, identifierTypes, func() error {
             return bind.VisitListValue( vc, typeUnionTypeTypesList, ln, f )
         })
+        if err != nil || ! utd.IsTagged() { return err }
+        return bind.VisitFieldFunc( vc, identifierTags, func() error {
+            f := func( i int ) interface{} { return utd.Tags[ i ] }
+            return bind.VisitListValue( vc, typeIdentifierPointerList, ln, f )
+        })
     })
 }
 
 func newUnionTypeDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
-    type utBldr struct { typs []mg.TypeReference }
+    type utBldr struct { 
+        typs []mg.TypeReference 
+        tags []*mg.Identifier
+    }
     return bind.CheckedStructFactory(
         reg,
         func() interface{} { return &utBldr{} },
         func( val interface{}, path objpath.PathNode ) ( interface{}, error ) {
             utb := val.( *utBldr )
-            res, err := types.CreateUnionTypeDefinitionTypes( utb.typs... )
+            var res *types.UnionTypeDefinition
+            var err error
+            if utb.tags == nil {
+                res, err = types.CreateUnionTypeDefinitionTypes( utb.typs... )
+            } else if len( utb.tags ) != len( utb.typs ) {
+                tmpl := "union has %d tags for %d types"
+                return nil, mg.NewInputErrorf( 
+                    path, tmpl, len( utb.tags ), len( utb.typs ) )
+            } else {
+                res, err = 
+                    types.CreateTaggedUnionTypeDefinition( utb.tags, utb.typs )
+            }
             if err == nil { return res, nil }
             return nil, mg.NewInputError( path, err.Error() )
         },
+        &bind.CheckedFieldSetter{
+            Field: identifierTags,
+            StartField: idSliceBuilderFactory,
+            Assign: func( obj, val interface{} ) {
+                obj.( *utBldr ).tags = val.( []*mg.Identifier )
+            },
+        },
         &bind.CheckedFieldSetter{
             Field: identifierTypes,
             StartField: bind.CheckedListFieldStarter(
@@ -915,7 +1229,10 @@ func VisitUnionDefinition(
         err := bind.VisitFieldValue( vc, identifierName, ud.Name )
         if err != nil { return err }
         err = bind.VisitFieldValue( vc, identifierUnion, ud.Union )
-        return err
+        if err != nil { return err }
+        err = visitOptAnnotations( ud.Annotations, vc )
+        if err != nil { return err }
+        return visitOptDoc( ud.Doc, vc )
     })
 }
 
@@ -940,6 +1257,12 @@ func newUnionDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
                     val.( *types.UnionTypeDefinition )
             },
         },
+        annotationsFieldSetter( func( obj interface{}, a []*types.Annotation ) {
+            obj.( *types.UnionDefinition ).Annotations = a
+        }),
+        docFieldSetter( func( obj interface{}, doc string ) {
+            obj.( *types.UnionDefinition ).Doc = doc
+        }),
     )
 }
 
@@ -996,7 +1319,10 @@ func VisitPrototypeDefinition(
         err := bind.VisitFieldValue( vc, identifierName, pd.Name )
         if err != nil { return err }
         err = bind.VisitFieldValue( vc, identifierSignature, pd.Signature )
-        return err
+        if err != nil { return err }
+        err = visitOptAnnotations( pd.Annotations, vc )
+        if err != nil { return err }
+        return visitOptDoc( pd.Doc, vc )
     })
 }
 
@@ -1021,6 +1347,156 @@ func newProtoDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
                     val.( *types.CallSignature )
             },
         },
+        annotationsFieldSetter( func( obj interface{}, a []*types.Annotation ) {
+            obj.( *types.PrototypeDefinition ).Annotations = a
+        }),
+        docFieldSetter( func( obj interface{}, doc string ) {
+            obj.( *types.PrototypeDefinition ).Doc = doc
+        }),
+    )
+}
+
+func VisitFieldComparison(
+    fc *types.FieldComparison, vc bind.VisitContext ) error {
+
+    return bind.VisitStruct( vc, QnameFieldComparison, func() error {
+        err := bind.VisitFieldValue( vc, identifierField, fc.Field )
+        if err != nil { return err }
+        op := mg.String( fc.Operator )
+        err = bind.VisitFieldValue( vc, identifierOperator, op )
+        if err != nil { return err }
+        if fc.Other != nil {
+            return bind.VisitFieldValue( vc, identifierOther, fc.Other )
+        }
+        return bind.VisitFieldValue( vc, identifierValue, fc.Value )
+    })
+}
+
+func newFieldComparisonFactory( reg *bind.Registry ) mgRct.BuilderFactory {
+    return bind.CheckedStructFactory(
+        reg,
+        func() interface{} { return &types.FieldComparison{} },
+        func( val interface{}, path objpath.PathNode ) ( interface{}, error ) {
+            fc := val.( *types.FieldComparison )
+            if _, ok := types.ComparisonOperatorOf( string( fc.Operator ) ); 
+               ! ok {
+                tmpl := "invalid operator: %q"
+                return nil, mg.NewInputErrorf( path, tmpl, fc.Operator )
+            }
+            if ( fc.Other == nil ) == ( fc.Value == nil ) {
+                msg := "exactly one of other or value must be set"
+                return nil, mg.NewInputError( path, msg )
+            }
+            return fc, nil
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierField,
+            Type: mg.TypeIdentifier,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.FieldComparison ).Field = val.( *mg.Identifier )
+            },
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierOperator,
+            Type: mg.TypeString,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.FieldComparison ).Operator = 
+                    types.ComparisonOperator( val.( string ) )
+            },
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierOther,
+            Type: mg.TypeIdentifier,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.FieldComparison ).Other = val.( *mg.Identifier )
+            },
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierValue,
+            Type: mg.TypeValue,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.FieldComparison ).Value = mg.MustValue( val )
+            },
+        },
+    )
+}
+
+func VisitFieldPresence(
+    fp *types.FieldPresence, vc bind.VisitContext ) error {
+
+    return bind.VisitStruct( vc, QnameFieldPresence, func() error {
+        rule := mg.String( fp.Rule )
+        err := bind.VisitFieldValue( vc, identifierRule, rule )
+        if err != nil { return err }
+        return bind.VisitFieldFunc( vc, identifierFields, func() error {
+            ln := len( fp.Fields )
+            f := func( i int ) interface{} { return fp.Fields[ i ] }
+            return bind.VisitListValue( vc, typeIdentifierPointerList, ln, f )
+        })
+    })
+}
+
+func newFieldPresenceFactory( reg *bind.Registry ) mgRct.BuilderFactory {
+    return bind.CheckedStructFactory(
+        reg,
+        func() interface{} { return &types.FieldPresence{} },
+        func( val interface{}, path objpath.PathNode ) ( interface{}, error ) {
+            fp := val.( *types.FieldPresence )
+            if _, ok := types.PresenceRuleOf( string( fp.Rule ) ); ! ok {
+                tmpl := "invalid rule: %q"
+                return nil, mg.NewInputErrorf( path, tmpl, fp.Rule )
+            }
+            return fp, nil
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierRule,
+            Type: mg.TypeString,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.FieldPresence ).Rule = 
+                    types.PresenceRule( val.( string ) )
+            },
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierFields,
+            StartField: idSliceBuilderFactory,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.FieldPresence ).Fields = val.( []*mg.Identifier )
+            },
+        },
+    )
+}
+
+func VisitStructConstraint(
+    sc *types.StructConstraint, vc bind.VisitContext ) error {
+
+    return bind.VisitStruct( vc, QnameStructConstraint, func() error {
+        err := bind.VisitFieldValue( vc, identifierExpression, sc.Expression )
+        if err != nil || sc.Message == "" { return err }
+        msg := mg.String( sc.Message )
+        return bind.VisitFieldValue( vc, identifierMessage, msg )
+    })
+}
+
+func newStructConstraintFactory( reg *bind.Registry ) mgRct.BuilderFactory {
+    return bind.CheckedStructFactory(
+        reg,
+        func() interface{} { return &types.StructConstraint{} },
+        nil,
+        &bind.CheckedFieldSetter{
+            Field: identifierExpression,
+            Type: mg.TypeValue,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.StructConstraint ).Expression = 
+                    val.( types.ConstraintExpression )
+            },
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierMessage,
+            Type: mg.TypeString,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.StructConstraint ).Message = val.( string )
+            },
+        },
     )
 }
 
@@ -1036,7 +1512,28 @@ func VisitStructDefinition(
             err = bind.VisitFieldValue( vc, identifierConstructors, c )
             if err != nil { return err }
         }
-        return nil
+        if len( sd.Constraints ) > 0 {
+            fld := identifierConstraints
+            err = bind.VisitFieldFunc( vc, fld, func() error {
+                ln := len( sd.Constraints )
+                f := func( i int ) interface{} { return sd.Constraints[ i ] }
+                lt := typeStructConstraintList
+                return bind.VisitListValue( vc, lt, ln, f )
+            })
+            if err != nil { return err }
+        }
+        if len( sd.Reserved ) > 0 {
+            err = bind.VisitFieldFunc( vc, identifierReserved, func() error {
+                ln := len( sd.Reserved )
+                f := func( i int ) interface{} { return sd.Reserved[ i ] }
+                lt := typeIdentifierPointerList
+                return bind.VisitListValue( vc, lt, ln, f )
+            })
+            if err != nil { return err }
+        }
+        err = visitOptAnnotations( sd.Annotations, vc )
+        if err != nil { return err }
+        return visitOptDoc( sd.Doc, vc )
     })
 }
 
@@ -1068,6 +1565,37 @@ func newStructDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
                     val.( *types.UnionTypeDefinition )
             },
         },
+        &bind.CheckedFieldSetter{
+            Field: identifierConstraints,
+            StartField: bind.CheckedListFieldStarter(
+                func() interface{} { 
+                    return make( []*types.StructConstraint, 0, 2 )
+                },
+                bind.ListElementFactoryFuncForType( TypeStructConstraint ),
+                func( l, val interface{} ) interface{} {
+                    scs := l.( []*types.StructConstraint )
+                    return append( scs, val.( *types.StructConstraint ) )
+                },
+            ),
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.StructDefinition ).Constraints = 
+                    val.( []*types.StructConstraint )
+            },
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierReserved,
+            StartField: idSliceBuilderFactory,
+            Assign: func( obj, val interface{} ) {
+                obj.( *types.StructDefinition ).Reserved = 
+                    val.( []*mg.Identifier )
+            },
+        },
+        annotationsFieldSetter( func( obj interface{}, a []*types.Annotation ) {
+            obj.( *types.StructDefinition ).Annotations = a
+        }),
+        docFieldSetter( func( obj interface{}, doc string ) {
+            obj.( *types.StructDefinition ).Doc = doc
+        }),
     )
 }
 
@@ -1077,7 +1605,11 @@ func VisitSchemaDefinition(
     return bind.VisitStruct( vc, QnameSchemaDefinition, func() error {
         err := bind.VisitFieldValue( vc, identifierName, sd.Name )
         if err != nil { return err }
-        return bind.VisitFieldValue( vc, identifierFields, sd.Fields )
+        err = bind.VisitFieldValue( vc, identifierFields, sd.Fields )
+        if err != nil { return err }
+        err = visitOptAnnotations( sd.Annotations, vc )
+        if err != nil { return err }
+        return visitOptDoc( sd.Doc, vc )
     })
 }
 
@@ -1101,6 +1633,12 @@ func newSchemaDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
                 obj.( *types.SchemaDefinition ).Fields = val.( *types.FieldSet )
             },
         },
+        annotationsFieldSetter( func( obj interface{}, a []*types.Annotation ) {
+            obj.( *types.SchemaDefinition ).Annotations = a
+        }),
+        docFieldSetter( func( obj interface{}, doc string ) {
+            obj.( *types.SchemaDefinition ).Doc = doc
+        }),
     )
 }
 
@@ -1110,7 +1648,11 @@ func VisitAliasedTypeDefinition(
     return bind.VisitStruct( vc, QnameAliasedTypeDefinition, func() error {
         err := bind.VisitFieldValue( vc, identifierName, ad.Name )
         if err != nil { return err }
-        return bind.VisitFieldValue( vc, identifierAliasedType, ad.AliasedType )
+        err = bind.VisitFieldValue( vc, identifierAliasedType, ad.AliasedType )
+        if err != nil { return err }
+        err = visitOptAnnotations( ad.Annotations, vc )
+        if err != nil { return err }
+        return visitOptDoc( ad.Doc, vc )
     })
 }
 
@@ -1135,6 +1677,12 @@ func newAliasedTypeDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
                     val.( mg.TypeReference )
             },
         },
+        annotationsFieldSetter( func( obj interface{}, a []*types.Annotation ) {
+            obj.( *types.AliasedTypeDefinition ).Annotations = a
+        }),
+        docFieldSetter( func( obj interface{}, doc string ) {
+            obj.( *types.AliasedTypeDefinition ).Doc = doc
+        }),
     )
 }
 
@@ -1144,24 +1692,70 @@ func VisitEnumDefinition(
     return bind.VisitStruct( vc, QnameEnumDefinition, func() error {
         err := bind.VisitFieldValue( vc, identifierName, ed.Name )
         if err != nil { return err }
-        return bind.VisitFieldFunc( vc, identifierValues, func() error {
+        err = bind.VisitFieldFunc( vc, identifierValues, func() error {
             ln := len( ed.Values )
             f := func( i int ) interface{} { return ed.Values[ i ] }
             return bind.VisitListValue( vc, typeIdentifierPointerList, ln, f )
         })
+        if err != nil { return err }
+        err = visitOptAnnotations( ed.Annotations, vc )
+        if err != nil { return err }
+        err = visitOptDoc( ed.Doc, vc )
+        if err != nil { return err }
+        if len( ed.ValueAnnotations ) > 0 {
+            fld := identifierValueAnnotations
+            err = bind.VisitFieldFunc( vc, fld, func() error {
+                ln := len( ed.ValueAnnotations )
+                f := func( i int ) interface{} { 
+                    return ed.ValueAnnotations[ i ] 
+                }
+                lt := typeEnumValueAnnotationsList
+                return bind.VisitListValue( vc, lt, ln, f )
+            })
+            if err != nil { return err }
+        }
+        if len( ed.Ordinals ) > 0 {
+            err = bind.VisitFieldFunc( vc, identifierOrdinals, func() error {
+                ln := len( ed.Ordinals )
+                f := func( i int ) interface{} { return ed.Ordinals[ i ] }
+                lt := typeEnumValueOrdinalList
+                return bind.VisitListValue( vc, lt, ln, f )
+            })
+            if err != nil { return err }
+        }
+        if len( ed.Aliases ) == 0 { return nil }
+        return bind.VisitFieldFunc( vc, identifierAliases, func() error {
+            ln := len( ed.Aliases )
+            f := func( i int ) interface{} { return ed.Aliases[ i ] }
+            return bind.VisitListValue( vc, typeEnumValueAliasList, ln, f )
+        })
     })
 }
 
 func newEnumDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
-    type edBldr struct { nm *mg.QualifiedTypeName; vals []*mg.Identifier }
+    type edBldr struct { 
+        nm *mg.QualifiedTypeName
+        vals []*mg.Identifier 
+        annots []*types.Annotation
+        doc string
+        valAnnots []*types.EnumValueAnnotations
+        ords []*types.EnumValueOrdinal
+        aliases []*types.EnumValueAlias
+    }
     return bind.CheckedStructFactory(
         reg,
         func() interface{} { return &edBldr{} },
         func( val interface{}, path objpath.PathNode ) ( interface{}, error ) {
             edb := val.( *edBldr )
             ed, err := types.CreateEnumDefinition( edb.nm, edb.vals... )
-            if err == nil { return ed, nil }
-            return nil, mg.NewInputError( path, err.Error() )
+            if err != nil { return nil, mg.NewInputError( path, err.Error() ) }
+            ed.Annotations, ed.ValueAnnotations = edb.annots, edb.valAnnots
+            ed.Doc = edb.doc
+            ed.Ordinals, ed.Aliases = edb.ords, edb.aliases
+            if err := ed.CheckValueNames(); err != nil {
+                return nil, mg.NewInputError( path, err.Error() )
+            }
+            return ed, nil
         },
         &bind.CheckedFieldSetter{
             Field: identifierName,
@@ -1177,6 +1771,61 @@ func newEnumDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
                 obj.( *edBldr ).vals = val.( []*mg.Identifier )
             },
         },
+        annotationsFieldSetter( func( obj interface{}, a []*types.Annotation ) {
+            obj.( *edBldr ).annots = a
+        }),
+        docFieldSetter( func( obj interface{}, doc string ) {
+            obj.( *edBldr ).doc = doc
+        }),
+        &bind.CheckedFieldSetter{
+            Field: identifierValueAnnotations,
+            StartField: bind.CheckedListFieldStarter(
+                func() interface{} { 
+                    return make( []*types.EnumValueAnnotations, 0, 2 )
+                },
+                bind.ListElementFactoryFuncForType( TypeEnumValueAnnotations ),
+                func( l, val interface{} ) interface{} {
+                    vas := l.( []*types.EnumValueAnnotations )
+                    return append( vas, val.( *types.EnumValueAnnotations ) )
+                },
+            ),
+            Assign: func( obj, val interface{} ) {
+                obj.( *edBldr ).valAnnots = 
+                    val.( []*types.EnumValueAnnotations )
+            },
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierOrdinals,
+            StartField: bind.CheckedListFieldStarter(
+                func() interface{} { 
+                    return make( []*types.EnumValueOrdinal, 0, 4 )
+                },
+                bind.ListElementFactoryFuncForType( TypeEnumValueOrdinal ),
+                func( l, val interface{} ) interface{} {
+                    ords := l.( []*types.EnumValueOrdinal )
+                    return append( ords, val.( *types.EnumValueOrdinal ) )
+                },
+            ),
+            Assign: func( obj, val interface{} ) {
+                obj.( *edBldr ).ords = val.( []*types.EnumValueOrdinal )
+            },
+        },
+        &bind.CheckedFieldSetter{
+            Field: identifierAliases,
+            StartField: bind.CheckedListFieldStarter(
+                func() interface{} { 
+                    return make( []*types.EnumValueAlias, 0, 2 )
+                },
+                bind.ListElementFactoryFuncForType( TypeEnumValueAlias ),
+                func( l, val interface{} ) interface{} {
+                    als := l.( []*types.EnumValueAlias )
+                    return append( als, val.( *types.EnumValueAlias ) )
+                },
+            ),
+            Assign: func( obj, val interface{} ) {
+                obj.( *edBldr ).aliases = val.( []*types.EnumValueAlias )
+            },
+        },
     )
 }
 
@@ -1186,7 +1835,11 @@ func VisitOperationDefinition(
     return bind.VisitStruct( vc, QnameOperationDefinition, func() error {
         err := bind.VisitFieldValue( vc, identifierName, od.Name )
         if err != nil { return err }
-        return bind.VisitFieldValue( vc, identifierSignature, od.Signature )
+        err = bind.VisitFieldValue( vc, identifierSignature, od.Signature )
+        if err != nil { return err }
+        err = visitOptAnnotations( od.Annotations, vc )
+        if err != nil { return err }
+        return visitOptDoc( od.Doc, vc )
     })
 }
 
@@ -1210,6 +1863,12 @@ func newOpDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
                     val.( *types.CallSignature )
             },
         },
+        annotationsFieldSetter( func( obj interface{}, a []*types.Annotation ) {
+            obj.( *types.OperationDefinition ).Annotations = a
+        }),
+        docFieldSetter( func( obj interface{}, doc string ) {
+            obj.( *types.OperationDefinition ).Doc = doc
+        }),
     )
 }
 
@@ -1229,7 +1888,9 @@ func VisitServiceDefinition(
             err = bind.VisitFieldValue( vc, identifierSecurity, sec )
             if err != nil { return err }
         }
-        return nil
+        err = visitOptAnnotations( sd.Annotations, vc )
+        if err != nil { return err }
+        return visitOptDoc( sd.Doc, vc )
     })
 }
 
@@ -1280,6 +1941,12 @@ func newServiceDefFactory( reg *bind.Registry ) mgRct.BuilderFactory {
                 obj.( *svcBldr ).sd.Security = val.( *mg.QualifiedTypeName )
             },
         },
+        annotationsFieldSetter( func( obj interface{}, a []*types.Annotation ) {
+            obj.( *svcBldr ).sd.Annotations = a
+        }),
+        docFieldSetter( func( obj interface{}, doc string ) {
+            obj.( *svcBldr ).sd.Doc = doc
+        }),
     )
 }
 
@@ -1293,12 +1960,14 @@ func visitBuiltinTypeOk(
     case *mg.QualifiedTypeName: return VisitQualifiedTypeName( v, vc ), true
     case *mg.AtomicTypeReference: return VisitAtomicTypeReference( v, vc ), true
     case *mg.ListTypeReference: return VisitListTypeReference( v, vc ), true
+    case *mg.MapTypeReference: return VisitMapTypeReference( v, vc ), true
     case *mg.PointerTypeReference: 
         return VisitPointerTypeReference( v, vc ), true
     case *mg.NullableTypeReference:
         return VisitNullableTypeReference( v, vc ), true
     case *mg.RangeRestriction: return VisitRangeRestriction( v, vc ), true
     case *mg.RegexRestriction: return VisitRegexRestriction( v, vc ), true
+    case *mg.LengthRestriction: return VisitLengthRestriction( v, vc ), true
     case objpath.PathNode: return VisitIdentifierPath( v, vc ), true
     case *mg.InputError: return VisitInputError( v, vc ), true
     case *mg.UnrecognizedFieldError: 
@@ -1306,6 +1975,11 @@ func visitBuiltinTypeOk(
     case *mg.MissingFieldsError: return VisitMissingFieldsError( v, vc ), true
     case *types.PrimitiveDefinition:
         return VisitPrimitiveDefinition( v, vc ), true
+    case *types.Annotation: return VisitAnnotation( v, vc ), true
+    case *types.EnumValueAnnotations:
+        return VisitEnumValueAnnotations( v, vc ), true
+    case *types.EnumValueOrdinal: return VisitEnumValueOrdinal( v, vc ), true
+    case *types.EnumValueAlias: return VisitEnumValueAlias( v, vc ), true
     case *types.FieldDefinition: return VisitFieldDefinition( v, vc ), true
     case *types.FieldSet: return VisitFieldSet( v, vc ), true
     case *types.UnionTypeDefinition:
@@ -1314,6 +1988,9 @@ func visitBuiltinTypeOk(
     case *types.CallSignature: return VisitCallSignature( v, vc ), true
     case *types.PrototypeDefinition: 
         return VisitPrototypeDefinition( v, vc ), true
+    case *types.FieldComparison: return VisitFieldComparison( v, vc ), true
+    case *types.FieldPresence: return VisitFieldPresence( v, vc ), true
+    case *types.StructConstraint: return VisitStructConstraint( v, vc ), true
     case *types.StructDefinition: return VisitStructDefinition( v, vc ), true
     case *types.SchemaDefinition: return VisitSchemaDefinition( v, vc ), true
     case *types.AliasedTypeDefinition:
@@ -1339,6 +2016,14 @@ func initCoreBindings( reg *bind.Registry ) {
         mg.QnameListTypeReference,
         newListTypeBuilderFactory( reg ),
     )
+    reg.MustAddValue(
+        mg.QnameMapTypeReference,
+        newMapTypeBuilderFactory( reg ),
+    )
+    reg.MustAddValue(
+        mg.QnameLengthRestriction,
+        newLengthRestrictionBuilderFactory( reg ),
+    )
     reg.MustAddValue(
         mg.QnamePointerTypeReference,
         newPointerTypeBuilderFactory( reg ),
@@ -1359,12 +2044,22 @@ func initCoreBindings( reg *bind.Registry ) {
 
 func initTypesBindings( reg *bind.Registry ) {
     reg.MustAddValue( QnamePrimitiveDefinition, newPrimitiveDefFactory( reg ) )
+    reg.MustAddValue( QnameAnnotation, newAnnotationFactory( reg ) )
+    reg.MustAddValue( 
+        QnameEnumValueAnnotations, newEnumValueAnnotationsFactory( reg ) )
+    reg.MustAddValue( 
+        QnameEnumValueOrdinal, newEnumValueOrdinalFactory( reg ) )
+    reg.MustAddValue( QnameEnumValueAlias, newEnumValueAliasFactory( reg ) )
     reg.MustAddValue( QnameFieldDefinition, newFieldDefFactory( reg ) )
     reg.MustAddValue( QnameFieldSet, newFieldSetFactory( reg ) )
     reg.MustAddValue( QnameUnionTypeDefinition, newUnionTypeDefFactory( reg ) )
     reg.MustAddValue( QnameUnionDefinition, newUnionDefFactory( reg ) )
     reg.MustAddValue( QnameCallSignature, newCallSigFactory( reg ) )
     reg.MustAddValue( QnamePrototypeDefinition, newProtoDefFactory( reg ) )
+    reg.MustAddValue( QnameFieldComparison, newFieldComparisonFactory( reg ) )
+    reg.MustAddValue( QnameFieldPresence, newFieldPresenceFactory( reg ) )
+    reg.MustAddValue( 
+        QnameStructConstraint, newStructConstraintFactory( reg ) )
     reg.MustAddValue( QnameStructDefinition, newStructDefFactory( reg ) )
     reg.MustAddValue( QnameSchemaDefinition, newSchemaDefFactory( reg ) )
     reg.MustAddValue(
@@ -21,11 +21,18 @@ func mkTypesQnTypPair(
 }
 
 var (
+    identifierAlias = idUnsafe( "alias" )
     identifierAliasedType = idUnsafe( "aliased", "type" )
+    identifierAliases = idUnsafe( "aliases" )
     identifierAllowsEmpty = idUnsafe( "allows", "empty" )
+    identifierAnnotations = idUnsafe( "annotations" )
+    identifierArguments = idUnsafe( "arguments" )
+    identifierConstraints = idUnsafe( "constraints" )
     identifierConstructors = idUnsafe( "constructors" )
     identifierDefault = idUnsafe( "default" )
+    identifierDoc = idUnsafe( "doc" )
     identifierElementType = idUnsafe( "element", "type" )
+    identifierExpression = idUnsafe( "expression" )
     identifierField = idUnsafe( "field" )
     identifierFields = idUnsafe( "fields" )
     identifierLocation = idUnsafe( "location" )
@@ -38,16 +45,26 @@ var (
     identifierNamespace = idUnsafe( "namespace" )
     identifierOperation = idUnsafe( "operation" )
     identifierOperations = idUnsafe( "operations" )
+    identifierOperator = idUnsafe( "operator" )
+    identifierOrdinal = idUnsafe( "ordinal" )
+    identifierOrdinals = idUnsafe( "ordinals" )
+    identifierOther = idUnsafe( "other" )
     identifierParts = idUnsafe( "parts" )
     identifierPattern = idUnsafe( "pattern" )
+    identifierReserved = idUnsafe( "reserved" )
     identifierRestriction = idUnsafe( "restriction" )
     identifierReturn = idUnsafe( "return" )
+    identifierRule = idUnsafe( "rule" )
     identifierSecurity = idUnsafe( "security" )
     identifierSignature = idUnsafe( "signature" )
+    identifierTags = idUnsafe( "tags" )
     identifierThrows = idUnsafe( "throws" )
     identifierType = idUnsafe( "type" )
     identifierTypes = idUnsafe( "types" )
     identifierUnion = idUnsafe( "union" )
+    identifierValue = idUnsafe( "value" )
+    identifierValueAnnotations = idUnsafe( "value", "annotations" )
+    identifierValueType = idUnsafe( "value", "type" )
     identifierValues = idUnsafe( "values" )
     identifierVersion = idUnsafe( "version" )
 
@@ -76,6 +93,42 @@ var (
         Version: idUnsafe( "v1" ),
     }
 
+    typeValueList = &mg.ListTypeReference{ 
+        ElementType: mg.TypeValue, 
+        AllowsEmpty: true,
+    }
+
+    QnameAnnotation, TypeAnnotation = mkTypesQnTypPair( "Annotation" )
+
+    typeAnnotationList = &mg.ListTypeReference{
+        ElementType: ptrTyp( TypeAnnotation ),
+        AllowsEmpty: true,
+    }
+
+    QnameEnumValueAnnotations, TypeEnumValueAnnotations = 
+        mkTypesQnTypPair( "EnumValueAnnotations" )
+
+    typeEnumValueAnnotationsList = &mg.ListTypeReference{
+        ElementType: ptrTyp( TypeEnumValueAnnotations ),
+        AllowsEmpty: true,
+    }
+
+    QnameEnumValueOrdinal, TypeEnumValueOrdinal = 
+        mkTypesQnTypPair( "EnumValueOrdinal" )
+
+    typeEnumValueOrdinalList = &mg.ListTypeReference{
+        ElementType: ptrTyp( TypeEnumValueOrdinal ),
+        AllowsEmpty: true,
+    }
+
+    QnameEnumValueAlias, TypeEnumValueAlias = 
+        mkTypesQnTypPair( "EnumValueAlias" )
+
+    typeEnumValueAliasList = &mg.ListTypeReference{
+        ElementType: ptrTyp( TypeEnumValueAlias ),
+        AllowsEmpty: true,
+    }
+
     QnamePrimitiveDefinition, TypePrimitiveDefinition = 
         mkTypesQnTypPair( "PrimitiveDefinition" )
 
@@ -83,8 +136,8 @@ var (
         mkTypesQnTypPair( "FieldDefinition" )
 
     typeFieldDefList = &mg.ListTypeReference{ 
-        ptrTyp( TypeFieldDefinition ),
-        true,
+        ElementType: ptrTyp( TypeFieldDefinition ),
+        AllowsEmpty: true,
     }
 
     QnameFieldSet, TypeFieldSet = mkTypesQnTypPair( "FieldSet" )
@@ -103,6 +156,23 @@ var (
     QnameUnionDefinition, TypeUnionDefinition = 
         mkTypesQnTypPair( "UnionDefinition" )
 
+    QnameFieldComparison, TypeFieldComparison = 
+        mkTypesQnTypPair( "FieldComparison" )
+
+    QnameFieldPresence, TypeFieldPresence = 
+        mkTypesQnTypPair( "FieldPresence" )
+
+    QnameConstraintExpression, TypeConstraintExpression =
+        mkTypesQnTypPair( "ConstraintExpression" )
+
+    QnameStructConstraint, TypeStructConstraint = 
+        mkTypesQnTypPair( "StructConstraint" )
+
+    typeStructConstraintList = &mg.ListTypeReference{
+        ElementType: ptrTyp( TypeStructConstraint ),
+        AllowsEmpty: true,
+    }
+
     QnameStructDefinition, TypeStructDefinition = 
         mkTypesQnTypPair( "StructDefinition" )
 
@@ -241,12 +311,27 @@ func initCoreV1Types() {
     mustAddBuiltinStruct( mg.QnameRegexRestriction,
         mkField0( identifierPattern, mg.TypeString ),
     )
+    mustAddBuiltinStruct( mg.QnameLengthRestriction,
+        &types.FieldDefinition{
+            Name: identifierMinClosed,
+            Type: mg.TypeBoolean,
+            Default: mg.Boolean( false ),
+        },
+        mkField0( identifierMin, mg.TypeNullableValue ),
+        mkField0( identifierMax, mg.TypeNullableValue ),
+        &types.FieldDefinition{
+            Name: identifierMaxClosed,
+            Type: mg.TypeBoolean,
+            Default: mg.Boolean( false ),
+        },
+    )
     MustAddBuiltinType(
         &types.UnionDefinition{
             Name: mg.QnameValueRestriction,
             Union: types.MustUnionTypeDefinitionTypes(
                 mg.TypeRangeRestriction,
                 mg.TypeRegexRestriction,
+                mg.TypeLengthRestriction,
             ),
         },
     )
@@ -257,6 +342,11 @@ func initCoreV1Types() {
     mustAddBuiltinStruct( mg.QnameListTypeReference,
         mkField0( identifierElementType, mg.TypeTypeReference ),
         mkField0( identifierAllowsEmpty, mg.TypeBoolean ),
+        mkField0( 
+            identifierRestriction, nilPtrTyp( mg.TypeLengthRestriction ) ),
+    )
+    mustAddBuiltinStruct( mg.QnameMapTypeReference,
+        mkField0( identifierValueType, mg.TypeTypeReference ),
     )
     mustAddBuiltinStruct( mg.QnameNullableTypeReference,
         mkField0( identifierType, mg.TypeTypeReference ),
@@ -270,6 +360,7 @@ func initCoreV1Types() {
             Union: types.MustUnionTypeDefinitionTypes(
                 mg.TypeAtomicTypeReference,
                 mg.TypeListTypeReference,
+                mg.TypeMapTypeReference,
                 mg.TypeNullableTypeReference,
                 mg.TypePointerTypeReference,
             ),
@@ -302,24 +393,54 @@ func initCoreV1Types() {
     AddLocatableErrorFields( mfeDef )
 }
 
+func mkAnnotationsField() *types.FieldDefinition {
+    return mkField0( identifierAnnotations, typeAnnotationList )
+}
+
+func mkDocField() *types.FieldDefinition {
+    return mkField0( identifierDoc, nilTyp( mg.TypeString ) )
+}
+
 func initTypesTypes() {
     mustAddBuiltinStruct( QnamePrimitiveDefinition,
         mkField0( identifierName, ptrTyp( mg.TypeQualifiedTypeName ) ),
     )
+    mustAddBuiltinStruct( QnameAnnotation,
+        mkField0( identifierName, typeIdentifierPointer ),
+        mkField0( identifierArguments, typeValueList ),
+    )
+    mustAddBuiltinStruct( QnameEnumValueAnnotations,
+        mkField0( identifierValue, typeIdentifierPointer ),
+        mkAnnotationsField(),
+        mkDocField(),
+    )
+    mustAddBuiltinStruct( QnameEnumValueOrdinal,
+        mkField0( identifierValue, typeIdentifierPointer ),
+        mkField0( identifierOrdinal, mg.TypeInt32 ),
+    )
+    mustAddBuiltinStruct( QnameEnumValueAlias,
+        mkField0( identifierAlias, typeIdentifierPointer ),
+        mkField0( identifierValue, typeIdentifierPointer ),
+    )
     mustAddBuiltinStruct( QnameFieldDefinition,
         mkField0( identifierName, typeIdentifierPointer ),
         mkField0( identifierType, mg.TypeTypeReference ),
         mkField0( identifierDefault, mg.TypeNullableValue ),
+        mkAnnotationsField(),
+        mkDocField(),
     )
     mustAddBuiltinStruct( QnameFieldSet,
         mkField0( identifierFields, typeFieldDefList ),
     )
     mustAddBuiltinStruct( QnameUnionTypeDefinition,
         mkField0( identifierTypes, typeUnionTypeTypesList ),
+        mkField0( identifierTags, nilTyp( typeIdentifierPointerList ) ),
     )
     mustAddBuiltinStruct( QnameUnionDefinition,
         mkField0( identifierName, ptrTyp( mg.TypeQualifiedTypeName ) ),
         mkField0( identifierUnion, ptrTyp( TypeUnionTypeDefinition ) ),
+        mkAnnotationsField(),
+        mkDocField(),
     )
     mustAddBuiltinStruct( QnameCallSignature,
         mkField0( identifierFields, ptrTyp( TypeFieldSet ) ),
@@ -329,33 +450,75 @@ func initTypesTypes() {
     mustAddBuiltinStruct( QnamePrototypeDefinition,
         mkField0( identifierName, ptrTyp( mg.TypeQualifiedTypeName ) ),
         mkField0( identifierSignature, ptrTyp( TypeCallSignature ) ),
+        mkAnnotationsField(),
+        mkDocField(),
+    )
+    mustAddBuiltinStruct( QnameFieldComparison,
+        mkField0( identifierField, typeIdentifierPointer ),
+        mkField0( identifierOperator, mg.TypeString ),
+        mkField0( identifierOther, nilPtrTyp( mg.TypeIdentifier ) ),
+        mkField0( identifierValue, mg.TypeNullableValue ),
+    )
+    mustAddBuiltinStruct( QnameFieldPresence,
+        mkField0( identifierRule, mg.TypeString ),
+        mkField0( identifierFields, typeIdentifierPointerList ),
+    )
+    MustAddBuiltinType(
+        &types.UnionDefinition{
+            Name: QnameConstraintExpression,
+            Union: types.MustUnionTypeDefinitionTypes(
+                TypeFieldComparison,
+                TypeFieldPresence,
+            ),
+        },
+    )
+    mustAddBuiltinStruct( QnameStructConstraint,
+        mkField0( identifierExpression, ptrTyp( TypeConstraintExpression ) ),
+        mkField0( identifierMessage, nilTyp( mg.TypeString ) ),
     )
     mustAddBuiltinStruct( QnameStructDefinition,
         mkField0( identifierName, ptrTyp( mg.TypeQualifiedTypeName ) ),
         mkField0( identifierFields, ptrTyp( TypeFieldSet ) ),
         mkField0( 
             identifierConstructors, nilPtrTyp( TypeUnionTypeDefinition ) ),
+        mkField0( identifierConstraints, typeStructConstraintList ),
+        mkField0( identifierReserved, nilTyp( typeIdentifierPointerList ) ),
+        mkAnnotationsField(),
+        mkDocField(),
     )
     mustAddBuiltinStruct( QnameSchemaDefinition,
         mkField0( identifierName, ptrTyp( mg.TypeQualifiedTypeName ) ),
         mkField0( identifierFields, ptrTyp( TypeFieldSet ) ),
+        mkAnnotationsField(),
+        mkDocField(),
     )
     mustAddBuiltinStruct( QnameAliasedTypeDefinition,
         mkField0( identifierName, ptrTyp( mg.TypeQualifiedTypeName ) ),
         mkField0( identifierAliasedType, mg.TypeTypeReference ),
+        mkAnnotationsField(),
+        mkDocField(),
     )
     mustAddBuiltinStruct( QnameEnumDefinition,
         mkField0( identifierName, ptrTyp( mg.TypeQualifiedTypeName ) ),
         mkField0( identifierValues, typeIdentifierPointerList ),
+        mkAnnotationsField(),
+        mkDocField(),
+        mkField0( identifierValueAnnotations, typeEnumValueAnnotationsList ),
+        mkField0( identifierOrdinals, typeEnumValueOrdinalList ),
+        mkField0( identifierAliases, typeEnumValueAliasList ),
     )
     mustAddBuiltinStruct( QnameOperationDefinition,
         mkField0( identifierName, ptrTyp( mg.TypeIdentifier ) ),
         mkField0( identifierSignature, ptrTyp( TypeCallSignature ) ),
+        mkAnnotationsField(),
+        mkDocField(),
     )
     mustAddBuiltinStruct( QnameServiceDefinition,
         mkField0( identifierName, ptrTyp( mg.TypeQualifiedTypeName ) ),
         mkField0( identifierOperations, typeOpDefList ),
         mkField0( identifierSecurity, nilPtrTyp( mg.TypeQualifiedTypeName ) ),
+        mkAnnotationsField(),
+        mkDocField(),
     ) 
 }
 
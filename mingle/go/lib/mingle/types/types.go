@@ -117,9 +117,31 @@ func ( e *UnionTypeDefinitionError ) Error() string {
     return "union contains one or more ambiguous types"
 }
 
-// Should not be created directly -- use CreateUnionTypeDefinition()
+type UnionTagError struct { Tag *mg.Identifier }
+
+func ( e *UnionTagError ) Error() string {
+    return "duplicate union tag: " + e.Tag.ExternalForm()
+}
+
+// Should not be created directly -- use CreateUnionTypeDefinition() or
+// CreateTaggedUnionTypeDefinition()
 type UnionTypeDefinition struct {
     Types []mg.TypeReference
+
+    // nil for a union matched by the type of its values; otherwise Tags[ i ]
+    // is the discriminator for Types[ i ]
+    Tags []*mg.Identifier
+}
+
+func ( utd *UnionTypeDefinition ) IsTagged() bool { return utd.Tags != nil }
+
+func ( utd *UnionTypeDefinition ) MatchTag( 
+    tag *mg.Identifier ) ( mg.TypeReference, bool ) {
+
+    for i, t := range utd.Tags {
+        if t.Equals( tag ) { return utd.Types[ i ], true }
+    }
+    return nil, false
 }
 
 func ( utd *UnionTypeDefinition ) MatchType( 
@@ -147,6 +169,9 @@ func UnionTypeKeyForType( typ mg.TypeReference ) string {
     case *mg.PointerTypeReference: return UnionTypeKeyForType( v.Type )
     case *mg.ListTypeReference: 
         return UnionTypeKeyForType( v.ElementType ) + "[]"
+    case *mg.MapTypeReference: 
+        // values of any map type are all SymbolMaps
+        return mg.QnameSymbolMap.ExternalForm()
     }
     panic( libErrorf( "unhandled type: %T", typ ) )
 }
@@ -202,7 +227,7 @@ func CreateUnionTypeDefinition(
 
     if in.Len() == 0 { panic( libErrorf( "attempt to create empty union" ) ) }
     typs, errGroups := checkUnionType( in )
-    if len( errGroups ) == 0 { return &UnionTypeDefinition{ typs }, nil }
+    if len( errGroups ) == 0 { return &UnionTypeDefinition{ Types: typs }, nil }
     return nil, &UnionTypeDefinitionError{ errGroups } 
 }
 
@@ -220,9 +245,72 @@ func MustUnionTypeDefinitionTypes(
     panic( err )
 }
 
+// Creates a union in which each of typs is selected by the tag at the same
+// index in tags rather than by its runtime type, so that typs need not be
+// distinguishable from one another
+func CreateTaggedUnionTypeDefinition( 
+    tags []*mg.Identifier, 
+    typs []mg.TypeReference ) ( *UnionTypeDefinition, error ) {
+
+    if len( typs ) == 0 { 
+        panic( libErrorf( "attempt to create empty union" ) ) 
+    }
+    if len( tags ) != len( typs ) {
+        panic( libErrorf( "union has %d tags for %d types", 
+            len( tags ), len( typs ) ) )
+    }
+    seen := mg.NewIdentifierMap()
+    for _, tag := range tags {
+        if seen.HasKey( tag ) { return nil, &UnionTagError{ tag } }
+        seen.Put( tag, true )
+    }
+    return &UnionTypeDefinition{ Types: typs, Tags: tags }, nil
+}
+
+func MustTaggedUnionTypeDefinition( 
+    tags []*mg.Identifier, typs []mg.TypeReference ) *UnionTypeDefinition {
+
+    res, err := CreateTaggedUnionTypeDefinition( tags, typs )
+    if err == nil { return res }
+    panic( err )
+}
+
+// Annotation is a named, arbitrary piece of metadata attached to a definition,
+// field, enum value, or operation. Arguments are the evaluated constant values
+// supplied with the annotation, in declaration order, and may be empty.
+type Annotation struct {
+    Name *mg.Identifier
+    Arguments []mg.Value
+}
+
+// returns the first annotation in annots with name id, or nil if there is none
+func FindAnnotation( annots []*Annotation, id *mg.Identifier ) *Annotation {
+    for _, a := range annots { if a.Name.Equals( id ) { return a } }
+    return nil
+}
+
+// the name of the annotation marking a field, enum value, or operation as
+// deprecated, with an optional String argument explaining what to use instead
+var IdDeprecated = idUnsafe( "deprecated" )
+
+// returns the message of the deprecation annotation in annots, which may be
+// empty, and whether there is such an annotation
+func DeprecationMessage( annots []*Annotation ) ( string, bool ) {
+    a := FindAnnotation( annots, IdDeprecated )
+    if a == nil { return "", false }
+    if len( a.Arguments ) > 0 {
+        if s, ok := a.Arguments[ 0 ].( mg.String ); ok { 
+            return string( s ), true 
+        }
+    }
+    return "", true
+}
+
 type UnionDefinition struct {
     Name *mg.QualifiedTypeName
     Union *UnionTypeDefinition
+    Annotations []*Annotation
+    Doc string
 }
 
 func ( ud *UnionDefinition ) GetName() *mg.QualifiedTypeName { return ud.Name }
@@ -231,6 +319,8 @@ type FieldDefinition struct {
     Name *mg.Identifier
     Type mg.TypeReference
     Default mg.Value
+    Annotations []*Annotation
+    Doc string
 }
 
 func ( fd *FieldDefinition ) GetDefault() mg.Value {
@@ -320,16 +410,150 @@ func ( cs *CallSignature ) GetFields() *FieldSet { return cs.Fields }
 type PrototypeDefinition struct {
     Name *mg.QualifiedTypeName
     Signature *CallSignature
+    Annotations []*Annotation
+    Doc string
 }
 
 func ( pd *PrototypeDefinition ) GetName() *mg.QualifiedTypeName {
     return pd.Name
 }
 
+type ComparisonOperator string
+
+const (
+    ComparisonEqual = ComparisonOperator( "==" )
+    ComparisonNotEqual = ComparisonOperator( "!=" )
+    ComparisonLess = ComparisonOperator( "<" )
+    ComparisonLessOrEqual = ComparisonOperator( "<=" )
+    ComparisonGreater = ComparisonOperator( ">" )
+    ComparisonGreaterOrEqual = ComparisonOperator( ">=" )
+)
+
+var comparisonOperators = []ComparisonOperator{
+    ComparisonEqual,
+    ComparisonNotEqual,
+    ComparisonLess,
+    ComparisonLessOrEqual,
+    ComparisonGreater,
+    ComparisonGreaterOrEqual,
+}
+
+func ComparisonOperatorOf( s string ) ( ComparisonOperator, bool ) {
+    for _, op := range comparisonOperators {
+        if string( op ) == s { return op, true }
+    }
+    return "", false
+}
+
+// true if op requires its operands to be ordered, rather than only comparable
+// for equality
+func ( op ComparisonOperator ) IsOrdering() bool {
+    return ! ( op == ComparisonEqual || op == ComparisonNotEqual )
+}
+
+// returns the operator which holds for 'b op2 a' whenever op holds for 'a op b'
+func ( op ComparisonOperator ) Reverse() ComparisonOperator {
+    switch op {
+    case ComparisonLess: return ComparisonGreater
+    case ComparisonLessOrEqual: return ComparisonGreaterOrEqual
+    case ComparisonGreater: return ComparisonLess
+    case ComparisonGreaterOrEqual: return ComparisonLessOrEqual
+    }
+    return op
+}
+
+// Compares the value of Field with either the value of the field Other or the
+// constant Value, exactly one of which is set. A comparison involving a field
+// which is absent or null holds trivially, so that optional fields are only
+// constrained when given.
+type FieldComparison struct {
+    Field *mg.Identifier
+    Operator ComparisonOperator
+    Other *mg.Identifier
+    Value mg.Value
+}
+
+func ( fc *FieldComparison ) ExternalForm() string {
+    rhs := ""
+    if fc.Other == nil { 
+        rhs = mg.QuoteValue( fc.Value ) 
+    } else { rhs = fc.Other.ExternalForm() }
+    return fmt.Sprintf( "%s %s %s", fc.Field, fc.Operator, rhs )
+}
+
+type PresenceRule string
+
+const (
+    PresenceExactlyOne = PresenceRule( "exactly-one" )
+    PresenceAtMostOne = PresenceRule( "at-most-one" )
+    PresenceAtLeastOne = PresenceRule( "at-least-one" )
+)
+
+var presenceRules = []PresenceRule{
+    PresenceExactlyOne,
+    PresenceAtMostOne,
+    PresenceAtLeastOne,
+}
+
+func PresenceRuleOf( s string ) ( PresenceRule, bool ) {
+    for _, r := range presenceRules {
+        if string( r ) == s { return r, true }
+    }
+    return "", false
+}
+
+// the rule as an identifier, such as the name exactlyOne by which the rule
+// exactly-one is used in a source constraint
+func ( r PresenceRule ) Identifier() *mg.Identifier {
+    return idUnsafe( strings.Split( string( r ), "-" )... )
+}
+
+// true if n of the fields constrained by r are present
+func ( r PresenceRule ) Accepts( n int ) bool {
+    switch r {
+    case PresenceExactlyOne: return n == 1
+    case PresenceAtMostOne: return n <= 1
+    case PresenceAtLeastOne: return n >= 1
+    }
+    panic( libErrorf( "unhandled presence rule: %s", r ) )
+}
+
+// Constrains the number of Fields which are present with a non-null value
+type FieldPresence struct {
+    Rule PresenceRule
+    Fields []*mg.Identifier
+}
+
+func ( fp *FieldPresence ) ExternalForm() string {
+    strs := make( []string, len( fp.Fields ) )
+    for i, fld := range fp.Fields { strs[ i ] = fld.ExternalForm() }
+    fn := fp.Rule.Identifier().Format( mg.LcCamelCapped )
+    return fmt.Sprintf( "%s( %s )", fn, strings.Join( strs, ", " ) )
+}
+
+// Implemented by *FieldComparison and *FieldPresence
+type ConstraintExpression interface { ExternalForm() string }
+
+// A constraint relating the values of a struct's fields, which is checked once
+// all fields of an instance of the struct are known
+type StructConstraint struct {
+    Expression ConstraintExpression
+    Message string // empty if the constraint has no message of its own
+}
+
+func ( sc *StructConstraint ) FailureMessage() string {
+    if sc.Message != "" { return sc.Message }
+    return "constraint not satisfied: " + sc.Expression.ExternalForm()
+}
+
 type StructDefinition struct {
     Name *mg.QualifiedTypeName
     Fields *FieldSet
     Constructors *UnionTypeDefinition
+    Constraints []*StructConstraint
+    Reserved []*mg.Identifier // names no field of the struct may have
+    Annotations []*Annotation
+    Doc string
 }
 
 func NewStructDefinition() *StructDefinition {
@@ -340,6 +564,11 @@ func ( sd *StructDefinition ) GetName() *mg.QualifiedTypeName {
     return sd.Name
 }
 
+func ( sd *StructDefinition ) IsReserved( fld *mg.Identifier ) bool {
+    for _, id := range sd.Reserved { if id.Equals( fld ) { return true } }
+    return false
+}
+
 func ( sd *StructDefinition ) GetFields() *FieldSet { return sd.Fields }
 
 func ( sd *StructDefinition ) MustMixinSchema( schema *SchemaDefinition ) {
@@ -353,6 +582,8 @@ func ( sd *StructDefinition ) SatisfiesSchema( sc *SchemaDefinition ) bool {
 type SchemaDefinition struct {
     Name *mg.QualifiedTypeName
     Fields *FieldSet
+    Annotations []*Annotation
+    Doc string
 }
 
 func NewSchemaDefinition() *SchemaDefinition {
@@ -366,6 +597,8 @@ func ( sd *SchemaDefinition ) GetFields() *FieldSet { return sd.Fields }
 type AliasedTypeDefinition struct {
     Name *mg.QualifiedTypeName
     AliasedType mg.TypeReference
+    Annotations []*Annotation
+    Doc string
 }
 
 func ( ad *AliasedTypeDefinition ) GetName() *mg.QualifiedTypeName {
@@ -383,9 +616,34 @@ type EnumDefinitionError string
 
 func ( e EnumDefinitionError ) Error() string { return string( e ) }
 
+type EnumValueAnnotations struct {
+    Value *mg.Identifier
+    Annotations []*Annotation
+    Doc string
+}
+
+// An explicit ordinal for an enum value, which unlike the position of the
+// value in EnumDefinition.Values is unaffected by reordering the values
+type EnumValueOrdinal struct {
+    Value *mg.Identifier
+    Ordinal int32
+}
+
+// An alternate name by which an enum value may be given on input, such as a
+// name the value had before it was renamed
+type EnumValueAlias struct {
+    Alias *mg.Identifier
+    Value *mg.Identifier
+}
+
 type EnumDefinition struct {
     Name *mg.QualifiedTypeName
     Values []*mg.Identifier
+    Annotations []*Annotation
+    Doc string
+    ValueAnnotations []*EnumValueAnnotations
+    Ordinals []*EnumValueOrdinal
+    Aliases []*EnumValueAlias
 }
 
 func CreateEnumDefinition( 
@@ -402,13 +660,29 @@ func CreateEnumDefinition(
     m.EachPair( func( fld *mg.Identifier, ct interface{} ) {
         if ct.( int ) > 1 { dups = append( dups, fld ) }
     })
-    if len( dups ) == 0 { return &EnumDefinition{ nm, vals }, nil }
+    if len( dups ) == 0 { return &EnumDefinition{ Name: nm, Values: vals }, nil }
     msg := fmt.Sprintf( "duplicate enum value(s): %s", makeDupsDesc( dups ) )
     return nil, EnumDefinitionError( msg )
 }
 
 func ( ed *EnumDefinition ) GetName() *mg.QualifiedTypeName { return ed.Name }
 
+func ( ed *EnumDefinition ) GetValueAnnotations( 
+    id *mg.Identifier ) []*Annotation {
+
+    for _, va := range ed.ValueAnnotations {
+        if va.Value.Equals( id ) { return va.Annotations }
+    }
+    return nil
+}
+
+func ( ed *EnumDefinition ) GetValueDoc( id *mg.Identifier ) string {
+    for _, va := range ed.ValueAnnotations {
+        if va.Value.Equals( id ) { return va.Doc }
+    }
+    return ""
+}
+
 func ( ed *EnumDefinition ) GetValueMap() *EnumValueMap {
     res := &EnumValueMap{ mg.NewIdentifierMap() }
     for _, val := range ed.Values {
@@ -426,9 +700,72 @@ func ( ed *EnumDefinition ) GetValue( id *mg.Identifier ) *mg.Enum {
     return nil
 }
 
+// Like GetValue() but also accepts an alias of a value, returning the value
+// itself
+func ( ed *EnumDefinition ) ResolveValue( id *mg.Identifier ) *mg.Enum {
+    if res := ed.GetValue( id ); res != nil { return res }
+    for _, al := range ed.Aliases {
+        if al.Alias.Equals( id ) { return ed.GetValue( al.Value ) }
+    }
+    return nil
+}
+
+func ( ed *EnumDefinition ) GetOrdinal( id *mg.Identifier ) ( int32, bool ) {
+    for _, o := range ed.Ordinals {
+        if o.Value.Equals( id ) { return o.Ordinal, true }
+    }
+    return 0, false
+}
+
+func ( ed *EnumDefinition ) GetValueForOrdinal( ord int32 ) *mg.Enum {
+    for _, o := range ed.Ordinals {
+        if o.Ordinal == ord { return ed.GetValue( o.Value ) }
+    }
+    return nil
+}
+
+// Checks that Ordinals and Aliases refer only to values of ed, that no value
+// has more than one ordinal, that no two values share an ordinal, and that no
+// alias is the same as a value or another alias.
+func ( ed *EnumDefinition ) CheckValueNames() error {
+    vals := mg.NewIdentifierMap()
+    for _, val := range ed.Values { vals.Put( val, true ) }
+    errorf := func( tmpl string, args ...interface{} ) error {
+        return EnumDefinitionError( fmt.Sprintf( tmpl, args... ) )
+    }
+    ordVals, ords := mg.NewIdentifierMap(), make( map[ int32 ]bool )
+    for _, o := range ed.Ordinals {
+        if ! vals.HasKey( o.Value ) {
+            return errorf( "ordinal for unknown enum value: %s", o.Value )
+        }
+        if ordVals.HasKey( o.Value ) {
+            return errorf( "multiple ordinals for enum value: %s", o.Value )
+        }
+        if ords[ o.Ordinal ] {
+            return errorf( "duplicate enum ordinal: %d", o.Ordinal )
+        }
+        ordVals.Put( o.Value, true )
+        ords[ o.Ordinal ] = true
+    }
+    for _, al := range ed.Aliases {
+        if ! vals.HasKey( al.Value ) {
+            return errorf( "alias %s for unknown enum value: %s", 
+                al.Alias, al.Value )
+        }
+        if vals.HasKey( al.Alias ) {
+            return errorf( "enum alias is already a value or alias: %s", 
+                al.Alias )
+        }
+        vals.Put( al.Alias, true )
+    }
+    return nil
+}
+
 type OperationDefinition struct {
     Name *mg.Identifier
     Signature *CallSignature
+    Annotations []*Annotation
+    Doc string
 }
 
 func OpDefsByName( defs []*OperationDefinition ) *mg.IdentifierMap {
@@ -445,6 +782,8 @@ type ServiceDefinition struct {
     Name *mg.QualifiedTypeName
     Operations []*OperationDefinition
     Security *mg.QualifiedTypeName
+    Annotations []*Annotation
+    Doc string
 }
 
 func NewServiceDefinition() *ServiceDefinition {
@@ -8,6 +8,7 @@ import (
     mgRct "mingle/reactor"
     "mingle/parser"
     "time"
+    "net/url"
     "fmt"
 )
 
@@ -142,6 +143,10 @@ func ensureTestBuilderFactories() {
 
 var (
     tm1 = mg.MustTimestamp( "2013-10-19T02:47:00-08:00" )
+    date1 = mg.Date{ Year: 2013, Month: time.October, Day: 19 }
+    uuid1 = mg.Uuid{ 0xa0, 0xb1, 0xc2, 0xd3, 0xe4, 0xf5, 0x46, 0x17, 
+                     0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff }
+    uri1 = &url.URL{ Scheme: "http", Host: "example.com", Path: "/a" }
 )
 
 func getDefaultValBindTestValues() *mg.IdentifierMap {
@@ -157,6 +162,10 @@ func getDefaultValBindTestValues() *mg.IdentifierMap {
     res.Put( mkId( "float32-val1" ), float32( 1.0 ) )
     res.Put( mkId( "float64-val1" ), float64( 1.0 ) )
     res.Put( mkId( "time-val1" ), time.Time( tm1 ) )
+    res.Put( mkId( "date-val1" ), date1 )
+    res.Put( mkId( "duration-val1" ), time.Minute )
+    res.Put( mkId( "uuid-val1" ), uuid1 )
+    res.Put( mkId( "uri-val1" ), uri1 )
     res.Put( mkId( "s1-val1" ), &S1{ f1: 1, f2: []int32{ 0, 1, 2 } } )
     res.Put( mkId( "e1-val1" ), E1V1 )
     res.Put( 
@@ -198,6 +207,10 @@ func getDefaultValBindTests( tests []*BindTest ) []*BindTest {
     addOk( mg.Float32( 1.0 ), "float32-val1" )
     addOk( mg.Float64( 1.0 ), "float64-val1" )
     addOk( tm1, "time-val1" )
+    addOk( date1, "date-val1" )
+    addOk( mg.Duration( time.Minute ), "duration-val1" )
+    addOk( uuid1, "uuid-val1" )
+    addOk( mg.Uri( "http://example.com/a" ), "uri-val1" )
     s1V1 := parser.MustStruct( "mingle:bind@v1/S1", 
         "f1", int32( 1 ),
         "f2", mg.MustList( asType( "Int32*" ), 
@@ -27,12 +27,14 @@ func assertBinIoRoundtrip( val interface{}, a *assert.PathAsserter ) {
 
 func TestCoreIo( t *testing.T ) {
     a := assert.NewPathAsserter( t )
-    for _, test := range CreateCoreIoTests() { 
+    for _, test := range CreateGoCoreIoTests() { 
         if rt, ok := test.( *BinIoRoundtripTest ); ok {
             switch v := rt.Val.( type ) {
             case *Null, Boolean, Buffer, String, *Enum, Int32, Uint32, Int64,
-                 Uint64, Float32, Float64, Timestamp, *Identifier, *Namespace,
-                 *QualifiedTypeName, TypeReference, *DeclaredTypeName:
+                 Uint64, Float32, Float64, BigInt, Decimal, Timestamp, 
+                 Date, LocalTime, Duration, Uuid, Uri,
+                 *Identifier, *Namespace, *QualifiedTypeName, TypeReference,
+                 *DeclaredTypeName:
                 assertBinIoRoundtrip( v, a.Descend( rt.Name ) )
             case *SymbolMap, *List, *Struct: ; // okay but skip
             default: a.Fatalf( "unhandled rt val: %T", rt.Val )
@@ -59,3 +61,38 @@ func TestAsAndFromBytes( t *testing.T ) {
     ns2, err := NamespaceFromBytes( NamespaceAsBytes( ns ) )
     if err == nil { a.True( ns.Equals( ns2 ) ) } else { a.Fatal( err ) }
 }
+
+// List types without a restriction keep the layout they had before list
+// restrictions were added, so that data written then is still readable
+func TestReadUnrestrictedListTypeLayout( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    bb := &bytes.Buffer{}
+    w := NewWriter( bb )
+    id := mkId( "id1" )
+    err := w.WriteTypeCode( IoTypeCodeListTyp )
+    if err == nil { err = w.WriteTypeReference( TypeInt32 ) }
+    if err == nil { err = w.WriteBool( true ) }
+    if err == nil { err = w.WriteIdentifier( id ) }
+    if err != nil { a.Fatal( err ) }
+    r := NewReader( bb )
+    typ, err := r.ReadTypeReference()
+    if err != nil { a.Fatal( err ) }
+    a.True( typ.Equals( &ListTypeReference{ TypeInt32, true, nil } ) )
+    id2, err := r.ReadIdentifier()
+    if err != nil { a.Fatal( err ) }
+    a.True( id.Equals( id2 ) )
+}
+
+func TestReadUriCanonicalizes( t *testing.T ) {
+    bb := &bytes.Buffer{}
+    w := NewWriter( bb )
+    if err := w.WriteScalarValue( Uri( "HTTP://Example.COM/A" ) ); err != nil {
+        t.Fatal( err )
+    }
+    r := NewReader( bb )
+    tc, err := r.ReadTypeCode()
+    if err != nil { t.Fatal( err ) }
+    val, err := r.ReadScalarValue( tc )
+    if err != nil { t.Fatal( err ) }
+    assert.Equal( Uri( "http://example.com/A" ), val )
+}
@@ -5,6 +5,8 @@ import (
     "strings"
     "fmt"
     "math"
+    "math/big"
+    "time"
 //    "log"
 )
 
@@ -103,19 +105,23 @@ func ( b *binIoRoundtripTestBuilder ) addValueTests() {
         MustList( int32( 1 ), MustList(), MustList( "hello" ), NullVal ) )
     b.setVal( "list-typed",
         MustList( 
-            &ListTypeReference{ TypeInt32, true }, 
+            &ListTypeReference{ TypeInt32, true, nil }, 
             int32( 0 ), int32( 1 ),
         ),
     )
 }
 
+func ( b *binIoRoundtripTestBuilder ) setDefinition( 
+    ef interface { ExternalForm() string } ) {
+
+    fqNm := fmt.Sprintf( "%T", ef )
+    lastDot := strings.LastIndex( fqNm, "." )
+    simplNm := fqNm[ lastDot + 1 : ]
+    b.setVal( fmt.Sprintf( "%s/%s", simplNm, ef ), ef )
+}
+
 func ( b *binIoRoundtripTestBuilder ) addDefinitionTests() {
-    set := func( ef interface { ExternalForm() string } ) { 
-        fqNm := fmt.Sprintf( "%T", ef )
-        lastDot := strings.LastIndex( fqNm, "." )
-        simplNm := fqNm[ lastDot + 1 : ]
-        b.setVal( fmt.Sprintf( "%s/%s", simplNm, ef ), ef )
-    }
+    set := b.setDefinition
     set( mkId( "id1" ) )
     set( mkId( "id1", "id2" ) )
     ns1V1 := mkNs( mkId( "v1" ), mkId( "ns1" ) )
@@ -209,6 +215,87 @@ func ( b *binIoRoundtripTestBuilder ) addDefinitionTests() {
     )
 }
 
+// values which the other implementations can't yet read
+func ( b *binIoRoundtripTestBuilder ) addGoValueTests() {
+    b.setVal( "bigint-zero", MustBigInt( "0" ) )
+    b.setVal( "bigint-pos", MustBigInt( "123456789012345678901234567890" ) )
+    b.setVal( "bigint-neg", MustBigInt( "-123456789012345678901234567890" ) )
+    b.setVal( "decimal-zero", MustDecimal( "0" ) )
+    b.setVal( "decimal-pos", MustDecimal( "1234567890123456789.0123456789" ) )
+    b.setVal( "decimal-neg", MustDecimal( "-0.001" ) )
+    b.setVal( "decimal-neg-scale", MustDecimal( "1e10" ) )
+    b.setVal( "date-val1", Date{ 2013, time.October, 19 } )
+    b.setVal( "date-neg-year", Date{ -44, time.March, 15 } )
+    b.setVal( "local-time-val1", LocalTime{ 2, 47, 0, 0 } )
+    b.setVal( "local-time-max", LocalTime{ 23, 59, 59, 999999999 } )
+    b.setVal( "duration-val1", Duration( 90 * time.Minute ) )
+    b.setVal( "duration-neg", Duration( math.MinInt64 ) )
+    b.setVal( "uuid-zero", Uuid{} )
+    b.setVal( "uuid-val1", Uuid{ 0xa0, 0xb1, 0xc2, 0xd3, 0xe4, 0xf5, 0x46, 
+        0x17, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff } )
+    b.setVal( "uri-val1", Uri( "http://example.com/a?b=c" ) )
+}
+
+// types which the other implementations can't yet read
+func ( b *binIoRoundtripTestBuilder ) addGoDefinitionTests() {
+    set := b.setDefinition
+    mkV1Typ := func( nm string, rx ValueRestriction ) *AtomicTypeReference {
+        return NewAtomicTypeReference( mkQn( CoreNsV1, mkDeclNm( nm ) ), rx )
+    }
+    mkLen := MustLengthRestriction
+    set( mkV1Typ( "String", mkLen( true, Int64( 1 ), Int64( 10 ), true ) ) )
+    set( mkV1Typ( "Buffer", mkLen( false, nil, Int64( 10 ), false ) ) )
+    ns1V1 := mkNs( mkId( "v1" ), mkId( "ns1" ) )
+    typNs1V1T1 := mkQn( ns1V1, mkDeclNm( "T1" ) ).AsAtomicType()
+    set( 
+        &ListTypeReference{
+            ElementType: typNs1V1T1,
+            AllowsEmpty: true,
+            Restriction: mkLen( true, Int64( 0 ), nil, false ),
+        },
+    )
+    set( NewMapTypeReference( typNs1V1T1 ) )
+    set( &NullableTypeReference{ NewMapTypeReference( typNs1V1T1 ) } )
+    mkRng := MustRangeRestriction
+    set(
+        mkV1Typ(
+            "BigInt",
+            mkRng( QnameBigInt, true, MustBigInt( "-1" ), nil, false ),
+        ),
+    )
+    set(
+        mkV1Typ(
+            "Decimal",
+            mkRng( QnameDecimal, 
+                false, MustDecimal( "0.0" ), MustDecimal( "1.5" ), true ),
+        ),
+    )
+    set( 
+        mkV1Typ( 
+            "Date",
+            mkRng(
+                QnameDate,
+                true,
+                Date{ 2012, time.January, 1 },
+                Date{ 2012, time.February, 1 },
+                false,
+            ),
+        ),
+    )
+    set( 
+        mkV1Typ( 
+            "LocalTime",
+            mkRng( QnameLocalTime, true, LocalTime{ 9, 0, 0, 0 }, nil, false ),
+        ),
+    )
+    set( 
+        mkV1Typ( 
+            "Duration",
+            mkRng( QnameDuration, false, Duration( 0 ), nil, false ),
+        ),
+    )
+}
+
 func addBinIoRoundtripTests( tests []interface{} ) []interface{} {
     b := &binIoRoundtripTestBuilder{}
     b.nmCheck = map[ string ]interface{}{}
@@ -218,6 +305,15 @@ func addBinIoRoundtripTests( tests []interface{} ) []interface{} {
     return b.tests
 }
 
+func addGoBinIoRoundtripTests( tests []interface{} ) []interface{} {
+    b := &binIoRoundtripTestBuilder{}
+    b.nmCheck = map[ string ]interface{}{}
+    b.tests = tests
+    b.addGoValueTests()
+    b.addGoDefinitionTests()
+    return b.tests
+}
+
 type BinIoSequenceRoundtripTest struct {
     Name string
     Seq []Value
@@ -413,6 +509,63 @@ func addBinIoInvalidDataTests( tests []interface{} ) []interface{} {
     return tests
 }
 
+// invalid encodings of values which the other implementations can't yet read
+func addGoBinIoInvalidDataTests( tests []interface{} ) []interface{} {
+    add := func( t *BinIoInvalidDataTest ) {
+        t.ReadType = BinIoInvalidDataTestReadTypeValue
+        tests = append( tests, t )
+    }
+    add(
+        &BinIoInvalidDataTest{
+            Name: "decimal-scale-out-of-range",
+            ErrMsg: `[offset 7]: Decimal scale out of range: 4097`,
+            Input: makeBinIoInvalidDataTest(
+                Decimal{ big.NewInt( 1 ), maxParsedDecimalScale + 1 },
+            ),
+        },
+    )
+    add(
+        &BinIoInvalidDataTest{
+            Name: "decimal-negative-scale-out-of-range",
+            ErrMsg: `[offset 7]: Decimal scale out of range: -4097`,
+            Input: makeBinIoInvalidDataTest(
+                Decimal{ big.NewInt( 1 ), -maxParsedDecimalScale - 1 },
+            ),
+        },
+    )
+    add(
+        &BinIoInvalidDataTest{
+            Name: "date-invalid-day",
+            ErrMsg: `[offset 1]: invalid date: 2013-02-29`,
+            Input: makeBinIoInvalidDataTest( 
+                Date{ Year: 2013, Month: time.February, Day: 29 } ),
+        },
+    )
+    add(
+        &BinIoInvalidDataTest{
+            Name: "date-invalid-month",
+            ErrMsg: `[offset 1]: invalid date: 2013-13-01`,
+            Input: makeBinIoInvalidDataTest( 
+                Date{ Year: 2013, Month: time.Month( 13 ), Day: 1 } ),
+        },
+    )
+    add(
+        &BinIoInvalidDataTest{
+            Name: "uri-relative",
+            ErrMsg: `[offset 1]: Invalid URI: "a/b"`,
+            Input: makeBinIoInvalidDataTest( Uri( "a/b" ) ),
+        },
+    )
+    add(
+        &BinIoInvalidDataTest{
+            Name: "uri-unparseable",
+            ErrMsg: `[offset 1]: Invalid URI: "http://[::1"`,
+            Input: makeBinIoInvalidDataTest( Uri( "http://[::1" ) ),
+        },
+    )
+    return tests
+}
+
 // We can't create this result in an init() func since we make use of other
 // library functions, such as MustTypeReference, which assume proper library
 // initialization.
@@ -424,6 +577,16 @@ func CreateCoreIoTests() []interface{} {
     return res
 }
 
+// Returns the tests of CreateCoreIoTests() along with those of values and types
+// which so far only the go implementation reads. The latter are kept out of the
+// test data shared with other implementations.
+func CreateGoCoreIoTests() []interface{} {
+    res := CreateCoreIoTests()
+    res = addGoBinIoRoundtripTests( res )
+    res = addGoBinIoInvalidDataTests( res )
+    return res
+}
+
 func CoreIoTestNameFor( test interface{} ) string {
     mk := func( pref, nm string ) string {
         return fmt.Sprintf( "%s/%s", pref, nm )
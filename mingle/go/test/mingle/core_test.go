@@ -7,6 +7,9 @@ import (
     "bitgirder/objpath"
     "bytes"
     "time"
+    "math"
+    "math/big"
+    "net/url"
 //    "log"
 )
 
@@ -58,6 +61,9 @@ func assertAsTimeValues( t *testing.T ) {
     tm := Now()
     assert.Equal( Timestamp( tm ), MustValue( tm ) )
     assert.Equal( Timestamp( tm ), MustValue( Timestamp( tm ) ) )
+    assert.Equal( Duration( time.Second ), MustValue( time.Second ) )
+    d := Date{ 2012, time.January, 1 }
+    assert.Equal( d, MustValue( d ) )
 }
 
 func assertAsNullValues( t *testing.T ) {
@@ -342,6 +348,27 @@ func TestObjectPathFormatting( t *testing.T ) {
                 Descend( mkId( "some", "fld3" ) ),
         )
     assert.Equal( "f1.some-fld1[ 1 ][ 0 ].some-fld2[ 2 ].some-fld3", str )
+    str = FormatIdPath(
+        objpath.RootedAt( IdPathWildcard ).
+            Descend( mkId( "f1" ) ).
+            Descend( IdPathWildcard ),
+    )
+    assert.Equal( "*.f1.*", str )
+}
+
+func TestIdPathMatches( t *testing.T ) {
+    elts := IdPathElements(
+        objpath.RootedAt( mkId( "f1" ) ).StartList().SetIndex( 2 ) )
+    assert.Equal( []interface{}{ mkId( "f1" ), uint64( 2 ) }, elts )
+    assert.Equal( 0, len( IdPathElements( nil ) ) )
+    chk := func( expct bool, pat objpath.PathNode ) {
+        assert.Equal( expct, IdPathMatches( IdPathElements( pat ), elts ) )
+    }
+    chk( true, objpath.RootedAt( mkId( "f1" ) ).StartList().SetIndex( 2 ) )
+    chk( true, objpath.RootedAt( IdPathWildcard ).Descend( IdPathWildcard ) )
+    chk( false, objpath.RootedAt( mkId( "f1" ) ).StartList().SetIndex( 1 ) )
+    chk( false, objpath.RootedAt( mkId( "f2" ) ).Descend( IdPathWildcard ) )
+    chk( false, objpath.RootedAt( IdPathWildcard ) )
 }
 
 func TestTypeCastFormatting( t *testing.T ) {
@@ -367,6 +394,11 @@ func TestTypeOf( t *testing.T ) {
     a.Equal( TypeFloat32, TypeOf( Float32( 1.0 ) ) )
     a.Equal( TypeFloat64, TypeOf( Float64( 1.0 ) ) )
     a.Equal( TypeTimestamp, TypeOf( Now() ) )
+    a.Equal( TypeDate, TypeOf( Date{ 2012, time.January, 1 } ) )
+    a.Equal( TypeLocalTime, TypeOf( LocalTime{} ) )
+    a.Equal( TypeDuration, TypeOf( Duration( 1 ) ) )
+    a.Equal( TypeUuid, TypeOf( Uuid{} ) )
+    a.Equal( TypeUri, TypeOf( Uri( "http://a" ) ) )
     a.Equal( TypeSymbolMap, TypeOf( MustSymbolMap() ) )
     a.Equal( TypeOpaqueList, TypeOf( MustList() ) )
     qn := ns1V1Qn( "T1" )
@@ -383,8 +415,8 @@ func TestAtomicTypeIn( t *testing.T ) {
             at.Equals( AtomicTypeIn( NewPointerTypeReference( typ ) ) ) )
     }
     chk( at )
-    chk( &ListTypeReference{ at, true } )
-    chk( &ListTypeReference{ at, false } )
+    chk( &ListTypeReference{ at, true, nil } )
+    chk( &ListTypeReference{ at, false, nil } )
     chk( 
         &ListTypeReference{ 
             ElementType: &ListTypeReference{ 
@@ -413,6 +445,8 @@ func TestAtomicTypeIn( t *testing.T ) {
     )
     chk( NewPointerTypeReference( at ) )
     chk( &NullableTypeReference{ NewPointerTypeReference( at ) } )
+    chk( NewMapTypeReference( at ) )
+    chk( NewMapTypeReference( &ListTypeReference{ at, true, nil } ) )
 }
 
 func TestResolveInCore( t *testing.T ) {
@@ -432,6 +466,11 @@ func TestResolveInCore( t *testing.T ) {
     f( "Float32", QnameFloat32 )
     f( "Float64", QnameFloat64 )
     f( "Timestamp", QnameTimestamp )
+    f( "Date", QnameDate )
+    f( "LocalTime", QnameLocalTime )
+    f( "Duration", QnameDuration )
+    f( "Uuid", QnameUuid )
+    f( "Uri", QnameUri )
     f( "SymbolMap", QnameSymbolMap )
     f( "Null", QnameNull )
 }
@@ -464,6 +503,15 @@ func TestComparer( t *testing.T ) {
         f( tm, tm, true )
         f( tm, tm2, false ) 
     }
+    f( Date{ 2012, 1, 1 }, Date{ 2012, 1, 1 }, true )
+    f( Date{ 2011, 12, 31 }, Date{ 2012, 1, 1 }, false )
+    f( Date{ 2012, 1, 31 }, Date{ 2012, 2, 1 }, false )
+    f( Date{ 2012, 1, 1 }, Date{ 2012, 1, 2 }, false )
+    f( LocalTime{ 1, 2, 3, 4 }, LocalTime{ 1, 2, 3, 4 }, true )
+    f( LocalTime{ 1, 59, 59, 0 }, LocalTime{ 2, 0, 0, 0 }, false )
+    f( LocalTime{ 1, 2, 3, 4 }, LocalTime{ 1, 2, 3, 5 }, false )
+    f( Duration( -1 ), Duration( -1 ), true )
+    f( Duration( -1 ), Duration( 0 ), false )
 }
 
 func TestRestrictionAccept( t *testing.T ) {
@@ -487,6 +535,47 @@ func TestRestrictionAccept( t *testing.T ) {
     f( String( "aaaaa" ), vr3, false )
 }
 
+func TestLengthRestriction( t *testing.T ) {
+    la := assert.NewListPathAsserter( t )
+    lr := MustLengthRestriction( true, Int32( 1 ), Uint64( 3 ), false )
+    la.Descend( "externalForm" ).Equal( "[1,3)", lr.ExternalForm() )
+    for _, s := range []struct { val Value; expct bool } {
+        { val: String( "" ), expct: false },
+        { val: String( "ab" ), expct: true },
+        { val: String( "\u00e9\u00e9" ), expct: true }, // 2 chars, 4 bytes
+        { val: String( "abc" ), expct: false },
+        { val: Buffer( []byte{ 0, 1 } ), expct: true },
+        { val: Buffer( []byte{ 0, 1, 2 } ), expct: false },
+        { val: MustList( 1, 2 ), expct: true },
+        { val: MustList(), expct: false },
+    } {
+        la.Equal( s.expct, lr.AcceptsValue( s.val ) )
+        la = la.Next()
+    }
+    ea := assert.NewListPathAsserter( t )
+    for _, s := range []struct { lb *LengthRestrictionBuilder; err string } {
+        { lb: &LengthRestrictionBuilder{ Min: Int32( -1 ) },
+          err: "negative min length: -1" },
+        { lb: &LengthRestrictionBuilder{ Max: Uint64( 1 << 63 ) },
+          err: "max length out of range: 9223372036854775808" },
+        { lb: &LengthRestrictionBuilder{ Min: String( "1" ) },
+          err: "illegal min value of type mingle:core@v1/String in " +
+               "length range" },
+        { lb: &LengthRestrictionBuilder{ Min: Int32( 2 ), Max: Int32( 1 ) },
+          err: "unsatisfiable range" },
+    } {
+        _, err := s.lb.Build()
+        ea.EqualErrors( &RestrictionError{ s.err }, err )
+        ea = ea.Next()
+    }
+    _, err := CreateAtomicTypeReference( QnameInt32, lr )
+    ea.EqualErrors( 
+        &RestrictionError{ 
+            "length restriction cannot be applied to mingle:core@v1/Int32" },
+        err,
+    )
+}
+
 func testAtomicRestrictionError( 
     t *AtomicRestrictionErrorTest, a *assert.PathAsserter ) {
 
@@ -535,35 +624,39 @@ func TestCanAssign( t *testing.T ) {
           expctFail: false,
         },
         { typ: TypeInt32,
-          val: mkList( &ListTypeReference{ TypeInt32, true } ),
+          val: mkList( &ListTypeReference{ TypeInt32, true, nil } ),
           expctFail: true,
         },
-        { typ: &ListTypeReference{ TypeInt32, true }, 
-          val: mkList( &ListTypeReference{ TypeInt32, true } ),
+        { typ: &ListTypeReference{ TypeInt32, true, nil }, 
+          val: mkList( &ListTypeReference{ TypeInt32, true, nil } ),
         },
         { typ: &ListTypeReference{ 
-            &ListTypeReference{ TypeInt32, true }, 
+            &ListTypeReference{ TypeInt32, true, nil }, 
             true,
+            nil,
           },
           val: mkList( 
             &ListTypeReference{ 
-                &ListTypeReference{ TypeInt32, true }, 
+                &ListTypeReference{ TypeInt32, true, nil }, 
                 true,
+                nil,
             },
           ),
         },
         { typ: &ListTypeReference{ 
-            &ListTypeReference{ TypeInt32, true }, 
+            &ListTypeReference{ TypeInt32, true, nil }, 
             true,
+            nil,
           },
-          val: mkList( &ListTypeReference{ TypeInt32, true } ),
+          val: mkList( &ListTypeReference{ TypeInt32, true, nil } ),
           expctFail: true,
         },
-        { typ: &ListTypeReference{ TypeInt32, true },
+        { typ: &ListTypeReference{ TypeInt32, true, nil },
           val: mkList( 
             &ListTypeReference{ 
-                &ListTypeReference{ TypeInt32, true }, 
+                &ListTypeReference{ TypeInt32, true, nil }, 
                 true,
+                nil,
             },
           ),
           expctFail: true,
@@ -588,6 +681,28 @@ func TestCanAssign( t *testing.T ) {
         { typ: TypeValue, val: MustStruct( ns1V1Qn( "S1" ) ) },
         { typ: TypeNullableValue, val: NullVal },
         { typ: TypeNullableValue, val: String( "s" ) },
+        { typ: NewMapTypeReference( TypeInt32 ), val: MustSymbolMap() },
+        { typ: NewMapTypeReference( TypeInt32 ),
+          val: MustSymbolMap(
+            mkId( "f1" ), Int32( 1 ), mkId( "f2" ), Int32( 2 ) ),
+        },
+        { typ: NewMapTypeReference( TypeInt32 ),
+          val: MustSymbolMap(
+            mkId( "f1" ), Int32( 1 ), mkId( "f2" ), String( "s" ) ),
+          expctFail: true,
+        },
+        { typ: NewMapTypeReference( int32Rng ),
+          val: MustSymbolMap( mkId( "f1" ), Int32( 2 ) ),
+          expctFail: true,
+        },
+        { typ: NewMapTypeReference( int32Rng ),
+          val: MustSymbolMap( mkId( "f1" ), Int32( 2 ) ),
+          ignoreRestriction: true,
+        },
+        { typ: NewMapTypeReference( TypeInt32 ),
+          val: Int32( 1 ),
+          expctFail: true,
+        },
     } {
 //        la.Logf( 
 //            "checking typ %s, val: %s, expctFail: %t, ignoreRestriction: %t",
@@ -612,7 +727,7 @@ func TestCanAssignType( t *testing.T ) {
     }
     mkLt := func( typ TypeReference, bools ...bool ) TypeReference {
         for _, allowsEmpty := range bools {
-            typ = &ListTypeReference{ typ, allowsEmpty }
+            typ = &ListTypeReference{ typ, allowsEmpty, nil }
         }
         return typ
     }
@@ -658,6 +773,12 @@ func TestCanAssignType( t *testing.T ) {
     chk( TypeValue, TypeValue, true )
     chk( ltInt32( true ), mkLt( TypeValue, true ), false )
     chk( ltInt32( false ), mkLt( TypeValue, false ), false )
+    mapInt32 := NewMapTypeReference( TypeInt32 )
+    chk( mapInt32, mapInt32, true )
+    chk( mapInt32, &NullableTypeReference{ mapInt32 }, true )
+    chk( mapInt32, TypeSymbolMap, false )
+    chk( mapInt32, NewMapTypeReference( TypeInt64 ), false )
+    chk( NewMapTypeReference( ltInt32( true ) ), mapInt32, false )
 }
 
 type quoteValueAsserter struct {
@@ -903,6 +1024,13 @@ func TestTypeReferenceEquals( t *testing.T ) {
     chk( nt1, MustNullableTypeReference( pt1 ), true )
     chk( nt1, nt2, false )
     chk( nt1, lt1Empty, false )
+    mt1 := NewMapTypeReference( at1 )
+    chk( mt1, mt1, true )
+    chk( mt1, NewMapTypeReference( at1 ), true )
+    chk( mt1, NewMapTypeReference( at2 ), false )
+    chk( mt1, NewMapTypeReference( lt1Empty ), false )
+    chk( mt1, at1, false )
+    chk( mt1, pt1, false )
 }
 
 type numberParseTest struct {
@@ -936,6 +1064,34 @@ func TestNumberParsers( t *testing.T ) {
             in: "1.1", out: Float32( float32( 1.1 ) ), typ: QnameFloat32 },
         &numberParseTest{ 
             in: "1.1", out: Float64( float64( 1.1 ) ), typ: QnameFloat64 },
+        &numberParseTest{ 
+            in: "-18446744073709551617",
+            out: NewBigInt( 
+                new( big.Int ).Neg( 
+                    new( big.Int ).Add( 
+                        new( big.Int ).SetUint64( math.MaxUint64 ),
+                        big.NewInt( 2 ),
+                    ),
+                ),
+            ),
+            typ: QnameBigInt,
+        },
+        &numberParseTest{ 
+            in: "1.10", out: NewDecimal( big.NewInt( 110 ), 2 ), 
+            typ: QnameDecimal,
+        },
+        &numberParseTest{ 
+            in: "-1.5e-3", out: NewDecimal( big.NewInt( -15 ), 4 ), 
+            typ: QnameDecimal,
+        },
+        &numberParseTest{ 
+            in: "12E+2", out: NewDecimal( big.NewInt( 12 ), -2 ), 
+            typ: QnameDecimal,
+        },
+        &numberParseTest{ 
+            in: ".5", out: NewDecimal( big.NewInt( 5 ), 1 ), 
+            typ: QnameDecimal,
+        },
     )
     rngErr := func( val string, typ *QualifiedTypeName ) {
         err := newNumberRangeError( val )
@@ -949,6 +1105,8 @@ func TestNumberParsers( t *testing.T ) {
     rngErr( "-9223372036854775809", QnameInt64 )
     rngErr( "18446744073709551616", QnameUint64 )
     rngErr( "-1", QnameUint64 )
+    rngErr( "1e-5000", QnameDecimal )
+    rngErr( "1e99999999999", QnameDecimal )
     sxErr := func( in string, typ *QualifiedTypeName ) {
         err := newNumberSyntaxError( typ, in )
         test := &numberParseTest{ in: in, typ: typ, err: err }
@@ -958,6 +1116,10 @@ func TestNumberParsers( t *testing.T ) {
     sxErr( "1.1", QnameUint32 )
     sxErr( "1.1", QnameInt64 )
     sxErr( "1.1", QnameUint64 )
+    sxErr( "1.1", QnameBigInt )
+    for _, s := range []string{ "", "-", "+-1", "1.2.3", "1e", "1x", "Inf" } {
+        sxErr( s, QnameDecimal )
+    }
     for _, qn := range NumericTypeNames { sxErr( "badNum", qn ) }
     for _, t := range tests {
         t.PathAsserter = la
@@ -966,6 +1128,144 @@ func TestNumberParsers( t *testing.T ) {
     }
 }
 
+func TestBigNumberStrings( t *testing.T ) {
+    a := assert.NewListPathAsserter( t )
+    for _, s := range []struct{ in Value; expct string } {
+        { BigInt{}, "0" },
+        { MustBigInt( "-123456789012345678901234567890" ),
+          "-123456789012345678901234567890" },
+        { Decimal{}, "0" },
+        { NewDecimal( big.NewInt( 150 ), 2 ), "1.50" },
+        { NewDecimal( big.NewInt( -5 ), 3 ), "-0.005" },
+        { NewDecimal( big.NewInt( 12 ), -3 ), "12000" },
+        { MustDecimal( "-0.0" ), "0.0" },
+    } {
+        a.Equal( s.expct, s.in.( fmt.Stringer ).String() )
+        a.Equal( s.expct, QuoteValue( s.in ) )
+        a = a.Next()
+    }
+}
+
+func TestTemporalStrings( t *testing.T ) {
+    a := assert.NewListPathAsserter( t )
+    for _, s := range []struct{ in Value; expct string } {
+        { Date{ 2012, time.February, 3 }, "2012-02-03" },
+        { Date{ 1, time.January, 1 }, "0001-01-01" },
+        { LocalTime{}, "00:00:00" },
+        { LocalTime{ 23, 5, 6, 0 }, "23:05:06" },
+        { LocalTime{ 1, 2, 3, 400000000 }, "01:02:03.4" },
+        { LocalTime{ 1, 2, 3, 1 }, "01:02:03.000000001" },
+        { Duration( 0 ), "PT0S" },
+        { Duration( 48 * time.Hour ), "P2D" },
+        { Duration( 25 * time.Hour + time.Second ), "P1DT1H1S" },
+        { Duration( -90 * time.Minute ), "-PT1H30M" },
+        { Duration( 1500 * time.Millisecond ), "PT1.5S" },
+        { Duration( math.MinInt64 ), "-P106751DT23H47M16.854775808S" },
+    } {
+        a.Equal( s.expct, s.in.( fmt.Stringer ).String() )
+        a.Equal( s.expct, QuoteValue( s.in ) )
+        a = a.Next()
+    }
+}
+
+func TestLocalTimeNanos( t *testing.T ) {
+    lt := LocalTime{ 23, 59, 59, 999999999 }
+    nanos := int64( 24 * time.Hour - 1 )
+    assert.Equal( nanos, lt.NanoOfDay() )
+    if lt2, err := LocalTimeOfNanos( nanos ); err == nil {
+        assert.Equal( lt, lt2 )
+    } else { t.Fatal( err ) }
+    for _, nanos := range []int64{ -1, int64( 24 * time.Hour ) } {
+        _, err := LocalTimeOfNanos( nanos )
+        assert.Equal( 
+            fmt.Sprintf( "time of day out of range: %d", nanos ), 
+            err.Error() )
+    }
+}
+
+func TestNewDate( t *testing.T ) {
+    if d, err := NewDate( 2012, time.February, 29 ); err == nil {
+        assert.Equal( Date{ Year: 2012, Month: time.February, Day: 29 }, d )
+    } else { t.Fatal( err ) }
+    if d, err := NewDate( -44, time.March, 15 ); err == nil {
+        assert.Equal( "-044-03-15", d.String() )
+    } else { t.Fatal( err ) }
+    for _, d := range []Date{
+        { Year: 2013, Month: time.February, Day: 29 },
+        { Year: 2013, Month: time.Month( 0 ), Day: 1 },
+        { Year: 2013, Month: time.Month( 13 ), Day: 1 },
+        { Year: 2013, Month: time.January, Day: 0 },
+        { Year: 2013, Month: time.April, Day: 31 },
+    } {
+        _, err := NewDate( d.Year, d.Month, d.Day )
+        assert.Equal( fmt.Sprintf( "invalid date: %s", d ), err.Error() )
+    }
+}
+
+func TestUuidValues( t *testing.T ) {
+    u := Uuid{ 0xa0, 0xb1, 0xc2, 0xd3, 0xe4, 0xf5, 0x46, 0x17, 
+               0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff }
+    s := "a0b1c2d3-e4f5-4617-8899-aabbccddeeff"
+    assert.Equal( s, u.String() )
+    assert.Equal( s, QuoteValue( u ) )
+    u1, u2 := MustNewUuid(), MustNewUuid()
+    assert.True( EqualValues( u1, u1 ) )
+    assert.False( EqualValues( u1, u2 ) )
+    assert.Equal( byte( 0x40 ), u1[ 6 ] & 0xf0 )
+}
+
+func TestUriValues( t *testing.T ) {
+    f := func( in, expct string ) {
+        u, err := url.Parse( in )
+        if err != nil { t.Fatal( err ) }
+        uri, err := UriOf( u )
+        if err != nil { t.Fatal( err ) }
+        assert.Equal( Uri( expct ), uri )
+        assert.Equal( uri, MustValue( u ) )
+        assert.Equal( expct, QuoteValue( uri ) )
+        u2, err := uri.Url()
+        if err != nil { t.Fatal( err ) }
+        assert.Equal( expct, u2.String() )
+    }
+    f( "http://example.com/a/b?c=d#e", "http://example.com/a/b?c=d#e" )
+    f( "HTTP://EXAMPLE.com/A", "http://example.com/A" )
+    f( "urn:isbn:0451450523", "urn:isbn:0451450523" )
+    f( "http://example.com/a b", "http://example.com/a%20b" )
+    _, err := UriOf( &url.URL{ Path: "/a/b" } )
+    assert.Equal( `uri is not absolute: "/a/b"`, err.Error() )
+    _, err = AsValue( &url.URL{ Path: "a" } )
+    assert.Equal( `inVal: uri is not absolute: "a"`, err.Error() )
+    assert.False( EqualValues( Uri( "http://a" ), String( "http://a" ) ) )
+}
+
+func TestBigNumberValues( t *testing.T ) {
+    assert.Equal( 0, MustDecimal( "1.5" ).Compare( MustDecimal( "1.50" ) ) )
+    assert.Equal( -1, MustDecimal( "-2" ).Compare( MustDecimal( "-1.99" ) ) )
+    assert.Equal( 1, MustDecimal( "1e3" ).Compare( MustDecimal( "999.9" ) ) )
+    assert.True( EqualValues( MustDecimal( "1.5" ), MustDecimal( "15e-1" ) ) )
+    assert.False( EqualValues( MustDecimal( "1" ), MustBigInt( "1" ) ) )
+    assert.Equal( "-1", MustDecimal( "-1.9" ).Truncate().String() )
+    assert.Equal( "1200", MustDecimal( "1.2e3" ).Truncate().String() )
+    assert.Equal( "3/2", MustDecimal( "1.50" ).Rat().String() )
+    i := big.NewInt( 1 )
+    bi, d := NewBigInt( i ), NewDecimal( i, 0 )
+    i.SetInt64( 2 ) // values must not share state with their inputs
+    assert.Equal( "1", bi.String() )
+    assert.Equal( "1", d.String() )
+    bi.Int().SetInt64( 3 )
+    assert.Equal( "1", bi.String() )
+    assert.Equal( TypeBigInt, TypeOf( MustValue( big.NewInt( 1 ) ) ) )
+    assert.Equal( TypeDecimal, TypeOf( d ) )
+    rr := MustRangeRestriction( 
+        QnameBigInt, false, MustBigInt( "1" ), MustBigInt( "3" ), false )
+    assert.True( rr.AcceptsValue( MustBigInt( "2" ) ) )
+    assert.False( rr.AcceptsValue( MustBigInt( "3" ) ) )
+    rb := &RangeRestrictionBuilder{ 
+        QnameBigInt, false, MustBigInt( "1" ), MustBigInt( "2" ), false }
+    _, err := rb.Build()
+    assert.Equal( errMsgUnsatisfiableRange, err.Error() )
+}
+
 func assertEquality( c1, c2 interface{}, eq, expctEq bool, t *testing.T ) {
     if expctEq && ( ! eq ) { t.Fatalf( "%v != %v", c1, c2 ) }
     if eq && ( ! expctEq ) { t.Fatalf( "%v == %v", c1, c2 ) }
@@ -1167,6 +1467,17 @@ func TestTypeReferenceStringer( t *testing.T ) {
             AllowsEmpty: false,
         },
     )
+    chk( "{ns1@v1/T1}", NewMapTypeReference( at ) )
+    chk( "{ns1@v1/T1}*", 
+        &ListTypeReference{ 
+            ElementType: NewMapTypeReference( at ), 
+            AllowsEmpty: true,
+        },
+    )
+    chk( "&({&(ns1@v1/T1)?})", 
+        NewPointerTypeReference( 
+            NewMapTypeReference( &NullableTypeReference{ ptr } ) ),
+    )
     chk(
         "&(&(ns1@v1/T1)*)",
         NewPointerTypeReference(
@@ -1265,51 +1576,51 @@ func TestEqualValues( t *testing.T ) {
         {
             EmptyList(),
             &List{ 
-                &ListTypeReference{ TypeInt32, true },
+                &ListTypeReference{ TypeInt32, true, nil },
                 []Value{ Int32( 0 ), Int32( 1 ) },
             },
             false,
         },
         {
             &List{ 
-                &ListTypeReference{ TypeInt32, true },
+                &ListTypeReference{ TypeInt32, true, nil },
                 []Value{ Int32( 0 ), Int32( 1 ) },
             },
             &List{ 
-                &ListTypeReference{ TypeInt32, true },
+                &ListTypeReference{ TypeInt32, true, nil },
                 []Value{ Int32( 0 ), Int32( 1 ) },
             },
             true,
         },
         {
             &List{ 
-                &ListTypeReference{ TypeInt32, true },
+                &ListTypeReference{ TypeInt32, true, nil },
                 []Value{ Int32( 0 ), Int32( 1 ) },
             },
             &List{ 
-                &ListTypeReference{ TypeInt32, true },
+                &ListTypeReference{ TypeInt32, true, nil },
                 []Value{ Int32( 0 ), Int32( 2 ) },
             },
             false,
         },
         {
             &List{ 
-                &ListTypeReference{ TypeInt64, true },
+                &ListTypeReference{ TypeInt64, true, nil },
                 []Value{ Int64( 0 ), Int64( 1 ) },
             },
             &List{ 
-                &ListTypeReference{ TypeInt32, true },
+                &ListTypeReference{ TypeInt32, true, nil },
                 []Value{ Int32( 0 ), Int32( 1 ) },
             },
             false,
         },
         {
             &List{ 
-                &ListTypeReference{ TypeValue, true },
+                &ListTypeReference{ TypeValue, true, nil },
                 []Value{ Int32( 0 ), Int32( 1 ) },
             },
             &List{ 
-                &ListTypeReference{ TypeInt32, true },
+                &ListTypeReference{ TypeInt32, true, nil },
                 []Value{ Int32( 0 ), Int32( 1 ) },
             },
             false,
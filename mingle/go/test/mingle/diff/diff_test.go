@@ -0,0 +1,268 @@
+package diff
+
+import (
+    "testing"
+    "bitgirder/assert"
+    "bitgirder/objpath"
+    mg "mingle"
+    mgRct "mingle/reactor"
+    "mingle/parser"
+)
+
+type diffTest struct {
+    from mg.Value
+    to mg.Value
+    expct []string
+}
+
+func s1( pairs ...interface{} ) *mg.Struct {
+    return parser.MustStruct( "ns1@v1/S1", pairs... )
+}
+
+func s2( pairs ...interface{} ) *mg.Struct {
+    return parser.MustStruct( "ns1@v1/S2", pairs... )
+}
+
+func i32( i int ) mg.Int32 { return mg.Int32( i ) }
+
+func mkPath( s string ) objpath.PathNode {
+    if s == "" { return nil }
+    return parser.MustIdentifierPath( s )
+}
+
+func getDiffTests() []*diffTest {
+    return []*diffTest{
+        { i32( 1 ), i32( 1 ), []string{} },
+        { i32( 1 ), i32( 2 ), []string{ "replace <root> 2" } },
+        { i32( 1 ), mg.String( "1" ), []string{ `replace <root> "1"` } },
+        { s1(), s2(), []string{ "replace <root> ns1@v1/S2{}" } },
+        {
+            s1( "f1", i32( 1 ), "f2", i32( 2 ), "f3", i32( 3 ) ),
+            s1( "f2", i32( 2 ), "f3", i32( 4 ), "f4", i32( 5 ) ),
+            []string{ "remove f1", "replace f3 4", "add f4 5" },
+        },
+        {
+            s1( "f1", s2( "f1", i32( 1 ), "f2", i32( 2 ) ) ),
+            s1( "f1", s2( "f1", i32( 1 ), "f2", i32( 3 ) ) ),
+            []string{ "replace f1.f2 3" },
+        },
+        {
+            s1( "f1", s2( "f1", i32( 1 ) ) ),
+            s1( "f1", s1( "f1", i32( 1 ) ) ),
+            []string{ "replace f1 ns1@v1/S1{f1:1}" },
+        },
+        {
+            parser.MustSymbolMap( 
+                "k1", parser.MustSymbolMap( "f1", i32( 1 ) ) ),
+            parser.MustSymbolMap( "k1", parser.MustSymbolMap() ),
+            []string{ "remove k1.f1" },
+        },
+        {
+            s1( "f1", mg.MustList( i32( 1 ), i32( 2 ), i32( 3 ) ) ),
+            s1( "f1", mg.MustList( i32( 1 ), i32( 4 ) ) ),
+            []string{ "replace f1[ 1 ] 4", "remove f1[ 2 ]" },
+        },
+        {
+            s1( "f1", mg.MustList( i32( 1 ), i32( 2 ), i32( 3 ) ) ),
+            s1( "f1", mg.MustList( i32( 1 ) ) ),
+            []string{ "remove f1[ 2 ]", "remove f1[ 1 ]" },
+        },
+        {
+            mg.MustList( s1( "f1", i32( 1 ) ) ),
+            mg.MustList( s1( "f1", i32( 2 ) ), i32( 3 ), i32( 4 ) ),
+            []string{ "replace [ 0 ].f1 2", "add [ 1 ] 3", "add [ 2 ] 4" },
+        },
+        {
+            s1( "f1", mg.NullVal ),
+            s1( "f1", mg.MustList( i32( 1 ) ) ),
+            []string{ "replace f1 [1]" },
+        },
+    }
+}
+
+func opStrings( ops []*Operation ) []string {
+    res := make( []string, len( ops ) )
+    for i, op := range ops { res[ i ] = op.String() }
+    return res
+}
+
+func patchStream(
+    v mg.Value, ops []*Operation ) ( mg.Value, error ) {
+
+    br := mgRct.NewBuildReactor( mgRct.ValueBuilderFactory )
+    pip := mgRct.InitReactorPipeline( NewPatchReactor( ops ), br )
+    if err := mgRct.VisitValue( v, pip ); err != nil { return nil, err }
+    return br.GetValue().( mg.Value ), nil
+}
+
+func assertDiffTest( dt *diffTest, a *assert.PathAsserter ) {
+    ops := Diff( dt.from, dt.to )
+    a.Descend( "ops" ).Equal( dt.expct, opStrings( ops ) )
+    act, err := Apply( dt.from, ops )
+    if err != nil { a.Fatal( err ) }
+    mg.AssertEqualValues( dt.to, act, a.Descend( "apply" ) )
+    act, err = patchStream( dt.from, ops )
+    if err != nil { a.Fatal( err ) }
+    mg.AssertEqualValues( dt.to, act, a.Descend( "stream" ) )
+    ops2, err := OperationsFromList( OperationsAsList( ops ) )
+    if err != nil { a.Fatal( err ) }
+    a.Descend( "asList" ).Equal( dt.expct, opStrings( ops2 ) )
+}
+
+func TestDiff( t *testing.T ) {
+    la := assert.NewListPathAsserter( t )
+    for _, dt := range getDiffTests() {
+        assertDiffTest( dt, la )
+        la = la.Next()
+    }
+}
+
+func TestOperationAsValue( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    op := &Operation{
+        Kind: OperationReplace,
+        Path: mkPath( "f1[ 2 ]" ),
+        Value: i32( 1 ),
+    }
+    expct := parser.MustStruct( "mingle:diff@v1/Replace",
+        "path", mg.String( "f1[ 2 ]" ),
+        "value", i32( 1 ),
+    )
+    mg.AssertEqualValues( expct, OperationAsValue( op ), a )
+    op = &Operation{ Kind: OperationRemove }
+    expct = parser.MustStruct( "mingle:diff@v1/Remove" )
+    mg.AssertEqualValues( expct, OperationAsValue( op ), a )
+}
+
+func TestOperationFromValueErrors( t *testing.T ) {
+    la := assert.NewListPathAsserter( t )
+    ie := func( path, msg string ) *mg.InputError {
+        var p objpath.PathNode
+        if path != "" { p = parser.MustIdentifierPath( path ) }
+        return mg.NewInputError( p, msg )
+    }
+    for _, tc := range []struct { val mg.Value; err *mg.InputError } {
+        { i32( 1 ), ie( "", "not an operation: mingle:core@v1/Int32" ) },
+        { s1(), ie( "", "unknown operation type: ns1@v1/S1" ) },
+        {
+            parser.MustStruct( "mingle:diff@v1/Add", "path", i32( 1 ) ),
+            ie( "path", "invalid path: mingle:core@v1/Int32" ),
+        },
+        {
+            parser.MustStruct( "mingle:diff@v1/Add", "path", "f1" ),
+            ie( "", "invalid value for add operation" ),
+        },
+        {
+            parser.MustStruct( "mingle:diff@v1/Remove", "value", i32( 1 ) ),
+            ie( "", "invalid value for remove operation" ),
+        },
+    } {
+        _, err := OperationFromValue( tc.val )
+        la.EqualErrors( tc.err, err )
+        la = la.Next()
+    }
+}
+
+func TestOperationFromValueInvalidPathString( t *testing.T ) {
+    v := parser.MustStruct( "mingle:diff@v1/Remove", "path", "f1.[" )
+    _, err := OperationFromValue( v )
+    if ie, ok := err.( *mg.InputError ); ok {
+        assert.Equal( "path", mg.FormatIdPath( ie.Location ) )
+    } else {
+        t.Fatalf( "expected input error, got: %v", err )
+    }
+}
+
+func TestOperationsFromListErrorLocation( t *testing.T ) {
+    l := mg.MustList(
+        parser.MustStruct( "mingle:diff@v1/Remove", "path", "f1" ),
+        parser.MustStruct( "mingle:diff@v1/Add", "path", i32( 1 ) ),
+    )
+    _, err := OperationsFromList( l )
+    expct := mg.NewInputError( 
+        objpath.Descend( objpath.RootedAtList().SetIndex( 1 ), IdPath ),
+        "invalid path: mingle:core@v1/Int32",
+    )
+    assert.NewPathAsserter( t ).EqualErrors( expct, err )
+}
+
+type patchErrorTest struct {
+    val mg.Value
+    op *Operation
+    err *PatchError
+    streamErr *PatchError // if different from err
+}
+
+func getPatchErrorTests() []*patchErrorTest {
+    op := func( kind OperationKind, path string, val mg.Value ) *Operation {
+        return &Operation{ Kind: kind, Path: mkPath( path ), Value: val }
+    }
+    pe := func( path, msg string ) *PatchError {
+        return NewPatchError( mkPath( path ), msg )
+    }
+    src := s1( "f1", i32( 1 ), "f2", mg.MustList( i32( 1 ) ) )
+    return []*patchErrorTest{
+        {
+            val: src,
+            op: op( OperationRemove, "", nil ),
+            err: pe( "", "cannot remove root value" ),
+        },
+        {
+            val: src,
+            op: op( OperationAdd, "f1", i32( 2 ) ),
+            err: pe( "f1", "field already present" ),
+        },
+        {
+            val: src,
+            op: op( OperationReplace, "f3", i32( 2 ) ),
+            err: pe( "f3", "no such field" ),
+        },
+        {
+            val: src,
+            op: op( OperationRemove, "f2[ 1 ]", nil ),
+            err: pe( "f2[ 1 ]", "list index out of range" ),
+        },
+        {
+            val: src,
+            op: op( OperationRemove, "f2.f1", nil ),
+            err: pe( "f2", "expected a list index in path" ),
+        },
+        {
+            val: src,
+            op: op( OperationRemove, "f1.f1", nil ),
+            err: pe( "f1",
+                "cannot apply remove to member of mingle:core@v1/Int32" ),
+            streamErr: pe( "f1.f1", "no value at path" ),
+        },
+    }
+}
+
+func assertPatchError( pt *patchErrorTest, a *assert.PathAsserter ) {
+    ops := []*Operation{ pt.op }
+    _, err := Apply( pt.val, ops )
+    a.Descend( "apply" ).Equal( pt.err.Error(), err.Error() )
+    expct := pt.err
+    if pt.streamErr != nil { expct = pt.streamErr }
+    _, err = patchStream( pt.val, ops )
+    a.Descend( "stream" ).Equal( expct.Error(), err.Error() )
+}
+
+func TestPatchErrors( t *testing.T ) {
+    la := assert.NewListPathAsserter( t )
+    for _, pt := range getPatchErrorTests() {
+        assertPatchError( pt, la )
+        la = la.Next()
+    }
+}
+
+func TestApplyIsOrdered( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    ops := []*Operation{
+        { Kind: OperationAdd, Path: mkPath( "[ 0 ]" ), Value: i32( 2 ) },
+        { Kind: OperationAdd, Path: mkPath( "[ 0 ]" ), Value: i32( 1 ) },
+        { Kind: OperationRemove, Path: mkPath( "[ 2 ]" ) },
+    }
+    act, err := Apply( mg.MustList( i32( 3 ) ), ops )
+    if err != nil { a.Fatal( err ) }
+    mg.AssertEqualValues( mg.MustList( i32( 1 ), i32( 2 ) ), act, a )
+}
@@ -4,7 +4,9 @@ import (
     "testing"
     "bitgirder/assert"
     "bytes"
+    "io"
     mg "mingle"
+    mgRct "mingle/reactor"
 )
 
 func assertWriteValue( wr *BinWriter, val mg.Value, a *assert.PathAsserter ) {
@@ -54,7 +56,7 @@ func assertInvalidData( t *mg.BinIoInvalidDataTest, a *assert.PathAsserter ) {
 
 func TestIo( t *testing.T ) {
     a := assert.NewPathAsserter( t )
-    for _, test := range mg.CreateCoreIoTests() {
+    for _, test := range mg.CreateGoCoreIoTests() {
         ta := a.Descend( mg.CoreIoTestNameFor( test ) )
         switch v := test.( type ) {
         case *mg.BinIoRoundtripTest: assertRoundtrip( v, ta )
@@ -64,3 +66,58 @@ func TestIo( t *testing.T ) {
         }
     }
 }
+
+func TestRecorderReplay( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    vals := []mg.Value{ 
+        mg.Int32( 1 ), 
+        mg.MustList( mg.String( "a" ), mg.MustList() ),
+        mg.MustSymbolMap( mg.MakeTestId( 1 ), mg.Int32( 1 ) ),
+    }
+    bb := &bytes.Buffer{}
+    rec := NewRecorder( bb )
+    for _, val := range vals {
+        if err := mgRct.VisitValue( val, rec ); err != nil { a.Fatal( err ) }
+    }
+    acc := make( []mg.Value, 0, len( vals ) )
+    var br *mgRct.BuildReactor
+    dt := mgRct.NewDepthTracker()
+    rep := mgRct.EventProcessorFunc( func( ev mgRct.Event ) error {
+        if br == nil { br = mgRct.NewBuildReactor( mgRct.ValueBuilderFactory ) }
+        if err := br.ProcessEvent( ev ); err != nil { return err }
+        dt.ProcessEvent( ev )
+        if dt.Depth() == 0 {
+            acc = append( acc, br.GetValue().( mg.Value ) )
+            br = nil
+        }
+        return nil
+    })
+    if err := Replay( bb, rep ); err != nil { a.Fatal( err ) }
+    a.Equal( len( vals ), len( acc ) )
+    for i, val := range vals { mg.AssertEqualValues( val, acc[ i ], a ) }
+}
+
+func TestReplayTruncated( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    bb := &bytes.Buffer{}
+    val := mg.MustList( mg.Int32( 1 ), mg.Int32( 2 ) )
+    if err := mgRct.VisitValue( val, NewRecorder( bb ) ); err != nil { 
+        a.Fatal( err ) 
+    }
+    bb.Truncate( bb.Len() - 1 )
+    err := Replay( bb, mgRct.DiscardProcessor )
+    a.Truef( err != nil, "expected error" )
+}
+
+func TestEventReaderFromBinReader( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    bb := &bytes.Buffer{}
+    val := mg.MustList( mg.Int32( 1 ), mg.String( "a" ) )
+    assertWriteValue( NewWriter( bb ), val, a )
+    r := mgRct.NewEventReader( NewReader( bb ).ReadReactorValue )
+    defer r.Close()
+    if _, err := r.ExpectList(); err != nil { a.Fatal( err ) }
+    if err := r.Skip(); err != nil { a.Fatal( err ) }
+    _, err := r.Next()
+    a.Equal( io.EOF, err )
+}
@@ -0,0 +1,197 @@
+package merge
+
+import (
+    "testing"
+    "bitgirder/assert"
+    mg "mingle"
+    mgRct "mingle/reactor"
+    "mingle/parser"
+)
+
+func i32( i int ) mg.Int32 { return mg.Int32( i ) }
+
+func sm( pairs ...interface{} ) *mg.SymbolMap {
+    return parser.MustSymbolMap( pairs... )
+}
+
+func s1( pairs ...interface{} ) *mg.Struct {
+    return parser.MustStruct( "ns1@v1/S1", pairs... )
+}
+
+type mergeTest struct {
+    layers []mg.Value
+    merger *Merger // NewMerger() if nil
+    expct mg.Value
+    err *MergeError
+}
+
+func getMergeTests() []*mergeTest {
+    id := parser.MustIdentifier
+    p := parser.MustIdentifierPath
+    pat := parser.MustIdentifierPathPattern
+    appender := NewMerger()
+    appender.ListStrategy = ListStrategyAppend
+    byKey := NewMerger()
+    byKey.SetListStrategy( 
+        pat( "servers" ), ListStrategyMergeByKey, id( "name" ) )
+    byKey.SetListStrategy( 
+        pat( "servers[ * ].tags" ), ListStrategyAppend, nil )
+    noKey := NewMerger()
+    noKey.ListStrategy = ListStrategyMergeByKey
+    return []*mergeTest{
+        { layers: []mg.Value{}, expct: mg.NullVal },
+        { layers: []mg.Value{ i32( 1 ) }, expct: i32( 1 ) },
+        { layers: []mg.Value{ i32( 1 ), i32( 2 ), i32( 3 ) }, expct: i32( 3 ) },
+        {
+            layers: []mg.Value{
+                sm( "f1", i32( 1 ), "f2", sm( "f1", "a", "f2", "b" ) ),
+                sm( "f2", sm( "f2", "c", "f3", "d" ), "f3", i32( 3 ) ),
+                sm( "f2", sm( "f3", mg.NullVal ) ),
+            },
+            expct: sm(
+                "f1", i32( 1 ),
+                "f2", sm( "f1", "a", "f2", "c", "f3", mg.NullVal ),
+                "f3", i32( 3 ),
+            ),
+        },
+        {
+            layers: []mg.Value{ 
+                s1( "f1", mg.NullVal, "f2", i32( 2 ) ), 
+                s1( "f1", sm( "f1", i32( 1 ) ) ),
+            },
+            expct: s1( "f1", sm( "f1", i32( 1 ) ), "f2", i32( 2 ) ),
+        },
+        {
+            layers: []mg.Value{ 
+                sm( "f1", mg.MustList( i32( 1 ), i32( 2 ) ) ),
+                sm( "f1", mg.MustList( i32( 3 ) ) ),
+            },
+            expct: sm( "f1", mg.MustList( i32( 3 ) ) ),
+        },
+        {
+            layers: []mg.Value{ 
+                sm( "f1", mg.MustList( i32( 1 ), i32( 2 ) ) ),
+                sm( "f1", mg.MustList( i32( 3 ) ) ),
+            },
+            merger: appender,
+            expct: sm( "f1", mg.MustList( i32( 1 ), i32( 2 ), i32( 3 ) ) ),
+        },
+        {
+            layers: []mg.Value{
+                sm( "servers", mg.MustList(
+                    sm( "name", "a", "port", i32( 1 ), "tags", 
+                        mg.MustList( "t1" ) ),
+                    s1( "name", "b", "port", i32( 2 ) ),
+                )),
+                sm( "servers", mg.MustList(
+                    s1( "name", "b", "port", i32( 3 ) ),
+                    sm( "name", "c" ),
+                    sm( "name", "a", "tags", mg.MustList( "t2" ) ),
+                )),
+            },
+            merger: byKey,
+            expct: sm( "servers", mg.MustList(
+                sm( "name", "a", "port", i32( 1 ), "tags",
+                    mg.MustList( "t1", "t2" ) ),
+                s1( "name", "b", "port", i32( 3 ) ),
+                sm( "name", "c" ),
+            )),
+        },
+        {
+            layers: []mg.Value{ 
+                sm( "f1", sm( "f1", i32( 1 ) ) ), 
+                sm( "f1", sm( "f1", mg.Int64( 1 ) ) ), 
+            },
+            err: NewMergeError( p( "f1.f1" ), 
+                "layer 1 has mingle:core@v1/Int64 where lower layers have " +
+                "mingle:core@v1/Int32" ),
+        },
+        {
+            layers: []mg.Value{ 
+                i32( 1 ),
+                sm( "f1", s1() ), 
+                sm( "f1", parser.MustStruct( "ns1@v1/S2" ) ), 
+            },
+            err: NewMergeError( nil, 
+                "layer 1 has mingle:core@v1/SymbolMap where lower layers " +
+                "have mingle:core@v1/Int32" ),
+        },
+        {
+            layers: []mg.Value{ 
+                sm( "f1", s1() ), 
+                sm( "f1", parser.MustStruct( "ns1@v1/S2" ) ), 
+            },
+            err: NewMergeError( p( "f1" ), 
+                "layer 1 has ns1@v1/S2 where lower layers have ns1@v1/S1" ),
+        },
+        {
+            layers: []mg.Value{ 
+                sm( "servers", mg.MustList( sm( "name", "a" ) ) ),
+                sm( "servers", mg.MustList( sm( "host", "a" ) ) ),
+            },
+            merger: byKey,
+            err: NewMergeError( p( "servers[ 0 ]" ), 
+                "list element has no key field name" ),
+        },
+        {
+            layers: []mg.Value{ 
+                sm( "servers", mg.MustList( i32( 1 ) ) ),
+                sm( "servers", mg.MustList() ),
+            },
+            merger: byKey,
+            err: NewMergeError( p( "servers[ 0 ]" ), 
+                "cannot merge by key list element of type " +
+                "mingle:core@v1/Int32" ),
+        },
+        {
+            layers: []mg.Value{ mg.MustList(), mg.MustList() },
+            merger: noKey,
+            err: NewMergeError( nil, "no key field set for list" ),
+        },
+    }
+}
+
+func assertMergeTest( mt *mergeTest, a *assert.PathAsserter ) {
+    m := mt.merger
+    if m == nil { m = NewMerger() }
+    act, err := m.Merge( mt.layers... )
+    if mt.err != nil {
+        mg.AssertErrors( mt.err, err, a )
+        return
+    }
+    if err != nil { a.Fatal( err ) }
+    mg.AssertEqualValues( mt.expct, act, a )
+}
+
+func TestMerge( t *testing.T ) {
+    la := assert.NewListPathAsserter( t )
+    for _, mt := range getMergeTests() {
+        assertMergeTest( mt, la )
+        la = la.Next()
+    }
+}
+
+func TestMergeDoesNotModifyLayers( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    lo := sm( "f1", sm( "f1", i32( 1 ) ) )
+    if _, err := Merge( lo, sm( "f1", sm( "f1", i32( 2 ) ) ) ); err != nil {
+        a.Fatal( err )
+    }
+    mg.AssertEqualValues( sm( "f1", sm( "f1", i32( 1 ) ) ), lo, a )
+}
+
+func TestMergeSources( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    act, err := NewMerger().MergeSources(
+        mgRct.ValueEventSource( sm( "f1", i32( 1 ), "f2", i32( 2 ) ) ),
+        mgRct.ValueEventSource( sm( "f2", i32( 3 ) ) ),
+    )
+    if err != nil { a.Fatal( err ) }
+    mg.AssertEqualValues( sm( "f1", i32( 1 ), "f2", i32( 3 ) ), act, a )
+}
+func TestMergeNoSources( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    act, err := NewMerger().MergeSources()
+    if err != nil { a.Fatal( err ) }
+    mg.AssertEqualValues( mg.NullVal, act, a )
+}
@@ -636,6 +636,43 @@ func init() {
                 },
             },
         ),
+        typRefSucc( "{ ns1@v1/T1* }?",
+            &CompletableTypeReference{
+                Expression: &NullableTypeExpression{
+                    Loc: loc( 15 ),
+                    Expression: &MapTypeExpression{
+                        Loc: loc( 1 ),
+                        Expression: &ListTypeExpression{
+                            Loc: loc( 12 ),
+                            Expression: &AtomicTypeExpression{
+                                Name: qnNs1V1T1,
+                                NameLoc: loc( 3 ),
+                            },
+                            AllowsEmpty: true,
+                        },
+                    },
+                },
+            },
+        ),
+        typRefSucc( `&{ns1@v1/T1~"a"}*`,
+            &CompletableTypeReference{
+                Expression: &ListTypeExpression{
+                    Loc: loc( 17 ),
+                    Expression: &PointerTypeExpression{
+                        Loc: loc( 1 ),
+                        Expression: &MapTypeExpression{
+                            Loc: loc( 2 ),
+                            Expression: &AtomicTypeExpression{
+                                Name: qnNs1V1T1,
+                                NameLoc: loc( 3 ),
+                                Restriction: rx( "a", 13 ),
+                            },
+                        },
+                    },
+                    AllowsEmpty: true,
+                },
+            },
+        ),
         typRefSucc( "&ns1@v1/T1*+", 
             &CompletableTypeReference{ 
                 Expression: &ListTypeExpression{
@@ -677,7 +714,11 @@ func init() {
         typRefFail( "()", 2, "Expected type reference but found: )" ),
         typRefFail( "(   )", 5, "Expected type reference but found: )" ),
         typRefFail( "&(ns1@v1/T1", 2, `Unmatched "("` ),
-        typRefFail( "&{ns1@v1/T1}", 2, "Expected type reference but found: {" ),
+        typRefFail( "&[ns1@v1/T1]", 2, "Expected type reference but found: [" ),
+        typRefFail( "{", 2, "Unexpected end of input" ),
+        typRefFail( "{}", 2, "Expected type reference but found: }" ),
+        typRefFail( "{ns1@v1/T1", 11, "Expected } but found: END" ),
+        typRefFail( "{ns1@v1/T1]", 11, "Expected } but found: ]" ),
         typRefFail( "(&(ns1@v1/T1)", 1, `Unmatched "("` ),
         typRefFail( "(ns1@v1/T1))", 12, "Unexpected token: )" ),
         typRefFail( "ns1@v1/T1~", 11, "Unexpected end of input" ),
@@ -697,5 +738,8 @@ func init() {
         typRefFail( "&ns1@v1/T1???", 12, 
             "a nullable type cannot itself be made nullable" ),
         typRefFail( "T1~12.1", 4, "Expected type restriction but found: 12.1" ),
+        typRefFail( `T1*~"a"`, 5, "Expected length range but found: a" ),
+        typRefFail( "T1+~", 5, "Unexpected end of input" ),
+        typRefFail( "T1*~[,8]", 5, "Infinite low range must be open" ),
     )
 }
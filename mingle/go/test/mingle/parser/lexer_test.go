@@ -330,6 +330,20 @@ func TestTokenReadAtEof( t *testing.T ) {
     a.expectEof()
 }
 
+func TestComparisonTokens( t *testing.T ) {
+    a := newLexerAsserter( "a<=b<c>= > == !=", true, t )
+    a.expectToken( 1, 1, id( "a" ) )
+    a.expectToken( 1, 2, SpecialTokenLessThanOrEqual )
+    a.expectToken( 1, 4, id( "b" ) )
+    a.expectToken( 1, 5, SpecialTokenLessThan )
+    a.expectToken( 1, 6, id( "c" ) )
+    a.expectToken( 1, 7, SpecialTokenGreaterThanOrEqual )
+    a.expectToken( 1, 10, SpecialTokenGreaterThan )
+    a.expectToken( 1, 12, SpecialTokenEqual )
+    a.expectToken( 1, 15, SpecialTokenNotEqual )
+    a.expectEof()
+}
+
 func TestManualSetSynthEnd( t *testing.T ) {
     a := newLexerAsserter( "+\n-", false, t )
     a.expectToken( 1, 1, SpecialTokenPlus )
@@ -486,3 +500,34 @@ func TestLexerWithoutRecognizeComments( t *testing.T ) {
     expct := &ParseErrorExpect{ 5, "Unexpected comment start" }
     AssertParseError( err, expct, assert.NewPathAsserter( t ) )
 }
+
+func TestCommentHandler( t *testing.T ) {
+    type comment struct { 
+        c CommentToken
+        line, col int
+        trailing bool 
+    }
+    comments := make( []comment, 0, 4 )
+    opts := &LexerOptions{
+        CommentHandler: func( c CommentToken, lc *Location, trailing bool ) {
+            cmt := comment{ c, lc.Line, lc.Col, trailing }
+            comments = append( comments, cmt )
+        },
+    }
+    lx := newTestLexerOptions( opts, "# c1\n  # c2\na # c3\nb #c4", true )
+    for {
+        tok, _, err := lx.ReadToken()
+        if err == io.EOF { break }
+        if err != nil { t.Fatal( err ) }
+        if tok == nil { break }
+    }
+    assert.Equal( 
+        []comment{
+            { " c1\n", 1, 1, false },
+            { " c2\n", 2, 3, false },
+            { " c3\n", 3, 3, true },
+            { "c4", 4, 3, true },
+        },
+        comments,
+    )
+}
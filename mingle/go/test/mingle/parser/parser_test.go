@@ -4,7 +4,11 @@ import (
     "testing"
     "bitgirder/assert"
     "fmt"
+    "math"
+    "time"
+    "strings"
     mg "mingle"
+    "bitgirder/objpath"
 )
 
 func TestTimestampParse( t *testing.T ) {
@@ -33,3 +37,168 @@ func TestTimestampParseFail( t *testing.T ) {
         assert.Equal( 1, pe.Loc.Col )
     }
 }
+
+func assertTemporalParseFail( str, msgTyp string, err error ) {
+    if err == nil { panic( fmt.Errorf( "Was able to parse: %q", str ) ) }
+    pe := err.( *ParseError )
+    assert.Equal( fmt.Sprintf( "Invalid ISO 8601 %s: %q", msgTyp, str ),
+        pe.Message )
+    assert.Equal( 1, pe.Loc.Line )
+    assert.Equal( 1, pe.Loc.Col )
+}
+
+func TestUuidParse( t *testing.T ) {
+    s := "a0b1c2d3-e4f5-4617-8899-aabbccddeeff"
+    for _, in := range []string{ s, strings.ToUpper( s ) } {
+        u, err := ParseUuid( in )
+        if err != nil { t.Fatal( err ) }
+        assert.Equal( s, u.String() )
+    }
+    for _, str := range []string{ "", "a0b1c2d3e4f546178899aabbccddeeff" } {
+        _, err := ParseUuid( str )
+        pe := err.( *ParseError )
+        assert.Equal( fmt.Sprintf( "Invalid UUID: %q", str ), pe.Message )
+    }
+}
+
+func TestUriParse( t *testing.T ) {
+    f := func( in, expct string ) {
+        u, err := ParseUri( in )
+        if err != nil { t.Fatal( err ) }
+        if expct == "" { expct = in }
+        assert.Equal( mg.Uri( expct ), u )
+    }
+    f( "http://example.com", "" )
+    f( "Https://Example.COM:8080/Path?Q=1", 
+        "https://example.com:8080/Path?Q=1" )
+    f( "mailto:someone@example.com", "" )
+    for _, str := range []string{ "", "/a/b", "example.com", "http://a b", 
+                                  "%zz" } {
+        _, err := ParseUri( str )
+        pe := err.( *ParseError )
+        assert.Equal( fmt.Sprintf( "Invalid URI: %q", str ), pe.Message )
+    }
+}
+
+func TestDateParse( t *testing.T ) {
+    f := func( src string, y int, m time.Month, d int ) {
+        dt, err := ParseDate( src )
+        if err != nil { t.Fatal( err ) }
+        assert.Equal( mg.Date{ Year: y, Month: m, Day: d }, dt )
+        assert.Equal( src, dt.String() )
+    }
+    f( "2012-01-02", 2012, time.January, 2 )
+    f( "0001-12-31", 1, time.December, 31 )
+    f( "2012-02-29", 2012, time.February, 29 )
+    for _, str := range []string {
+        "", "2012-1-02", "2012-01-02T00:00:00Z", "2013-02-29", "2012-13-01",
+        "20120102",
+    } {
+        _, err := ParseDate( str )
+        assertTemporalParseFail( str, "date", err )
+    }
+}
+
+func TestLocalTimeParse( t *testing.T ) {
+    f := func( src, expct string, h, m, s, ns int ) {
+        tm, err := ParseLocalTime( src )
+        if err != nil { t.Fatal( err ) }
+        expctTm := mg.LocalTime{ 
+            Hour: h, 
+            Minute: m, 
+            Second: s, 
+            Nanosecond: ns,
+        }
+        assert.Equal( expctTm, tm )
+        if expct == "" { expct = src }
+        assert.Equal( expct, tm.String() )
+    }
+    f( "00:00:00", "", 0, 0, 0, 0 )
+    f( "12:01:02", "", 12, 1, 2, 0 )
+    f( "23:59:59.999999999", "", 23, 59, 59, 999999999 )
+    f( "12:01:02.5", "", 12, 1, 2, 500000000 )
+    f( "12:01:02.000100", "12:01:02.0001", 12, 1, 2, 100000 )
+    for _, str := range []string {
+        "", "24:00:00", "12:60:00", "12:00", "12:00:00Z", "1:00:00",
+    } {
+        _, err := ParseLocalTime( str )
+        assertTemporalParseFail( str, "time", err )
+    }
+}
+
+func TestDurationParse( t *testing.T ) {
+    f := func( src, expct string, d time.Duration ) {
+        dur, err := ParseDuration( src )
+        if err != nil { t.Fatal( err ) }
+        assert.Equal( mg.Duration( d ), dur )
+        if expct == "" { expct = src }
+        assert.Equal( expct, dur.String() )
+    }
+    f( "PT0S", "", 0 )
+    f( "P0D", "PT0S", 0 )
+    f( "-PT0S", "PT0S", 0 )
+    f( "P1D", "", 24 * time.Hour )
+    f( "PT36H", "P1DT12H", 36 * time.Hour )
+    f( "P1DT2H3M4.5S", "", 26 * time.Hour + 3 * time.Minute + 4500 * 
+        time.Millisecond )
+    f( "PT1M", "", time.Minute )
+    f( "PT90S", "PT1M30S", 90 * time.Second )
+    f( "PT0.000000001S", "", 1 )
+    f( "-PT1.25S", "", -1250 * time.Millisecond )
+    f( "PT9223372036.854775807S", "P106751DT23H47M16.854775807S", 
+        math.MaxInt64 )
+    f( "-PT9223372036.854775808S", "-P106751DT23H47M16.854775808S", 
+        math.MinInt64 )
+    for _, str := range []string {
+        "", "P", "PT", "P1DT", "1D", "P1Y", "P1M", "P1W", "PT1.5M", "PT1H1H",
+        "PT1S2M", "PT1.0000000001S", "+PT1S", "P-1D",
+    } {
+        _, err := ParseDuration( str )
+        assertTemporalParseFail( str, "duration", err )
+    }
+    for _, str := range []string { 
+        "PT9223372036.854775808S", "P106752D", "P99999999999999999999D",
+    } {
+        _, err := ParseDuration( str )
+        pe := err.( *ParseError )
+        assert.Equal( 
+            fmt.Sprintf( "ISO 8601 duration out of range: %q", str ), 
+            pe.Message )
+    }
+}
+
+func TestIdentifierPathPatternParse( t *testing.T ) {
+    id := MustIdentifier
+    la := assert.NewListPathAsserter( t )
+    f := func( in, extForm string, expct objpath.PathNode, strictErr string ) {
+        act, err := ParseIdentifierPathPattern( in )
+        if err != nil { la.Fatal( err ) }
+        la.Equal( expct, act )
+        la.Equal( extForm, mg.FormatIdPath( act ) )
+        if _, err := ParseIdentifierPath( in ); err == nil {
+            la.Fatalf( "strict parse accepted %q", in )
+        } else { la.Equal( strictErr, err.( *ParseError ).Message ) }
+        la = la.Next()
+    }
+    f( "*", "*", objpath.RootedAt( mg.IdPathWildcard ),
+        "Expected identifier or list index but found: *" )
+    f( "i1.*.i2", "i1.*.i2",
+        objpath.RootedAt( id( "i1" ) ).
+            Descend( mg.IdPathWildcard ).
+            Descend( id( "i2" ) ),
+        "Expected identifier but found: *",
+    )
+    f( "i1[ * ][ 2 ]", "i1.*[ 2 ]",
+        objpath.RootedAt( id( "i1" ) ).
+            Descend( mg.IdPathWildcard ).
+            StartList().SetIndex( 2 ),
+        "Expected numeric token but found: *",
+    )
+    f( "[*].i1", "*.i1",
+        objpath.RootedAt( mg.IdPathWildcard ).Descend( id( "i1" ) ),
+        "Expected numeric token but found: *",
+    )
+    p, err := ParseIdentifierPathPattern( "i1[ 2 ].i2" )
+    if err != nil { t.Fatal( err ) }
+    assert.Equal( MustIdentifierPath( "i1[ 2 ].i2" ), p )
+}
@@ -315,6 +315,28 @@ func TestTypeReferenceSetsSynth( t *testing.T ) {
                 },
             },
         },
+        {
+            `A*~[0,1]`,
+            &CompletableTypeReference{
+                Expression: &ListTypeExpression{
+                    Loc: lc( 2 ),
+                    AllowsEmpty: true,
+                    Expression: &AtomicTypeExpression{
+                        Name: nmA,
+                        NameLoc: lc( 1 ),
+                    },
+                    Restriction: &RangeRestrictionSyntax{
+                        Loc: lc( 4 ),
+                        LeftClosed: true,
+                        Left: &NumRestrictionSyntax{
+                            Num: &NumericToken{ Int: "0" }, Loc: lc( 5 ) },
+                        Right: &NumRestrictionSyntax{
+                            Num: &NumericToken{ Int: "1" }, Loc: lc( 7 ) },
+                        RightClosed: true,
+                    },
+                },
+            },
+        },
         {
             `&A*`,
             &CompletableTypeReference{
@@ -2,6 +2,7 @@ package parser
 
 import (
     "bitgirder/assert"
+    "bitgirder/objpath"
     "bytes"
     mg "mingle"
 //    "log"
@@ -112,6 +113,7 @@ func assertListTypeExpression(
     a.Truef( ok, "not list type: %T", v )
     a.Descend( "Loc" ).Equal( expct.Loc, act.Loc )
     a.Descend( "AllowsEmpty" ).Equal( expct.AllowsEmpty, act.AllowsEmpty )
+    assertRestriction( expct.Restriction, act.Restriction, a )
     assertEqualExpression( expct.Expression, act.Expression, a )
 }
 
@@ -135,12 +137,23 @@ func assertPointerTypeExpression(
     assertEqualExpression( expct.Expression, act.Expression, a )
 }
 
+func assertMapTypeExpression(
+    expct *MapTypeExpression, v interface{}, a *assert.PathAsserter ) {
+
+    a = a.Descend( "mapTyp" )
+    act, ok := v.( *MapTypeExpression )
+    a.Truef( ok, "not a map type: %T", v )
+    a.Descend( "Loc" ).Equal( expct.Loc, act.Loc )
+    assertEqualExpression( expct.Expression, act.Expression, a )
+}
+
 func assertEqualExpression( expct, act interface{}, a *assert.PathAsserter ) {
     switch v := expct.( type ) {
     case *AtomicTypeExpression: assertAtomicTypeExpression( v, act, a )
     case *ListTypeExpression: assertListTypeExpression( v, act, a )
     case *NullableTypeExpression: assertNullableTypeExpression( v, act, a )
     case *PointerTypeExpression: assertPointerTypeExpression( v, act, a )
+    case *MapTypeExpression: assertMapTypeExpression( v, act, a )
     default: a.Fatalf( "unhandled exp: %T", expct )
     }
 }
@@ -161,6 +174,18 @@ func MustIdentifier( s string ) *mg.Identifier {
     panic( err )
 }
 
+func MustIdentifierPath( s string ) objpath.PathNode {
+    p, err := ParseIdentifierPath( s )
+    if err == nil { return p }
+    panic( err )
+}
+
+func MustIdentifierPathPattern( s string ) objpath.PathNode {
+    p, err := ParseIdentifierPathPattern( s )
+    if err == nil { return p }
+    panic( err )
+}
+
 func MustNamespace( s string ) *mg.Namespace {
     ns, err := ParseNamespace( s )
     if err == nil { return ns }
@@ -210,6 +235,9 @@ func ( tc *unsafeTypeCompleter ) setRangeValue(
         if tm, err := ParseTimestamp( sx.Str ); err == nil {
             *valPtr = tm
         } else { panic( err ) }
+    case qn.Equals( mg.QnameDate ): *valPtr = MustDate( sx.Str )
+    case qn.Equals( mg.QnameLocalTime ): *valPtr = MustLocalTime( sx.Str )
+    case qn.Equals( mg.QnameDuration ): *valPtr = MustDuration( sx.Str )
     case qn.Equals( mg.QnameString ): *valPtr = mg.String( sx.Str )
     default: panic( libErrorf( "unhandled range type: %s", qn ) )
     }
@@ -233,7 +261,13 @@ func ( tc *unsafeTypeCompleter ) getRestriction(
             return tc.getStringRestriction( regx )
         }
     }
-    return tc.getRangeRestriction( qn, rx.( *RangeRestrictionSyntax ) )
+    rng := rx.( *RangeRestrictionSyntax )
+    if IsLengthRange( qn, rng ) {
+        lr, err := CreateLengthRestriction( rng )
+        if err != nil { panic( err ) }
+        return lr
+    }
+    return tc.getRangeRestriction( qn, rng )
 }
 
 func ( tc *unsafeTypeCompleter ) CompleteBaseType(
@@ -273,6 +307,36 @@ func MustTimestamp( s string ) mg.Timestamp {
     panic( err )
 }
 
+func MustDate( s string ) mg.Date {
+    d, err := ParseDate( s )
+    if err == nil { return d }
+    panic( err )
+}
+
+func MustLocalTime( s string ) mg.LocalTime {
+    t, err := ParseLocalTime( s )
+    if err == nil { return t }
+    panic( err )
+}
+
+func MustDuration( s string ) mg.Duration {
+    d, err := ParseDuration( s )
+    if err == nil { return d }
+    panic( err )
+}
+
+func MustUuid( s string ) mg.Uuid {
+    u, err := ParseUuid( s )
+    if err == nil { return u }
+    panic( err )
+}
+
+func MustUri( s string ) mg.Uri {
+    u, err := ParseUri( s )
+    if err == nil { return u }
+    panic( err )
+}
+
 func mustAsQname( val interface{} ) *mg.QualifiedTypeName {
     if qn, ok := val.( *mg.QualifiedTypeName ); ok { return qn }
     return MustQualifiedTypeName( val.( string ) )
@@ -0,0 +1,144 @@
+package reactor
+
+import (
+    "testing"
+    "context"
+    "errors"
+    mg "mingle"
+    "mingle/parser"
+    "bitgirder/assert"
+    "bitgirder/objpath"
+)
+
+func concurrentTestValue() *mg.Struct {
+    lst := make( []interface{}, 50 )
+    for i := range lst {
+        lst[ i ] = parser.MustStruct( "ns1@v1/S2", "f1", mg.Int32( i ) )
+    }
+    return parser.MustStruct( "ns1@v1/S1",
+        "f1", mg.Int32( 1 ),
+        "f2", mg.MustList( lst... ),
+        "f3", parser.MustSymbolMap( "k1", mg.String( "v1" ) ),
+    )
+}
+
+func TestConcurrentPipelineBuildsValue( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    val := concurrentTestValue()
+    for _, bufSize := range []int{ 0, 1, 16 } {
+        br := NewBuildReactor( ValueBuilderFactory )
+        cp := InitConcurrentReactorPipeline( context.Background(),
+            NewStageBoundary( bufSize ),
+            NewStructuralReactor( ReactorTopTypeValue ),
+            NewStageBoundary( bufSize ),
+            br,
+        )
+        if err := VisitValue( val, cp ); err != nil { a.Fatal( err ) }
+        if err := cp.Close(); err != nil { a.Fatal( err ) }
+        mg.AssertEqualValues( val, br.GetValue().( mg.Value ), a )
+    }
+}
+
+// checks that event paths set upstream of a boundary are intact downstream,
+// even though the upstream list paths are modified in place
+func TestConcurrentPipelineEventPaths( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    br := NewBuildReactor( ValueBuilderFactory )
+    paths := []objpath.PathNode{ parser.MustIdentifierPath( "f2[ 3 ]" ) }
+    cp := InitConcurrentReactorPipeline( context.Background(),
+        NewPathSettingProcessor(),
+        NewStageBoundary( 8 ),
+        NewFieldMaskReactor( paths ),
+        br,
+    )
+    if err := VisitValue( concurrentTestValue(), cp ); err != nil {
+        a.Fatal( err )
+    }
+    if err := cp.Close(); err != nil { a.Fatal( err ) }
+    expct := parser.MustStruct( "ns1@v1/S1",
+        "f2", mg.MustList(
+            parser.MustStruct( "ns1@v1/S2", "f1", mg.Int32( 3 ) ) ),
+    )
+    mg.AssertEqualValues( expct, br.GetValue().( mg.Value ), a )
+}
+
+type failAfter struct {
+    n int
+    err error
+}
+
+func ( f *failAfter ) ProcessEvent( ev Event ) error {
+    if f.n == 0 { return f.err }
+    f.n--
+    return nil
+}
+
+// feeds events until one fails, returning the number fed successfully
+func feedUntilError( cp *ConcurrentPipeline, max int ) ( int, error ) {
+    ev := NewValueEvent( mg.Int32( 1 ) )
+    for i := 0; i < max; i++ {
+        if err := cp.ProcessEvent( ev ); err != nil { return i, err }
+    }
+    return max, nil
+}
+
+func TestConcurrentPipelineDownstreamError( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    failErr := errors.New( "test-error" )
+    cp := InitConcurrentReactorPipeline( context.Background(),
+        NewStageBoundary( 1 ),
+        &failAfter{ n: 2, err: failErr },
+    )
+    n, err := feedUntilError( cp, 1000 )
+    a.Equal( failErr, err )
+    a.Truef( n < 1000, "upstream was not stopped" )
+    a.Equal( failErr, cp.Close() )
+}
+
+func TestConcurrentPipelineUpstreamError( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    failErr := errors.New( "test-error" )
+    cnt := 0
+    counter := EventProcessorFunc( func( _ Event ) error {
+        cnt++
+        return nil
+    })
+    cp := InitConcurrentReactorPipeline( context.Background(),
+        &failAfter{ n: 2, err: failErr },
+        NewStageBoundary( 4 ),
+        counter,
+    )
+    n, err := feedUntilError( cp, 10 )
+    a.Equal( 2, n )
+    a.Equal( failErr, err )
+    a.Equal( failErr, cp.ProcessEvent( NewValueEvent( mg.Int32( 1 ) ) ) )
+    a.Equal( failErr, cp.Close() )
+    a.Truef( cnt <= 2, "downstream saw %d events", cnt )
+}
+
+func TestConcurrentPipelineCancel( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    ctx, cancel := context.WithCancel( context.Background() )
+    blocked := make( chan struct{} )
+    cp := InitConcurrentReactorPipeline( ctx,
+        NewStageBoundary( 0 ),
+        EventProcessorFunc( func( _ Event ) error {
+            <-blocked
+            return nil
+        }),
+    )
+    cancel()
+    _, err := feedUntilError( cp, 1000 )
+    a.Equal( context.Canceled, err )
+    close( blocked )
+    a.Equal( context.Canceled, cp.Close() )
+}
+
+func TestStageBoundaryInSerialPipeline( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    br := NewBuildReactor( ValueBuilderFactory )
+    pip := InitReactorPipeline( NewStageBoundary( 1 ), br )
+    val := concurrentTestValue()
+    if err := VisitValue( val, pip ); err != nil { a.Fatal( err ) }
+    mg.AssertEqualValues( val, br.GetValue().( mg.Value ), a )
+}
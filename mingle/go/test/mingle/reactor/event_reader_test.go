@@ -0,0 +1,128 @@
+package reactor
+
+import (
+    "testing"
+    "errors"
+    "io"
+    mg "mingle"
+    "mingle/parser"
+    "bitgirder/assert"
+)
+
+func eventReaderTestValue() *mg.Struct {
+    return parser.MustStruct( "ns1@v1/S1",
+        "f1", mg.Int32( 1 ),
+        "f2", mg.MustList( mg.Int32( 2 ), parser.MustSymbolMap( "k1", "v1" ) ),
+        "f3", parser.MustStruct( "ns1@v1/S2", "f1", "a" ),
+    )
+}
+
+// reads eventReaderTestValue() the way a hand-written parser would
+func TestEventReaderParse( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    id := parser.MustIdentifier
+    r := NewEventReader( ValueEventSource( eventReaderTestValue() ) )
+    defer r.Close()
+    qn := parser.MustQualifiedTypeName( "ns1@v1/S1" )
+    if _, err := r.ExpectStruct( qn ); err != nil { a.Fatal( err ) }
+    seen := []string{}
+    for {
+        fld, err := r.NextField()
+        if err != nil { a.Fatal( err ) }
+        if fld == nil { break }
+        seen = append( seen, fld.ExternalForm() )
+        switch {
+        case fld.Equals( id( "f1" ) ):
+            val, err := r.ReadValue()
+            if err != nil { a.Fatal( err ) }
+            a.Equal( mg.Int32( 1 ), val )
+        case fld.Equals( id( "f2" ) ):
+            if err := r.Skip(); err != nil { a.Fatal( err ) }
+        case fld.Equals( id( "f3" ) ):
+            if _, err := r.ExpectStruct( nil ); err != nil { a.Fatal( err ) }
+            if err := r.ExpectField( id( "f1" ) ); err != nil { a.Fatal( err ) }
+            ev, err := r.Peek()
+            if err != nil { a.Fatal( err ) }
+            a.Equal( "f3.f1", mg.FormatIdPath( ev.GetPath() ) )
+            val, err := r.ReadValue()
+            if err != nil { a.Fatal( err ) }
+            a.Equal( mg.String( "a" ), val )
+            if err := r.ExpectEnd(); err != nil { a.Fatal( err ) }
+        }
+    }
+    a.Equal( 3, len( seen ) )
+    _, err := r.Next()
+    a.Equal( io.EOF, err )
+}
+
+func TestEventReaderSendValue( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    val := eventReaderTestValue()
+    r := NewEventReader( ValueEventSource( val ) )
+    defer r.Close()
+    br := NewBuildReactor( ValueBuilderFactory )
+    if err := r.SendValue( br ); err != nil { a.Fatal( err ) }
+    mg.AssertEqualValues( val, br.GetValue().( mg.Value ), a )
+}
+
+func TestEventReaderSkipContainer( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    r := NewEventReader( ValueEventSource( eventReaderTestValue() ) )
+    defer r.Close()
+    if _, err := r.Next(); err != nil { a.Fatal( err ) }
+    if err := r.Skip(); err != nil { a.Fatal( err ) }
+    _, err := r.Next()
+    a.Equal( io.EOF, err )
+}
+
+func TestEventReaderErrors( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    r := NewEventReader( ValueEventSource( eventReaderTestValue() ) )
+    _, err := r.ExpectStruct( parser.MustQualifiedTypeName( "ns1@v1/S2" ) )
+    mg.AssertErrors(
+        NewReactorError( nil, 
+            "expected start of struct ns1@v1/S2 but saw start of struct " +
+            "ns1@v1/S1" ),
+        err, a,
+    )
+    if err := r.ExpectMap(); err == nil { a.Fatal( "expected error" ) }
+    r.Close()
+    r = NewEventReader( ValueEventSource( mg.Int32( 1 ) ) )
+    defer r.Close()
+    if _, err := r.Next(); err != nil { a.Fatal( err ) }
+    a.Equal( io.ErrUnexpectedEOF, r.ExpectEnd() )
+}
+
+func TestEventReaderSourceError( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    failErr := errors.New( "test-error" )
+    r := NewEventReader( func( rep EventProcessor ) error {
+        ev := NewListStartEvent( mg.TypeOpaqueList )
+        if err := rep.ProcessEvent( ev ); err != nil { return err }
+        return failErr
+    })
+    defer r.Close()
+    if _, err := r.ExpectList(); err != nil { a.Fatal( err ) }
+    _, err := r.Next()
+    a.Equal( failErr, err )
+    _, err = r.Next()
+    a.Equal( failErr, err )
+}
+
+// checks that closing a reader stops a source which would otherwise never end
+func TestEventReaderClose( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    srcErr := make( chan error, 1 )
+    r := NewEventReader( func( rep EventProcessor ) error {
+        err := rep.ProcessEvent( NewListStartEvent( mg.TypeOpaqueList ) )
+        for err == nil { err = rep.ProcessEvent( NewValueEvent( mg.NullVal ) ) }
+        srcErr <- err
+        return err
+    })
+    for i := 0; i < 3; i++ {
+        if _, err := r.Next(); err != nil { a.Fatal( err ) }
+    }
+    r.Close()
+    a.Truef( <-srcErr != nil, "source was not stopped" )
+    if _, err := r.Next(); err == nil { a.Fatal( "expected error" ) }
+}
@@ -0,0 +1,129 @@
+package reactor
+
+import (
+    "testing"
+    "errors"
+    mg "mingle"
+    "mingle/parser"
+    "bitgirder/assert"
+)
+
+func recorderTestValue() *mg.Struct {
+    return parser.MustStruct( "ns1@v1/S1",
+        "f1", mg.Int32( 1 ),
+        "f2", mg.MustList( mg.Int32( 1 ), mg.MustList( mg.String( "a" ) ) ),
+        "f3", parser.MustSymbolMap( "k1", mg.String( "v1" ) ),
+    )
+}
+
+func TestEventRecorderReplay( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    rec := NewEventRecorder()
+    val := recorderTestValue()
+    pip := InitReactorPipeline( NewPathSettingProcessor(), rec )
+    if err := VisitValue( val, pip ); err != nil { a.Fatal( err ) }
+    a.Equal( 16, rec.Len() )
+    for i := 0; i < 2; i++ {
+        br := NewBuildReactor( ValueBuilderFactory )
+        if err := rec.Replay( br ); err != nil { a.Fatal( err ) }
+        mg.AssertEqualValues( val, br.GetValue().( mg.Value ), a )
+    }
+    p := parser.MustIdentifierPath( "f2[ 1 ][ 0 ]" )
+    var seen bool
+    err := rec.Replay( EventProcessorFunc( func( ev Event ) error {
+        if ve, ok := ev.( *ValueEvent ); ok && ve.Val == mg.String( "a" ) {
+            seen = true
+            a.Equal( mg.FormatIdPath( p ), mg.FormatIdPath( ev.GetPath() ) )
+        }
+        return nil
+    }))
+    if err != nil { a.Fatal( err ) }
+    a.True( seen )
+    rec.Reset()
+    a.Equal( 0, rec.Len() )
+}
+
+func TestEventRecorderReplayError( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    rec := NewEventRecorder()
+    if err := VisitValue( recorderTestValue(), rec ); err != nil { 
+        a.Fatal( err ) 
+    }
+    failErr := errors.New( "test-error" )
+    cnt := 0
+    err := rec.Replay( EventProcessorFunc( func( ev Event ) error {
+        if cnt++; cnt == 3 { return failErr }
+        return nil
+    }))
+    a.Equal( failErr, err )
+    a.Equal( 3, cnt )
+}
+
+type teeTestBranch struct {
+    failAt int // 1-based index of event at which to fail, or 0
+    err error
+    br *BuildReactor
+    cnt int
+}
+
+func newTeeTestBranch( failAt int ) *teeTestBranch {
+    return &teeTestBranch{
+        failAt: failAt,
+        err: errors.New( "branch-error" ),
+        br: NewBuildReactor( ValueBuilderFactory ),
+    }
+}
+
+func ( b *teeTestBranch ) ProcessEvent( ev Event ) error {
+    if b.cnt++; b.cnt == b.failAt { return b.err }
+    return b.br.ProcessEvent( ev )
+}
+
+func TestTeeProcessorSendsToAllBranches( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    b1, b2 := newTeeTestBranch( 0 ), newTeeTestBranch( 0 )
+    // each branch sets its own paths
+    tee := NewTeeProcessor( TeeErrorPolicyFailFast,
+        InitReactorPipeline( NewPathSettingProcessor(), b1 ),
+        InitReactorPipeline( NewPathSettingProcessorPath(
+            parser.MustIdentifierPath( "root" ) ), b2 ),
+    )
+    val := recorderTestValue()
+    if err := VisitValue( val, tee ); err != nil { a.Fatal( err ) }
+    mg.AssertEqualValues( val, b1.br.GetValue().( mg.Value ), a )
+    mg.AssertEqualValues( val, b2.br.GetValue().( mg.Value ), a )
+    a.Equal( []error{ nil, nil }, tee.BranchErrors() )
+}
+
+func TestTeeProcessorFailFast( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    b1, b2 := newTeeTestBranch( 0 ), newTeeTestBranch( 2 )
+    tee := NewTeeProcessor( TeeErrorPolicyFailFast, b1, b2 )
+    a.Equal( b2.err, VisitValue( recorderTestValue(), tee ) )
+    a.Equal( b2.err, tee.ProcessEvent( NewEndEvent() ) )
+    a.Equal( 2, b1.cnt )
+    a.Equal( 2, b2.cnt )
+    a.Equal( []error{ nil, b2.err }, tee.BranchErrors() )
+}
+
+func TestTeeProcessorIsolate( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    b1, b2, b3 := newTeeTestBranch( 3 ), newTeeTestBranch( 0 ), 
+        newTeeTestBranch( 5 )
+    tee := NewTeeProcessor( TeeErrorPolicyIsolate, b1, b2, b3 )
+    val := recorderTestValue()
+    if err := VisitValue( val, tee ); err != nil { a.Fatal( err ) }
+    mg.AssertEqualValues( val, b2.br.GetValue().( mg.Value ), a )
+    a.Equal( 3, b1.cnt )
+    a.Equal( 5, b3.cnt )
+    a.Equal( []error{ b1.err, nil, b3.err }, tee.BranchErrors() )
+}
+
+func TestTeeProcessorIsolateAllFailed( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    b1, b2 := newTeeTestBranch( 3 ), newTeeTestBranch( 2 )
+    tee := NewTeeProcessor( TeeErrorPolicyIsolate, b1, b2 )
+    a.Equal( b2.err, VisitValue( recorderTestValue(), tee ) )
+    a.Equal( 3, b1.cnt )
+    a.Equal( 2, b2.cnt )
+}
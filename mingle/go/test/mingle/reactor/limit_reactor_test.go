@@ -0,0 +1,31 @@
+package reactor
+
+import (
+    "testing"
+    mg "mingle"
+    "mingle/parser"
+    "bitgirder/assert"
+)
+
+// fed as events rather than visited from a value so that the field at which
+// the limit is exceeded is known
+func TestLimitReactorFieldCount( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    pip := InitReactorPipeline( NewLimitReactor( Limits{ MaxFields: 1 } ) )
+    evs := []Event{
+        NewStructStartEvent( parser.MustQualifiedTypeName( "ns1@v1/S1" ) ),
+        NewFieldStartEvent( parser.MustIdentifier( "f1" ) ),
+        NewMapStartEvent(),
+        NewFieldStartEvent( parser.MustIdentifier( "k1" ) ),
+        NewValueEvent( mg.Int32( 1 ) ),
+        NewEndEvent(),
+        NewFieldStartEvent( parser.MustIdentifier( "f2" ) ),
+    }
+    var err error
+    for _, ev := range evs {
+        if err = pip.ProcessEvent( ev ); err != nil { break }
+    }
+    expct := NewReactorError( 
+        parser.MustIdentifierPath( "f2" ), "field count exceeds limit of 1" )
+    mg.AssertErrors( expct, err, a )
+}
@@ -0,0 +1,105 @@
+package reactor
+
+import (
+    "testing"
+    "context"
+    "errors"
+    mg "mingle"
+    "mingle/parser"
+    "bitgirder/assert"
+    "bitgirder/objpath"
+)
+
+type listElementCollector struct {
+    paths []string
+    vals []mg.Value
+}
+
+func ( c *listElementCollector ) handle(
+    path objpath.PathNode, val interface{} ) error {
+
+    c.paths = append( c.paths, mg.FormatIdPath( path ) )
+    c.vals = append( c.vals, val.( mg.Value ) )
+    return nil
+}
+
+func TestListElementReactorTopLevel( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    elts := []mg.Value{
+        parser.MustStruct( "ns1@v1/S1", "f1", mg.Int32( 1 ) ),
+        mg.Int32( 2 ),
+        mg.MustList( mg.Int32( 3 ), mg.MustList() ),
+    }
+    c := &listElementCollector{}
+    br := NewBuildReactor( ValueBuilderFactory )
+    pip := InitReactorPipeline( 
+        NewListElementReactor( nil, ValueBuilderFactory, c.handle ), br )
+    if err := VisitValue( mg.NewListValues( elts ), pip ); err != nil { 
+        a.Fatal( err ) 
+    }
+    a.Equal( []string{ "[ 0 ]", "[ 1 ]", "[ 2 ]" }, c.paths )
+    la := a.StartList()
+    for i, elt := range elts {
+        mg.AssertEqualValues( elt, c.vals[ i ], la )
+        la = la.Next()
+    }
+    mg.AssertEqualValues( mg.MustList(), br.GetValue().( mg.Value ), a )
+}
+
+func TestListElementReactorNested( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    s1 := func( pairs ...interface{} ) *mg.Struct {
+        return parser.MustStruct( "ns1@v1/S1", pairs... )
+    }
+    src := mg.MustList(
+        s1( "f1", mg.MustList( mg.Int32( 1 ), mg.Int32( 2 ) ) ),
+        s1( "f1", mg.MustList( s1( "f1", mg.MustList( mg.Int32( 3 ) ) ) ) ),
+        s1( "f2", mg.MustList( mg.Int32( 4 ) ) ),
+    )
+    c := &listElementCollector{}
+    br := NewBuildReactor( ValueBuilderFactory )
+    path := parser.MustIdentifierPathPattern( "[ * ].f1" )
+    pip := InitReactorPipeline( 
+        NewListElementReactor( path, ValueBuilderFactory, c.handle ), br )
+    if err := VisitValue( src, pip ); err != nil { a.Fatal( err ) }
+    expctPaths := []string{ "[ 0 ].f1[ 0 ]", "[ 0 ].f1[ 1 ]", "[ 1 ].f1[ 0 ]" }
+    a.Equal( expctPaths, c.paths )
+    expct := mg.MustList(
+        s1( "f1", mg.MustList() ),
+        s1( "f1", mg.MustList() ),
+        s1( "f2", mg.MustList( mg.Int32( 4 ) ) ),
+    )
+    mg.AssertEqualValues( expct, br.GetValue().( mg.Value ), a )
+}
+
+func TestListElementReactorHandlerError( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    failErr := errors.New( "test-error" )
+    cnt := 0
+    h := func( _ objpath.PathNode, _ interface{} ) error {
+        cnt++
+        if cnt == 2 { return failErr }
+        return nil
+    }
+    pip := InitReactorPipeline( 
+        NewListElementReactor( nil, ValueBuilderFactory, h ) )
+    src := mg.MustList( mg.Int32( 1 ), mg.Int32( 2 ), mg.Int32( 3 ) )
+    a.Equal( failErr, VisitValue( src, pip ) )
+    a.Equal( 2, cnt )
+}
+
+func TestListElementChannel( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    ch := make( chan interface{} )
+    ctx, cancel := context.WithCancel( context.Background() )
+    h := ListElementChannel( ctx, ch )
+    pip := InitReactorPipeline( 
+        NewListElementReactor( nil, ValueBuilderFactory, h ) )
+    src := mg.MustList( mg.Int32( 1 ), mg.Int32( 2 ), mg.Int32( 3 ) )
+    errs := make( chan error, 1 )
+    go func() { errs <- VisitValue( src, pip ) }()
+    a.Equal( mg.Int32( 1 ), <-ch )
+    a.Equal( mg.Int32( 2 ), <-ch )
+    cancel()
+    a.Equal( context.Canceled, <-errs )
+}
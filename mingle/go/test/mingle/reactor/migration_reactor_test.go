@@ -0,0 +1,36 @@
+package reactor
+
+import (
+    "testing"
+    mg "mingle"
+    "mingle/parser"
+    "bitgirder/assert"
+)
+
+// value comparison does not check list types, so they are checked here
+func TestMigrationReactorListTypes( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    m := NewMigration()
+    m.PutStruct( parser.MustQualifiedTypeName( "ns1@v1/S1" ),
+        &StructMigration{ Type: parser.MustQualifiedTypeName( "ns1@v2/S1" ) } )
+    act := []string{}
+    rec := EventProcessorFunc( func( ev Event ) error {
+        if ls, ok := ev.( *ListStartEvent ); ok {
+            act = append( act, ls.Type.ExternalForm() )
+        }
+        return nil
+    })
+    for _, s := range []string{ 
+        "ns1@v1/S1*", "&ns1@v1/S1?+", "ns1@v1/S1**", "ns1@v1/S2*",
+    } {
+        typ := parser.MustTypeReference( s ).( *mg.ListTypeReference )
+        pip := InitReactorPipeline( NewMigrationReactor( m ), rec )
+        if err := VisitValue( mg.NewList( typ ), pip ); err != nil {
+            a.Fatal( err )
+        }
+    }
+    expct := []string{ 
+        "ns1@v2/S1*", "&(ns1@v2/S1)?+", "ns1@v2/S1**", "ns1@v1/S2*",
+    }
+    a.Equal( expct, act )
+}
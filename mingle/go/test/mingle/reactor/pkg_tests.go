@@ -73,6 +73,31 @@ type FieldOrderPathTest struct {
     Orders []FieldOrderReactorTestOrder
 }
 
+type FieldMaskReactorTest struct {
+    Source mg.Value
+    Paths []objpath.PathNode
+    Expect mg.Value
+}
+
+type LimitReactorTest struct {
+    Source mg.Value
+    Limits Limits
+    Error *ReactorError // if nil Source should pass through unchanged
+}
+
+type MigrationReactorTest struct {
+    Source mg.Value
+    Migration *Migration
+    Expect mg.Value
+    Error *ReactorError
+}
+
+type RedactionReactorTest struct {
+    Source mg.Value
+    Policy *RedactionPolicy
+    Expect mg.Value
+}
+
 type DepthTrackerTest struct {
     Source []Event
     Expect []int
@@ -474,3 +474,40 @@ func ( t *DepthTrackerTest ) Call( c *ReactorTestCall ) {
     AssertFeedSource( t.Source, pip, c )
     chk.complete()
 }
+
+func ( t *FieldMaskReactorTest ) Call( c *ReactorTestCall ) {
+    br := NewBuildReactor( ValueBuilderFactory )
+    pip := InitReactorPipeline( NewFieldMaskReactor( t.Paths ), br )
+    if err := VisitValue( t.Source, pip ); err != nil { c.Fatal( err ) }
+    mg.AssertEqualValues( t.Expect, br.GetValue().( mg.Value ), c.PathAsserter )
+}
+
+func ( t *MigrationReactorTest ) Call( c *ReactorTestCall ) {
+    br := NewBuildReactor( ValueBuilderFactory )
+    pip := InitReactorPipeline( NewMigrationReactor( t.Migration ), br )
+    err := VisitValue( t.Source, pip )
+    if t.Error != nil {
+        mg.AssertErrors( t.Error, err, c.PathAsserter )
+        return
+    }
+    if err != nil { c.Fatal( err ) }
+    mg.AssertEqualValues( t.Expect, br.GetValue().( mg.Value ), c.PathAsserter )
+}
+
+func ( t *RedactionReactorTest ) Call( c *ReactorTestCall ) {
+    act, err := RedactValue( t.Source, t.Policy )
+    if err != nil { c.Fatal( err ) }
+    mg.AssertEqualValues( t.Expect, act, c.PathAsserter )
+}
+
+func ( t *LimitReactorTest ) Call( c *ReactorTestCall ) {
+    br := NewBuildReactor( ValueBuilderFactory )
+    pip := InitReactorPipeline( NewLimitReactor( t.Limits ), br )
+    err := VisitValue( t.Source, pip )
+    if t.Error != nil {
+        mg.AssertErrors( t.Error, err, c.PathAsserter )
+        return
+    }
+    if err != nil { c.Fatal( err ) }
+    mg.AssertEqualValues( t.Source, br.GetValue().( mg.Value ), c.PathAsserter )
+}
@@ -90,7 +90,7 @@ func initBuildReactorValueTests( b *ReactorTestSliceBuilder ) {
     addTest(
         &BuildReactorTest{
             Source: []Event{
-                nextListStart( listTypeRef( `String~"a*"*"` ) ),
+                nextListStart( listTypeRef( `String~"a*"*` ) ),
                     NewValueEvent( mg.String( "a" ) ),
                 NewEndEvent(),
             },
@@ -1026,6 +1026,322 @@ func initDepthTrackerTests( b *ReactorTestSliceBuilder ) {
     )
 }
 
+func initFieldMaskReactorTests( b *ReactorTestSliceBuilder ) {
+    i := func( v int ) mg.Int32 { return mg.Int32( v ) }
+    s2 := func( pairs ...interface{} ) *mg.Struct {
+        return parser.MustStruct( "ns1@v1/S2", pairs... )
+    }
+    s1 := func( pairs ...interface{} ) *mg.Struct {
+        return parser.MustStruct( "ns1@v1/S1", pairs... )
+    }
+    src := s1(
+        "f1", i( 1 ),
+        "f2", s2( "f1", i( 1 ), "f2", i( 2 ) ),
+        "f3", mg.MustList( 
+            s2( "f1", i( 1 ), "f2", i( 2 ) ),
+            s2( "f1", i( 3 ), "f2", i( 4 ) ),
+        ),
+        "f4", parser.MustSymbolMap(
+            "k1", parser.MustSymbolMap( "f1", i( 1 ), "f2", i( 2 ) ),
+            "k2", parser.MustSymbolMap( "f1", i( 3 ) ),
+        ),
+        "f5", mg.NullVal,
+        "f6", mg.MustList( 
+            mg.MustList( i( 1 ), i( 2 ) ), 
+            mg.MustList( i( 3 ), i( 4 ) ),
+        ),
+    )
+    add := func( src, expct mg.Value, paths ...string ) {
+        t := &FieldMaskReactorTest{ Source: src, Expect: expct }
+        for _, p := range paths {
+            t.Paths = append( t.Paths, parser.MustIdentifierPathPattern( p ) )
+        }
+        b.AddTests( t )
+    }
+    add( src, s1() )
+    add( src, s1( "f1", i( 1 ) ), "f1" )
+    add( src, s1( "f1", i( 1 ) ), "f1.f2" )
+    add( src, src, "*" )
+    add( src, s1( "f2", s2( "f1", i( 1 ), "f2", i( 2 ) ) ), "f2" )
+    add( src, s1( "f2", s2( "f2", i( 2 ) ) ), "f2.f2" )
+    add( src, s1( "f1", i( 1 ), "f2", s2( "f1", i( 1 ) ) ), "f1", "f2.f1" )
+    add( src, s1( "f2", s2( "f1", i( 1 ) ) ), "f2.f1", "f2.f3" )
+    add( src, s1( "f3", mg.MustList( s2( "f1", i( 3 ), "f2", i( 4 ) ) ) ),
+        "f3[ 1 ]" )
+    add( src, s1( "f3", mg.MustList() ), "f3[ 2 ]" )
+    add( 
+        src, 
+        s1( "f3", mg.MustList( s2( "f1", i( 1 ) ), s2( "f1", i( 3 ) ) ) ),
+        "f3[ * ].f1",
+    )
+    add(
+        src,
+        s1( 
+            "f4", parser.MustSymbolMap( 
+                "k1", parser.MustSymbolMap( "f1", i( 1 ) ),
+                "k2", parser.MustSymbolMap( "f1", i( 3 ) ),
+            ),
+        ),
+        "f4.*.f1",
+    )
+    add(
+        src,
+        s1( 
+            "f4", parser.MustSymbolMap( 
+                "k1", parser.MustSymbolMap( "f2", i( 2 ) ),
+                "k2", parser.MustSymbolMap(),
+            ),
+        ),
+        "f4.*.f2",
+    )
+    add( src, s1( "f5", mg.NullVal ), "f5.f1" )
+    add( 
+        src, 
+        s1( "f6", mg.MustList( mg.MustList( i( 2 ) ), mg.MustList( i( 4 ) ) ) ),
+        "f6[ * ][ 1 ]",
+    )
+    lst := mg.MustList( 
+        s2( "f1", i( 1 ), "f2", i( 2 ) ), 
+        s2( "f1", i( 3 ), "f2", i( 4 ) ),
+    )
+    add( lst, mg.MustList( s2( "f1", i( 1 ), "f2", i( 2 ) ) ), "[ 0 ]" )
+    add( lst, mg.MustList( s2( "f2", i( 2 ) ), s2( "f2", i( 4 ) ) ), "*.f2" )
+    add( i( 1 ), i( 1 ), "f1" )
+}
+
+func initMigrationReactorTests( b *ReactorTestSliceBuilder ) {
+    i := func( v int ) mg.Int32 { return mg.Int32( v ) }
+    id := parser.MustIdentifier
+    qn := parser.MustQualifiedTypeName
+    s1 := func( pairs ...interface{} ) *mg.Struct {
+        return parser.MustStruct( "ns1@v1/S1", pairs... )
+    }
+    s2 := func( pairs ...interface{} ) *mg.Struct {
+        return parser.MustStruct( "ns1@v2/S1", pairs... )
+    }
+    typedList := func( typ string, vals ...mg.Value ) *mg.List {
+        lt := parser.MustTypeReference( typ ).( *mg.ListTypeReference )
+        res := mg.NewList( lt )
+        for _, v := range vals { res.AddUnsafe( v ) }
+        return res
+    }
+    renames := func( pairs ...string ) *mg.IdentifierMap {
+        res := mg.NewIdentifierMap()
+        for j := 0; j < len( pairs ); j += 2 {
+            res.Put( id( pairs[ j ] ), id( pairs[ j + 1 ] ) )
+        }
+        return res
+    }
+    m := NewMigration()
+    m.PutStruct( qn( "ns1@v1/S1" ), &StructMigration{
+        Type: qn( "ns1@v2/S1" ),
+        RenameFields: renames( "f1", "g1", "f2", "g2" ),
+        DropFields: []*mg.Identifier{ id( "f3" ) },
+        MoveFields: []*FieldMove{
+            {
+                Field: id( "f4" ),
+                Into: id( "g5" ),
+                IntoType: qn( "ns1@v2/S2" ),
+            },
+            {
+                Field: id( "f5" ),
+                Into: id( "g5" ),
+                IntoType: qn( "ns1@v2/S2" ),
+                As: id( "h2" ),
+            },
+        },
+        Defaults: []*FieldDefault{
+            { Field: id( "g2" ), Value: i( 0 ) },
+            { Field: id( "g6" ), Value: mg.String( "x" ) },
+        },
+    })
+    m.PutEnum( qn( "ns1@v1/E1" ), &EnumMigration{
+        Type: qn( "ns1@v2/E1" ),
+        Values: renames( "red", "crimson" ),
+    })
+    add := func( src, expct mg.Value ) {
+        b.AddTests(
+            &MigrationReactorTest{ Source: src, Migration: m, Expect: expct } )
+    }
+    addErr := func( src mg.Value, err *ReactorError ) {
+        b.AddTests(
+            &MigrationReactorTest{ Source: src, Migration: m, Error: err } )
+    }
+    add( i( 1 ), i( 1 ) )
+    add( s1(), s2( "g2", i( 0 ), "g6", "x" ) )
+    add(
+        s1( "f1", i( 1 ), "f2", i( 2 ), "f3", i( 3 ) ),
+        s2( "g1", i( 1 ), "g2", i( 2 ), "g6", "x" ),
+    )
+    add(
+        s1( "f4", i( 4 ), "f5", s1( "f1", i( 1 ) ), "g6", "y" ),
+        s2(
+            "g6", "y",
+            "g5", parser.MustStruct( "ns1@v2/S2",
+                "f4", i( 4 ),
+                "h2", s2( "g1", i( 1 ), "g2", i( 0 ), "g6", "x" ),
+            ),
+            "g2", i( 0 ),
+        ),
+    )
+    add(
+        parser.MustStruct( "ns1@v1/S3",
+            "f1", parser.MustSymbolMap( "k1", s1( "f3", i( 3 ) ) ),
+            "f2", typedList( "ns1@v1/E1*",
+                parser.MustEnum( "ns1@v1/E1", "red" ),
+                parser.MustEnum( "ns1@v1/E1", "green" ),
+            ),
+            "f3", typedList( "&ns1@v1/S1?*", mg.NullVal ),
+        ),
+        parser.MustStruct( "ns1@v1/S3",
+            "f1", parser.MustSymbolMap(
+                "k1", s2( "g2", i( 0 ), "g6", "x" ) ),
+            "f2", typedList( "ns1@v2/E1*",
+                parser.MustEnum( "ns1@v2/E1", "crimson" ),
+                parser.MustEnum( "ns1@v2/E1", "green" ),
+            ),
+            "f3", typedList( "&ns1@v2/S1?*", mg.NullVal ),
+        ),
+    )
+    p := parser.MustIdentifierPath
+    addErr(
+        s1( "f1", i( 1 ), "g1", i( 2 ) ),
+        NewReactorError( nil, "field g1 is already present in migrated value" ),
+    )
+    addErr(
+        s1( "g5", i( 1 ), "f4", i( 2 ) ),
+        NewReactorError( nil, "field g5 is already present in migrated value" ),
+    )
+    addErr(
+        parser.MustSymbolMap( "k1", s1( "g5", i( 1 ), "f5", i( 2 ) ) ),
+        NewReactorError(
+            p( "k1" ), "field g5 is already present in migrated value" ),
+    )
+}
+
+func initRedactionReactorTests( b *ReactorTestSliceBuilder ) {
+    i := func( v int ) mg.Int32 { return mg.Int32( v ) }
+    id := parser.MustIdentifier
+    qn := parser.MustQualifiedTypeName
+    s1 := func( pairs ...interface{} ) *mg.Struct {
+        return parser.MustStruct( "ns1@v1/S1", pairs... )
+    }
+    s2 := func( pairs ...interface{} ) *mg.Struct {
+        return parser.MustStruct( "ns1@v1/S2", pairs... )
+    }
+    red := DefaultRedactionPlaceholder
+    byField := NewRedactionPolicy()
+    byField.AddField( qn( "ns1@v1/S1" ), id( "f1" ) )
+    byField.AddField( qn( "ns1@v1/S1" ), id( "f2" ) )
+    byPath := NewRedactionPolicy()
+    byPath.Placeholder = mg.NullVal
+    byPath.AddPath( parser.MustIdentifierPathPattern( "f1.f2" ) )
+    byPath.AddPath( parser.MustIdentifierPathPattern( "f3[ * ].k1" ) )
+    byPath.AddPath( parser.MustIdentifierPathPattern( "f4[ 1 ]" ) )
+    add := func( p *RedactionPolicy, src, expct mg.Value ) {
+        b.AddTests( 
+            &RedactionReactorTest{ Source: src, Policy: p, Expect: expct } )
+    }
+    add( byField, i( 1 ), i( 1 ) )
+    add( byField, 
+        s1( "f1", "secret", "f2", s2( "f1", i( 1 ) ), "f3", i( 3 ) ),
+        s1( "f1", red, "f2", red, "f3", i( 3 ) ),
+    )
+    add( byField,
+        s2(
+            "f1", "visible",
+            "f2", mg.MustList( s1( "f1", mg.MustList( i( 1 ) ) ), i( 2 ) ),
+            "f3", parser.MustSymbolMap( "f1", i( 1 ) ),
+        ),
+        s2(
+            "f1", "visible",
+            "f2", mg.MustList( s1( "f1", red ), i( 2 ) ),
+            "f3", parser.MustSymbolMap( "f1", i( 1 ) ),
+        ),
+    )
+    add( byPath,
+        s1(
+            "f1", s2( "f1", i( 1 ), "f2", "secret" ),
+            "f2", s2( "f2", "visible" ),
+            "f3", mg.MustList(
+                parser.MustSymbolMap( "k1", "secret", "k2", i( 1 ) ),
+                parser.MustSymbolMap( "k1", mg.MustList( i( 1 ) ) ),
+            ),
+            "f4", mg.MustList( i( 1 ), s2( "f1", i( 2 ) ), i( 3 ) ),
+        ),
+        s1(
+            "f1", s2( "f1", i( 1 ), "f2", mg.NullVal ),
+            "f2", s2( "f2", "visible" ),
+            "f3", mg.MustList(
+                parser.MustSymbolMap( "k1", mg.NullVal, "k2", i( 1 ) ),
+                parser.MustSymbolMap( "k1", mg.NullVal ),
+            ),
+            "f4", mg.MustList( i( 1 ), mg.NullVal, i( 3 ) ),
+        ),
+    )
+}
+
+func initLimitReactorTests( b *ReactorTestSliceBuilder ) {
+    i := func( v int ) mg.Int32 { return mg.Int32( v ) }
+    s1 := func( pairs ...interface{} ) *mg.Struct {
+        return parser.MustStruct( "ns1@v1/S1", pairs... )
+    }
+    src := s1(
+        "f1", mg.String( "abc" ),
+        "f2", mg.Buffer( []byte{ 0, 1, 2, 3 } ),
+        "f3", mg.MustList( i( 1 ), i( 2 ), mg.MustList( i( 3 ) ) ),
+        "f4", parser.MustSymbolMap( "k1", s1( "f1", i( 1 ) ) ),
+    )
+    p := parser.MustIdentifierPath
+    add := func( l Limits, err *ReactorError ) {
+        b.AddTests( &LimitReactorTest{ Source: src, Limits: l, Error: err } )
+    }
+    add( Limits{}, nil )
+    add( 
+        Limits{
+            MaxDepth: 3,
+            MaxListLength: 3,
+            MaxFields: 4,
+            MaxStringBytes: 3,
+            MaxBufferBytes: 4,
+            MaxEvents: 22,
+        }, 
+        nil,
+    )
+    add( Limits{ MaxListLength: 2 },
+        NewReactorError( p( "f3[ 2 ]" ), "list length exceeds limit of 2" ) )
+    add( Limits{ MaxStringBytes: 2 },
+        NewReactorError( p( "f1" ), "string of 3 bytes exceeds limit of 2" ) )
+    add( Limits{ MaxBufferBytes: 3 },
+        NewReactorError( p( "f2" ), "buffer of 4 bytes exceeds limit of 3" ) )
+    add( Limits{ MaxEvents: 21 },
+        NewReactorError( nil, "event count exceeds limit of 21" ) )
+    b.AddTests(
+        &LimitReactorTest{
+            Source: mg.MustList( mg.MustList( mg.MustList() ) ),
+            Limits: Limits{ MaxDepth: 2 },
+            Error: NewReactorError( 
+                objpath.RootedAtList().StartList(), 
+                "nesting depth exceeds limit of 2",
+            ),
+        },
+        // f1 is the only field of depth greater than 2, since the order in
+        // which fields are visited is unspecified
+        &LimitReactorTest{
+            Source: s1( "f1", mg.MustList( mg.MustList() ), "f2", i( 1 ) ),
+            Limits: Limits{ MaxDepth: 2 },
+            Error: NewReactorError( 
+                p( "f1[ 0 ]" ), "nesting depth exceeds limit of 2" ),
+        },
+        &LimitReactorTest{
+            Source: mg.String( "abcd" ),
+            Limits: Limits{ MaxStringBytes: 3 },
+            Error: 
+                NewReactorError( nil, "string of 4 bytes exceeds limit of 3" ),
+        },
+    )
+}
+
 func GetReactorTests() []ReactorTest {
     b := NewReactorTestSliceBuilder()
     initStructuralReactorTests( b )
@@ -1033,5 +1349,9 @@ func GetReactorTests() []ReactorTest {
     initEventPathTests( b )
     initFieldOrderReactorTests( b )
     initDepthTrackerTests( b )
+    initFieldMaskReactorTests( b )
+    initLimitReactorTests( b )
+    initMigrationReactorTests( b )
+    initRedactionReactorTests( b )
     return b.GetTests()
 }
@@ -0,0 +1,37 @@
+package reactor
+
+import (
+    "testing"
+    mg "mingle"
+    "mingle/parser"
+    "bitgirder/assert"
+    "strings"
+)
+
+func redactHashed( 
+    val mg.Value, key []byte, a *assert.PathAsserter ) mg.String {
+
+    p := NewRedactionPolicy()
+    p.Mode = RedactionModeHash
+    p.HashKey = key
+    p.AddPath( parser.MustIdentifierPathPattern( "f1" ) )
+    act, err := RedactValue( parser.MustStruct( "ns1@v1/S1", "f1", val ), p )
+    if err != nil { a.Fatal( err ) }
+    res := act.( *mg.Struct ).Fields.Get( parser.MustIdentifier( "f1" ) )
+    return res.( mg.String )
+}
+
+func TestRedactionHash( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    m1 := parser.MustSymbolMap( "k1", "v1", "k2", mg.Int32( 1 ) )
+    h1 := redactHashed( m1, nil, a )
+    a.Truef( strings.HasPrefix( string( h1 ), "sha256:" ), "bad hash: %s", h1 )
+    m2 := parser.MustSymbolMap( "k2", mg.Int32( 1 ), "k1", "v1" )
+    a.Equal( h1, redactHashed( m2, nil, a ) )
+    m3 := parser.MustSymbolMap( "k1", "v1", "k2", mg.Int64( 1 ) )
+    a.Truef( h1 != redactHashed( m3, nil, a ), "types not distinguished" )
+    hKey := redactHashed( m1, []byte( "key1" ), a )
+    a.Truef( h1 != hKey, "key not used" )
+    a.Equal( hKey, redactHashed( m2, []byte( "key1" ), a ) )
+    a.Truef( hKey != redactHashed( m1, []byte( "key2" ), a ), "key ignored" )
+}
@@ -14,6 +14,18 @@ func MustTimestamp( s string ) Timestamp {
     return Timestamp( tm )
 }
 
+func MustBigInt( s string ) BigInt {
+    val, err := ParseNumber( s, QnameBigInt )
+    if err != nil { panic( err ) }
+    return val.( BigInt )
+}
+
+func MustDecimal( s string ) Decimal {
+    val, err := ParseNumber( s, QnameDecimal )
+    if err != nil { panic( err ) }
+    return val.( Decimal )
+}
+
 func mkId( parts ...string ) *Identifier { return NewIdentifierUnsafe( parts ) }
 
 func mkNs( ids ...*Identifier ) *Namespace {
@@ -421,3 +433,12 @@ func MustRangeRestriction(
     if err == nil { return res }
     panic( err )
 }
+
+func MustLengthRestriction(
+    minClosed bool, min, max Value, maxClosed bool ) *LengthRestriction {
+
+    lb := &LengthRestrictionBuilder{ minClosed, min, max, maxClosed }
+    res, err := lb.Build()
+    if err == nil { return res }
+    panic( err )
+}
@@ -38,6 +38,14 @@ func MakeFieldDef(
     return res
 }
 
+func MakeAnnotation( nm string, args ...interface{} ) *Annotation {
+    res := &Annotation{ Name: mkId( nm ) }
+    if len( args ) == 0 { return res }
+    res.Arguments = make( []mg.Value, len( args ) )
+    for i, arg := range args { res.Arguments[ i ] = mg.MustValue( arg ) }
+    return res
+}
+
 func buildFieldSet( fs *FieldSet, flds []*FieldDefinition ) {
     for _, fld := range flds { fs.MustAdd( fld ) }
 }
@@ -158,6 +166,9 @@ func ( a *DefAsserter ) assertAliasDef(
     a2 := a.equalType( a1, d2 ).( *AliasedTypeDefinition )
     a.Descend( "Name" ).Equal( a1.Name, a2.Name )
     a.Descend( "AliasedType" ).Equal( a1.AliasedType, a2.AliasedType )
+    a.descend( "(Annotations)" ).assertAnnotations( 
+        a1.Annotations, a2.Annotations )
+    a.descend( "(Doc)" ).Equal( a1.Doc, a2.Doc )
 }
 
 func asCompStr( ids []*mg.Identifier ) string {
@@ -173,10 +184,34 @@ func ( a *DefAsserter ) assertIdSets( ids1, ids2 []*mg.Identifier ) {
     }
 }
 
+func ( a *DefAsserter ) assertAnnotation( a1, a2 *Annotation ) {
+    a.descend( "(Name)" ).Equal( a1.Name, a2.Name )
+    args1, args2 := a1.Arguments, a2.Arguments
+    aa := a.descend( "(Arguments)" )
+    aa.descend( "(Len)" ).Equal( len( args1 ), len( args2 ) )
+    la := aa.startList()
+    for i, arg := range args1 {
+        mg.AssertEqualValues( arg, args2[ i ], la )
+        la = la.next()
+    }
+}
+
+func ( a *DefAsserter ) assertAnnotations( annots1, annots2 []*Annotation ) {
+    a.descend( "(Len)" ).Equal( len( annots1 ), len( annots2 ) )
+    la := a.startList()
+    for i, a1 := range annots1 {
+        la.assertAnnotation( a1, annots2[ i ] )
+        la = la.next()
+    }
+}
+
 func ( a *DefAsserter ) assertFieldDef( fd1, fd2 *FieldDefinition ) {
     a.descend( "(Name)" ).Equal( fd1.Name, fd2.Name )
     a.descend( "(Type)" ).equalTypeRef( fd1.Type, fd2.Type )
     mg.AssertEqualValues( fd1.Default, fd2.Default, a.descend( "(Default)" ) )
+    a.descend( "(Annotations)" ).
+        assertAnnotations( fd1.Annotations, fd2.Annotations )
+    a.descend( "(Doc)" ).Equal( fd1.Doc, fd2.Doc )
 }
 
 // First check that both have same field sets, then check field by field
@@ -199,12 +234,16 @@ func ( a *DefAsserter ) assertUnionType( ut1, ut2 *UnionTypeDefinition ) {
         la.descend( "(Type)" ).Equal( typs1[ i ], typs2[ i ] )
         la = la.next()
     }
+    a.descend( "(Tags)" ).Equal( ut1.Tags, ut2.Tags )
 }
 
 func ( a *DefAsserter ) assertUnionDef( ud1 *UnionDefinition, d2 Definition ) {
     ud2 := a.equalType( ud1, d2 ).( *UnionDefinition )
     a.descend( "(Name)" ).Equal( ud1.Name, ud2.Name )
     a.descend( "(Union)" ).assertUnionType( ud1.Union, ud2.Union )
+    a.descend( "(Annotations)" ).
+        assertAnnotations( ud1.Annotations, ud2.Annotations )
+    a.descend( "(Doc)" ).Equal( ud1.Doc, ud2.Doc )
 }
 
 func ( a *DefAsserter ) assertStructDef(
@@ -214,17 +253,42 @@ func ( a *DefAsserter ) assertStructDef(
     a.descend( "(Fields)" ).assertFieldSets( s1.Fields, s2.Fields )
     a.descend( "(Constructors)" ).
         assertUnionType( s1.Constructors, s2.Constructors )
+    a.descend( "(Reserved)" ).Equal( s1.Reserved, s2.Reserved )
+    a.descend( "(Annotations)" ).
+        assertAnnotations( s1.Annotations, s2.Annotations )
+    a.descend( "(Doc)" ).Equal( s1.Doc, s2.Doc )
 }
 
 func ( a *DefAsserter ) assertSchemaDef( s1 *SchemaDefinition, d2 Definition ) {
     s2 := a.equalType( s1, d2 ).( *SchemaDefinition )
     a.descend( "(Fields)" ).assertFieldSets( s1.Fields, s2.Fields )
+    a.descend( "(Annotations)" ).
+        assertAnnotations( s1.Annotations, s2.Annotations )
+    a.descend( "(Doc)" ).Equal( s1.Doc, s2.Doc )
 }
 
 func ( a *DefAsserter ) assertEnumDef( 
     e1 *EnumDefinition, v2 interface{} ) {
     e2 := a.equalType( e1, v2 ).( *EnumDefinition )
     a.descend( "(Values)" ).assertIdSets( e1.Values, e2.Values )
+    a.descend( "(Annotations)" ).
+        assertAnnotations( e1.Annotations, e2.Annotations )
+    a.descend( "(Doc)" ).Equal( e1.Doc, e2.Doc )
+    for _, val := range e1.Values {
+        a.descend( "(ValueAnnotations)" ).descend( val ).assertAnnotations(
+            e1.GetValueAnnotations( val ), e2.GetValueAnnotations( val ) )
+        a.descend( "(ValueDoc)" ).descend( val ).Equal(
+            e1.GetValueDoc( val ), e2.GetValueDoc( val ) )
+        ord1, ok1 := e1.GetOrdinal( val )
+        ord2, ok2 := e2.GetOrdinal( val )
+        a.descend( "(Ordinals)" ).descend( val ).Equal( ok1, ok2 )
+        a.descend( "(Ordinals)" ).descend( val ).Equal( ord1, ord2 )
+    }
+    a.descend( "(Aliases)" ).Equal( len( e1.Aliases ), len( e2.Aliases ) )
+    for _, al := range e1.Aliases {
+        a.descend( "(Aliases)" ).descend( al.Alias ).Equal( 
+            e1.ResolveValue( al.Alias ), e2.ResolveValue( al.Alias ) )
+    }
 }
 
 func ( a *DefAsserter ) assertCallSig( s1, s2 *CallSignature ) {
@@ -237,11 +301,17 @@ func ( a *DefAsserter ) assertProtoDef(
     p1 *PrototypeDefinition, v2 interface{} ) {
     p2 := a.equalType( p1, v2 ).( *PrototypeDefinition )
     a.descend( "Signature" ).assertCallSig( p1.Signature, p2.Signature )
+    a.descend( "(Annotations)" ).
+        assertAnnotations( p1.Annotations, p2.Annotations )
+    a.descend( "(Doc)" ).Equal( p1.Doc, p2.Doc )
 }
 
 func ( a *DefAsserter ) assertOpDef( od1, od2 *OperationDefinition ) {
     a.descend( "(Name)" ).Equal( od1.Name, od2.Name )
     a.descend( "(Signature" ).assertCallSig( od1.Signature, od2.Signature )
+    a.descend( "(Annotations)" ).
+        assertAnnotations( od1.Annotations, od2.Annotations )
+    a.descend( "(Doc)" ).Equal( od1.Doc, od2.Doc )
 }
 
 func ( a *DefAsserter ) assertOpDefs( 
@@ -262,6 +332,9 @@ func ( a *DefAsserter ) assertServiceDef(
     s2 := a.equalType( s1, v2 ).( *ServiceDefinition )
     a.descend( "(Operations)" ).assertOpDefs( s1.Operations, s2.Operations )
     a.descend( "(Security)" ).Equal( s1.Security, s2.Security )
+    a.descend( "(Annotations)" ).
+        assertAnnotations( s1.Annotations, s2.Annotations )
+    a.descend( "(Doc)" ).Equal( s1.Doc, s2.Doc )
 }
 
 func ( a *DefAsserter ) AssertDef( d1, d2 Definition ) {
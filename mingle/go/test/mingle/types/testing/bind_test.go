@@ -35,7 +35,11 @@ func putCoreBoundTestValues( m *mg.IdentifierMap ) {
     m.Put( mkId( "atomic-type-name-ns1-v1-name1" ), asType( "ns1@v1/Name1" ) )
     m.Put( mkId( "int32-closed-zero-ten-open" ), asType( "Int32~[0,10)" ) )
     m.Put( mkId( "string-a-star" ), asType( `String~"a*"` ) )
+    m.Put( mkId( "string-length-one-ten" ), asType( "String~[1,10]" ) )
     m.Put( mkId( "list-type1-allows-empty" ), asType( "ns1@v1/Name1*" ) )
+    m.Put( 
+        mkId( "list-type1-length-max-ten" ), asType( "ns1@v1/Name1*~(,10]" ) )
+    m.Put( mkId( "map-type1" ), asType( "{ns1@v1/Name1}" ) )
     m.Put( mkId( "pointer-type1" ), asType( "&ns1@v1/Name1" ) )
     m.Put( mkId( "nullable-type1" ), asType( "&ns1@v1/Name1?" ) )
     m.Put( 
@@ -81,6 +85,26 @@ func putTypesBoundTestValues( m *mg.IdentifierMap ) {
             Default: mg.Int32( 1 ),
         },
     )
+    annot1 := types.MakeAnnotation( "annot1" )
+    annot2 := types.MakeAnnotation( "annot2", "a", int32( 1 ) )
+    m.Put( mkId( "annotation1" ), annot1 )
+    m.Put( mkId( "annotation2" ), annot2 )
+    m.Put(
+        mkId( "field-def2" ),
+        &types.FieldDefinition{
+            Name: mkId( "f1" ),
+            Type: mg.TypeInt32,
+            Annotations: []*types.Annotation{ annot1, annot2 },
+        },
+    )
+    m.Put(
+        mkId( "field-def3" ),
+        &types.FieldDefinition{
+            Name: mkId( "f1" ),
+            Type: mg.TypeInt32,
+            Doc: "A field\nover two lines",
+        },
+    )
     m.Put( mkId( "empty-field-set" ), types.NewFieldSet() )
     fieldSet := func( sz int ) *types.FieldSet {
         flds := make( []*types.FieldDefinition, sz )
@@ -119,6 +143,13 @@ func putTypesBoundTestValues( m *mg.IdentifierMap ) {
         return types.MustUnionTypeDefinitionTypes( typs... )
     }
     m.Put( mkId( "union-type-def1" ), unionTypeDef( 2 ) )
+    m.Put(
+        mkId( "union-type-def2" ),
+        types.MustTaggedUnionTypeDefinition(
+            []*mg.Identifier{ mkId( "t0" ), mkId( "t1" ) },
+            []mg.TypeReference{ atomicQnNs1V1Name1, atomicQnNs1V1Name1 },
+        ),
+    )
     m.Put( 
         mkId( "union-def1" ),
         &types.UnionDefinition{ Name: qnNs1V1Name1, Union: unionTypeDef( 2 ) },
@@ -136,6 +167,43 @@ func putTypesBoundTestValues( m *mg.IdentifierMap ) {
     structDef2.Fields = fieldSet( 1 )
     structDef2.Constructors = unionTypeDef( 2 )
     m.Put( mkId( "struct-def2" ), structDef2 )
+    structDef3 := types.NewStructDefinition()
+    structDef3.Name = qnNs1V1Name1
+    structDef3.Fields = fieldSet( 1 )
+    structDef3.Annotations = []*types.Annotation{ annot2 }
+    m.Put( mkId( "struct-def3" ), structDef3 )
+    structDef4 := types.NewStructDefinition()
+    structDef4.Name = qnNs1V1Name1
+    structDef4.Fields = fieldSet( 1 )
+    structDef4.Constraints = []*types.StructConstraint{
+        {
+            Expression: &types.FieldComparison{
+                Field: mkId( "f1" ),
+                Operator: types.ComparisonLess,
+                Other: mkId( "f2" ),
+            },
+        },
+        {
+            Expression: &types.FieldComparison{
+                Field: mkId( "f1" ),
+                Operator: types.ComparisonNotEqual,
+                Value: mg.Int32( 1 ),
+            },
+            Message: "f1 is 1",
+        },
+        {
+            Expression: &types.FieldPresence{
+                Rule: types.PresenceExactlyOne,
+                Fields: []*mg.Identifier{ mkId( "f1" ), mkId( "f2" ) },
+            },
+        },
+    }
+    m.Put( mkId( "struct-def4" ), structDef4 )
+    structDef5 := types.NewStructDefinition()
+    structDef5.Name = qnNs1V1Name1
+    structDef5.Fields = fieldSet( 1 )
+    structDef5.Reserved = []*mg.Identifier{ mkId( "f2" ), mkId( "f3" ) }
+    m.Put( mkId( "struct-def5" ), structDef5 )
     schemaDefEmpty := types.NewSchemaDefinition()
     schemaDefEmpty.Name = qnNs1V1Name1
     m.Put( mkId( "schema-def-empty-fields" ), schemaDefEmpty )
@@ -150,6 +218,14 @@ func putTypesBoundTestValues( m *mg.IdentifierMap ) {
             AliasedType: mg.TypeInt32,
         },
     )
+    m.Put(
+        mkId( "aliased-def2" ),
+        &types.AliasedTypeDefinition{
+            Name: qnNs1V1Name1,
+            AliasedType: mg.TypeInt32,
+            Doc: "An alias",
+        },
+    )
     m.Put( 
         mkId( "enum-def1" ),
         &types.EnumDefinition{
@@ -157,10 +233,52 @@ func putTypesBoundTestValues( m *mg.IdentifierMap ) {
             Values: []*mg.Identifier{ mkId( "v1" ), mkId( "v2" ) },
         },
     )
+    m.Put(
+        mkId( "enum-def2" ),
+        &types.EnumDefinition{
+            Name: qnNs1V1Name1,
+            Values: []*mg.Identifier{ mkId( "v1" ), mkId( "v2" ) },
+            Annotations: []*types.Annotation{ annot1 },
+            ValueAnnotations: []*types.EnumValueAnnotations{
+                { 
+                    Value: mkId( "v2" ), 
+                    Annotations: []*types.Annotation{ annot2 },
+                },
+            },
+        },
+    )
+    m.Put(
+        mkId( "enum-def3" ),
+        &types.EnumDefinition{
+            Name: qnNs1V1Name1,
+            Values: []*mg.Identifier{ mkId( "v1" ), mkId( "v2" ) },
+            Doc: "An enum",
+            ValueAnnotations: []*types.EnumValueAnnotations{
+                { Value: mkId( "v1" ), Doc: "The first value" },
+            },
+        },
+    )
+    m.Put(
+        mkId( "enum-def4" ),
+        &types.EnumDefinition{
+            Name: qnNs1V1Name1,
+            Values: []*mg.Identifier{ mkId( "v1" ), mkId( "v2" ) },
+            Ordinals: []*types.EnumValueOrdinal{
+                { Value: mkId( "v1" ), Ordinal: 1 },
+                { Value: mkId( "v2" ), Ordinal: -2 },
+            },
+            Aliases: []*types.EnumValueAlias{
+                { Alias: mkId( "v3" ), Value: mkId( "v1" ) },
+            },
+        },
+    )
     opDef := func( nm string ) *types.OperationDefinition {
         return types.MakeOpDef( nm, callSig2() )
     }
     m.Put( mkId( "op-def1" ), opDef( "op1" ) )
+    opDef2 := opDef( "op1" )
+    opDef2.Annotations = []*types.Annotation{ annot1 }
+    m.Put( mkId( "op-def2" ), opDef2 )
     m.Put(
         mkId( "service-def1" ),
         types.MakeServiceDef( qnNs1V1Name1.ExternalForm(), "" ),
@@ -706,6 +824,19 @@ func ( b *bindTestBuilder ) addAtomicTypeReferenceTests() {
         mg.TypeAtomicTypeReference,
         "string-a-star",
     )
+    b.addRt(
+        parser.MustStruct( mg.QnameAtomicTypeReference,
+            "name", stringQn,
+            "restriction", parser.MustStruct( mg.QnameLengthRestriction,
+                "min-closed", true,
+                "min", mg.Int64( 1 ),
+                "max", mg.Int64( 10 ),
+                "max-closed", true,
+            ),
+        ),
+        mg.TypeAtomicTypeReference,
+        "string-length-one-ten",
+    )
     b.addTest(
         &bind.BindTest{
             Mingle: parser.MustStruct( mg.QnameAtomicTypeReference,
@@ -740,6 +871,29 @@ func ( b *bindTestBuilder ) addListTypeReferenceTests() {
         mg.TypeListTypeReference,
         "list-type1-allows-empty",
     )
+    b.addRt(
+        parser.MustStruct( mg.QnameListTypeReference,
+            "element-type", b.atomicQnNs1V1Name1(),
+            "allows-empty", true,
+            "restriction", parser.MustStruct( mg.QnameLengthRestriction,
+                "min-closed", false,
+                "max", mg.Int64( 10 ),
+                "max-closed", true,
+            ),
+        ),
+        mg.TypeListTypeReference,
+        "list-type1-length-max-ten",
+    )
+}
+
+func ( b *bindTestBuilder ) addMapTypeReferenceTests() {
+    b.addRt(
+        parser.MustStruct( mg.QnameMapTypeReference,
+            "value-type", b.atomicQnNs1V1Name1(),
+        ),
+        mg.TypeMapTypeReference,
+        "map-type1",
+    )
 }
 
 func ( b *bindTestBuilder ) addPointerTypeReferenceTests() {
@@ -774,6 +928,34 @@ func ( b *bindTestBuilder ) addPrimitiveDefinitionTests() {
     )
 }
 
+func ( b *bindTestBuilder ) annotation1() *mg.Struct {
+    return parser.MustStruct( builtin.QnameAnnotation,
+        "name", makeIdStruct( "annot1" ),
+    )
+}
+
+func ( b *bindTestBuilder ) annotation2() *mg.Struct {
+    return parser.MustStruct( builtin.QnameAnnotation,
+        "name", makeIdStruct( "annot2" ),
+        "arguments", mg.MustList( asType( "mingle:core@v1/Value*" ),
+            mg.String( "a" ),
+            mg.Int32( 1 ),
+        ),
+    )
+}
+
+func ( b *bindTestBuilder ) annotations( annots ...*mg.Struct ) *mg.List {
+    res := mg.NewList( 
+        asType( "&mingle:types@v1/Annotation*" ).( *mg.ListTypeReference ) )
+    for _, annot := range annots { res.AddUnsafe( annot ) }
+    return res
+}
+
+func ( b *bindTestBuilder ) addAnnotationTests() {
+    b.addRt( b.annotation1(), builtin.TypeAnnotation, "annotation1" )
+    b.addRt( b.annotation2(), builtin.TypeAnnotation, "annotation2" )
+}
+
 func ( b *bindTestBuilder ) fieldDef( i int ) *mg.Struct {
     return parser.MustStruct( builtin.QnameFieldDefinition,
         "name", makeIdStruct( fmt.Sprintf( "f%d", i ) ),
@@ -794,6 +976,28 @@ func ( b *bindTestBuilder ) addFieldDefinitionTests() {
         "field-def0",
     )
     b.addRt( b.fieldDef( 1 ), builtin.TypeFieldDefinition, "field-def1" )
+    b.addRt(
+        parser.MustStruct( builtin.QnameFieldDefinition,
+            "name", makeIdStruct( "f1" ),
+            "type", parser.MustStruct( mg.QnameAtomicTypeReference,
+                "name", b.coreQn( "Int32" ),
+            ),
+            "annotations", b.annotations( b.annotation1(), b.annotation2() ),
+        ),
+        builtin.TypeFieldDefinition,
+        "field-def2",
+    )
+    b.addRt(
+        parser.MustStruct( builtin.QnameFieldDefinition,
+            "name", makeIdStruct( "f1" ),
+            "type", parser.MustStruct( mg.QnameAtomicTypeReference,
+                "name", b.coreQn( "Int32" ),
+            ),
+            "doc", "A field\nover two lines",
+        ),
+        builtin.TypeFieldDefinition,
+        "field-def3",
+    )
 }
 
 func ( b *bindTestBuilder ) fieldSet( sz int ) *mg.Struct {
@@ -856,6 +1060,44 @@ func ( b *bindTestBuilder ) addUnionDefinitionTests() {
         builtin.TypeUnionTypeDefinition,
         mg.NewInputError( nil, "union contains one or more ambiguous types" ),
     )
+    idListTyp := 
+        asType( "&mingle:core@v1/Identifier+" ).( *mg.ListTypeReference )
+    sameTypes := func( sz int ) *mg.List {
+        lt := asType( "mingle:core@v1/TypeReference+" )
+        l := mg.NewList( lt.( *mg.ListTypeReference ) )
+        for i := 0; i < sz; i++ { l.AddUnsafe( b.atomicQnNs1V1Name1() ) }
+        return l
+    }
+    b.addRt(
+        parser.MustStruct( builtin.QnameUnionTypeDefinition,
+            "types", sameTypes( 2 ),
+            "tags", mg.MustList( idListTyp, 
+                makeIdStruct( "t0" ), 
+                makeIdStruct( "t1" ),
+            ),
+        ),
+        builtin.TypeUnionTypeDefinition,
+        "union-type-def2",
+    )
+    b.addInErr(
+        parser.MustStruct( builtin.QnameUnionTypeDefinition,
+            "types", sameTypes( 2 ),
+            "tags", mg.MustList( idListTyp, 
+                makeIdStruct( "t0" ), 
+                makeIdStruct( "t0" ),
+            ),
+        ),
+        builtin.TypeUnionTypeDefinition,
+        mg.NewInputError( nil, "duplicate union tag: t0" ),
+    )
+    b.addInErr(
+        parser.MustStruct( builtin.QnameUnionTypeDefinition,
+            "types", sameTypes( 2 ),
+            "tags", mg.MustList( idListTyp, makeIdStruct( "t0" ) ),
+        ),
+        builtin.TypeUnionTypeDefinition,
+        mg.NewInputError( nil, "union has 1 tags for 2 types" ),
+    )
 }
 
 func ( b *bindTestBuilder ) callSig2() *mg.Struct {
@@ -907,6 +1149,102 @@ func ( b *bindTestBuilder ) addStructDefinitionTests() {
         builtin.TypeStructDefinition,
         "struct-def2",
     )
+    b.addRt(
+        parser.MustStruct( builtin.QnameStructDefinition,
+            "name", b.qnNs1V1Name1(),
+            "fields", b.fieldSet( 1 ),
+            "annotations", b.annotations( b.annotation2() ),
+        ),
+        builtin.TypeStructDefinition,
+        "struct-def3",
+    )
+    idListTyp := 
+        asType( "&mingle:core@v1/Identifier+" ).( *mg.ListTypeReference )
+    cstrsTyp := asType( "&mingle:types@v1/StructConstraint*" )
+    comparison := func( pairs ...interface{} ) *mg.Struct {
+        return parser.MustStruct( builtin.QnameFieldComparison, pairs... )
+    }
+    constraint := func( expr *mg.Struct, pairs ...interface{} ) *mg.Struct {
+        pairs = append( []interface{}{ "expression", expr }, pairs... )
+        return parser.MustStruct( builtin.QnameStructConstraint, pairs... )
+    }
+    structDef4 := func( cstrs ...interface{} ) *mg.Struct {
+        return parser.MustStruct( builtin.QnameStructDefinition,
+            "name", b.qnNs1V1Name1(),
+            "fields", b.fieldSet( 1 ),
+            "constraints", mg.MustList( append( []interface{}{ cstrsTyp }, 
+                cstrs... )... ),
+        )
+    }
+    b.addRt(
+        structDef4(
+            constraint(
+                comparison(
+                    "field", makeIdStruct( "f1" ),
+                    "operator", "<",
+                    "other", makeIdStruct( "f2" ),
+                ),
+            ),
+            constraint(
+                comparison(
+                    "field", makeIdStruct( "f1" ),
+                    "operator", "!=",
+                    "value", int32( 1 ),
+                ),
+                "message", "f1 is 1",
+            ),
+            constraint(
+                parser.MustStruct( builtin.QnameFieldPresence,
+                    "rule", "exactly-one",
+                    "fields", mg.MustList( idListTyp,
+                        makeIdStruct( "f1" ),
+                        makeIdStruct( "f2" ),
+                    ),
+                ),
+            ),
+        ),
+        builtin.TypeStructDefinition,
+        "struct-def4",
+    )
+    b.addRt(
+        parser.MustStruct( builtin.QnameStructDefinition,
+            "name", b.qnNs1V1Name1(),
+            "fields", b.fieldSet( 1 ),
+            "reserved", mg.MustList( idListTyp,
+                makeIdStruct( "f2" ),
+                makeIdStruct( "f3" ),
+            ),
+        ),
+        builtin.TypeStructDefinition,
+        "struct-def5",
+    )
+    b.addInErr(
+        comparison(
+            "field", makeIdStruct( "f1" ),
+            "operator", "=",
+            "other", makeIdStruct( "f2" ),
+        ),
+        builtin.TypeFieldComparison,
+        mg.NewInputError( nil, `invalid operator: "="` ),
+    )
+    b.addInErr(
+        comparison(
+            "field", makeIdStruct( "f1" ),
+            "operator", "<",
+            "other", makeIdStruct( "f2" ),
+            "value", int32( 1 ),
+        ),
+        builtin.TypeFieldComparison,
+        mg.NewInputError( nil, "exactly one of other or value must be set" ),
+    )
+    b.addInErr(
+        parser.MustStruct( builtin.QnameFieldPresence,
+            "rule", "exactly-two",
+            "fields", mg.MustList( idListTyp, makeIdStruct( "f1" ) ),
+        ),
+        builtin.TypeFieldPresence,
+        mg.NewInputError( nil, `invalid rule: "exactly-two"` ),
+    )
 }
 
 func ( b *bindTestBuilder ) addSchemaDefinitionTests() {
@@ -939,6 +1277,17 @@ func ( b *bindTestBuilder ) addAliasedTypeDefinition() {
         builtin.TypeAliasedTypeDefinition,
         "aliased-def1",
     )
+    b.addRt(
+        parser.MustStruct( builtin.QnameAliasedTypeDefinition,
+            "name", b.qnNs1V1Name1(),
+            "aliased-type", parser.MustStruct( mg.QnameAtomicTypeReference,
+                "name", b.coreQn( "Int32" ),
+            ),
+            "doc", "An alias",
+        ),
+        builtin.TypeAliasedTypeDefinition,
+        "aliased-def2",
+    )
 }
 
 func ( b *bindTestBuilder ) addEnumDefinition() {
@@ -955,6 +1304,92 @@ func ( b *bindTestBuilder ) addEnumDefinition() {
         builtin.TypeEnumDefinition,
         "enum-def1",
     )
+    valAnnotsTyp := asType( "&mingle:types@v1/EnumValueAnnotations*" )
+    b.addRt(
+        parser.MustStruct( builtin.QnameEnumDefinition,
+            "name", b.qnNs1V1Name1(),
+            "values", mg.MustList( idListTyp, 
+                makeIdStruct( "v1" ), 
+                makeIdStruct( "v2" ),
+            ),
+            "annotations", b.annotations( b.annotation1() ),
+            "value-annotations", mg.MustList( valAnnotsTyp,
+                parser.MustStruct( builtin.QnameEnumValueAnnotations,
+                    "value", makeIdStruct( "v2" ),
+                    "annotations", b.annotations( b.annotation2() ),
+                ),
+            ),
+        ),
+        builtin.TypeEnumDefinition,
+        "enum-def2",
+    )
+    b.addRt(
+        parser.MustStruct( builtin.QnameEnumDefinition,
+            "name", b.qnNs1V1Name1(),
+            "values", mg.MustList( idListTyp, 
+                makeIdStruct( "v1" ), 
+                makeIdStruct( "v2" ),
+            ),
+            "doc", "An enum",
+            "value-annotations", mg.MustList( valAnnotsTyp,
+                parser.MustStruct( builtin.QnameEnumValueAnnotations,
+                    "value", makeIdStruct( "v1" ),
+                    "doc", "The first value",
+                ),
+            ),
+        ),
+        builtin.TypeEnumDefinition,
+        "enum-def3",
+    )
+    ordsTyp := asType( "&mingle:types@v1/EnumValueOrdinal*" )
+    aliasesTyp := asType( "&mingle:types@v1/EnumValueAlias*" )
+    ordinal := func( val string, ord int32 ) *mg.Struct {
+        return parser.MustStruct( builtin.QnameEnumValueOrdinal,
+            "value", makeIdStruct( val ),
+            "ordinal", ord,
+        )
+    }
+    alias := func( al, val string ) *mg.Struct {
+        return parser.MustStruct( builtin.QnameEnumValueAlias,
+            "alias", makeIdStruct( al ),
+            "value", makeIdStruct( val ),
+        )
+    }
+    enumDef4 := func( ords, aliases *mg.List ) *mg.Struct {
+        return parser.MustStruct( builtin.QnameEnumDefinition,
+            "name", b.qnNs1V1Name1(),
+            "values", mg.MustList( idListTyp, 
+                makeIdStruct( "v1" ), 
+                makeIdStruct( "v2" ),
+            ),
+            "ordinals", ords,
+            "aliases", aliases,
+        )
+    }
+    b.addRt(
+        enumDef4(
+            mg.MustList( ordsTyp, ordinal( "v1", 1 ), ordinal( "v2", -2 ) ),
+            mg.MustList( aliasesTyp, alias( "v3", "v1" ) ),
+        ),
+        builtin.TypeEnumDefinition,
+        "enum-def4",
+    )
+    b.addInErr(
+        enumDef4(
+            mg.MustList( ordsTyp, ordinal( "v1", 1 ), ordinal( "v2", 1 ) ),
+            mg.MustList( aliasesTyp ),
+        ),
+        builtin.TypeEnumDefinition,
+        mg.NewInputError( nil, "duplicate enum ordinal: 1" ),
+    )
+    b.addInErr(
+        enumDef4(
+            mg.MustList( ordsTyp ),
+            mg.MustList( aliasesTyp, alias( "v2", "v1" ) ),
+        ),
+        builtin.TypeEnumDefinition,
+        mg.NewInputError( nil, "enum alias is already a value or alias: v2" ),
+    )
     b.addInErr(
         parser.MustStruct( builtin.QnameEnumDefinition,
             "name", b.qnNs1V1Name1(),
@@ -977,6 +1412,15 @@ func ( b *bindTestBuilder ) opDef( opNm string ) *mg.Struct {
 
 func ( b *bindTestBuilder ) addOperationDefinitionTests() {
     b.addRt( b.opDef( "op1" ), builtin.TypeOperationDefinition, "op-def1" )
+    b.addRt(
+        parser.MustStruct( builtin.QnameOperationDefinition,
+            "name", makeIdStruct( "op1" ),
+            "signature", b.callSig2(),
+            "annotations", b.annotations( b.annotation1() ),
+        ),
+        builtin.TypeOperationDefinition,
+        "op-def2",
+    )
 }
 
 func ( b *bindTestBuilder ) addServiceDefinitionTests() {
@@ -1022,12 +1466,14 @@ func ( b *bindTestBuilder ) addCoreBindTests() {
     b.addCoreErrorTests()
     b.addAtomicTypeReferenceTests()
     b.addListTypeReferenceTests()
+    b.addMapTypeReferenceTests()
     b.addPointerTypeReferenceTests()
     b.addNullableTypeReferenceTests()
 }
 
 func ( b *bindTestBuilder ) addTypesBindTests() {
     b.addPrimitiveDefinitionTests()
+    b.addAnnotationTests()
     b.addFieldDefinitionTests()
     b.addFieldSetTests()
     b.addUnionDefinitionTests()
@@ -159,7 +159,7 @@ func getUnionDefinitionTests() []*unionTypeDefinitionTest {
                 mg.TypeInt32,
                 mg.TypeUint32,
                 mg.NewPointerTypeReference( mg.TypeString ),
-                &mg.ListTypeReference{ mg.TypeString, false },
+                &mg.ListTypeReference{ ElementType: mg.TypeString },
                 mg.MustNullableTypeReference( mg.TypeSymbolMap ),
             },
         },
@@ -174,13 +174,20 @@ func getUnionDefinitionTests() []*unionTypeDefinitionTest {
                 mg.NewPointerTypeReference( mg.TypeInt64 ),
                 mg.MustNullableTypeReference(
                     mg.NewPointerTypeReference( mg.TypeInt64 ) ),
-                &mg.ListTypeReference{ mg.TypeInt32, true },
-                &mg.ListTypeReference{ mg.TypeInt32, false },
+                &mg.ListTypeReference{
+                    ElementType: mg.TypeInt32,
+                    AllowsEmpty: true,
+                },
+                &mg.ListTypeReference{ ElementType: mg.TypeInt32 },
                 mg.NewPointerTypeReference(
-                    &mg.ListTypeReference{ mg.TypeInt32, true } ),
+                    &mg.ListTypeReference{
+                        ElementType: mg.TypeInt32,
+                        AllowsEmpty: true,
+                    },
+                ),
                 &mg.ListTypeReference{
-                    mg.NewPointerTypeReference( mg.TypeInt32 ), 
-                    true,
+                    ElementType: mg.NewPointerTypeReference( mg.TypeInt32 ), 
+                    AllowsEmpty: true,
                 },
             },
             errGroups: [][]int{
@@ -215,6 +222,111 @@ func TestCreateEnumDefinitionError( t *testing.T ) {
         EnumDefinitionError( "duplicate enum value(s): v1, v2" ), err )
 }
 
+func TestEnumOrdinalsAndAliases( t *testing.T ) {
+    a := assert.Asserter{ t }
+    mkDef := func() *EnumDefinition {
+        res := MakeEnumDef( "ns1@v1/E1", "v1", "v2", "v3" )
+        res.Ordinals = []*EnumValueOrdinal{
+            { Value: mkId( "v1" ), Ordinal: 1 },
+            { Value: mkId( "v3" ), Ordinal: -3 },
+        }
+        res.Aliases = []*EnumValueAlias{
+            { Alias: mkId( "a1" ), Value: mkId( "v1" ) },
+            { Alias: mkId( "a2" ), Value: mkId( "v1" ) },
+        }
+        return res
+    }
+    ed := mkDef()
+    a.Equal( nil, ed.CheckValueNames() )
+    a.Equal( mkId( "v1" ), ed.ResolveValue( mkId( "a2" ) ).Value )
+    a.Equal( mkId( "v2" ), ed.ResolveValue( mkId( "v2" ) ).Value )
+    a.True( ed.ResolveValue( mkId( "a3" ) ) == nil )
+    a.True( ed.GetValue( mkId( "a1" ) ) == nil )
+    ord, ok := ed.GetOrdinal( mkId( "v3" ) )
+    a.Equal( int32( -3 ), ord )
+    a.True( ok )
+    _, ok = ed.GetOrdinal( mkId( "v2" ) )
+    a.False( ok )
+    a.Equal( mkId( "v1" ), ed.GetValueForOrdinal( 1 ).Value )
+    a.True( ed.GetValueForOrdinal( 2 ) == nil )
+    la := assert.NewListPathAsserter( t )
+    for _, test := range []struct { 
+        f func( ed *EnumDefinition ) 
+        msg string 
+    }{
+        {
+            func( ed *EnumDefinition ) { ed.Ordinals[ 1 ].Ordinal = 1 },
+            "duplicate enum ordinal: 1",
+        },
+        {
+            func( ed *EnumDefinition ) { 
+                ed.Ordinals[ 1 ].Value = mkId( "v1" ) 
+            },
+            "multiple ordinals for enum value: v1",
+        },
+        {
+            func( ed *EnumDefinition ) { 
+                ed.Ordinals[ 1 ].Value = mkId( "v4" ) 
+            },
+            "ordinal for unknown enum value: v4",
+        },
+        {
+            func( ed *EnumDefinition ) { ed.Aliases[ 1 ].Value = mkId( "v4" ) },
+            "alias a2 for unknown enum value: v4",
+        },
+        {
+            func( ed *EnumDefinition ) { ed.Aliases[ 1 ].Alias = mkId( "v2" ) },
+            "enum alias is already a value or alias: v2",
+        },
+        {
+            func( ed *EnumDefinition ) { ed.Aliases[ 1 ].Alias = mkId( "a1" ) },
+            "enum alias is already a value or alias: a1",
+        },
+    } {
+        ed := mkDef()
+        test.f( ed )
+        la.EqualErrors( EnumDefinitionError( test.msg ), ed.CheckValueNames() )
+        la = la.Next()
+    }
+}
+
+func TestStructConstraints( t *testing.T ) {
+    a := assert.Asserter{ t }
+    fc := &FieldComparison{ 
+        Field: mkId( "f1" ), 
+        Operator: ComparisonLess, 
+        Other: mkId( "f2" ),
+    }
+    a.Equal( "f1 < f2", fc.ExternalForm() )
+    fc.Other, fc.Value = nil, mg.String( "a" )
+    a.Equal( `f1 < "a"`, fc.ExternalForm() )
+    fp := &FieldPresence{ 
+        Rule: PresenceAtMostOne, 
+        Fields: []*mg.Identifier{ mkId( "f1" ), mkId( "f2" ) },
+    }
+    a.Equal( "atMostOne( f1, f2 )", fp.ExternalForm() )
+    sc := &StructConstraint{ Expression: fp }
+    a.Equal( "constraint not satisfied: atMostOne( f1, f2 )", 
+        sc.FailureMessage() )
+    sc.Message = "too many"
+    a.Equal( "too many", sc.FailureMessage() )
+    a.Equal( ComparisonGreaterOrEqual, ComparisonLessOrEqual.Reverse() )
+    a.Equal( ComparisonNotEqual, ComparisonNotEqual.Reverse() )
+    a.False( ComparisonEqual.IsOrdering() )
+    a.True( ComparisonLess.IsOrdering() )
+    for _, tt := range []struct { r PresenceRule; n int; ok bool } {
+        { PresenceExactlyOne, 0, false },
+        { PresenceExactlyOne, 1, true },
+        { PresenceExactlyOne, 2, false },
+        { PresenceAtMostOne, 0, true },
+        { PresenceAtMostOne, 2, false },
+        { PresenceAtLeastOne, 0, false },
+        { PresenceAtLeastOne, 2, true },
+    } {
+        a.Equalf( tt.ok, tt.r.Accepts( tt.n ), "%s( %d )", tt.r, tt.n )
+    }
+}
+
 func TestUnionTypeDefinitionMatch( t *testing.T ) {
     ut := MustUnionTypeDefinitionTypes(
         asType( "Int32" ),
@@ -244,3 +356,48 @@ func TestUnionTypeDefinitionMatch( t *testing.T ) {
     chk( "&&ns1@v1/S1++", "ns1@v1/S1*+", true )
     chk( "ns1@v1/E1", nil, false )
 }
+
+func TestTaggedUnionTypeDefinition( t *testing.T ) {
+    a := assert.NewPathAsserter( t )
+    mkTags := func( strs ...string ) []*mg.Identifier {
+        res := make( []*mg.Identifier, len( strs ) )
+        for i, str := range strs { res[ i ] = mkId( str ) }
+        return res
+    }
+    typs := []mg.TypeReference{ mg.TypeString, mg.TypeString, mg.TypeInt32 }
+    ut := MustTaggedUnionTypeDefinition( mkTags( "t0", "t1", "t2" ), typs )
+    a.True( ut.IsTagged() )
+    a.False( MustUnionTypeDefinitionTypes( mg.TypeString ).IsTagged() )
+    chk := func( tag string, expct mg.TypeReference ) {
+        act, ok := ut.MatchTag( mkId( tag ) )
+        a.Descend( tag ).Equal( expct != nil, ok )
+        if ok { a.Descend( tag ).Equal( expct, act ) }
+    }
+    chk( "t0", mg.TypeString )
+    chk( "t2", mg.TypeInt32 )
+    chk( "t3", nil )
+    _, err := CreateTaggedUnionTypeDefinition( 
+        mkTags( "t0", "t1", "t0" ), typs )
+    a.Equal( "duplicate union tag: t0", err.Error() )
+}
+
+func TestDeprecationMessage( t *testing.T ) {
+    a := assert.NewListPathAsserter( t )
+    chk := func( annots []*Annotation, expct string, expctOk bool ) {
+        msg, ok := DeprecationMessage( annots )
+        a.Equal( expctOk, ok )
+        a.Equal( expct, msg )
+        a = a.Next()
+    }
+    other := &Annotation{ Name: mkId( "other" ) }
+    chk( nil, "", false )
+    chk( []*Annotation{ other }, "", false )
+    chk( []*Annotation{ other, { Name: IdDeprecated } }, "", true )
+    chk( 
+        []*Annotation{ 
+            { Name: IdDeprecated, Arguments: []mg.Value{ mg.String( "m" ) } },
+        },
+        "m", 
+        true,
+    )
+}
@@ -140,6 +140,14 @@ func listTypeExpressionAsStruct( e *parser.ListTypeExpression ) *mg.Struct {
         "expression", asValue( e.Expression ),
         "loc", asValue( e.Loc ),
         "allows-empty", e.AllowsEmpty,
+        "restriction", asValue( e.Restriction ),
+    )
+}
+
+func mapTypeExpressionAsStruct( e *parser.MapTypeExpression ) *mg.Struct {
+    return mustStruct( ptTyp( "MapTypeExpression" ),
+        "expression", asValue( e.Expression ),
+        "loc", asValue( e.Loc ),
     )
 }
 
@@ -198,6 +206,7 @@ func asValue( val interface{} ) mg.Value {
     case *parser.RangeRestrictionSyntax:
         return rangeRestrictionSyntaxAsStruct( v )
     case *parser.ListTypeExpression: return listTypeExpressionAsStruct( v )
+    case *parser.MapTypeExpression: return mapTypeExpressionAsStruct( v )
     case *parser.PointerTypeExpression: return ptrTypeExpressionAsStruct( v )
     case *parser.NullableTypeExpression:
         return nullableTypeExpressionAsStruct( v )
@@ -267,8 +267,18 @@ func ( td testData ) StructAt( i int ) *mg.Struct {
     return asValue( td[ i ] ).( *mg.Struct )
 }
 
+// Tests of reactors which exist only in the go implementation are kept out of
+// the shared test data
 func getReactorTests() []mgRct.ReactorTest {
-    res := mgRct.GetReactorTests()
+    res := make( []mgRct.ReactorTest, 0, 512 )
+    for _, t := range mgRct.GetReactorTests() {
+        switch t.( type ) {
+        case *mgRct.FieldMaskReactorTest, *mgRct.LimitReactorTest,
+             *mgRct.MigrationReactorTest, *mgRct.RedactionReactorTest:
+            continue
+        }
+        res = append( res, t )
+    }
     return res
 }
 